@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,10 +18,19 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"go-favorites-app/internal/adapter/api/rest"
+	"go-favorites-app/internal/adapter/cache/guard"
+	memcache "go-favorites-app/internal/adapter/cache/memory"
 	"go-favorites-app/internal/adapter/cache/redis"
+	"go-favorites-app/internal/adapter/enricher"
+	"go-favorites-app/internal/adapter/enricher/metadata"
+	"go-favorites-app/internal/adapter/mailer/memory"
+	"go-favorites-app/internal/adapter/mailer/smtp"
 	repo "go-favorites-app/internal/adapter/storage/postgres"
+	"go-favorites-app/internal/adapter/webhook"
 	"go-favorites-app/internal/config"
+	"go-favorites-app/internal/core/domain/auth"
 	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
 	"go-favorites-app/internal/core/service"
 	"go-favorites-app/internal/observability"
 )
@@ -40,6 +53,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Admin commands
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(cfg, logger, os.Args[2:]))
+	}
+
 	ctx := context.Background()
 
 	// Init Tracing
@@ -72,28 +90,108 @@ func main() {
 	observability.StartDBStatsCollector(dbPool)
 
 	// Init Cache
-	redisAdapter := redis.NewAdapter(cfg.RedisAddr)
-	// Wrap with metrics
-	cacheSvc := observability.NewInstrumentedCache(redisAdapter)
+	redisOpts, err := redisOptions(cfg)
+	if err != nil {
+		logger.Error("failed to configure redis", "error", err)
+		os.Exit(1)
+	}
+	redisAdapter := redis.NewAdapter(redisOpts)
+	// Wrap with metrics, and bypass Redis while it is failing
+	cacheGuard := guard.NewCache(observability.NewInstrumentedCache(redisAdapter), guard.Config{
+		Timeout:          cfg.CacheTimeout,
+		FailureThreshold: cfg.CacheBreakerThreshold,
+		Cooldown:         cfg.CacheBreakerCooldown,
+		MaxPending:       cfg.CacheMaxPendingInvalidations,
+	}, logger)
+	go cacheGuard.Run(ctx)
+	var cacheSvc ports.Cache = cacheGuard
+	// In-process tier in front of Redis
+	if cfg.CacheL1Size > 0 {
+		l1 := memcache.NewCache(cacheSvc, redisAdapter.Invalidations(), memcache.Config{
+			Size:   cfg.CacheL1Size,
+			TTL:    cfg.CacheL1TTL,
+			Jitter: cfg.CacheL1Jitter,
+		}, logger)
+		go l1.Listen(ctx)
+		cacheSvc = l1
+	}
 
-	// Init Service
-	// Mock Enricher for now
-	enricher := &NoOpEnricher{}
+	// Enrichment Pipeline
+	enrichers := newEnricherRegistry(cfg, logger)
 
 	// Repository Init
 	favRepo := repo.NewRepository(dbPool)
+	enrichmentQueue := repo.NewEnrichmentQueue(dbPool)
 	userRepo := repo.NewUserRepository(dbPool)
+	sessionRepo := repo.NewSessionRepository(dbPool)
+
+	// Mailer Init
+	var mailer ports.Mailer = memory.NewMailer(logger)
+	if cfg.Mailer == "smtp" {
+		mailer = smtp.NewMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+
+	// Password Policy
+	passwordPolicy, err := loadPasswordPolicy(cfg)
+	if err != nil {
+		logger.Error("failed to load password policy", "error", err)
+		os.Exit(1)
+	}
 
 	// Service Init
-	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret)
-	favSvc := service.NewService(favRepo, cacheSvc, enricher, logger)
+	auditSvc := service.NewAuditService(repo.NewAuditLog(dbPool), userRepo, logger)
+	authSvc := service.NewAuthService(userRepo, sessionRepo, mailer, service.AuthConfig{
+		JWTSecret:            cfg.JWTSecret,
+		BaseURL:              cfg.BaseURL,
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+		PasswordPolicy:       passwordPolicy,
+	}).WithAudit(auditSvc).WithSessionCache(redisAdapter.Sessions(), logger)
+	favSvc := service.NewService(favRepo, cacheSvc, enrichers, enrichmentQueue, logger).
+		WithAudit(auditSvc).
+		WithQuota(quota(cfg))
+	if cfg.CacheFillLock {
+		favSvc.WithFillLock(redisAdapter.FillLocks(), cfg.CacheFillLockTTL)
+	}
+	accountSvc := service.NewAccountService(userRepo, favRepo, repo.NewAccountStore(dbPool), cacheSvc, logger).
+		WithSessionCache(redisAdapter.Sessions()).
+		WithIdempotency(redisAdapter.Idempotency())
+	sessionSvc := service.NewSessionService(sessionRepo, redisAdapter.Sessions(), logger)
+	webhookSvc := service.NewWebhookService(repo.NewWebhookRepository(dbPool), webhook.NewSender(webhook.Config{
+		Timeout:              cfg.WebhookTimeout,
+		AllowPrivateNetworks: cfg.WebhookAllowPrivateNetworks,
+	}), logger)
+	feedSvc := service.NewFeed(redisAdapter.Feed(int64(cfg.FeedHistorySize), cfg.FeedHistoryTTL), logger)
 
 	// Init Handlers
-	favHandler := rest.NewHandler(favSvc, logger)
+	favHandler := rest.NewHandler(favSvc, logger).WithMaxBatchItems(cfg.BatchMaxItems)
 	authHandler := rest.NewAuthHandler(authSvc)
+	accountHandler := rest.NewAccountHandler(accountSvc, authSvc, logger)
+	sessionHandler := rest.NewSessionHandler(sessionSvc, logger)
+	webhookHandler := rest.NewWebhookHandler(webhookSvc, logger)
+	feedHandler := rest.NewFeedHandler(feedSvc, cfg.FeedHeartbeatInterval, logger)
+	auditHandler := rest.NewAuditHandler(auditSvc, logger)
 
 	// Init Router
-	router := rest.NewRouter(favHandler, authHandler, cfg.JWTSecret, rest.RequestID, rest.Logger(logger), observability.Middleware)
+	router := rest.NewRouter(rest.Handlers{
+		Favorites: favHandler,
+		Auth:      authHandler,
+		Account:   accountHandler,
+		Sessions:  sessionHandler,
+		Webhooks:  webhookHandler,
+		Feed:      feedHandler,
+		Audit:     auditHandler,
+		RateLimits: rest.NewRateLimits(rateLimiter(cfg, redisAdapter, logger), map[rest.RouteClass]ports.Rate{
+			rest.RouteClassAuth:  {Limit: cfg.RateLimitAuth, Period: cfg.RateLimitPeriod},
+			rest.RouteClassRead:  {Limit: cfg.RateLimitRead, Period: cfg.RateLimitPeriod},
+			rest.RouteClassWrite: {Limit: cfg.RateLimitWrite, Period: cfg.RateLimitPeriod},
+		}, logger),
+		Idempotency: rest.NewIdempotency(redisAdapter.Idempotency(), cfg.IdempotencyTTL, logger),
+		Health: rest.NewHealthHandler(logger,
+			rest.HealthCheck{Name: "database", Check: dbPool.Ping, Critical: true},
+			rest.HealthCheck{Name: "cache", Check: cacheGuard.Check},
+		),
+	}, cfg.JWTSecret, rest.RequestID, rest.ClientIP(cfg.TrustedProxies), rest.RequestMetadata, rest.Logger(logger), observability.Middleware,
+		rest.MaxBodySize(cfg.MaxRequestBodyBytes))
 
 	// Add /metrics endpoint
 	// Note: Usually /metrics is on a separate admin port or protected, adding to main mux for simplicity
@@ -106,6 +204,68 @@ func main() {
 		Handler: mux,
 	}
 
+	// Event streams never go idle, so Shutdown would wait for them until its
+	// deadline; stopping the feed ends them and clients reconnect elsewhere.
+	feedCtx, stopFeed := context.WithCancel(ctx)
+	srv.RegisterOnShutdown(stopFeed)
+	go feedSvc.Run(feedCtx)
+
+	// Background Enrichment (ADR 002)
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	workers.Go(func() {
+		favSvc.RunEnrichmentWorkers(workerCtx, service.EnrichmentWorkerConfig{
+			Workers:     cfg.EnrichmentWorkers,
+			JobTimeout:  cfg.EnrichmentJobTimeout,
+			MaxAttempts: cfg.EnrichmentMaxAttempts,
+		})
+	})
+	workers.Go(func() {
+		favSvc.RunReenrichmentScheduler(workerCtx, repo.NewAdvisoryLock(dbPool, "favorites:reenrichment"), service.ReenrichmentConfig{
+			Interval:  cfg.EnrichmentRefreshInterval,
+			MaxAge:    cfg.EnrichmentMaxAge,
+			BatchSize: cfg.EnrichmentRefreshBatchSize,
+		})
+	})
+	// Outbox relay: cache invalidation is retried from the outbox rather
+	// than queued in memory by the guard.
+	relay := service.NewOutboxRelay(repo.NewOutbox(dbPool), logger)
+	relay.Subscribe("cache", service.NewCacheInvalidator(invalidatingCache(cfg, redisAdapter, logger)))
+	relay.Subscribe("webhooks", webhookSvc)
+	relay.Subscribe("feed", feedSvc)
+	workers.Go(func() {
+		relay.Run(workerCtx, service.OutboxRelayConfig{
+			PollInterval: cfg.OutboxPollInterval,
+			BatchSize:    cfg.OutboxBatchSize,
+			MaxAttempts:  cfg.OutboxMaxAttempts,
+		})
+	})
+	workers.Go(func() {
+		webhookSvc.RunDeliveryWorkers(workerCtx, service.WebhookWorkerConfig{
+			Workers:     cfg.WebhookWorkers,
+			Lease:       2 * cfg.WebhookTimeout,
+			MaxAttempts: cfg.WebhookMaxAttempts,
+		})
+	})
+	// Cache/database drift repair
+	workers.Go(func() {
+		reconciler := service.NewCacheReconciler(favRepo, redisAdapter, cacheSvc, logger)
+		reconciler.Run(workerCtx, repo.NewAdvisoryLock(dbPool, "favorites:cache-reconciler"), service.ReconcileConfig{
+			Interval:  cfg.CacheReconcileInterval,
+			BatchSize: cfg.CacheReconcileBatchSize,
+			DryRun:    cfg.CacheReconcileDryRun,
+		})
+	})
+	// Expired session rows
+	workers.Go(func() {
+		sessionSvc.RunCleanup(workerCtx, cfg.SessionCleanupInterval)
+	})
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+
 	// Graceful Shutdown
 	go func() {
 		logger.Info("Starting server", "addr", srv.Addr, "env", cfg.AppEnv)
@@ -126,10 +286,134 @@ func main() {
 		logger.Error("Server forced to shutdown", "error", err)
 	}
 
+	// Let claimed enrichment jobs, outbox events and webhook deliveries finish; unfinished ones are retried after their lease expires.
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		logger.Warn("background workers did not drain before shutdown deadline")
+	}
+
 	logger.Info("Server exited")
 }
 
-// Simple NoOp Enricher
-type NoOpEnricher struct{}
+// loadPasswordPolicy builds the password policy, reading the optional breached list from disk.
+func loadPasswordPolicy(cfg config.Config) (auth.PasswordPolicy, error) {
+	if cfg.PasswordBreachedListFile == "" {
+		return auth.NewPasswordPolicy(cfg.PasswordMinLength, nil)
+	}
+	f, err := os.Open(cfg.PasswordBreachedListFile)
+	if err != nil {
+		return auth.PasswordPolicy{}, err
+	}
+	defer f.Close()
+	return auth.NewPasswordPolicy(cfg.PasswordMinLength, f)
+}
 
-func (e *NoOpEnricher) Enrich(ctx context.Context, asset favorites.Asset) error { return nil }
+// quota converts the configured per-user limits.
+func quota(cfg config.Config) favorites.Quota {
+	q := favorites.Quota{
+		MaxFavorites:    cfg.QuotaMaxFavorites,
+		MaxBytes:        cfg.QuotaMaxBytes,
+		MaxFieldLengths: make(map[favorites.AssetType]map[string]int, len(cfg.QuotaMaxFieldLengths)),
+	}
+	for assetType, fields := range cfg.QuotaMaxFieldLengths {
+		q.MaxFieldLengths[favorites.AssetType(assetType)] = fields
+	}
+	return q
+}
+
+// redisOptions maps the Redis settings, reading the optional CA file from disk.
+func redisOptions(cfg config.Config) (redis.Options, error) {
+	opts := redis.Options{
+		Mode:             cfg.RedisMode,
+		Addrs:            cfg.RedisAddrs,
+		MasterName:       cfg.RedisMasterName,
+		SentinelUsername: cfg.RedisSentinelUsername,
+		SentinelPassword: cfg.RedisSentinelPassword,
+		Username:         cfg.RedisUsername,
+		Password:         cfg.RedisPassword,
+		DB:               cfg.RedisDB,
+		PoolSize:         cfg.RedisPoolSize,
+		MinIdleConns:     cfg.RedisMinIdleConns,
+		DialTimeout:      cfg.RedisDialTimeout,
+		ReadTimeout:      cfg.RedisReadTimeout,
+		WriteTimeout:     cfg.RedisWriteTimeout,
+		PoolTimeout:      cfg.RedisPoolTimeout,
+		KeyPrefix:        cfg.RedisKeyPrefix,
+		AssetTTL:         cfg.CacheAssetTTL,
+		SetTTL:           cfg.CacheSetTTL,
+		TTLJitter:        cfg.CacheTTLJitter,
+	}
+	if !cfg.RedisTLS {
+		return opts, nil
+	}
+
+	opts.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.RedisTLSServerName,
+	}
+	if cfg.RedisTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.RedisTLSCAFile)
+		if err != nil {
+			return redis.Options{}, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return redis.Options{}, fmt.Errorf("no certificates found in %s", cfg.RedisTLSCAFile)
+		}
+		opts.TLS.RootCAs = pool
+	}
+	return opts, nil
+}
+
+// invalidatingCache writes to Redis without the guard, so failures reach the
+// caller to be retried, and tells the replicas to drop their local copies of
+// what it removes when the in-process tier is enabled.
+func invalidatingCache(cfg config.Config, redisAdapter *redis.Adapter, logger *slog.Logger) ports.Cache {
+	if cfg.CacheL1Size == 0 {
+		return redisAdapter
+	}
+	return memcache.NewCache(redisAdapter, redisAdapter.Invalidations(), memcache.Config{
+		Size: cfg.CacheL1Size,
+		TTL:  cfg.CacheL1TTL,
+	}, logger)
+}
+
+// rateLimiter counts requests in Redis, and in process while Redis is
+// failing.
+func rateLimiter(cfg config.Config, redisAdapter *redis.Adapter, logger *slog.Logger) ports.RateLimiter {
+	return guard.NewRateLimiter(redisAdapter.RateLimiter(), memcache.NewRateLimiter(), guard.RateLimiterConfig{
+		Timeout:          cfg.CacheTimeout,
+		FailureThreshold: cfg.CacheBreakerThreshold,
+		Cooldown:         cfg.CacheBreakerCooldown,
+	}, logger)
+}
+
+// newEnricherRegistry registers an HTTP enricher for every enabled stage.
+// Each stage gets its own circuit breakers, so one failing service does not
+// stop the others.
+func newEnricherRegistry(cfg config.Config, logger *slog.Logger) *enricher.Registry {
+	registry := enricher.NewRegistry(logger)
+	if len(cfg.EnrichmentStages) == 0 {
+		logger.Warn("no enrichment stages configured, assets will not be enriched")
+	}
+
+	for _, stage := range cfg.EnrichmentStages {
+		assetType := favorites.AssetType(stage.AssetType)
+		registry.Register(assetType, enricher.Stage{
+			Name: stage.Name,
+			Enricher: metadata.NewEnricher(metadata.Config{
+				Stage:            stage.Name,
+				Endpoints:        map[favorites.AssetType]string{assetType: stage.URL},
+				Timeout:          cfg.EnricherTimeout,
+				MaxRetries:       cfg.EnricherMaxRetries,
+				FailureThreshold: cfg.EnricherBreakerThreshold,
+				BreakerCooldown:  cfg.EnricherBreakerCooldown,
+			}, logger),
+			Timeout: stage.Timeout,
+		})
+		logger.Info("enrichment stage enabled", "stage", stage.Key(), "timeout", stage.Timeout)
+	}
+	return registry
+}
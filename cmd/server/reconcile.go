@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/adapter/cache/redis"
+	repo "go-favorites-app/internal/adapter/storage/postgres"
+	"go-favorites-app/internal/config"
+	"go-favorites-app/internal/core/service"
+)
+
+// runReconcile is the "reconcile" admin command: one pass of the cache
+// reconciler, printing the report as JSON. It only reports drift unless
+// -apply is given, so it is safe to run against production.
+func runReconcile(cfg config.Config, logger *slog.Logger, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "repair the drift found instead of only reporting it")
+	batchSize := flags.Int("batch-size", cfg.CacheReconcileBatchSize, "IDs checked per round trip")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		logger.Error("Unable to connect to database", "error", err)
+		return 1
+	}
+	defer dbPool.Close()
+
+	redisOpts, err := redisOptions(cfg)
+	if err != nil {
+		logger.Error("failed to configure redis", "error", err)
+		return 1
+	}
+	redisAdapter := redis.NewAdapter(redisOpts)
+
+	reconciler := service.NewCacheReconciler(repo.NewRepository(dbPool), redisAdapter, invalidatingCache(cfg, redisAdapter, logger), logger)
+	report, err := reconciler.Reconcile(ctx, service.ReconcileConfig{
+		BatchSize: *batchSize,
+		DryRun:    !*apply,
+	})
+	if err != nil {
+		logger.Error("cache reconciliation failed", "error", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return 1
+	}
+	return 0
+}
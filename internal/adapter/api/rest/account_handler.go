@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
+)
+
+// AccountHandler serves the /me endpoints for the authenticated user.
+type AccountHandler struct {
+	accounts ports.AccountService
+	auth     ports.AuthService
+	logger   *slog.Logger
+}
+
+func NewAccountHandler(accounts ports.AccountService, authSvc ports.AuthService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{accounts: accounts, auth: authSvc, logger: logger}
+}
+
+type meResponse struct {
+	ID              string         `json:"id"`
+	Email           string         `json:"email"`
+	EmailVerified   bool           `json:"email_verified"`
+	EmailVerifiedAt time.Time      `json:"email_verified_at,omitzero"`
+	DisplayName     string         `json:"display_name"`
+	Preferences     map[string]any `json:"preferences"`
+	Roles           []string       `json:"roles"`
+	CreatedAt       time.Time      `json:"created_at"`
+	FavoriteCount   int            `json:"favorite_count"`
+}
+
+func newMeResponse(p auth.Profile) meResponse {
+	prefs := p.User.Preferences
+	if prefs == nil {
+		prefs = map[string]any{}
+	}
+	return meResponse{
+		ID:              p.User.ID,
+		Email:           p.User.Email,
+		EmailVerified:   p.User.IsVerified(),
+		EmailVerifiedAt: p.User.EmailVerifiedAt,
+		DisplayName:     p.User.DisplayName,
+		Preferences:     prefs,
+		Roles:           p.User.Roles,
+		CreatedAt:       p.User.CreatedAt,
+		FavoriteCount:   p.FavoriteCount,
+	}
+}
+
+// Me handles GET /me
+func (h *AccountHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	profile, err := h.accounts.Me(r.Context(), userID)
+	if err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, newMeResponse(profile))
+}
+
+// UpdateMe handles PATCH /me
+// Payload: {"display_name": "...", "preferences": {...}}
+func (h *AccountHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req struct {
+		DisplayName *string        `json:"display_name"`
+		Preferences map[string]any `json:"preferences"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	profile, err := h.accounts.UpdateProfile(r.Context(), userID, auth.ProfilePatch{
+		DisplayName: req.DisplayName,
+		Preferences: req.Preferences,
+	})
+	if err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, newMeResponse(profile))
+}
+
+// ChangePassword handles POST /me/password
+// Payload: {"current_password": "...", "new_password": "..."}
+// Every other session of the user is logged out.
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sessionID, _ := r.Context().Value(sessionIDKey).(string)
+	if err := h.auth.ChangePassword(r.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangeEmail handles POST /me/email
+// Payload: {"email": "...", "password": "..."}
+// The new address must be verified again through the emailed link.
+func (h *AccountHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.auth.ChangeEmail(r.Context(), userID, req.Password, req.Email); err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// exportRecord is one line of the NDJSON account export.
+type exportRecord struct {
+	Kind string `json:"kind"`
+	Data any    `json:"data"`
+}
+
+// Export handles GET /me/export
+// It streams the user's personal data as NDJSON: one "user" record followed
+// by one "favorite" record per saved asset.
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	user, assets, err := h.accounts.Export(r.Context(), userID)
+	if err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="favorites-export.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(exportRecord{Kind: "user", Data: user}); err != nil {
+		h.logger.Error("encode error", "err", err)
+		return
+	}
+	for asset, err := range assets {
+		if err != nil {
+			h.logger.Error("export stream error", "user_id", userID, "err", err)
+			return
+		}
+		if err := enc.Encode(exportRecord{Kind: "favorite", Data: asset}); err != nil {
+			h.logger.Error("encode error", "err", err)
+			return
+		}
+	}
+}
+
+// DeleteMe handles DELETE /me
+// Payload: {"password": "..."}
+// It permanently removes the account and every favorite it owns.
+func (h *AccountHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.accounts.Delete(r.Context(), userID, req.Password); err != nil {
+		h.respondAccountError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AccountHandler) respondAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrValidation):
+		h.respondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		h.respondError(w, http.StatusForbidden, err)
+	case errors.Is(err, auth.ErrEmailTaken):
+		h.respondError(w, http.StatusConflict, err)
+	case errors.Is(err, auth.ErrUserNotFound):
+		h.respondError(w, http.StatusNotFound, err)
+	default:
+		h.logger.Error("account request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+	}
+}
+
+func (h *AccountHandler) respondJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h *AccountHandler) respondError(w http.ResponseWriter, code int, err error) {
+	h.respondJSON(w, code, map[string]string{"error": err.Error()})
+}
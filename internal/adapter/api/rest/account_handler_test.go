@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) Me(ctx context.Context, userID string) (auth.Profile, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(auth.Profile), args.Error(1)
+}
+
+func (m *MockAccountService) UpdateProfile(ctx context.Context, userID string, patch auth.ProfilePatch) (auth.Profile, error) {
+	args := m.Called(ctx, userID, patch)
+	return args.Get(0).(auth.Profile), args.Error(1)
+}
+
+func (m *MockAccountService) Export(ctx context.Context, userID string) (auth.User, iter.Seq2[favorites.Asset, error], error) {
+	args := m.Called(ctx, userID)
+	if args.Get(1) == nil {
+		return args.Get(0).(auth.User), nil, args.Error(2)
+	}
+	return args.Get(0).(auth.User), args.Get(1).(iter.Seq2[favorites.Asset, error]), args.Error(2)
+}
+
+func (m *MockAccountService) Delete(ctx context.Context, userID, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func withUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+}
+
+func TestAccountHandler_Me(t *testing.T) {
+	accounts := new(MockAccountService)
+	h := NewAccountHandler(accounts, new(MockAuthService), slog.Default())
+
+	accounts.On("Me", mock.Anything, "user1").Return(auth.Profile{
+		User:          auth.User{ID: "user1", Email: "a@example.com", Roles: []string{auth.RoleUser}},
+		FavoriteCount: 7,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	h.Me(w, withUser(httptest.NewRequest(http.MethodGet, "/me", nil), "user1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "a@example.com", body["email"])
+	assert.Equal(t, float64(7), body["favorite_count"])
+	assert.Equal(t, false, body["email_verified"])
+	assert.Equal(t, map[string]any{}, body["preferences"])
+	assert.NotContains(t, body, "password_hash")
+}
+
+func TestAccountHandler_UpdateMe(t *testing.T) {
+	accounts := new(MockAccountService)
+	h := NewAccountHandler(accounts, new(MockAuthService), slog.Default())
+
+	accounts.On("UpdateProfile", mock.Anything, "user1", mock.MatchedBy(func(p auth.ProfilePatch) bool {
+		return p.DisplayName != nil && *p.DisplayName == "Jane" && p.Preferences["theme"] == "dark"
+	})).Return(auth.Profile{User: auth.User{ID: "user1", DisplayName: "Jane"}}, nil)
+
+	body := bytes.NewBufferString(`{"display_name":"Jane","preferences":{"theme":"dark"}}`)
+	w := httptest.NewRecorder()
+	h.UpdateMe(w, withUser(httptest.NewRequest(http.MethodPatch, "/me", body), "user1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	accounts.AssertExpectations(t)
+}
+
+func TestAccountHandler_ChangePassword(t *testing.T) {
+	authSvc := new(MockAuthService)
+	h := NewAccountHandler(new(MockAccountService), authSvc, slog.Default())
+
+	authSvc.On("ChangePassword", mock.Anything, "user1", "", "wrong", "newPassword1").Return(auth.ErrInvalidCredentials)
+	authSvc.On("ChangePassword", mock.Anything, "user1", "s1", "right", "newPassword1").Return(nil)
+
+	w := httptest.NewRecorder()
+	h.ChangePassword(w, withUser(httptest.NewRequest(http.MethodPost, "/me/password",
+		bytes.NewBufferString(`{"current_password":"wrong","new_password":"newPassword1"}`)), "user1"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	req := withUser(httptest.NewRequest(http.MethodPost, "/me/password",
+		bytes.NewBufferString(`{"current_password":"right","new_password":"newPassword1"}`)), "user1")
+	h.ChangePassword(w, req.WithContext(context.WithValue(req.Context(), sessionIDKey, "s1")))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAccountHandler_ChangeEmail(t *testing.T) {
+	authSvc := new(MockAuthService)
+	h := NewAccountHandler(new(MockAccountService), authSvc, slog.Default())
+
+	authSvc.On("ChangeEmail", mock.Anything, "user1", "pw", "taken@example.com").Return(auth.ErrEmailTaken)
+
+	w := httptest.NewRecorder()
+	h.ChangeEmail(w, withUser(httptest.NewRequest(http.MethodPost, "/me/email",
+		bytes.NewBufferString(`{"email":"taken@example.com","password":"pw"}`)), "user1"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAccountHandler_Export(t *testing.T) {
+	accounts := new(MockAccountService)
+	h := NewAccountHandler(accounts, new(MockAuthService), slog.Default())
+
+	user := auth.User{ID: "user1", Email: "a@example.com", PasswordHash: "secret-hash"}
+	assets := func(yield func(favorites.Asset, error) bool) {
+		yield(favorites.Chart{BaseAsset: favorites.BaseAsset{ID: "c1", UserID: "user1", Type: favorites.AssetTypeChart, Name: "Sales"}}, nil)
+	}
+	accounts.On("Export", mock.Anything, "user1").Return(user, iter.Seq2[favorites.Asset, error](assets), nil)
+
+	w := httptest.NewRecorder()
+	h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/me/export", nil), "user1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		var rec struct {
+			Kind string         `json:"kind"`
+			Data map[string]any `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+		assert.Equal(t, "user", rec.Kind)
+		assert.Equal(t, "a@example.com", rec.Data["email"])
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+		assert.Equal(t, "favorite", rec.Kind)
+		assert.Equal(t, "c1", rec.Data["id"])
+	}
+}
+
+func TestAccountHandler_DeleteMe(t *testing.T) {
+	accounts := new(MockAccountService)
+	h := NewAccountHandler(accounts, new(MockAuthService), slog.Default())
+
+	accounts.On("Delete", mock.Anything, "user1", "wrong").Return(auth.ErrInvalidCredentials)
+	accounts.On("Delete", mock.Anything, "user1", "right").Return(nil)
+
+	w := httptest.NewRecorder()
+	h.DeleteMe(w, withUser(httptest.NewRequest(http.MethodDelete, "/me", bytes.NewBufferString(`{"password":"wrong"}`)), "user1"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	h.DeleteMe(w, withUser(httptest.NewRequest(http.MethodDelete, "/me", bytes.NewBufferString(`{"password":"right"}`)), "user1"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
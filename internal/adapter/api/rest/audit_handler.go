@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/ports"
+)
+
+// AuditHandler serves the audit log to users (/me/audit) and admins (/admin/audit).
+type AuditHandler struct {
+	service ports.AuditService
+	logger  *slog.Logger
+}
+
+func NewAuditHandler(service ports.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{service: service, logger: logger}
+}
+
+type auditEntryResponse struct {
+	ID        int64                   `json:"id"`
+	At        time.Time               `json:"at"`
+	ActorID   string                  `json:"actor_id,omitempty"`
+	Action    audit.Action            `json:"action"`
+	TargetID  string                  `json:"target_id,omitempty"`
+	Changes   map[string]audit.Change `json:"changes,omitempty"`
+	RequestID string                  `json:"request_id,omitempty"`
+	IP        string                  `json:"ip,omitempty"`
+	UserAgent string                  `json:"user_agent,omitempty"`
+	// RedactedAt is set once the actor deleted their account.
+	RedactedAt *time.Time `json:"redacted_at,omitempty"`
+}
+
+func newAuditEntryResponse(e audit.Entry) auditEntryResponse {
+	resp := auditEntryResponse{
+		ID:        e.ID,
+		At:        e.At,
+		ActorID:   e.ActorID,
+		Action:    e.Action,
+		TargetID:  e.TargetID,
+		Changes:   e.Changes,
+		RequestID: e.RequestID,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+	}
+	if !e.RedactedAt.IsZero() {
+		resp.RedactedAt = &e.RedactedAt
+	}
+	return resp
+}
+
+// auditPage is a page of entries. NextBefore is passed as "before" to get
+// the next page; it is absent on the last one.
+type auditPage struct {
+	Data       []auditEntryResponse `json:"data"`
+	NextBefore int64                `json:"next_before,omitempty"`
+}
+
+// Mine handles GET /me/audit
+func (h *AuditHandler) Mine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	f, err := parseAuditFilter(r, false)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.Mine(r.Context(), userID, f)
+	if err != nil {
+		h.respondAuditError(w, err)
+		return
+	}
+	h.respondPage(w, entries, f.Limit)
+}
+
+// ExportMine handles GET /me/audit/export
+func (h *AuditHandler) ExportMine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	f, err := parseAuditFilter(r, false)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.ExportMine(r.Context(), userID, f)
+	if err != nil {
+		h.respondAuditError(w, err)
+		return
+	}
+	h.stream(w, entries)
+}
+
+// Search handles GET /admin/audit
+// Unlike /me/audit, it can filter by actor_id.
+func (h *AuditHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	f, err := parseAuditFilter(r, true)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.Search(r.Context(), userID, f)
+	if err != nil {
+		h.respondAuditError(w, err)
+		return
+	}
+	h.respondPage(w, entries, f.Limit)
+}
+
+// Export handles GET /admin/audit/export
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	f, err := parseAuditFilter(r, true)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := h.service.Export(r.Context(), userID, f)
+	if err != nil {
+		h.respondAuditError(w, err)
+		return
+	}
+	h.stream(w, entries)
+}
+
+// parseAuditFilter reads action, target_id, from and to (RFC 3339), before
+// and limit, and actor_id if byActor is set.
+func parseAuditFilter(r *http.Request, byActor bool) (audit.Filter, error) {
+	q := r.URL.Query()
+	f := audit.Filter{
+		Action:   audit.Action(q.Get("action")),
+		TargetID: q.Get("target_id"),
+	}
+	if byActor {
+		f.ActorID = q.Get("actor_id")
+	}
+	var err error
+	if f.From, err = parseAuditTime(q.Get("from")); err != nil {
+		return f, fmt.Errorf("invalid from: %w", err)
+	}
+	if f.To, err = parseAuditTime(q.Get("to")); err != nil {
+		return f, fmt.Errorf("invalid to: %w", err)
+	}
+	if v := q.Get("before"); v != "" {
+		if f.BeforeID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return f, errors.New("invalid before")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil {
+			return f, errors.New("invalid limit")
+		}
+	}
+	if f.Limit == 0 {
+		f.Limit = audit.DefaultLimit
+	}
+	return f, nil
+}
+
+func parseAuditTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func (h *AuditHandler) respondPage(w http.ResponseWriter, entries []audit.Entry, limit int) {
+	page := auditPage{Data: make([]auditEntryResponse, 0, len(entries))}
+	for _, e := range entries {
+		page.Data = append(page.Data, newAuditEntryResponse(e))
+	}
+	if len(entries) > 0 && len(entries) == limit {
+		page.NextBefore = entries[len(entries)-1].ID
+	}
+	h.respondJSON(w, http.StatusOK, page)
+}
+
+// stream writes the entries as NDJSON. Errors after the first line can only
+// be logged; the response is cut short.
+func (h *AuditHandler) stream(w http.ResponseWriter, entries iter.Seq2[audit.Entry, error]) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-export.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for e, err := range entries {
+		if err != nil {
+			h.logger.Error("audit export stream error", "error", err)
+			return
+		}
+		if err := enc.Encode(newAuditEntryResponse(e)); err != nil {
+			h.logger.Error("encode error", "error", err)
+			return
+		}
+	}
+}
+
+func (h *AuditHandler) respondAuditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, audit.ErrInvalidFilter):
+		h.respondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, audit.ErrForbidden):
+		h.respondError(w, http.StatusForbidden, err)
+	default:
+		h.logger.Error("audit request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+	}
+}
+
+func (h *AuditHandler) respondJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h *AuditHandler) respondError(w http.ResponseWriter, code int, err error) {
+	h.respondJSON(w, code, map[string]string{"error": err.Error()})
+}
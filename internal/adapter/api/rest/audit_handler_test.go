@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/audit"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) Mine(ctx context.Context, userID string, f audit.Filter) ([]audit.Entry, error) {
+	args := m.Called(ctx, userID, f)
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditService) ExportMine(ctx context.Context, userID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	args := m.Called(ctx, userID, f)
+	return args.Get(0).(iter.Seq2[audit.Entry, error]), args.Error(1)
+}
+
+func (m *MockAuditService) Search(ctx context.Context, adminID string, f audit.Filter) ([]audit.Entry, error) {
+	args := m.Called(ctx, adminID, f)
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditService) Export(ctx context.Context, adminID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	args := m.Called(ctx, adminID, f)
+	return args.Get(0).(iter.Seq2[audit.Entry, error]), args.Error(1)
+}
+
+func TestAuditHandler_Mine(t *testing.T) {
+	svc := new(MockAuditService)
+	h := NewAuditHandler(svc, slog.Default())
+
+	t.Run("pages with filters", func(t *testing.T) {
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		svc.On("Mine", mock.Anything, "user1", audit.Filter{
+			Action: audit.ActionFavoriteDeleted, From: from, BeforeID: 90, Limit: 2,
+		}).Return([]audit.Entry{
+			{ID: 80, ActorID: "user1", Action: audit.ActionFavoriteDeleted, TargetID: "a1"},
+			{ID: 70, ActorID: "user1", Action: audit.ActionFavoriteDeleted, TargetID: "a2"},
+		}, nil).Once()
+
+		// actor_id is only honored for admins.
+		req := httptest.NewRequest(http.MethodGet, "/me/audit?action=favorite.deleted&from=2026-01-01T00:00:00Z&before=90&limit=2&actor_id=other", nil)
+		w := httptest.NewRecorder()
+		h.Mine(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page struct {
+			Data       []map[string]any `json:"data"`
+			NextBefore int64            `json:"next_before"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Len(t, page.Data, 2)
+		assert.Equal(t, int64(70), page.NextBefore)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("bad query", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Mine(w, withUser(httptest.NewRequest(http.MethodGet, "/me/audit?from=yesterday", nil), "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAuditHandler_Search(t *testing.T) {
+	svc := new(MockAuditService)
+	h := NewAuditHandler(svc, slog.Default())
+
+	svc.On("Search", mock.Anything, "user1", audit.Filter{ActorID: "u2", Limit: audit.DefaultLimit}).
+		Return([]audit.Entry(nil), audit.ErrForbidden).Once()
+
+	w := httptest.NewRecorder()
+	h.Search(w, withUser(httptest.NewRequest(http.MethodGet, "/admin/audit?actor_id=u2", nil), "user1"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAuditHandler_Export(t *testing.T) {
+	svc := new(MockAuditService)
+	h := NewAuditHandler(svc, slog.Default())
+
+	entries := func(yield func(audit.Entry, error) bool) {
+		_ = yield(audit.Entry{ID: 2, Action: audit.ActionLoginFailed, IP: "192.0.2.9"}, nil) &&
+			yield(audit.Entry{ID: 1, ActorID: "u1", Action: audit.ActionSignup}, nil)
+	}
+	svc.On("Export", mock.Anything, "admin1", audit.Filter{Limit: audit.DefaultLimit}).
+		Return(iter.Seq2[audit.Entry, error](entries), nil).Once()
+
+	w := httptest.NewRecorder()
+	h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/admin/audit/export", nil), "admin1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.JSONEq(t, `{"id":2,"at":"0001-01-01T00:00:00Z","action":"user.login_failed","ip":"192.0.2.9"}`, lines[0])
+	}
+}
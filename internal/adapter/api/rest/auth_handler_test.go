@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) SignUp(ctx context.Context, email, password string) error {
+	args := m.Called(ctx, email, password)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) Login(ctx context.Context, email, password string, client auth.ClientInfo) (string, error) {
+	args := m.Called(ctx, email, password, client)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResendVerification(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, sessionID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangeEmail(ctx context.Context, userID, password, newEmail string) error {
+	args := m.Called(ctx, userID, password, newEmail)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) EnsureVerified(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestAuthHandler_SignUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		svcErr   error
+		wantCode int
+	}{
+		{"created", nil, http.StatusCreated},
+		{"validation error", fmt.Errorf("%w: email is required", auth.ErrValidation), http.StatusBadRequest},
+		{"duplicate email", auth.ErrEmailTaken, http.StatusConflict},
+		{"internal error", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockAuthService)
+			h := NewAuthHandler(svc)
+			svc.On("SignUp", mock.Anything, "a@example.com", "password123").Return(tt.svcErr)
+
+			body := bytes.NewBufferString(`{"email":"a@example.com","password":"password123"}`)
+			w := httptest.NewRecorder()
+			h.SignUp(w, httptest.NewRequest(http.MethodPost, "/signup", body))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.NotContains(t, w.Body.String(), "db down")
+		})
+	}
+}
+
+func TestAuthHandler_ResetPassword(t *testing.T) {
+	svc := new(MockAuthService)
+	h := NewAuthHandler(svc)
+
+	svc.On("ResetPassword", mock.Anything, "used", mock.Anything).Return(auth.ErrTokenUsed)
+	svc.On("ResetPassword", mock.Anything, "good", mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	h.ResetPassword(w, httptest.NewRequest(http.MethodPost, "/password/reset", bytes.NewBufferString(`{"token":"used","password":"x"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ResetPassword(w, httptest.NewRequest(http.MethodPost, "/password/reset", bytes.NewBufferString(`{"token":"good","password":"newPassword1"}`)))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAuthHandler_RequireVerifiedEmail(t *testing.T) {
+	svc := new(MockAuthService)
+	h := NewAuthHandler(svc)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+
+	svc.On("EnsureVerified", mock.Anything, "unverified").Return(auth.ErrEmailNotVerified)
+	svc.On("EnsureVerified", mock.Anything, "verified").Return(nil)
+
+	for userID, want := range map[string]int{"unverified": http.StatusForbidden, "verified": http.StatusTeapot} {
+		req := httptest.NewRequest(http.MethodPost, "/favorites", nil)
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+		w := httptest.NewRecorder()
+		h.RequireVerifiedEmail(next).ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, userID)
+	}
+}
+
+func TestAuthHandler_ResendVerification(t *testing.T) {
+	svc := new(MockAuthService)
+	h := NewAuthHandler(svc)
+	svc.On("ResendVerification", mock.Anything, "user1").Return(nil).Once()
+	svc.On("ResendVerification", mock.Anything, "user2").Return(errors.New("smtp down")).Once()
+
+	w := httptest.NewRecorder()
+	h.ResendVerification(w, withUser(httptest.NewRequest(http.MethodPost, "/verify-email/resend", nil), "user1"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ResendVerification(w, withUser(httptest.NewRequest(http.MethodPost, "/verify-email/resend", nil), "user2"))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	svc.AssertExpectations(t)
+}
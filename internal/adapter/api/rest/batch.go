@@ -0,0 +1,333 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/google/uuid"
+)
+
+// defaultMaxBatchItems caps a batch request unless WithMaxBatchItems is used.
+const defaultMaxBatchItems = 1000
+
+// batchItemResult is the outcome of one item of a batch request. Status is
+// what the item would have got as a request of its own.
+type batchItemResult struct {
+	Index  int                   `json:"index"`
+	ID     string                `json:"id,omitempty"`
+	Status int                   `json:"status"`
+	Action favorites.BatchAction `json:"action,omitempty"`
+	Error  string                `json:"error,omitempty"`
+	// Reason, Field and Limit describe a quota error, as respondQuotaError.
+	Reason string `json:"reason,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Limit  int64  `json:"limit,omitempty"`
+}
+
+type batchResponse struct {
+	Mode      favorites.BatchMode `json:"mode,omitempty"`
+	DryRun    bool                `json:"dry_run,omitempty"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []batchItemResult   `json:"results"`
+}
+
+type batchDeleteRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type batchUpdateRequest struct {
+	Updates []struct {
+		ID          string `json:"id"`
+		Description string `json:"description"`
+	} `json:"updates"`
+}
+
+// CreateBatch handles POST /favorites:batch
+// The body is a JSON array of assets, or NDJSON with Content-Type
+// application/x-ndjson. ?mode=best_effort saves the valid assets even if
+// others fail; the default, atomic, saves all of them or none.
+func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	mode := favorites.BatchMode(r.URL.Query().Get("mode"))
+	switch mode {
+	case "":
+		mode = favorites.BatchAtomic
+	case favorites.BatchAtomic, favorites.BatchBestEffort:
+	default:
+		h.respondError(w, http.StatusBadRequest, fmt.Errorf("unknown mode %q: use %s or %s", mode, favorites.BatchAtomic, favorites.BatchBestEffort))
+		return
+	}
+
+	format := formatJSON
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-ndjson" {
+		format = formatNDJSON
+	}
+	items, err := h.decodeItems(r.Body, format)
+	if err != nil {
+		code := decodeStatus(err)
+		if errors.Is(err, errBatchTooLarge) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		h.respondError(w, code, err)
+		return
+	}
+
+	// Items that do not parse are reported alongside the service's results.
+	results := make([]favorites.BatchResult, len(items))
+	var assets []favorites.Asset
+	var indexes []int
+	for i, raw := range items {
+		asset, err := parseBatchItem(raw, userID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].ID = asset.GetID()
+		assets = append(assets, asset)
+		indexes = append(indexes, i)
+	}
+
+	switch {
+	case mode == favorites.BatchAtomic && favorites.Failed(results):
+		favorites.Abort(results)
+	case len(assets) > 0:
+		saved, err := h.service.SaveBatch(r.Context(), userID, assets, mode)
+		if err != nil {
+			h.logger.Error("failed to save batch", "user_id", userID, "error", err)
+			h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+			return
+		}
+		for j, res := range saved {
+			results[indexes[j]] = res
+		}
+	}
+
+	code := http.StatusOK
+	if mode == favorites.BatchAtomic {
+		code = http.StatusCreated
+		if favorites.Failed(results) {
+			code = http.StatusUnprocessableEntity
+		}
+	}
+	h.respondBatch(w, code, batchResponse{Mode: mode}, results)
+}
+
+// DeleteBatch handles POST /favorites:batchDelete
+// Payload: {"ids": ["..."]}
+// Each asset the user owns is deleted; the others are reported.
+func (h *Handler) DeleteBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req batchDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, decodeStatus(err), err)
+		return
+	}
+	if len(req.IDs) == 0 {
+		h.respondError(w, http.StatusBadRequest, errors.New("ids are required"))
+		return
+	}
+	if len(req.IDs) > h.maxBatchItems {
+		h.respondError(w, http.StatusRequestEntityTooLarge, errBatchTooLarge)
+		return
+	}
+
+	results, err := h.service.DeleteBatch(r.Context(), userID, req.IDs)
+	if err != nil {
+		h.logger.Error("failed to delete batch", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+	h.respondBatch(w, http.StatusOK, batchResponse{}, results)
+}
+
+// UpdateBatch handles POST /favorites:batchUpdate
+// Payload: {"updates": [{"id": "...", "description": "..."}]}
+// Each asset the user owns gets its new description; the others are reported.
+func (h *Handler) UpdateBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req batchUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, decodeStatus(err), err)
+		return
+	}
+	if len(req.Updates) == 0 {
+		h.respondError(w, http.StatusBadRequest, errors.New("updates are required"))
+		return
+	}
+	if len(req.Updates) > h.maxBatchItems {
+		h.respondError(w, http.StatusRequestEntityTooLarge, errBatchTooLarge)
+		return
+	}
+	updates := make([]favorites.DescriptionUpdate, len(req.Updates))
+	for i, u := range req.Updates {
+		if u.ID == "" {
+			h.respondError(w, http.StatusBadRequest, fmt.Errorf("update %d: id is required", i))
+			return
+		}
+		updates[i] = favorites.DescriptionUpdate{ID: u.ID, Description: u.Description}
+	}
+
+	results, err := h.service.UpdateBatch(r.Context(), userID, updates)
+	if err != nil {
+		h.logger.Error("failed to update batch", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+	h.respondBatch(w, http.StatusOK, batchResponse{}, results)
+}
+
+var errBatchTooLarge = errors.New("too many items in the batch")
+
+// decodeItems returns the raw items of a batch request body in format.
+func (h *Handler) decodeItems(body io.Reader, format string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	add := func(raw json.RawMessage) error {
+		if len(items) == h.maxBatchItems {
+			return fmt.Errorf("%w: at most %d", errBatchTooLarge, h.maxBatchItems)
+		}
+		items = append(items, raw)
+		return nil
+	}
+
+	dec := json.NewDecoder(body)
+	switch format {
+	case formatCSV:
+		if err := decodeCSV(body, add); err != nil {
+			return nil, err
+		}
+	case formatNDJSON:
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", len(items)+1, err)
+			}
+			if err := add(raw); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if tok, err := dec.Token(); err != nil {
+			return nil, err
+		} else if tok != json.Delim('[') {
+			return nil, errors.New("expected a JSON array of assets")
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("item %d: %w", len(items), err)
+			}
+			if err := add(raw); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("the batch is empty")
+	}
+	return items, nil
+}
+
+// parseBatchItem parses one asset of a batch as Create does. Its ID, when
+// given, must be a UUID, so that it cannot fail the insert of the others.
+func parseBatchItem(raw json.RawMessage, userID string) (favorites.Asset, error) {
+	var req createAssetRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("%w: %v", favorites.ErrValidation, err)
+	}
+	asset, err := parseAsset(req.Raw, req.Type, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", favorites.ErrValidation, err)
+	}
+	if _, err := uuid.Parse(asset.GetID()); err != nil {
+		return nil, fmt.Errorf("%w: id must be a UUID", favorites.ErrValidation)
+	}
+	return asset, nil
+}
+
+// respondBatch writes resp with the outcome of each item.
+func (h *Handler) respondBatch(w http.ResponseWriter, code int, resp batchResponse, results []favorites.BatchResult) {
+	resp.Results = make([]batchItemResult, len(results))
+	for i, res := range results {
+		item := batchItemResult{Index: i, ID: res.ID, Status: batchActionStatus(res.Action), Action: res.Action}
+		if res.Err != nil {
+			resp.Failed++
+			item.Status, item.Error = batchItemStatus(res.Err), res.Err.Error()
+			var qerr *favorites.QuotaError
+			if errors.As(res.Err, &qerr) {
+				item.Reason, item.Field, item.Limit = qerr.Reason, qerr.Field, qerr.Limit
+			}
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results[i] = item
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+// batchActionStatus is the status of an item applied with action.
+func batchActionStatus(action favorites.BatchAction) int {
+	switch action {
+	case favorites.ActionCreated:
+		return http.StatusCreated
+	case favorites.ActionDeleted:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+// batchItemStatus is the status an item that failed with err would have got
+// as a request of its own. Items not applied because of another one get 424.
+func batchItemStatus(err error) int {
+	switch {
+	case errors.Is(err, favorites.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, favorites.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, favorites.ErrFieldTooLong):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, favorites.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, favorites.ErrAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, favorites.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, favorites.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
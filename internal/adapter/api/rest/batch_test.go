@@ -0,0 +1,197 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func decodeBatchResponse(t *testing.T, w *httptest.ResponseRecorder) batchResponse {
+	t.Helper()
+	var resp batchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestHandler_CreateBatch(t *testing.T) {
+	insight := func(id string) string {
+		return `{"type":"insight","id":"` + id + `","name":"n","content":"c"}`
+	}
+
+	t.Run("json array best effort", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		first, second := uuid.NewString(), uuid.NewString()
+		body := `[` + insight(first) + `,{"type":"unknown","name":"n"},` + insight(second) + `]`
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batch?mode=best_effort", strings.NewReader(body)), "user1")
+		w := httptest.NewRecorder()
+
+		mockSvc.On("SaveBatch", mock.Anything, "user1", mock.MatchedBy(func(assets []favorites.Asset) bool {
+			return len(assets) == 2 && assets[0].GetID() == first && assets[1].GetUserID() == "user1"
+		}), favorites.BatchBestEffort).Return([]favorites.BatchResult{
+			{ID: first, Action: favorites.ActionCreated},
+			{ID: second, Err: &favorites.QuotaError{Reason: favorites.QuotaReasonFavorites, Limit: 10}},
+		}, nil).Once()
+
+		h.CreateBatch(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, 1, resp.Succeeded)
+		assert.Equal(t, 2, resp.Failed)
+		if assert.Len(t, resp.Results, 3) {
+			assert.Equal(t, batchItemResult{Index: 0, ID: first, Status: http.StatusCreated, Action: favorites.ActionCreated}, resp.Results[0])
+			assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+			assert.Equal(t, http.StatusTooManyRequests, resp.Results[2].Status)
+			assert.Equal(t, favorites.QuotaReasonFavorites, resp.Results[2].Reason)
+		}
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("ndjson atomic", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		body := insight(uuid.NewString()) + "\n" + `{"type":"chart","name":"c","x_axis":"x","y_axis":"y"}` + "\n"
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batch", strings.NewReader(body)), "user1")
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+
+		mockSvc.On("SaveBatch", mock.Anything, "user1", mock.MatchedBy(func(assets []favorites.Asset) bool {
+			// The server picks the ID of the chart.
+			return len(assets) == 2 && assets[1].GetID() != ""
+		}), favorites.BatchAtomic).Return([]favorites.BatchResult{{ID: "a", Action: favorites.ActionCreated}, {ID: "b", Action: favorites.ActionCreated}}, nil).Once()
+
+		h.CreateBatch(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, favorites.BatchAtomic, resp.Mode)
+		assert.Equal(t, 2, resp.Succeeded)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("atomic with an invalid item saves nothing", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		body := `[` + insight(uuid.NewString()) + `,` + insight("not-a-uuid") + `]`
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batch", strings.NewReader(body)), "user1")
+		w := httptest.NewRecorder()
+
+		h.CreateBatch(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+		mockSvc.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejected requests", func(t *testing.T) {
+		h := NewHandler(new(MockService), slog.Default()).WithMaxBatchItems(1)
+		tests := []struct {
+			name string
+			url  string
+			body string
+			code int
+		}{
+			{name: "unknown mode", url: "/favorites:batch?mode=some", body: `[]`, code: http.StatusBadRequest},
+			{name: "empty", url: "/favorites:batch", body: `[]`, code: http.StatusBadRequest},
+			{name: "not an array", url: "/favorites:batch", body: `{}`, code: http.StatusBadRequest},
+			{name: "too many items", url: "/favorites:batch", body: `[{},{}]`, code: http.StatusRequestEntityTooLarge},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := withUser(httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body)), "user1")
+				w := httptest.NewRecorder()
+				h.CreateBatch(w, req)
+				assert.Equal(t, tt.code, w.Code)
+			})
+		}
+	})
+}
+
+func TestHandler_UpdateBatch(t *testing.T) {
+	mockSvc := new(MockService)
+	h := NewHandler(mockSvc, slog.Default())
+
+	t.Run("reports each update", func(t *testing.T) {
+		body := `{"updates":[{"id":"1","description":"a"},{"id":"2","description":"b"},{"id":"3","description":"c"}]}`
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batchUpdate", strings.NewReader(body)), "user1")
+		w := httptest.NewRecorder()
+		mockSvc.On("UpdateBatch", mock.Anything, "user1", []favorites.DescriptionUpdate{
+			{ID: "1", Description: "a"}, {ID: "2", Description: "b"}, {ID: "3", Description: "c"},
+		}).Return([]favorites.BatchResult{
+			{ID: "1", Action: favorites.ActionUpdated}, {ID: "2", Err: favorites.ErrForbidden}, {ID: "3", Err: favorites.ErrNotFound},
+		}, nil).Once()
+
+		h.UpdateBatch(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, 1, resp.Succeeded)
+		if assert.Len(t, resp.Results, 3) {
+			assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+			assert.Equal(t, favorites.ActionUpdated, resp.Results[0].Action)
+			assert.Equal(t, http.StatusForbidden, resp.Results[1].Status)
+			assert.Equal(t, http.StatusNotFound, resp.Results[2].Status)
+		}
+	})
+
+	for _, tt := range []struct{ name, body string }{
+		{"no updates", `{"updates":[]}`},
+		{"missing id", `{"updates":[{"description":"a"}]}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batchUpdate", strings.NewReader(tt.body)), "user1")
+			w := httptest.NewRecorder()
+
+			h.UpdateBatch(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestHandler_DeleteBatch(t *testing.T) {
+	mockSvc := new(MockService)
+	h := NewHandler(mockSvc, slog.Default())
+
+	t.Run("reports each id", func(t *testing.T) {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batchDelete", strings.NewReader(`{"ids":["1","2","3"]}`)), "user1")
+		w := httptest.NewRecorder()
+		mockSvc.On("DeleteBatch", mock.Anything, "user1", []string{"1", "2", "3"}).Return([]favorites.BatchResult{
+			{ID: "1", Action: favorites.ActionDeleted}, {ID: "2", Err: favorites.ErrForbidden}, {ID: "3", Err: favorites.ErrNotFound},
+		}, nil).Once()
+
+		h.DeleteBatch(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, 1, resp.Succeeded)
+		if assert.Len(t, resp.Results, 3) {
+			assert.Equal(t, http.StatusNoContent, resp.Results[0].Status)
+			assert.Equal(t, http.StatusForbidden, resp.Results[1].Status)
+			assert.Equal(t, http.StatusNotFound, resp.Results[2].Status)
+		}
+	})
+
+	t.Run("no ids", func(t *testing.T) {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/favorites:batchDelete", strings.NewReader(`{"ids":[]}`)), "user1")
+		w := httptest.NewRecorder()
+
+		h.DeleteBatch(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
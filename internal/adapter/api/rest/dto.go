@@ -70,6 +70,9 @@ func parseAsset(data []byte, assetType favorites.AssetType, userID string) (favo
 		return currentID
 	}
 
+	// Enrichment is server-owned: whatever the client sent is replaced.
+	pending := favorites.Enrichment{Status: favorites.EnrichmentPending}
+
 	switch assetType {
 	case favorites.AssetTypeChart:
 		var c favorites.Chart
@@ -78,6 +81,7 @@ func parseAsset(data []byte, assetType favorites.AssetType, userID string) (favo
 		}
 		c.UserID = userID
 		c.ID = generateID(c.ID)
+		c.Enrichment = pending
 		return c, nil
 	case favorites.AssetTypeInsight:
 		var i favorites.Insight
@@ -86,6 +90,7 @@ func parseAsset(data []byte, assetType favorites.AssetType, userID string) (favo
 		}
 		i.UserID = userID
 		i.ID = generateID(i.ID)
+		i.Enrichment = pending
 		return i, nil
 	case favorites.AssetTypeAudience:
 		var a favorites.Audience
@@ -94,6 +99,7 @@ func parseAsset(data []byte, assetType favorites.AssetType, userID string) (favo
 		}
 		a.UserID = userID
 		a.ID = generateID(a.ID)
+		a.Enrichment = pending
 		return a, nil
 	default:
 		return nil, errors.New("unknown asset type")
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+const (
+	// sseRetry is the reconnection delay suggested to clients, in milliseconds.
+	sseRetry = 3000
+	// sseWriteTimeout bounds each write, so a stalled client does not hold
+	// its connection forever.
+	sseWriteTimeout = 10 * time.Second
+)
+
+// FeedHandler streams the changes to a user's favorites as Server-Sent Events.
+type FeedHandler struct {
+	service   ports.FeedService
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+func NewFeedHandler(service ports.FeedService, heartbeat time.Duration, logger *slog.Logger) *FeedHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &FeedHandler{service: service, heartbeat: heartbeat, logger: logger}
+}
+
+// Events handles GET /favorites/events
+// A client resuming with Last-Event-ID first receives the events it missed,
+// or a "resync" event if they are no longer kept. The stream ends when the
+// server shuts down; clients then reconnect.
+func (h *FeedHandler) Events(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	watch, err := h.service.Watch(r.Context(), userID, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		h.logger.Error("failed to watch favorites", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep reverse proxies such as nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(write func(io.Writer) error) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+		if err := write(w); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send(func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry); err != nil {
+			return err
+		}
+		if watch.Resync {
+			if _, err := io.WriteString(w, "event: resync\ndata: {}\n\n"); err != nil {
+				return err
+			}
+		}
+		for _, e := range watch.Missed {
+			if err := writeEvent(w, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !send(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			}) {
+				return
+			}
+		case e, ok := <-watch.Live:
+			if !ok {
+				return
+			}
+			if !send(func(w io.Writer) error { return writeEvent(w, e) }) {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes one SSE frame. The data is single-line JSON.
+func writeEvent(w io.Writer, e ports.FeedEvent) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+	return err
+}
+
+func (h *FeedHandler) respondError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/observability"
+)
+
+type MockFeedService struct {
+	mock.Mock
+}
+
+func (m *MockFeedService) Watch(ctx context.Context, userID, lastEventID string) (ports.FeedWatch, error) {
+	args := m.Called(ctx, userID, lastEventID)
+	return args.Get(0).(ports.FeedWatch), args.Error(1)
+}
+
+func TestFeedHandler_Events(t *testing.T) {
+	t.Run("streams through the middleware chain", func(t *testing.T) {
+		svc := new(MockFeedService)
+		live := make(chan ports.FeedEvent)
+		svc.On("Watch", mock.Anything, "user1", "5-0").Return(ports.FeedWatch{
+			Missed: []ports.FeedEvent{{ID: "6-0", UserID: "user1", Type: favorites.EventCreated, Data: []byte(`{"asset_id":"a1"}`)}},
+			Resync: true,
+			Live:   live,
+		}, nil).Once()
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		h := NewFeedHandler(svc, 20*time.Millisecond, logger)
+		authenticated := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.Events(w, withUser(r, "user1"))
+		})
+		// The same wrappers as in production; any of them hiding
+		// http.Flusher would leave the client waiting for the first frame.
+		srv := httptest.NewServer(Chain(authenticated, RequestID, Logger(logger), observability.Middleware))
+		defer srv.Close()
+
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Last-Event-ID", "5-0")
+		resp, err := srv.Client().Do(req)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		lines := make(chan string)
+		go func() {
+			defer close(lines)
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+		}()
+		next := func() string {
+			select {
+			case l, ok := <-lines:
+				if !ok {
+					t.Fatal("stream ended early")
+				}
+				return l
+			case <-time.After(2 * time.Second):
+				t.Fatal("stream stalled")
+				return ""
+			}
+		}
+		expect := func(want ...string) {
+			for _, w := range want {
+				assert.Equal(t, w, next())
+			}
+		}
+
+		expect("retry: 3000", "")
+		expect("event: resync", "data: {}", "")
+		expect("id: 6-0", "event: favorite.created", `data: {"asset_id":"a1"}`, "")
+
+		select {
+		case live <- ports.FeedEvent{ID: "7-0", UserID: "user1", Type: favorites.EventDeleted, Data: []byte(`{"asset_id":"a1"}`)}:
+		case <-time.After(2 * time.Second):
+			t.Fatal("handler stopped reading live events")
+		}
+		// A heartbeat may come first.
+		l := next()
+		for l == ": heartbeat" || l == "" {
+			l = next()
+		}
+		assert.Equal(t, "id: 7-0", l)
+		expect("event: favorite.deleted", `data: {"asset_id":"a1"}`, "")
+
+		// The stream ends when the watch does.
+		close(live)
+		for l := range lines {
+			assert.True(t, l == ": heartbeat" || l == "", l)
+		}
+	})
+
+	t.Run("watch error", func(t *testing.T) {
+		svc := new(MockFeedService)
+		svc.On("Watch", mock.Anything, "user1", "").Return(ports.FeedWatch{}, errors.New("redis down")).Once()
+		h := NewFeedHandler(svc, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		w := httptest.NewRecorder()
+		h.Events(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/events", nil), "user1"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.False(t, strings.Contains(w.Body.String(), "redis down"))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h := NewFeedHandler(new(MockFeedService), time.Second, slog.Default())
+
+		w := httptest.NewRecorder()
+		h.Events(w, httptest.NewRequest(http.MethodGet, "/favorites/events", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
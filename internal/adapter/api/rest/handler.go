@@ -14,10 +14,19 @@ import (
 type Handler struct {
 	service ports.FavoriteService
 	logger  *slog.Logger
+
+	// maxBatchItems caps the items of one batch request.
+	maxBatchItems int
 }
 
 func NewHandler(service ports.FavoriteService, logger *slog.Logger) *Handler {
-	return &Handler{service: service, logger: logger}
+	return &Handler{service: service, logger: logger, maxBatchItems: defaultMaxBatchItems}
+}
+
+// WithMaxBatchItems caps the items of one batch request at n.
+func (h *Handler) WithMaxBatchItems(n int) *Handler {
+	h.maxBatchItems = n
+	return h
 }
 
 // Create handles POST /favorites
@@ -30,7 +39,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req createAssetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, err)
+		h.respondError(w, decodeStatus(err), err)
 		return
 	}
 
@@ -47,10 +56,20 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			h.respondError(w, http.StatusBadRequest, err)
 			return
 		}
+		if errors.Is(err, favorites.ErrAlreadyExists) {
+			h.respondError(w, http.StatusConflict, favorites.ErrAlreadyExists)
+			return
+		}
+		var qerr *favorites.QuotaError
+		if errors.As(err, &qerr) {
+			h.respondQuotaError(w, qerr)
+			return
+		}
 		h.respondError(w, http.StatusInternalServerError, err) // Or 400 if validation error
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(asset); err != nil {
 		h.logger.Error("failed to write response", "error", err)
@@ -58,7 +77,13 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 // Get handles GET /favorites/{id}
+// Other users' assets are reported as not found.
 func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
 	id := r.PathValue("id")
 	if id == "" {
 		h.respondError(w, http.StatusBadRequest, errors.New("missing id"))
@@ -66,6 +91,9 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	asset, err := h.service.FindByID(r.Context(), id)
+	if err == nil && asset.GetUserID() != userID {
+		err = favorites.ErrNotFound
+	}
 	if err != nil {
 		// Should check if not found
 		h.logger.Error("failed to find asset", "id", id, "error", err)
@@ -125,11 +153,14 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 	if err := h.service.Delete(r.Context(), id, userID); err != nil {
-		if err.Error() == "forbidden: you do not own this asset" {
+		switch {
+		case errors.Is(err, favorites.ErrForbidden):
 			h.respondError(w, http.StatusForbidden, err)
-			return
+		case errors.Is(err, favorites.ErrNotFound):
+			h.respondError(w, http.StatusNotFound, err)
+		default:
+			h.respondError(w, http.StatusInternalServerError, err)
 		}
-		h.respondError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -149,17 +180,23 @@ func (h *Handler) UpdateDescription(w http.ResponseWriter, r *http.Request) {
 		Description string `json:"description"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, err)
+		h.respondError(w, decodeStatus(err), err)
 		return
 	}
 
 	asset, err := h.service.UpdateDescription(r.Context(), id, req.Description, userID)
 	if err != nil {
-		if err.Error() == "forbidden: you do not own this asset" {
+		var qerr *favorites.QuotaError
+		switch {
+		case errors.As(err, &qerr):
+			h.respondQuotaError(w, qerr)
+		case errors.Is(err, favorites.ErrForbidden):
 			h.respondError(w, http.StatusForbidden, err)
-			return
+		case errors.Is(err, favorites.ErrNotFound):
+			h.respondError(w, http.StatusNotFound, err)
+		default:
+			h.respondError(w, http.StatusInternalServerError, err)
 		}
-		h.respondError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -168,6 +205,72 @@ func (h *Handler) UpdateDescription(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// usageMeter is the consumption of one limit. Limit is absent when unlimited.
+type usageMeter struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit,omitempty"`
+}
+
+type usageResponse struct {
+	Favorites usageMeter `json:"favorites"`
+	Bytes     usageMeter `json:"bytes"`
+	// MaxFieldLengths is keyed by asset type ("*" for every type), then field.
+	MaxFieldLengths map[favorites.AssetType]map[string]int `json:"max_field_lengths,omitempty"`
+}
+
+// Usage handles GET /me/usage
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	usage, quota, err := h.service.Usage(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to read usage", "user_id", userID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(usageResponse{
+		Favorites:       usageMeter{Used: int64(usage.Favorites), Limit: int64(quota.MaxFavorites)},
+		Bytes:           usageMeter{Used: usage.Bytes, Limit: quota.MaxBytes},
+		MaxFieldLengths: quota.MaxFieldLengths,
+	}); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+// decodeStatus is the status for a request body that failed to decode.
+func decodeStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// respondQuotaError refuses an asset over a limit: 422 for a field too long,
+// which the client must shorten, and 429 for a full quota, which frees up as
+// the user deletes favorites.
+func (h *Handler) respondQuotaError(w http.ResponseWriter, qerr *favorites.QuotaError) {
+	code := http.StatusTooManyRequests
+	if errors.Is(qerr, favorites.ErrFieldTooLong) {
+		code = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(struct {
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+		Field  string `json:"field,omitempty"`
+		Limit  int64  `json:"limit"`
+	}{qerr.Error(), qerr.Reason, qerr.Field, qerr.Limit}); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
 func (h *Handler) respondError(w http.ResponseWriter, code int, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"iter"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"go-favorites-app/internal/core/domain/favorites"
@@ -63,6 +65,48 @@ func (m *MockService) UpdateDescription(ctx context.Context, id, description, us
 	return args.Get(0).(favorites.Asset), args.Error(1)
 }
 
+func (m *MockService) SaveBatch(ctx context.Context, userID string, assets []favorites.Asset, mode favorites.BatchMode) ([]favorites.BatchResult, error) {
+	args := m.Called(ctx, userID, assets, mode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]favorites.BatchResult), args.Error(1)
+}
+
+func (m *MockService) UpdateBatch(ctx context.Context, userID string, updates []favorites.DescriptionUpdate) ([]favorites.BatchResult, error) {
+	args := m.Called(ctx, userID, updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]favorites.BatchResult), args.Error(1)
+}
+
+func (m *MockService) DeleteBatch(ctx context.Context, userID string, ids []string) ([]favorites.BatchResult, error) {
+	args := m.Called(ctx, userID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]favorites.BatchResult), args.Error(1)
+}
+
+func (m *MockService) Export(ctx context.Context, userID string) iter.Seq2[favorites.Asset, error] {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(iter.Seq2[favorites.Asset, error])
+}
+
+func (m *MockService) Import(ctx context.Context, userID string, assets []favorites.Asset, opts favorites.ImportOptions) ([]favorites.BatchResult, error) {
+	args := m.Called(ctx, userID, assets, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]favorites.BatchResult), args.Error(1)
+}
+
+func (m *MockService) Usage(ctx context.Context, userID string) (favorites.Usage, favorites.Quota, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(favorites.Usage), args.Get(1).(favorites.Quota), args.Error(2)
+}
+
 func (m *MockService) Shutdown() {
 	m.Called()
 }
@@ -104,12 +148,120 @@ func TestHandler_Create(t *testing.T) {
 		mockSvc.AssertExpectations(t)
 	})
 
+	t.Run("client enrichment is ignored", func(t *testing.T) {
+		id := uuid.NewString()
+		body := `{"type":"insight","id":"` + id + `","name":"Spoofed","content":"x",` +
+			`"enrichment":{"status":"ready","data":{"row_count":99}}}`
+		req := httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, uuid.NewString()))
+		w := httptest.NewRecorder()
+
+		mockSvc.On("Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool {
+			return a.GetID() == id
+		})).Return(nil).Once()
+
+		h.Create(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp struct {
+			Enrichment favorites.Enrichment `json:"enrichment"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, favorites.Enrichment{Status: favorites.EnrichmentPending}, resp.Enrichment)
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/favorites", nil)
 		w := httptest.NewRecorder()
 		h.Create(w, req)
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
+
+	t.Run("limits", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			err    error
+			code   int
+			reason string
+		}{
+			{"field too long", &favorites.QuotaError{Reason: favorites.QuotaReasonFieldLength, Field: "content", Limit: 3}, http.StatusUnprocessableEntity, "max_field_length"},
+			{"quota exceeded", fmt.Errorf("failed to save to db: %w", &favorites.QuotaError{Reason: favorites.QuotaReasonFavorites, Limit: 10}), http.StatusTooManyRequests, "max_favorites"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				id := uuid.NewString()
+				body := `{"type":"insight","id":"` + id + `","name":"n","content":"long"}`
+				req := httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBufferString(body))
+				req = req.WithContext(context.WithValue(req.Context(), userIDKey, uuid.NewString()))
+				w := httptest.NewRecorder()
+				mockSvc.On("Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool {
+					return a.GetID() == id
+				})).Return(tt.err).Once()
+
+				h.Create(w, req)
+
+				assert.Equal(t, tt.code, w.Code)
+				var resp struct {
+					Error  string `json:"error"`
+					Reason string `json:"reason"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.reason, resp.Reason)
+				assert.NotEmpty(t, resp.Error)
+			})
+		}
+	})
+
+	t.Run("duplicate id", func(t *testing.T) {
+		id := uuid.NewString()
+		body := `{"type":"insight","id":"` + id + `","name":"n","content":"c"}`
+		req := httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBufferString(body))
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, uuid.NewString()))
+		w := httptest.NewRecorder()
+		mockSvc.On("Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool {
+			return a.GetID() == id
+		})).Return(fmt.Errorf("failed to save to db: %w", favorites.ErrAlreadyExists)).Once()
+
+		h.Create(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":"asset already exists"}`, w.Body.String())
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		body := `{"type":"insight","id":"` + uuid.NewString() + `","name":"n","content":"` + strings.Repeat("x", 64) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/favorites", bytes.NewBufferString(body))
+		req.ContentLength = -1
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, uuid.NewString()))
+		w := httptest.NewRecorder()
+
+		MaxBodySize(32)(http.HandlerFunc(h.Create)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func TestHandler_Usage(t *testing.T) {
+	mockSvc := new(MockService)
+	h := NewHandler(mockSvc, slog.Default())
+	userID := uuid.NewString()
+	mockSvc.On("Usage", mock.Anything, userID).Return(
+		favorites.Usage{Favorites: 3, Bytes: 120},
+		favorites.Quota{MaxFavorites: 10, MaxFieldLengths: map[favorites.AssetType]map[string]int{favorites.AnyType: {"name": 200}}},
+		nil,
+	).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/me/usage", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+	w := httptest.NewRecorder()
+	h.Usage(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"favorites": {"used": 3, "limit": 10},
+		"bytes": {"used": 120},
+		"max_field_lengths": {"*": {"name": 200}}
+	}`, w.Body.String())
 }
 
 func TestHandler_Get(t *testing.T) {
@@ -120,7 +272,7 @@ func TestHandler_Get(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		id := uuid.NewString()
 		asset := &favorites.Audience{
-			BaseAsset: favorites.BaseAsset{ID: id, Name: "Found", Type: favorites.AssetTypeAudience},
+			BaseAsset: favorites.BaseAsset{ID: id, Name: "Found", Type: favorites.AssetTypeAudience, UserID: "user1"},
 			Rules:     favorites.AudienceRules{Gender: "female"},
 		}
 
@@ -130,11 +282,27 @@ func TestHandler_Get(t *testing.T) {
 
 		mockSvc.On("FindByID", mock.Anything, id).Return(asset, nil)
 
-		h.Get(w, req)
+		h.Get(w, withUser(req, "user1"))
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockSvc.AssertExpectations(t)
 	})
+
+	t.Run("another user's asset is not found", func(t *testing.T) {
+		id := uuid.NewString()
+		asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: id, Type: favorites.AssetTypeInsight, UserID: "user1"}}
+
+		req := httptest.NewRequest(http.MethodGet, "/favorites/"+id, nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+
+		mockSvc.On("FindByID", mock.Anything, id).Return(asset, nil)
+
+		h.Get(w, withUser(req, "user2"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.NotContains(t, w.Body.String(), "user1")
+	})
 }
 
 func TestHandler_Delete(t *testing.T) {
@@ -160,6 +328,27 @@ func TestHandler_Delete(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 		mockSvc.AssertExpectations(t)
 	})
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"forbidden", favorites.ErrForbidden, http.StatusForbidden},
+		{"not found", favorites.ErrNotFound, http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.NewString()
+			req := withUser(httptest.NewRequest(http.MethodDelete, "/favorites/"+id, nil), userID)
+			req.SetPathValue("id", id)
+			w := httptest.NewRecorder()
+			mockSvc.On("Delete", mock.Anything, id, userID).Return(tt.err).Once()
+
+			h.Delete(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
 }
 
 func TestHandler_UpdateDescription(t *testing.T) {
@@ -209,4 +398,30 @@ func TestHandler_UpdateDescription(t *testing.T) {
 		assert.Equal(t, id, respAsset.ID)
 		assert.Equal(t, desc, respAsset.Description)
 	})
+
+	t.Run("description too long", func(t *testing.T) {
+		id, userID := uuid.NewString(), uuid.NewString()
+		qerr := &favorites.QuotaError{Reason: favorites.QuotaReasonFieldLength, Field: "description", Limit: 2000}
+		mockSvc.On("UpdateDescription", mock.Anything, id, "x", userID).Return(nil, qerr).Once()
+		req := withUser(httptest.NewRequest(http.MethodPatch, "/favorites/"+id, strings.NewReader(`{"description":"x"}`)), userID)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+
+		handler.UpdateDescription(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"reason":"max_field_length"`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id, userID := uuid.NewString(), uuid.NewString()
+		mockSvc.On("UpdateDescription", mock.Anything, id, "x", userID).Return(nil, favorites.ErrNotFound).Once()
+		req := withUser(httptest.NewRequest(http.MethodPatch, "/favorites/"+id, strings.NewReader(`{"description":"x"}`)), userID)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+
+		handler.UpdateDescription(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds all checks of one readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck probes one dependency.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Critical checks make the instance unready when they fail. A failing
+	// non-critical check only reports the instance as degraded, e.g. when
+	// the cache is down and reads are served from the database.
+	Critical bool
+}
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	checks []HealthCheck
+	logger *slog.Logger
+}
+
+func NewHealthHandler(logger *slog.Logger, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks, logger: logger}
+}
+
+type readinessResponse struct {
+	// Status is "ok", "degraded" or "unavailable".
+	Status string `json:"status"`
+	// Checks maps each check to "ok", "degraded" or "down".
+	Checks map[string]string `json:"checks"`
+}
+
+// Live handles GET /healthz
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready handles GET /readyz
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	resp := readinessResponse{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	code := http.StatusOK
+	for _, check := range h.checks {
+		err := check.Check(ctx)
+		if err == nil {
+			resp.Checks[check.Name] = "ok"
+			continue
+		}
+		// Errors stay in the logs; probes may be reachable from outside.
+		h.logger.Warn("readiness check failed", "check", check.Name, "error", err)
+		if check.Critical {
+			resp.Checks[check.Name] = "down"
+			resp.Status, code = "unavailable", http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[check.Name] = "degraded"
+		if resp.Status == "ok" {
+			resp.Status = "degraded"
+		}
+	}
+	h.respondJSON(w, code, resp)
+}
+
+func (h *HealthHandler) respondJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
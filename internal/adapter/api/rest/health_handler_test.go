@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("cache degraded: circuit open") }
+
+	tests := []struct {
+		name       string
+		checks     []HealthCheck
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:       "all healthy",
+			checks:     []HealthCheck{{Name: "database", Check: ok, Critical: true}, {Name: "cache", Check: ok}},
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+		},
+		{
+			name:       "cache down stays ready",
+			checks:     []HealthCheck{{Name: "database", Check: ok, Critical: true}, {Name: "cache", Check: down}},
+			wantCode:   http.StatusOK,
+			wantStatus: "degraded",
+		},
+		{
+			name:       "database down",
+			checks:     []HealthCheck{{Name: "database", Check: down, Critical: true}, {Name: "cache", Check: down}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(logger, tt.checks...)
+			rec := httptest.NewRecorder()
+
+			h.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "circuit open")
+			var resp readinessResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Len(t, resp.Checks, len(tt.checks))
+		})
+	}
+}
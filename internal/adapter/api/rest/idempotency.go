@@ -0,0 +1,180 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+const (
+	// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTTL bounds how long a request holds its key before it
+	// completes, so that a replica dying mid-request does not block retries.
+	idempotencyLockTTL = time.Minute
+)
+
+// Idempotency replays the response to a request sent again with the same
+// Idempotency-Key header. Keys are scoped to the user, and requests without
+// one are handled as usual. A nil *Idempotency lets every request through.
+type Idempotency struct {
+	store  ports.IdempotencyStore
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// NewIdempotency keeps responses for ttl.
+func NewIdempotency(store ports.IdempotencyStore, ttl time.Duration, logger *slog.Logger) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, logger: logger}
+}
+
+// Middleware must follow AuthMiddleware. Only successes and conflicts are
+// kept; after any other response the key is released, so the request can be
+// retried with the same key once its cause is fixed.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	if i == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		userID, _ := r.Context().Value(userIDKey).(string)
+		if key == "" || userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			i.respondError(w, http.StatusBadRequest, errors.New("Idempotency-Key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			i.respondError(w, decodeStatus(err), err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+		scoped := userID + ":" + key
+
+		rec, reserved, err := i.store.Reserve(r.Context(), scoped, fingerprint, idempotencyLockTTL)
+		if err != nil {
+			// Without the store a retry may run twice, as it would without
+			// the header; that beats refusing the request.
+			i.logger.ErrorContext(r.Context(), "idempotency store failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !reserved {
+			i.replay(w, r, rec, fingerprint)
+			return
+		}
+
+		// The request is over by the time its key is updated; do not let
+		// its cancellation lose the record.
+		ctx := context.WithoutCancel(r.Context())
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		handled := false
+		defer func() {
+			// A panicking handler leaves the key free for a retry.
+			if !handled || !keepResponse(rw.status) {
+				if err := i.store.Release(ctx, scoped); err != nil {
+					i.logger.ErrorContext(ctx, "failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+		next.ServeHTTP(rw, r)
+		handled = true
+		if !keepResponse(rw.status) {
+			return
+		}
+
+		done := ports.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      rw.status,
+			ContentType: rw.Header().Get("Content-Type"),
+			Body:        rw.body.Bytes(),
+		}
+		if err := i.store.Complete(ctx, scoped, done, i.ttl); err != nil {
+			i.logger.ErrorContext(ctx, "failed to store idempotent response", "error", err)
+		}
+	})
+}
+
+// keepResponse reports whether a response is final for its key. A 409 means
+// the favorite exists, which a retry cannot change. Other errors, such as an
+// unverified email, a full quota or an outage, may pass once their cause is
+// fixed, so replaying them for IDEMPOTENCY_TTL would wrongly block the retry.
+func keepResponse(status int) bool {
+	return status >= 200 && status < 300 || status == http.StatusConflict
+}
+
+// replay answers a request whose key was claimed before.
+func (i *Idempotency) replay(w http.ResponseWriter, r *http.Request, rec ports.IdempotencyRecord, fingerprint string) {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		i.respondError(w, http.StatusUnprocessableEntity, errors.New("Idempotency-Key was used with a different request"))
+	case !rec.Done:
+		w.Header().Set("Retry-After", "1")
+		i.respondError(w, http.StatusConflict, errors.New("a request with this Idempotency-Key is in progress"))
+	default:
+		if rec.ContentType != "" {
+			w.Header().Set("Content-Type", rec.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(rec.Status)
+		if _, err := w.Write(rec.Body); err != nil {
+			i.logger.ErrorContext(r.Context(), "failed to write response", "error", err)
+		}
+	}
+}
+
+// requestFingerprint identifies a request by method, path, query and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (i *Idempotency) respondError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
+		i.logger.Error("failed to write response", "error", err)
+	}
+}
+
+// recordingWriter passes the response through and keeps a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status, rw.wroteHeader = status, true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// memoryIdempotencyStore is a map-backed ports.IdempotencyStore.
+type memoryIdempotencyStore struct {
+	records map[string]ports.IdempotencyRecord
+	err     error
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (ports.IdempotencyRecord, bool, error) {
+	if s.err != nil {
+		return ports.IdempotencyRecord{}, false, s.err
+	}
+	if rec, ok := s.records[key]; ok {
+		return rec, false, nil
+	}
+	s.records[key] = ports.IdempotencyRecord{Fingerprint: fingerprint}
+	return ports.IdempotencyRecord{}, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, key string, rec ports.IdempotencyRecord, ttl time.Duration) error {
+	s.records[key] = rec
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ForgetUser(ctx context.Context, userID string) error {
+	for key := range s.records {
+		if strings.HasPrefix(key, userID+":") {
+			delete(s.records, key)
+		}
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	status := http.StatusCreated
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"n":` + string(rune('0'+calls)) + `}`))
+	})
+	store := &memoryIdempotencyStore{records: make(map[string]ports.IdempotencyRecord)}
+	h := NewIdempotency(store, time.Hour, slog.Default()).Middleware(next)
+
+	send := func(user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/favorites", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withUser(req, user))
+		return w
+	}
+
+	t.Run("retries replay the first response", func(t *testing.T) {
+		first := send("u1", "k1", `{"name":"a"}`)
+		retry := send("u1", "k1", `{"name":"a"}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+		assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+		assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("a different body is refused", func(t *testing.T) {
+		w := send("u1", "k1", `{"name":"b"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("keys are scoped to the user", func(t *testing.T) {
+		w := send("u2", "k1", `{"name":"a"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("a request in progress is not run twice", func(t *testing.T) {
+		store.records["u1:k2"] = ports.IdempotencyRecord{Fingerprint: requestFingerprint(httptest.NewRequest(http.MethodPost, "/favorites", nil), []byte(`{}`))}
+		w := send("u1", "k2", `{}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("server errors are not kept", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		send("u1", "k3", `{}`)
+		status = http.StatusCreated
+		w := send("u1", "k3", `{}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("client errors other than conflicts are not kept", func(t *testing.T) {
+		for i, code := range []int{http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusTooManyRequests} {
+			key := "k4-" + string(rune('a'+i))
+			status = code
+			send("u1", key, `{}`)
+			status = http.StatusCreated
+			w := send("u1", key, `{}`)
+			assert.Equal(t, http.StatusCreated, w.Code, "after %d", code)
+			assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+		}
+	})
+
+	t.Run("conflicts are kept", func(t *testing.T) {
+		status = http.StatusConflict
+		send("u1", "k5", `{}`)
+		status = http.StatusCreated
+		w := send("u1", "k5", `{}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("requests without a key run every time", func(t *testing.T) {
+		before := calls
+		send("u1", "", `{}`)
+		send("u1", "", `{}`)
+		assert.Equal(t, before+2, calls)
+	})
+
+	t.Run("store failure runs the request", func(t *testing.T) {
+		store.err = errors.New("down")
+		defer func() { store.err = nil }()
+		before := calls
+		w := send("u1", "k4", `{}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, before+1, calls)
+	})
+}
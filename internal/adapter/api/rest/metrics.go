@@ -0,0 +1,15 @@
+package rest
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var rateLimitedRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_rate_limited_requests_total",
+		Help: "Requests refused with 429 by the rate limiter, by route class (auth, read, write)",
+	},
+	[]string{"class"},
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitedRequests)
+}
@@ -2,13 +2,20 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
 )
 
 type contextKey string
@@ -16,6 +23,8 @@ type contextKey string
 const (
 	requestIDKey contextKey = "request_id"
 	userIDKey    contextKey = "user_id"
+	sessionIDKey contextKey = "session_id"
+	clientIPKey  contextKey = "client_ip"
 )
 
 // Middleware allows wrapping handlers with common logic.
@@ -67,8 +76,74 @@ func Logger(logger *slog.Logger) Middleware {
 	}
 }
 
-// AuthMiddleware validates JWT and extracts UserID
-func AuthMiddleware(secret string) Middleware {
+// ClientIP attaches the client's address to the context. It is the peer
+// address, unless the peer is one of the trusted proxies: then it is the
+// last address in X-Forwarded-For that is not a trusted proxy, since the
+// addresses before it can be forged by the client. It must precede
+// RequestMetadata and the rate limits.
+func ClientIP(trusted []netip.Prefix) Middleware {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := peerIP(r)
+			if addr, err := netip.ParseAddr(ip); err == nil && isTrusted(addr) {
+				hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+				for i := len(hops) - 1; i >= 0; i-- {
+					hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+					if err != nil {
+						break
+					}
+					ip = hop.Unmap().String()
+					if !isTrusted(hop) {
+						break
+					}
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey, ip)))
+		})
+	}
+}
+
+// RequestMetadata attaches the request ID and the client to the context, for
+// the audit entries recorded while serving the request. It must follow
+// RequestID.
+func RequestMetadata(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rid, _ := r.Context().Value(requestIDKey).(string)
+		client := clientInfo(r)
+		ctx := audit.WithMetadata(r.Context(), audit.Metadata{RequestID: rid, IP: client.IP, UserAgent: client.UserAgent})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// MaxBodySize limits request bodies to limit bytes. Requests that declare a
+// longer body are refused with 413 at once; for the others, reading past the
+// limit fails with an *http.MaxBytesError.
+func MaxBodySize(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": (&http.MaxBytesError{Limit: limit}).Error()})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthMiddleware validates JWT and extracts UserID.
+// When sessions is not nil, the token's "sid" claim must name an active session.
+func AuthMiddleware(secret string, sessions ports.SessionService) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -102,6 +177,13 @@ func AuthMiddleware(secret string) Middleware {
 				return
 			}
 
+			// Action tokens (password reset, email verification) share the signing key
+			// but must never be accepted as bearer tokens.
+			if _, scoped := claims["purpose"]; scoped {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+
 			sub, ok := claims["sub"].(string)
 			if !ok {
 				http.Error(w, "invalid token subject", http.StatusUnauthorized)
@@ -109,6 +191,24 @@ func AuthMiddleware(secret string) Middleware {
 			}
 
 			ctx := context.WithValue(r.Context(), userIDKey, sub)
+
+			if sessions != nil {
+				sid, _ := claims["sid"].(string)
+				if sid == "" {
+					http.Error(w, "invalid token session", http.StatusUnauthorized)
+					return
+				}
+				if err := sessions.Validate(r.Context(), sub, sid); err != nil {
+					if errors.Is(err, auth.ErrSessionRevoked) {
+						http.Error(w, err.Error(), http.StatusUnauthorized)
+						return
+					}
+					http.Error(w, "failed to check session", http.StatusInternalServerError)
+					return
+				}
+				ctx = context.WithValue(ctx, sessionIDKey, sid)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -123,3 +223,13 @@ func (rw *responseWriter) WriteHeader(status int) {
 	rw.status = status
 	rw.ResponseWriter.WriteHeader(status)
 }
+
+// Flush lets streaming handlers that assert http.Flusher flush through the
+// wrapper; http.ResponseController finds the writer through Unwrap.
+func (rw *responseWriter) Flush() {
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -1,11 +1,21 @@
 package rest
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/auth"
 )
 
 func TestRequestID(t *testing.T) {
@@ -42,6 +52,80 @@ func TestRequestID(t *testing.T) {
 	})
 }
 
+func TestRequestMetadata(t *testing.T) {
+	var got audit.Metadata
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = audit.MetadataFrom(r.Context())
+	}), RequestID, RequestMetadata)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:4321"
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("X-Request-ID", "rid-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, audit.Metadata{RequestID: "rid-1", IP: "192.0.2.1", UserAgent: "curl/8.0"}, got)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	resolve := func(remoteAddr string, forwardedFor ...string) string {
+		var got audit.Metadata
+		h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = audit.MetadataFrom(r.Context())
+		}), ClientIP(trusted), RequestMetadata)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		for _, v := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return got.IP
+	}
+
+	t.Run("forwarded for by a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.4", resolve("10.0.0.1:80", "198.51.100.4"))
+		// The client cannot pick its address by sending the header itself.
+		assert.Equal(t, "198.51.100.4", resolve("10.0.0.1:80", "203.0.113.66, 198.51.100.4"))
+		// Chained proxies are skipped, across repeated headers.
+		assert.Equal(t, "198.51.100.4", resolve("10.0.0.1:80", "203.0.113.66, 198.51.100.4", "10.0.0.2"))
+		assert.Equal(t, "10.0.0.1", resolve("10.0.0.1:80"))
+		assert.Equal(t, "10.0.0.1", resolve("10.0.0.1:80", "garbage"))
+	})
+
+	t.Run("header ignored from any other peer", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.1", resolve("192.0.2.1:4321", "198.51.100.4"))
+	})
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var readErr error
+	h := MaxBodySize(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	t.Run("declared length over the limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("0123456789")))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("undeclared length over the limit", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("0123456789"))
+		req.ContentLength = -1
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		var maxErr *http.MaxBytesError
+		assert.ErrorAs(t, readErr, &maxErr)
+	})
+
+	t.Run("within the limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("01234567")))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, readErr)
+	})
+}
+
 func TestChain(t *testing.T) {
 	var calls []string
 	mw1 := func(next http.Handler) http.Handler {
@@ -66,3 +150,73 @@ func TestChain(t *testing.T) {
 
 	assert.Equal(t, []string{"mw1", "mw2", "final"}, calls, "Middleware should be called in order")
 }
+
+func TestAuthMiddleware(t *testing.T) {
+	const secret = "test-secret"
+	sign := func(claims jwt.MapClaims) string {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return token
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user-1", r.Context().Value(userIDKey))
+	})
+	handler := AuthMiddleware(secret, nil)(next)
+
+	t.Run("accepts login token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("rejects action token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(jwt.MapClaims{
+			"sub":     "user-1",
+			"purpose": "password_reset",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAuthMiddleware_Sessions(t *testing.T) {
+	const secret = "test-secret"
+	sign := func(claims jwt.MapClaims) string {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return token
+	}
+	sessions := new(MockSessionService)
+	sessions.On("Validate", mock.Anything, "user-1", "live").Return(nil)
+	sessions.On("Validate", mock.Anything, "user-1", "gone").Return(auth.ErrSessionRevoked)
+	sessions.On("Validate", mock.Anything, "user-1", "broken").Return(errors.New("db down"))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "live", r.Context().Value(sessionIDKey))
+	})
+	handler := AuthMiddleware(secret, sessions)(next)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"active session", jwt.MapClaims{"sub": "user-1", "sid": "live"}, http.StatusOK},
+		{"revoked session", jwt.MapClaims{"sub": "user-1", "sid": "gone"}, http.StatusUnauthorized},
+		{"missing sid", jwt.MapClaims{"sub": "user-1"}, http.StatusUnauthorized},
+		{"lookup failure", jwt.MapClaims{"sub": "user-1", "sid": "broken"}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+sign(tt.claims))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
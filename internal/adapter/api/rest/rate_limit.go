@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// RouteClass groups routes that share a rate limit.
+type RouteClass string
+
+const (
+	// RouteClassAuth covers the public signup, login and password routes,
+	// limited per client IP.
+	RouteClassAuth RouteClass = "auth"
+	// RouteClassRead and RouteClassWrite cover authenticated GET and
+	// non-GET requests, limited per user.
+	RouteClassRead  RouteClass = "read"
+	RouteClassWrite RouteClass = "write"
+)
+
+// RateLimits throttles requests by route class. A nil *RateLimits, or a
+// class without a rate, lets every request through.
+type RateLimits struct {
+	limiter ports.RateLimiter
+	rates   map[RouteClass]ports.Rate
+	logger  *slog.Logger
+}
+
+func NewRateLimits(limiter ports.RateLimiter, rates map[RouteClass]ports.Rate, logger *slog.Logger) *RateLimits {
+	return &RateLimits{limiter: limiter, rates: rates, logger: logger}
+}
+
+// Auth limits the public authentication routes.
+func (l *RateLimits) Auth(next http.Handler) http.Handler {
+	return l.limit(next, func(*http.Request) RouteClass { return RouteClassAuth })
+}
+
+// API limits authenticated routes as reads or writes by method. It must
+// follow AuthMiddleware.
+func (l *RateLimits) API(next http.Handler) http.Handler {
+	return l.limit(next, func(r *http.Request) RouteClass {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return RouteClassRead
+		}
+		return RouteClassWrite
+	})
+}
+
+func (l *RateLimits) limit(next http.Handler, classOf func(*http.Request) RouteClass) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := classOf(r)
+		rate, ok := l.rates[class]
+		if !ok || rate.Limit < 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res, err := l.limiter.Allow(r.Context(), string(class)+":"+rateLimitSubject(r), rate)
+		if err != nil {
+			// Failing open keeps the API up; the limiter guard already
+			// falls back to in-process counting when Redis is down.
+			l.logger.ErrorContext(r.Context(), "rate limiter failed", "class", class, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rate.Limit, ceilSeconds(rate.Period)))
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+		if !res.Allowed {
+			rateLimitedRequests.WithLabelValues(string(class)).Inc()
+			h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			h.Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitSubject identifies the caller: the authenticated user, or the
+// client IP for anonymous requests.
+func rateLimitSubject(r *http.Request) string {
+	if userID, ok := r.Context().Value(userIDKey).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + clientInfo(r).IP
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers require.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
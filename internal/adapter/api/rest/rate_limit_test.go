@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// countingLimiter allows the first Limit requests of each key.
+type countingLimiter struct {
+	counts map[string]int
+	err    error
+}
+
+func (c *countingLimiter) Allow(ctx context.Context, key string, rate ports.Rate) (ports.RateLimitResult, error) {
+	if c.err != nil {
+		return ports.RateLimitResult{}, c.err
+	}
+	c.counts[key]++
+	if c.counts[key] > rate.Limit {
+		return ports.RateLimitResult{Limit: rate.Limit, RetryAfter: 1500 * time.Millisecond, ResetAfter: rate.Period}, nil
+	}
+	return ports.RateLimitResult{Allowed: true, Limit: rate.Limit, Remaining: rate.Limit - c.counts[key], ResetAfter: time.Second}, nil
+}
+
+func TestRateLimits(t *testing.T) {
+	limiter := &countingLimiter{counts: make(map[string]int)}
+	limits := NewRateLimits(limiter, map[RouteClass]ports.Rate{
+		RouteClassAuth:  {Limit: 1, Period: time.Minute},
+		RouteClassWrite: {Limit: 2, Period: time.Minute},
+	}, slog.Default())
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("auth routes are limited per IP", func(t *testing.T) {
+		h := limits.Auth(ok)
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, 2, limiter.counts["auth:ip:192.0.2.1"])
+	})
+
+	t.Run("clients behind a trusted proxy have their own budget", func(t *testing.T) {
+		h := Chain(limits.Auth(ok), ClientIP([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+		send := func(remoteAddr, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, send("10.0.0.1:1234", "198.51.100.1"))
+		assert.Equal(t, http.StatusOK, send("10.0.0.1:1234", "198.51.100.2"))
+		assert.Equal(t, http.StatusTooManyRequests, send("10.0.0.1:1234", "198.51.100.1"))
+		// An untrusted peer is counted by its own address, whatever it forwards.
+		assert.Equal(t, http.StatusOK, send("192.0.2.9:1234", "198.51.100.3"))
+		assert.Equal(t, http.StatusTooManyRequests, send("192.0.2.9:1234", "198.51.100.4"))
+		assert.Equal(t, 2, limiter.counts["auth:ip:192.0.2.9"])
+	})
+
+	t.Run("writes are limited per user", func(t *testing.T) {
+		h := limits.API(ok)
+		for _, user := range []string{"u1", "u1", "u2"} {
+			req := httptest.NewRequest(http.MethodPost, "/favorites", nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, withUser(req, user))
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withUser(httptest.NewRequest(http.MethodDelete, "/favorites/1", nil), "u1"))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("classes without a rate are not limited", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		limits.API(ok).ServeHTTP(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites", nil), "u1"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("limiter failure lets requests through", func(t *testing.T) {
+		failing := NewRateLimits(&countingLimiter{err: errors.New("down")}, map[RouteClass]ports.Rate{
+			RouteClassAuth: {Limit: 1, Period: time.Minute},
+		}, slog.Default())
+		w := httptest.NewRecorder()
+		failing.Auth(ok).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("nil limits", func(t *testing.T) {
+		var none *RateLimits
+		w := httptest.NewRecorder()
+		none.API(ok).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/favorites", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -2,29 +2,116 @@ package rest
 
 import (
 	"net/http"
+
+	"go-favorites-app/internal/core/ports"
 )
 
+// Handlers groups the HTTP handlers mounted by the router.
+// Optional handlers left nil are not registered.
+type Handlers struct {
+	Favorites *Handler
+	Auth      *AuthHandler
+	Account   *AccountHandler
+	// Sessions, when set, also makes AuthMiddleware reject revoked sessions.
+	Sessions *SessionHandler
+	Webhooks *WebhookHandler
+	Feed     *FeedHandler
+	Audit    *AuditHandler
+	Health   *HealthHandler
+	// RateLimits, when set, throttles the auth routes per client IP and the
+	// protected routes per user.
+	RateLimits *RateLimits
+	// Idempotency, when set, replays responses to POST /favorites,
+	// POST /favorites:batch and POST /favorites/import requests retried
+	// with the same Idempotency-Key.
+	Idempotency *Idempotency
+}
+
 // NewRouter initializes the HTTP router and registers routes.
-func NewRouter(h *Handler, authH *AuthHandler, jwtSecret string, mws ...Middleware) http.Handler {
+func NewRouter(handlers Handlers, jwtSecret string, mws ...Middleware) http.Handler {
 	mux := http.NewServeMux()
+	h, authH, limits := handlers.Favorites, handlers.Auth, handlers.RateLimits
 
 	// Auth Routes (Public)
-	mux.HandleFunc("POST /signup", authH.SignUp)
-	mux.HandleFunc("POST /login", authH.Login)
+	mux.Handle("POST /signup", limits.Auth(http.HandlerFunc(authH.SignUp)))
+	mux.Handle("POST /login", limits.Auth(http.HandlerFunc(authH.Login)))
+	mux.Handle("POST /password/forgot", limits.Auth(http.HandlerFunc(authH.ForgotPassword)))
+	mux.Handle("POST /password/reset", limits.Auth(http.HandlerFunc(authH.ResetPassword)))
+	mux.Handle("GET /verify-email", limits.Auth(http.HandlerFunc(authH.VerifyEmail)))
 
 	// Public Routes
 	// mux.HandleFunc("GET /favorites", h.List)  // Moved to protected
 	// mux.HandleFunc("GET /favorites/{id}", h.Get) // Moved to protected
 
 	// Protected Routes
-	auth := AuthMiddleware(jwtSecret)
+	var sessions ports.SessionService
+	if handlers.Sessions != nil {
+		sessions = handlers.Sessions.service
+	}
+	authenticate := AuthMiddleware(jwtSecret, sessions)
+	auth := func(next http.Handler) http.Handler {
+		return authenticate(limits.API(next))
+	}
+
+	// Every resend sends a mail, so it shares the budget of the auth routes.
+	mux.Handle("POST /verify-email/resend", limits.Auth(auth(http.HandlerFunc(authH.ResendVerification))))
 
 	mux.Handle("GET /favorites", auth(http.HandlerFunc(h.List)))
 	mux.Handle("GET /favorites/{id}", auth(http.HandlerFunc(h.Get)))
-	mux.Handle("POST /favorites", auth(http.HandlerFunc(h.Create)))
+	mux.Handle("POST /favorites", auth(handlers.Idempotency.Middleware(authH.RequireVerifiedEmail(http.HandlerFunc(h.Create)))))
+	mux.Handle("POST /favorites:batch", auth(handlers.Idempotency.Middleware(authH.RequireVerifiedEmail(http.HandlerFunc(h.CreateBatch)))))
+	mux.Handle("POST /favorites:batchUpdate", auth(http.HandlerFunc(h.UpdateBatch)))
+	mux.Handle("POST /favorites:batchDelete", auth(http.HandlerFunc(h.DeleteBatch)))
+	mux.Handle("GET /favorites/export", auth(http.HandlerFunc(h.Export)))
+	mux.Handle("POST /favorites/import", auth(handlers.Idempotency.Middleware(authH.RequireVerifiedEmail(http.HandlerFunc(h.Import)))))
 	// mux.Handle("GET /favorites/mine", auth(http.HandlerFunc(h.ListMine))) // Removed, redundant
 	mux.Handle("DELETE /favorites/{id}", auth(http.HandlerFunc(h.Delete)))
 	mux.Handle("PATCH /favorites/{id}", auth(http.HandlerFunc(h.UpdateDescription)))
+	mux.Handle("GET /me/usage", auth(http.HandlerFunc(h.Usage)))
+
+	if feedH := handlers.Feed; feedH != nil {
+		mux.Handle("GET /favorites/events", auth(http.HandlerFunc(feedH.Events)))
+	}
+
+	// Account Routes
+	if accountH := handlers.Account; accountH != nil {
+		mux.Handle("GET /me", auth(http.HandlerFunc(accountH.Me)))
+		mux.Handle("PATCH /me", auth(http.HandlerFunc(accountH.UpdateMe)))
+		mux.Handle("DELETE /me", auth(http.HandlerFunc(accountH.DeleteMe)))
+		mux.Handle("GET /me/export", auth(http.HandlerFunc(accountH.Export)))
+		mux.Handle("POST /me/password", auth(http.HandlerFunc(accountH.ChangePassword)))
+		mux.Handle("POST /me/email", auth(http.HandlerFunc(accountH.ChangeEmail)))
+	}
+
+	// Session Routes
+	if sessionH := handlers.Sessions; sessionH != nil {
+		mux.Handle("GET /me/sessions", auth(http.HandlerFunc(sessionH.List)))
+		mux.Handle("POST /me/sessions/revoke-others", auth(http.HandlerFunc(sessionH.RevokeOthers)))
+		mux.Handle("DELETE /me/sessions/{id}", auth(http.HandlerFunc(sessionH.Revoke)))
+	}
+
+	// Webhook Routes
+	if webhookH := handlers.Webhooks; webhookH != nil {
+		mux.Handle("POST /webhooks", auth(http.HandlerFunc(webhookH.Create)))
+		mux.Handle("GET /webhooks", auth(http.HandlerFunc(webhookH.List)))
+		mux.Handle("DELETE /webhooks/{id}", auth(http.HandlerFunc(webhookH.Delete)))
+		mux.Handle("GET /webhooks/{id}/deliveries", auth(http.HandlerFunc(webhookH.Deliveries)))
+		mux.Handle("POST /webhooks/{id}/test", auth(http.HandlerFunc(webhookH.Test)))
+	}
+
+	// Audit Routes
+	if auditH := handlers.Audit; auditH != nil {
+		mux.Handle("GET /me/audit", auth(http.HandlerFunc(auditH.Mine)))
+		mux.Handle("GET /me/audit/export", auth(http.HandlerFunc(auditH.ExportMine)))
+		mux.Handle("GET /admin/audit", auth(http.HandlerFunc(auditH.Search)))
+		mux.Handle("GET /admin/audit/export", auth(http.HandlerFunc(auditH.Export)))
+	}
+
+	// Probes
+	if healthH := handlers.Health; healthH != nil {
+		mux.HandleFunc("GET /healthz", healthH.Live)
+		mux.HandleFunc("GET /readyz", healthH.Ready)
+	}
 
 	// Documentation
 	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
+)
+
+// SessionHandler serves the /me/sessions endpoints.
+type SessionHandler struct {
+	service ports.SessionService
+	logger  *slog.Logger
+}
+
+func NewSessionHandler(service ports.SessionService, logger *slog.Logger) *SessionHandler {
+	return &SessionHandler{service: service, logger: logger}
+}
+
+type sessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// List handles GET /me/sessions
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	currentID, _ := r.Context().Value(sessionIDKey).(string)
+
+	sessions, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list sessions", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+
+	resp := make([]sessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, sessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentID,
+		})
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// Revoke handles DELETE /me/sessions/{id}
+// Revoking the current session logs the caller out.
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), userID, r.PathValue("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			h.respondError(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("failed to revoke session", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOthers handles POST /me/sessions/revoke-others
+// It logs out every device except the one making the request.
+func (h *SessionHandler) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	currentID, _ := r.Context().Value(sessionIDKey).(string)
+
+	n, err := h.service.RevokeOthers(r.Context(), userID, currentID)
+	if err != nil {
+		h.logger.Error("failed to revoke sessions", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+		return
+	}
+	h.respondJSON(w, http.StatusOK, map[string]int{"revoked": n})
+}
+
+func (h *SessionHandler) respondJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h *SessionHandler) respondError(w http.ResponseWriter, code int, err error) {
+	h.respondJSON(w, code, map[string]string{"error": err.Error()})
+}
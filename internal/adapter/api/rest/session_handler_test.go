@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/auth"
+)
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) List(ctx context.Context, userID string) ([]auth.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]auth.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeOthers(ctx context.Context, userID, currentID string) (int, error) {
+	args := m.Called(ctx, userID, currentID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSessionService) Validate(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func withSession(req *http.Request, userID, sessionID string) *http.Request {
+	ctx := context.WithValue(req.Context(), userIDKey, userID)
+	return req.WithContext(context.WithValue(ctx, sessionIDKey, sessionID))
+}
+
+func TestSessionHandler_List(t *testing.T) {
+	sessions := new(MockSessionService)
+	h := NewSessionHandler(sessions, slog.Default())
+
+	now := time.Now()
+	sessions.On("List", mock.Anything, "user1").Return([]auth.Session{
+		{ID: "s1", UserAgent: "Firefox", IP: "192.0.2.1", CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "s2", UserAgent: "curl", IP: "192.0.2.2", CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	h.List(w, withSession(httptest.NewRequest(http.MethodGet, "/me/sessions", nil), "user1", "s2"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body []sessionResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body, 2) {
+		assert.False(t, body[0].Current)
+		assert.True(t, body[1].Current)
+		assert.Equal(t, "Firefox", body[0].UserAgent)
+	}
+}
+
+func TestSessionHandler_Revoke(t *testing.T) {
+	sessions := new(MockSessionService)
+	h := NewSessionHandler(sessions, slog.Default())
+
+	sessions.On("Revoke", mock.Anything, "user1", "s1").Return(nil)
+	sessions.On("Revoke", mock.Anything, "user1", "other").Return(auth.ErrSessionNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/me/sessions/s1", nil)
+	req.SetPathValue("id", "s1")
+	w := httptest.NewRecorder()
+	h.Revoke(w, withSession(req, "user1", "s2"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/me/sessions/other", nil)
+	req.SetPathValue("id", "other")
+	w = httptest.NewRecorder()
+	h.Revoke(w, withSession(req, "user1", "s2"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionHandler_RevokeOthers(t *testing.T) {
+	sessions := new(MockSessionService)
+	h := NewSessionHandler(sessions, slog.Default())
+
+	sessions.On("RevokeOthers", mock.Anything, "user1", "s2").Return(3, nil)
+
+	w := httptest.NewRecorder()
+	h.RevokeOthers(w, withSession(httptest.NewRequest(http.MethodPost, "/me/sessions/revoke-others", nil), "user1", "s2"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked":3}`, w.Body.String())
+}
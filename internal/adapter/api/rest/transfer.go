@@ -0,0 +1,346 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+// Formats of an export or import.
+const (
+	formatNDJSON = "ndjson"
+	formatJSON   = "json"
+	formatCSV    = "csv"
+)
+
+var formatContentTypes = map[string]string{
+	formatNDJSON: "application/x-ndjson",
+	formatJSON:   "application/json",
+	formatCSV:    "text/csv",
+}
+
+// csvColumns are the columns of a CSV export. Type-specific fields are
+// flattened; those that do not apply to an asset's type are left empty.
+var csvColumns = []string{
+	"id", "type", "name", "description", "updated_at",
+	"content",
+	"x_axis", "y_axis",
+	"rules.gender", "rules.country", "rules.age_min", "rules.age_max",
+}
+
+// csvIntColumns hold numbers rather than strings.
+var csvIntColumns = map[string]bool{"rules.age_min": true, "rules.age_max": true}
+
+// Export handles GET /favorites/export?format=ndjson|csv|json
+// The user's favorites are streamed as an attachment, ndjson by default.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatNDJSON
+	}
+	contentType, ok := formatContentTypes[format]
+	if !ok {
+		h.respondError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q: use %s, %s or %s", format, formatNDJSON, formatCSV, formatJSON))
+		return
+	}
+
+	assets := h.service.Export(r.Context(), userID)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="favorites.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure mid-stream truncates the
+	// export; JSON and CSV readers see it as malformed.
+	switch format {
+	case formatCSV:
+		h.streamCSV(w, userID, assets)
+	case formatJSON:
+		h.streamJSONArray(w, userID, assets)
+	default:
+		h.streamResponse(w, assets)
+	}
+}
+
+func (h *Handler) streamJSONArray(w io.Writer, userID string, assets iter.Seq2[favorites.Asset, error]) {
+	enc := json.NewEncoder(w)
+	sep := "["
+	for asset, err := range assets {
+		if err != nil {
+			h.logger.Error("failed to export favorites", "user_id", userID, "error", err)
+			return
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return
+		}
+		sep = ","
+		if err := enc.Encode(asset); err != nil {
+			h.logger.Error("encode error", "err", err)
+			return
+		}
+	}
+	if sep == "[" {
+		if _, err := io.WriteString(w, "["); err != nil {
+			h.logger.Error("failed to write response", "error", err)
+			return
+		}
+	}
+	if _, err := io.WriteString(w, "]\n"); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h *Handler) streamCSV(w io.Writer, userID string, assets iter.Seq2[favorites.Asset, error]) {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+	if err := cw.Write(csvColumns); err != nil {
+		return
+	}
+	for asset, err := range assets {
+		if err != nil {
+			h.logger.Error("failed to export favorites", "user_id", userID, "error", err)
+			return
+		}
+		if err := cw.Write(csvRecord(asset)); err != nil {
+			h.logger.Error("encode error", "err", err)
+			return
+		}
+	}
+}
+
+// csvRecord flattens the asset into csvColumns, escaping formulas.
+func csvRecord(asset favorites.Asset) []string {
+	fields := map[string]string{
+		"id":   asset.GetID(),
+		"type": string(asset.GetType()),
+	}
+	if t := asset.GetUpdatedAt(); !t.IsZero() {
+		fields["updated_at"] = t.UTC().Format(time.RFC3339Nano)
+	}
+	maps.Copy(fields, favorites.Fields(asset))
+	if a, ok := asset.(favorites.Audience); ok {
+		if a.Rules.AgeMin != 0 {
+			fields["rules.age_min"] = strconv.Itoa(a.Rules.AgeMin)
+		}
+		if a.Rules.AgeMax != 0 {
+			fields["rules.age_max"] = strconv.Itoa(a.Rules.AgeMax)
+		}
+	}
+
+	record := make([]string, len(csvColumns))
+	for i, col := range csvColumns {
+		record[i] = escapeCSVCell(fields[col])
+	}
+	return record
+}
+
+// csvFormulaPrefixes start a cell that spreadsheets evaluate as a formula.
+// The escape character itself is included so that unescaping is lossless.
+const csvFormulaPrefixes = "=+-@'"
+
+// escapeCSVCell prefixes a cell that a spreadsheet would run as a formula
+// with a single quote, which shows it as text. unescapeCSVCell undoes it.
+func escapeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune(csvFormulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func unescapeCSVCell(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(cell[1])) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// Import handles POST /favorites/import
+// The body is an export in any format, chosen by ?format= or else by
+// Content-Type. ?on_conflict= says what to do with an ID that is taken:
+// skip (the default), overwrite or new_id. ?mode= is as for CreateBatch,
+// and ?dry_run=true reports the outcome without saving anything.
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	query := r.URL.Query()
+	opts := favorites.ImportOptions{
+		Mode:       favorites.BatchMode(query.Get("mode")),
+		OnConflict: favorites.ConflictPolicy(query.Get("on_conflict")),
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = favorites.BatchAtomic
+	case favorites.BatchAtomic, favorites.BatchBestEffort:
+	default:
+		h.respondError(w, http.StatusBadRequest, fmt.Errorf("unknown mode %q: use %s or %s", opts.Mode, favorites.BatchAtomic, favorites.BatchBestEffort))
+		return
+	}
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = favorites.ConflictSkip
+	case favorites.ConflictSkip, favorites.ConflictOverwrite, favorites.ConflictNewID:
+	default:
+		h.respondError(w, http.StatusBadRequest, fmt.Errorf("unknown on_conflict %q: use %s, %s or %s", opts.OnConflict, favorites.ConflictSkip, favorites.ConflictOverwrite, favorites.ConflictNewID))
+		return
+	}
+	if v := query.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Errorf("invalid dry_run %q", v))
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = formatJSON
+		for f, ct := range formatContentTypes {
+			if ct == mediaType {
+				format = f
+			}
+		}
+	}
+	if _, ok := formatContentTypes[format]; !ok {
+		h.respondError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q: use %s, %s or %s", format, formatNDJSON, formatCSV, formatJSON))
+		return
+	}
+
+	items, err := h.decodeItems(r.Body, format)
+	if err != nil {
+		code := decodeStatus(err)
+		if errors.Is(err, errBatchTooLarge) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		h.respondError(w, code, err)
+		return
+	}
+
+	// Items that do not parse are reported alongside the service's results.
+	results := make([]favorites.BatchResult, len(items))
+	var assets []favorites.Asset
+	var indexes []int
+	for i, raw := range items {
+		asset, err := parseBatchItem(raw, userID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].ID = asset.GetID()
+		assets = append(assets, asset)
+		indexes = append(indexes, i)
+	}
+
+	switch {
+	case opts.Mode == favorites.BatchAtomic && favorites.Failed(results):
+		favorites.Abort(results)
+	case len(assets) > 0:
+		imported, err := h.service.Import(r.Context(), userID, assets, opts)
+		if err != nil {
+			h.logger.Error("failed to import favorites", "user_id", userID, "error", err)
+			h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+			return
+		}
+		for j, res := range imported {
+			results[indexes[j]] = res
+		}
+	}
+
+	code := http.StatusOK
+	if opts.Mode == favorites.BatchAtomic && favorites.Failed(results) {
+		code = http.StatusUnprocessableEntity
+	}
+	h.respondBatch(w, code, batchResponse{Mode: opts.Mode, DryRun: opts.DryRun}, results)
+}
+
+// decodeCSV passes each row of a CSV body with a header to add as a JSON
+// object, nesting the flattened columns. A cell that does not convert is
+// kept as a string so the row fails validation on its own.
+func decodeCSV(body io.Reader, add func(json.RawMessage) error) error {
+	cr := csv.NewReader(body)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidCSV, err)
+	}
+	for _, col := range header {
+		if !slices.Contains(csvColumns, col) {
+			return fmt.Errorf("%w: unknown column %q", errInvalidCSV, col)
+		}
+	}
+	if !slices.Contains(header, "type") {
+		return fmt.Errorf("%w: missing column %q", errInvalidCSV, "type")
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidCSV, err)
+		}
+		raw, err := json.Marshal(csvObject(header, record))
+		if err != nil {
+			return err
+		}
+		if err := add(raw); err != nil {
+			return err
+		}
+	}
+}
+
+var errInvalidCSV = errors.New("invalid CSV")
+
+// csvObject unflattens a CSV row; empty cells are left out.
+func csvObject(header, record []string) map[string]any {
+	obj := map[string]any{}
+	for i, col := range header {
+		cell := unescapeCSVCell(record[i])
+		if cell == "" {
+			continue
+		}
+		var value any = cell
+		if csvIntColumns[col] {
+			if n, err := strconv.Atoi(cell); err == nil {
+				value = n
+			}
+		}
+		parent, key := obj, col
+		if prefix, rest, ok := strings.Cut(col, "."); ok {
+			nested, _ := obj[prefix].(map[string]any)
+			if nested == nil {
+				nested = map[string]any{}
+				obj[prefix] = nested
+			}
+			parent, key = nested, rest
+		}
+		parent[key] = value
+	}
+	return obj
+}
@@ -0,0 +1,242 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func seqOf(assets []favorites.Asset, err error) iter.Seq2[favorites.Asset, error] {
+	return func(yield func(favorites.Asset, error) bool) {
+		for _, a := range assets {
+			if !yield(a, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// failingWriter fails every write, as a connection the client closed.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestHandler_Export(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assets := []favorites.Asset{
+		favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "i1", Type: favorites.AssetTypeInsight, Name: "Sales", UpdatedAt: updated}, Content: "up, then down"},
+		favorites.Audience{BaseAsset: favorites.BaseAsset{ID: "a1", Type: favorites.AssetTypeAudience, Name: "Teens"}, Rules: favorites.AudienceRules{Country: "GR", AgeMin: 13, AgeMax: 19}},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		mockSvc.On("Export", mock.Anything, "user1").Return(seqOf(assets, nil)).Once()
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=csv", nil), "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="favorites.csv"`, w.Header().Get("Content-Disposition"))
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, csvColumns, rows[0])
+		assert.Equal(t, []string{"i1", "insight", "Sales", "", "2026-01-02T03:04:05Z", "up, then down", "", "", "", "", "", ""}, rows[1])
+		assert.Equal(t, []string{"a1", "audience", "Teens", "", "", "", "", "", "", "GR", "13", "19"}, rows[2])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		mockSvc.On("Export", mock.Anything, "user1").Return(seqOf(assets, nil)).Once()
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=json", nil), "user1"))
+
+		var got []map[string]any
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		require.Len(t, got, 2)
+		assert.Equal(t, "i1", got[0]["id"])
+		assert.Equal(t, "a1", got[1]["id"])
+	})
+
+	t.Run("json empty", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		mockSvc.On("Export", mock.Anything, "user1").Return(seqOf(nil, nil)).Once()
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=json", nil), "user1"))
+
+		assert.Equal(t, "[]\n", w.Body.String())
+	})
+
+	t.Run("json truncated on error", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		mockSvc.On("Export", mock.Anything, "user1").Return(seqOf(assets[:1], errors.New("db down"))).Once()
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=json", nil), "user1"))
+
+		var got []map[string]any
+		assert.Error(t, json.Unmarshal(w.Body.Bytes(), &got))
+	})
+
+	t.Run("csv escapes formulas", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		formula := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "i2", Type: favorites.AssetTypeInsight, Name: "=HYPERLINK(\"x\")", Description: "'quoted"}, Content: "-1+2"}
+		mockSvc.On("Export", mock.Anything, "user1").Return(seqOf([]favorites.Asset{formula}, nil)).Once()
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=csv", nil), "user1"))
+
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, []string{"i2", "insight", "'=HYPERLINK(\"x\")", "''quoted", "", "'-1+2", "", "", "", "", "", ""}, rows[1])
+	})
+
+	t.Run("json write error", func(t *testing.T) {
+		var logs strings.Builder
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.New(slog.NewTextHandler(&logs, nil)))
+
+		h.streamJSONArray(failingWriter{}, "user1", seqOf(nil, nil))
+
+		assert.Contains(t, logs.String(), "failed to write response")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		h := NewHandler(new(MockService), slog.Default())
+		w := httptest.NewRecorder()
+
+		h.Export(w, withUser(httptest.NewRequest(http.MethodGet, "/favorites/export?format=xml", nil), "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_Import(t *testing.T) {
+	t.Run("csv reports each row", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		first, second := uuid.NewString(), uuid.NewString()
+		body := "type,id,name,rules.country,rules.age_min\n" +
+			"audience," + first + ",Teens,GR,13\n" +
+			"audience," + second + ",Adults,GR,eighteen\n"
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import?mode=best_effort&on_conflict=overwrite&dry_run=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+
+		opts := favorites.ImportOptions{Mode: favorites.BatchBestEffort, OnConflict: favorites.ConflictOverwrite, DryRun: true}
+		mockSvc.On("Import", mock.Anything, "user1", mock.MatchedBy(func(assets []favorites.Asset) bool {
+			a, ok := assets[0].(favorites.Audience)
+			return len(assets) == 1 && ok && a.ID == first && a.Rules.AgeMin == 13 && a.UserID == "user1"
+		}), opts).Return([]favorites.BatchResult{{ID: first, Action: favorites.ActionReplaced}}, nil).Once()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, 1, resp.Succeeded)
+		if assert.Len(t, resp.Results, 2) {
+			assert.Equal(t, batchItemResult{Index: 0, ID: first, Status: http.StatusOK, Action: favorites.ActionReplaced}, resp.Results[0])
+			assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+			assert.Contains(t, resp.Results[1].Error, "age_min")
+		}
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("csv unescapes formulas", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		id := uuid.NewString()
+		body := "type,id,name,description,content\n" +
+			"insight," + id + ",'=SUM(A1),''quoted,'don't\n"
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import?format=csv", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		mockSvc.On("Import", mock.Anything, "user1", mock.MatchedBy(func(assets []favorites.Asset) bool {
+			a, ok := assets[0].(favorites.Insight)
+			return len(assets) == 1 && ok && a.Name == "=SUM(A1)" && a.Description == "'quoted" && a.Content == "'don't"
+		}), mock.Anything).Return([]favorites.BatchResult{{ID: id, Action: favorites.ActionCreated}}, nil).Once()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("ndjson defaults", func(t *testing.T) {
+		mockSvc := new(MockService)
+		h := NewHandler(mockSvc, slog.Default())
+		id := uuid.NewString()
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import?format=ndjson", strings.NewReader(`{"type":"insight","id":"`+id+`","name":"n","content":"c"}`+"\n"))
+		w := httptest.NewRecorder()
+
+		opts := favorites.ImportOptions{Mode: favorites.BatchAtomic, OnConflict: favorites.ConflictSkip}
+		mockSvc.On("Import", mock.Anything, "user1", mock.Anything, opts).
+			Return([]favorites.BatchResult{{ID: id, Action: favorites.ActionSkipped}}, nil).Once()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, favorites.ActionSkipped, resp.Results[0].Action)
+	})
+
+	t.Run("atomic aborts on an invalid row", func(t *testing.T) {
+		h := NewHandler(new(MockService), slog.Default())
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import", strings.NewReader(`[{"type":"insight","id":"`+uuid.NewString()+`","name":"n","content":"c"},{"type":"unknown","name":"n"}]`))
+		w := httptest.NewRecorder()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		resp := decodeBatchResponse(t, w)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+	})
+
+	t.Run("unknown csv column", func(t *testing.T) {
+		h := NewHandler(new(MockService), slog.Default())
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import?format=csv", strings.NewReader("type,colour\ninsight,red\n"))
+		w := httptest.NewRecorder()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown conflict policy", func(t *testing.T) {
+		h := NewHandler(new(MockService), slog.Default())
+		req := httptest.NewRequest(http.MethodPost, "/favorites/import?on_conflict=merge", strings.NewReader(`[]`))
+		w := httptest.NewRecorder()
+
+		h.Import(w, withUser(req, "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,202 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+	"go-favorites-app/internal/core/ports"
+)
+
+// WebhookHandler serves the /webhooks endpoints.
+type WebhookHandler struct {
+	service ports.WebhookService
+	logger  *slog.Logger
+}
+
+func NewWebhookHandler(service ports.WebhookService, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{service: service, logger: logger}
+}
+
+type createWebhookRequest struct {
+	URL    string                `json:"url"`
+	Events []favorites.EventType `json:"events"`
+	Secret string                `json:"secret"`
+}
+
+// webhookResponse leaves the secret out; only its owner set it.
+type webhookResponse struct {
+	ID        string                `json:"id"`
+	URL       string                `json:"url"`
+	Events    []favorites.EventType `json:"events"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+func newWebhookResponse(s webhooks.Subscription) webhookResponse {
+	return webhookResponse{ID: s.ID, URL: s.URL, Events: s.Events, CreatedAt: s.CreatedAt}
+}
+
+type deliveryResponse struct {
+	ID             string                  `json:"id"`
+	EventID        string                  `json:"event_id"`
+	EventType      favorites.EventType     `json:"event_type"`
+	Status         webhooks.DeliveryStatus `json:"status"`
+	Attempts       int                     `json:"attempts"`
+	ResponseStatus int                     `json:"response_status,omitempty"`
+	LastError      string                  `json:"last_error,omitempty"`
+	Payload        json.RawMessage         `json:"payload"`
+	CreatedAt      time.Time               `json:"created_at"`
+	NextAttemptAt  time.Time               `json:"next_attempt_at,omitzero"`
+	DeliveredAt    time.Time               `json:"delivered_at,omitzero"`
+}
+
+func newDeliveryResponse(d webhooks.Delivery) deliveryResponse {
+	return deliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		Payload:        d.Payload,
+		CreatedAt:      d.CreatedAt,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+// Create handles POST /webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, errors.New("invalid request body"))
+		return
+	}
+
+	sub, err := h.service.Create(r.Context(), webhooks.Subscription{
+		UserID: userID,
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+	})
+	if err != nil {
+		h.respondWebhookError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusCreated, newWebhookResponse(sub))
+}
+
+// List handles GET /webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	subs, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		h.respondWebhookError(w, err)
+		return
+	}
+	resp := make([]webhookResponse, 0, len(subs))
+	for _, s := range subs {
+		resp = append(resp, newWebhookResponse(s))
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// Delete handles DELETE /webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), userID, r.PathValue("id")); err != nil {
+		h.respondWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries handles GET /webhooks/{id}/deliveries
+// It returns the latest deliveries, newest first; limit defaults to 20.
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+	limit = min(limit, 100)
+
+	deliveries, err := h.service.Deliveries(r.Context(), userID, r.PathValue("id"), limit)
+	if err != nil {
+		h.respondWebhookError(w, err)
+		return
+	}
+	resp := make([]deliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, newDeliveryResponse(d))
+	}
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// Test handles POST /webhooks/{id}/test
+// The ping is sent right away; the response describes how the receiver answered.
+func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userIDKey).(string)
+	if !ok || userID == "" {
+		h.respondError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+
+	d, err := h.service.Test(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		h.respondWebhookError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, newDeliveryResponse(d))
+}
+
+func (h *WebhookHandler) respondWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, webhooks.ErrValidation):
+		h.respondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, webhooks.ErrNotFound):
+		h.respondError(w, http.StatusNotFound, err)
+	default:
+		h.logger.Error("webhook request failed", "error", err)
+		h.respondError(w, http.StatusInternalServerError, errors.New("internal error"))
+	}
+}
+
+func (h *WebhookHandler) respondJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h *WebhookHandler) respondError(w http.ResponseWriter, code int, err error) {
+	h.respondJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error) {
+	args := m.Called(ctx, sub)
+	return args.Get(0).(webhooks.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookService) List(ctx context.Context, userID string) ([]webhooks.Subscription, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]webhooks.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) Deliveries(ctx context.Context, userID, id string, limit int) ([]webhooks.Delivery, error) {
+	args := m.Called(ctx, userID, id, limit)
+	return args.Get(0).([]webhooks.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Test(ctx context.Context, userID, id string) (webhooks.Delivery, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(webhooks.Delivery), args.Error(1)
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	svc := new(MockWebhookService)
+	h := NewWebhookHandler(svc, slog.Default())
+
+	t.Run("created without echoing the secret", func(t *testing.T) {
+		svc.On("Create", mock.Anything, webhooks.Subscription{
+			UserID: "user1",
+			URL:    "https://example.com/hooks",
+			Events: []favorites.EventType{favorites.EventCreated},
+			Secret: "0123456789abcdef",
+		}).Return(webhooks.Subscription{
+			ID: "s1", UserID: "user1", URL: "https://example.com/hooks",
+			Events: []favorites.EventType{favorites.EventCreated}, Secret: "0123456789abcdef", CreatedAt: time.Now(),
+		}, nil).Once()
+
+		body := `{"url":"https://example.com/hooks","events":["favorite.created"],"secret":"0123456789abcdef"}`
+		w := httptest.NewRecorder()
+		h.Create(w, withUser(httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)), "user1"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"s1"`)
+		assert.NotContains(t, w.Body.String(), "0123456789abcdef")
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		svc.On("Create", mock.Anything, mock.MatchedBy(func(s webhooks.Subscription) bool { return s.Secret == "short" })).
+			Return(webhooks.Subscription{}, webhooks.ErrValidation).Once()
+
+		w := httptest.NewRecorder()
+		h.Create(w, withUser(httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"https://example.com","events":["favorite.created"],"secret":"short"}`)), "user1"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.Create(w, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	svc := new(MockWebhookService)
+	h := NewWebhookHandler(svc, slog.Default())
+
+	svc.On("Deliveries", mock.Anything, "user1", "s1", 20).Return([]webhooks.Delivery{{
+		ID: "d1", EventID: "e1", EventType: favorites.EventDeleted, Status: webhooks.DeliveryPending,
+		Attempts: 2, ResponseStatus: 503, LastError: "receiver returned status 503",
+		Payload: []byte(`{"id":"e1"}`), NextAttemptAt: time.Now(),
+	}}, nil).Once()
+	svc.On("Deliveries", mock.Anything, "user1", "other", 20).Return([]webhooks.Delivery(nil), webhooks.ErrNotFound).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/s1/deliveries", nil)
+	req.SetPathValue("id", "s1")
+	w := httptest.NewRecorder()
+	h.Deliveries(w, withUser(req, "user1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body []map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.Len(t, body, 1) {
+		assert.Equal(t, float64(503), body[0]["response_status"])
+		assert.Equal(t, map[string]any{"id": "e1"}, body[0]["payload"], "payload is embedded as JSON")
+		assert.NotContains(t, body[0], "delivered_at")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/webhooks/other/deliveries", nil)
+	req.SetPathValue("id", "other")
+	w = httptest.NewRecorder()
+	h.Deliveries(w, withUser(req, "user1"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhookHandler_Test(t *testing.T) {
+	svc := new(MockWebhookService)
+	h := NewWebhookHandler(svc, slog.Default())
+
+	svc.On("Test", mock.Anything, "user1", "s1").Return(webhooks.Delivery{
+		ID: "d1", EventType: webhooks.EventPing, Status: webhooks.DeliveryDelivered, Attempts: 1, ResponseStatus: 200, Payload: []byte(`{}`),
+	}, nil).Once()
+	svc.On("Test", mock.Anything, "user1", "s2").Return(webhooks.Delivery{}, errors.New("db down")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/s1/test", nil)
+	req.SetPathValue("id", "s1")
+	w := httptest.NewRecorder()
+	h.Test(w, withUser(req, "user1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"delivered"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/s2/test", nil)
+	req.SetPathValue("id", "s2")
+	w = httptest.NewRecorder()
+	h.Test(w, withUser(req, "user1"))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "db down")
+}
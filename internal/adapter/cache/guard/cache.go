@@ -0,0 +1,357 @@
+// Package guard protects callers from an unhealthy shared cache.
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+var (
+	// ErrDegraded is reported by Check while the cache is bypassed or
+	// invalidations are waiting to be replayed.
+	ErrDegraded = errors.New("cache degraded")
+	// ErrQueueFull is returned by a write that failed and could not be
+	// queued for replay.
+	ErrQueueFull = errors.New("pending invalidation queue is full")
+)
+
+// Config tunes the guard.
+type Config struct {
+	// Timeout bounds each cache call, so a slow cache counts as a failing one.
+	Timeout time.Duration
+	// FailureThreshold consecutive failures open the circuit for Cooldown.
+	FailureThreshold int
+	Cooldown         time.Duration
+	// MaxPending bounds the invalidations kept for replay. Beyond it they are
+	// dropped, and stale entries live until their TTL.
+	MaxPending int
+	// ReplayInterval is how often Run retries pending invalidations.
+	ReplayInterval time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.Timeout <= 0 {
+		c.Timeout = 200 * time.Millisecond
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 10 * time.Second
+	}
+	if c.MaxPending < 1 {
+		c.MaxPending = 10000
+	}
+	if c.ReplayInterval <= 0 {
+		c.ReplayInterval = time.Second
+	}
+}
+
+// pending is what must still happen to one asset's cache entries. A later
+// write to the same ID is merged into it, so the queue holds one entry per ID.
+type pending struct {
+	remove     bool
+	invalidate bool
+	addToSet   bool
+	score      float64
+	version    uint64
+}
+
+// Cache is a decorator that stops calling the inner cache while it is failing.
+//
+// Reads fail fast with resilience.ErrOpen, so callers fall back to the
+// database. Writes that cannot be applied are remembered and replayed once the
+// cache recovers; until then the affected IDs are hidden from reads, so a
+// deleted or updated asset is never served from a stale entry.
+type Cache struct {
+	inner   ports.Cache
+	cfg     Config
+	breaker *resilience.Breaker
+	logger  *slog.Logger
+	wake    chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]*pending
+	version uint64
+}
+
+// Ensure Cache implements ports.Cache
+var _ ports.Cache = (*Cache)(nil)
+
+func NewCache(inner ports.Cache, cfg Config, logger *slog.Logger) *Cache {
+	cfg.setDefaults()
+	c := &Cache{
+		inner:   inner,
+		cfg:     cfg,
+		breaker: resilience.NewBreaker(cfg.FailureThreshold, cfg.Cooldown),
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+		pending: make(map[string]*pending),
+	}
+	c.breaker.OnStateChange = func(from, to resilience.State) {
+		circuitState.Set(float64(to))
+		logger.Warn("cache circuit changed state", "from", from, "to", to)
+		if to == resilience.StateClosed {
+			select {
+			case c.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+	circuitState.Set(float64(resilience.StateClosed))
+	return c
+}
+
+// Check returns an error wrapping ErrDegraded while the cache is bypassed or
+// invalidations are pending. The service keeps working from the database.
+func (c *Cache) Check(ctx context.Context) error {
+	if state := c.breaker.State(); state != resilience.StateClosed {
+		return fmt.Errorf("%w: circuit %s", ErrDegraded, state)
+	}
+	c.mu.Lock()
+	n := len(c.pending)
+	c.mu.Unlock()
+	if n > 0 {
+		return fmt.Errorf("%w: %d invalidations pending", ErrDegraded, n)
+	}
+	return nil
+}
+
+// Run replays pending invalidations until ctx is canceled: when the circuit
+// closes, and every ReplayInterval otherwise, which also probes an open
+// circuit.
+func (c *Cache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.ReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.wake:
+		}
+		c.replay(ctx)
+	}
+}
+
+func (c *Cache) AddToSet(ctx context.Context, id string, score float64) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.AddToSet(ctx, id, score) })
+	if err != nil {
+		return c.enqueue(id, func(p *pending) {
+			p.remove = false
+			p.invalidate = true
+			p.addToSet, p.score = true, score
+		})
+	}
+	return nil
+}
+
+func (c *Cache) Set(ctx context.Context, id string, data []byte) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.Set(ctx, id, data) })
+	if err != nil {
+		// The entry may now be older than the database; drop it instead.
+		return c.enqueue(id, func(p *pending) {
+			if !p.remove {
+				p.invalidate = true
+			}
+		})
+	}
+	return nil
+}
+
+func (c *Cache) Remove(ctx context.Context, id string) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.Remove(ctx, id) })
+	if err != nil {
+		return c.enqueue(id, func(p *pending) {
+			*p = pending{remove: true}
+		})
+	}
+	return nil
+}
+
+func (c *Cache) Invalidate(ctx context.Context, id string) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.Invalidate(ctx, id) })
+	if err != nil {
+		return c.enqueue(id, func(p *pending) {
+			if !p.remove {
+				p.invalidate = true
+			}
+		})
+	}
+	return nil
+}
+
+func (c *Cache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.AddBatch(ctx, assets, score) })
+	if err == nil {
+		return nil
+	}
+	var queueErr error
+	for id := range assets {
+		if err := c.enqueue(id, func(p *pending) {
+			p.remove = false
+			p.invalidate = true
+			p.addToSet, p.score = true, score
+		}); err != nil {
+			queueErr = err
+		}
+	}
+	return queueErr
+}
+
+func (c *Cache) RemoveBatch(ctx context.Context, ids []string) error {
+	err := c.call(ctx, func(ctx context.Context) error { return c.inner.RemoveBatch(ctx, ids) })
+	if err == nil {
+		return nil
+	}
+	var queueErr error
+	for _, id := range ids {
+		if err := c.enqueue(id, func(p *pending) {
+			*p = pending{remove: true}
+		}); err != nil {
+			queueErr = err
+		}
+	}
+	return queueErr
+}
+
+func (c *Cache) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
+	var result map[string][]byte
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = c.inner.GetBatch(ctx, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range result {
+		if _, ok := c.pending[id]; ok {
+			delete(result, id)
+		}
+	}
+	return result, nil
+}
+
+func (c *Cache) GetIdsFromSet(ctx context.Context, start, stop int64) ([]string, error) {
+	var ids []string
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = c.inner.GetIdsFromSet(ctx, start, stop)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.pending) == 0 {
+		return ids, nil
+	}
+	live := ids[:0]
+	for _, id := range ids {
+		if p, ok := c.pending[id]; !ok || !p.remove {
+			live = append(live, id)
+		}
+	}
+	return live, nil
+}
+
+// call runs fn through the circuit breaker with the per-call timeout.
+func (c *Cache) call(ctx context.Context, fn func(context.Context) error) error {
+	if err := c.breaker.Allow(); err != nil {
+		shortCircuits.Inc()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	if err := fn(ctx); err != nil {
+		c.breaker.Failure()
+		return err
+	}
+	c.breaker.Success()
+	return nil
+}
+
+// enqueue records a write to replay later, merging it into what is already
+// pending for the ID.
+func (c *Cache) enqueue(id string, merge func(*pending)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.pending[id]
+	if !ok {
+		if len(c.pending) >= c.cfg.MaxPending {
+			droppedInvalidations.Inc()
+			return ErrQueueFull
+		}
+		p = &pending{}
+		c.pending[id] = p
+	}
+	merge(p)
+	c.version++
+	p.version = c.version
+	pendingInvalidations.Set(float64(len(c.pending)))
+	return nil
+}
+
+// replay applies pending writes until one fails.
+func (c *Cache) replay(ctx context.Context) {
+	c.mu.Lock()
+	snapshot := make(map[string]pending, len(c.pending))
+	for id, p := range c.pending {
+		snapshot[id] = *p
+	}
+	c.mu.Unlock()
+	if len(snapshot) == 0 {
+		return
+	}
+
+	replayed := 0
+	for id, p := range snapshot {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.apply(ctx, id, p); err != nil {
+			if !errors.Is(err, resilience.ErrOpen) {
+				c.logger.Warn("failed to replay cache invalidation", "id", id, "error", err)
+			}
+			break
+		}
+		c.mu.Lock()
+		// A write queued meanwhile must still be replayed.
+		if cur, ok := c.pending[id]; ok && cur.version == p.version {
+			delete(c.pending, id)
+		}
+		pendingInvalidations.Set(float64(len(c.pending)))
+		c.mu.Unlock()
+		replayed++
+	}
+	if replayed > 0 {
+		c.logger.Info("replayed cache invalidations", "count", replayed, "queued", len(snapshot))
+	}
+}
+
+func (c *Cache) apply(ctx context.Context, id string, p pending) error {
+	if p.remove {
+		return c.call(ctx, func(ctx context.Context) error { return c.inner.Remove(ctx, id) })
+	}
+	if p.invalidate {
+		if err := c.call(ctx, func(ctx context.Context) error { return c.inner.Invalidate(ctx, id) }); err != nil {
+			return err
+		}
+	}
+	if p.addToSet {
+		return c.call(ctx, func(ctx context.Context) error { return c.inner.AddToSet(ctx, id, p.score) })
+	}
+	return nil
+}
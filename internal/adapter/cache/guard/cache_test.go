@@ -0,0 +1,210 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/resilience"
+)
+
+// stubCache is a shared cache that can be taken down, recording the calls it
+// served.
+type stubCache struct {
+	mu    sync.Mutex
+	down  bool
+	slow  bool
+	calls []string
+	set   []string
+	data  map[string][]byte
+}
+
+func newStubCache() *stubCache {
+	return &stubCache{data: make(map[string][]byte)}
+}
+
+func (s *stubCache) do(ctx context.Context, call string) error {
+	if s.slow {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("connection refused")
+	}
+	s.calls = append(s.calls, call)
+	return nil
+}
+
+func (s *stubCache) AddToSet(ctx context.Context, id string, score float64) error {
+	if err := s.do(ctx, "AddToSet "+id); err != nil {
+		return err
+	}
+	s.set = append(s.set, id)
+	return nil
+}
+func (s *stubCache) Set(ctx context.Context, id string, data []byte) error {
+	if err := s.do(ctx, "Set "+id); err != nil {
+		return err
+	}
+	s.data[id] = data
+	return nil
+}
+func (s *stubCache) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
+	if err := s.do(ctx, "GetBatch"); err != nil {
+		return nil, err
+	}
+	res := make(map[string][]byte)
+	for _, id := range ids {
+		if d, ok := s.data[id]; ok {
+			res[id] = d
+		}
+	}
+	return res, nil
+}
+func (s *stubCache) GetIdsFromSet(ctx context.Context, start, stop int64) ([]string, error) {
+	if err := s.do(ctx, "GetIdsFromSet"); err != nil {
+		return nil, err
+	}
+	return append([]string(nil), s.set...), nil
+}
+func (s *stubCache) Remove(ctx context.Context, id string) error {
+	return s.do(ctx, "Remove "+id)
+}
+func (s *stubCache) Invalidate(ctx context.Context, id string) error {
+	return s.do(ctx, "Invalidate "+id)
+}
+func (s *stubCache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	if err := s.do(ctx, "AddBatch"); err != nil {
+		return err
+	}
+	for id, data := range assets {
+		s.set = append(s.set, id)
+		s.data[id] = data
+	}
+	return nil
+}
+func (s *stubCache) RemoveBatch(ctx context.Context, ids []string) error {
+	return s.do(ctx, "RemoveBatch")
+}
+
+func newTestCache(inner *stubCache, cfg Config) *Cache {
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = time.Hour
+	}
+	return NewCache(inner, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestCache_OpenCircuitSkipsCache(t *testing.T) {
+	inner := newStubCache()
+	c := newTestCache(inner, Config{})
+	ctx := context.Background()
+
+	inner.down = true
+	_, err := c.GetBatch(ctx, []string{"a"})
+	assert.Error(t, err)
+	assert.ErrorIs(t, c.Check(ctx), ErrDegraded)
+
+	inner.down = false
+	_, err = c.GetIdsFromSet(ctx, 0, 9)
+	assert.ErrorIs(t, err, resilience.ErrOpen)
+	assert.Empty(t, inner.calls)
+}
+
+func TestCache_SlowCacheCountsAsFailure(t *testing.T) {
+	inner := newStubCache()
+	inner.slow = true
+	c := newTestCache(inner, Config{Timeout: 10 * time.Millisecond})
+
+	_, err := c.GetBatch(context.Background(), []string{"a"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, resilience.StateOpen, c.breaker.State())
+}
+
+func TestCache_ReplaysInvalidationsOnRecovery(t *testing.T) {
+	inner := newStubCache()
+	_ = inner.AddToSet(context.Background(), "deleted", 1)
+	_ = inner.Set(context.Background(), "deleted", []byte("old"))
+	_ = inner.Set(context.Background(), "updated", []byte("old"))
+	inner.calls = nil
+
+	c := newTestCache(inner, Config{Cooldown: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	// Writes during the outage succeed for the caller and are queued.
+	inner.down = true
+	assert.NoError(t, c.Remove(ctx, "deleted"))
+	assert.NoError(t, c.Set(ctx, "updated", []byte("new")))
+	assert.NoError(t, c.AddToSet(ctx, "created", 2))
+	assert.ErrorIs(t, c.Check(ctx), ErrDegraded)
+
+	// Once the cache is back, stale entries are hidden until replayed.
+	inner.down = false
+	time.Sleep(30 * time.Millisecond)
+	batch, err := c.GetBatch(ctx, []string{"deleted", "updated"})
+	assert.NoError(t, err)
+	assert.Empty(t, batch)
+	ids, err := c.GetIdsFromSet(ctx, 0, -1)
+	assert.NoError(t, err)
+	assert.NotContains(t, ids, "deleted")
+	assert.ErrorIs(t, c.Check(ctx), ErrDegraded)
+
+	c.replay(ctx)
+
+	assert.Subset(t, inner.calls, []string{"Remove deleted", "Invalidate updated", "Invalidate created", "AddToSet created"})
+	assert.NoError(t, c.Check(ctx))
+}
+
+func TestCache_PendingWritesMerge(t *testing.T) {
+	inner := newStubCache()
+	inner.down = true
+	c := newTestCache(inner, Config{})
+	ctx := context.Background()
+
+	_ = c.AddToSet(ctx, "a", 1)
+	_ = c.Remove(ctx, "a")
+	_ = c.Set(ctx, "a", []byte("x"))
+	assert.Equal(t, pending{remove: true, version: 3}, *c.pending["a"])
+
+	// Re-created after the delete.
+	_ = c.AddToSet(ctx, "a", 2)
+	assert.Equal(t, pending{invalidate: true, addToSet: true, score: 2, version: 4}, *c.pending["a"])
+}
+
+func TestCache_BatchWritesQueuePerID(t *testing.T) {
+	inner := newStubCache()
+	inner.down = true
+	c := newTestCache(inner, Config{})
+	ctx := context.Background()
+
+	assert.NoError(t, c.AddBatch(ctx, map[string][]byte{"a": []byte("x"), "b": []byte("y")}, 5))
+	assert.NoError(t, c.RemoveBatch(ctx, []string{"b", "c"}))
+
+	a := *c.pending["a"]
+	assert.True(t, a.invalidate && a.addToSet && !a.remove)
+	assert.Equal(t, 5.0, a.score)
+	assert.True(t, c.pending["b"].remove)
+	assert.True(t, c.pending["c"].remove)
+}
+
+func TestCache_QueueFull(t *testing.T) {
+	inner := newStubCache()
+	inner.down = true
+	c := newTestCache(inner, Config{MaxPending: 1})
+	ctx := context.Background()
+
+	assert.NoError(t, c.Remove(ctx, "a"))
+	assert.NoError(t, c.Remove(ctx, "a"))
+	assert.ErrorIs(t, c.Remove(ctx, "b"), ErrQueueFull)
+}
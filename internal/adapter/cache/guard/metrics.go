@@ -0,0 +1,44 @@
+package guard
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	circuitState = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_circuit_state",
+			Help: "Cache circuit breaker state (0 closed, 1 half-open, 2 open)",
+		},
+	)
+	shortCircuits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "cache_short_circuits_total",
+			Help: "Cache calls skipped because the circuit was open",
+		},
+	)
+	pendingInvalidations = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_pending_invalidations",
+			Help: "Cache writes waiting to be replayed after the cache recovers",
+		},
+	)
+	droppedInvalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_dropped_total",
+			Help: "Cache writes that could not be queued for replay because the queue was full",
+		},
+	)
+	rateLimiterFallbacks = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rate_limiter_fallbacks_total",
+			Help: "Requests counted by the in-process rate limiter because the shared one was failing",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(circuitState)
+	prometheus.MustRegister(shortCircuits)
+	prometheus.MustRegister(pendingInvalidations)
+	prometheus.MustRegister(droppedInvalidations)
+	prometheus.MustRegister(rateLimiterFallbacks)
+}
@@ -0,0 +1,69 @@
+package guard
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+// RateLimiterConfig tunes the rate limiter guard.
+type RateLimiterConfig struct {
+	// Timeout bounds each call to the shared limiter.
+	Timeout time.Duration
+	// FailureThreshold consecutive failures open the circuit for Cooldown.
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// RateLimiter is a decorator that counts requests in process while the
+// shared limiter is failing, so that limits still apply, per replica, when
+// Redis is down.
+type RateLimiter struct {
+	inner    ports.RateLimiter
+	fallback ports.RateLimiter
+	timeout  time.Duration
+	breaker  *resilience.Breaker
+}
+
+// Ensure RateLimiter implements ports.RateLimiter
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+func NewRateLimiter(inner, fallback ports.RateLimiter, cfg RateLimiterConfig, logger *slog.Logger) *RateLimiter {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 200 * time.Millisecond
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Second
+	}
+	l := &RateLimiter{
+		inner:    inner,
+		fallback: fallback,
+		timeout:  cfg.Timeout,
+		breaker:  resilience.NewBreaker(cfg.FailureThreshold, cfg.Cooldown),
+	}
+	l.breaker.OnStateChange = func(from, to resilience.State) {
+		logger.Warn("rate limiter circuit changed state", "from", from, "to", to)
+	}
+	return l
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string, rate ports.Rate) (ports.RateLimitResult, error) {
+	if err := l.breaker.Allow(); err != nil {
+		rateLimiterFallbacks.Inc()
+		return l.fallback.Allow(ctx, key, rate)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+	res, err := l.inner.Allow(callCtx, key, rate)
+	if err != nil {
+		l.breaker.Failure()
+		rateLimiterFallbacks.Inc()
+		return l.fallback.Allow(ctx, key, rate)
+	}
+	l.breaker.Success()
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+// stubLimiter allows every request, or fails while down, counting its calls.
+type stubLimiter struct {
+	down  bool
+	calls int
+}
+
+func (s *stubLimiter) Allow(ctx context.Context, key string, rate ports.Rate) (ports.RateLimitResult, error) {
+	s.calls++
+	if s.down {
+		return ports.RateLimitResult{}, errors.New("connection refused")
+	}
+	return ports.RateLimitResult{Allowed: true, Limit: rate.Limit, Remaining: rate.Limit - 1}, nil
+}
+
+func TestRateLimiter_FallsBackWhileFailing(t *testing.T) {
+	inner, fallback := &stubLimiter{}, &stubLimiter{}
+	l := NewRateLimiter(inner, fallback, RateLimiterConfig{FailureThreshold: 1, Cooldown: time.Hour}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	rate := ports.Rate{Limit: 10, Period: time.Minute}
+
+	_, err := l.Allow(ctx, "k", rate)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, 0, fallback.calls)
+
+	// The failing call and those made while the circuit is open are counted
+	// in process.
+	inner.down = true
+	for range 3 {
+		res, err := l.Allow(ctx, "k", rate)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, 3, fallback.calls)
+	assert.Equal(t, resilience.StateOpen, l.breaker.State())
+}
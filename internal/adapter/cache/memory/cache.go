@@ -0,0 +1,245 @@
+// Package memory provides an in-process cache tier in front of a shared ports.Cache.
+package memory
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// Invalidations broadcasts evicted asset IDs to the other replicas.
+type Invalidations interface {
+	Publish(ctx context.Context, id string) error
+	// Subscribe calls evict for every published ID, and reset whenever the
+	// subscription is (re)established, until ctx is canceled.
+	Subscribe(ctx context.Context, evict func(id string), reset func()) error
+}
+
+// Config sizes the in-process tier.
+type Config struct {
+	// Size is the maximum number of assets held; the least recently used
+	// one is dropped first.
+	Size int
+	// TTL bounds how long an entry is served without asking the shared
+	// cache. It is the staleness limit for updates other replicas write with
+	// Set, which are not broadcast.
+	TTL time.Duration
+	// Jitter adds up to this much to each entry's TTL, so entries filled
+	// together do not all expire together.
+	Jitter time.Duration
+}
+
+type entry struct {
+	id      string
+	data    []byte
+	expires time.Time
+}
+
+// Cache is a decorator that keeps recently read asset data in memory, so that
+// repeated reads skip the round trip to the shared cache. Sorted-set calls go
+// straight through.
+type Cache struct {
+	inner         ports.Cache
+	invalidations Invalidations
+	cfg           Config
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+}
+
+// Ensure Cache implements ports.Cache
+var _ ports.Cache = (*Cache)(nil)
+
+func NewCache(inner ports.Cache, invalidations Invalidations, cfg Config, logger *slog.Logger) *Cache {
+	return &Cache{
+		inner:         inner,
+		invalidations: invalidations,
+		cfg:           cfg,
+		logger:        logger,
+		now:           time.Now,
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// Listen applies invalidations from other replicas until ctx is canceled.
+// Everything is dropped whenever the subscription is (re)established, since
+// invalidations may have been missed while it was down.
+func (c *Cache) Listen(ctx context.Context) {
+	if err := c.invalidations.Subscribe(ctx, c.evict, c.purge); err != nil && ctx.Err() == nil {
+		c.logger.Error("cache invalidation subscription stopped", "error", err)
+	}
+}
+
+func (c *Cache) AddToSet(ctx context.Context, id string, score float64) error {
+	return c.inner.AddToSet(ctx, id, score)
+}
+
+func (c *Cache) Set(ctx context.Context, id string, data []byte) error {
+	// Drop the local copy first, so a failed write cannot leave it newer
+	// than the shared one.
+	c.evict(id)
+	if err := c.inner.Set(ctx, id, data); err != nil {
+		return err
+	}
+	c.store(id, data)
+	return nil
+}
+
+func (c *Cache) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string][]byte, len(ids))
+	var misses []string
+	for _, id := range ids {
+		if data, ok := c.load(id); ok {
+			result[id] = data
+		} else {
+			misses = append(misses, id)
+		}
+	}
+	l1Hits.Add(float64(len(result)))
+	l1Misses.Add(float64(len(misses)))
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	fetched, err := c.inner.GetBatch(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for id, data := range fetched {
+		c.store(id, data)
+		result[id] = data
+	}
+	return result, nil
+}
+
+func (c *Cache) GetIdsFromSet(ctx context.Context, start, stop int64) ([]string, error) {
+	return c.inner.GetIdsFromSet(ctx, start, stop)
+}
+
+func (c *Cache) Remove(ctx context.Context, id string) error {
+	c.evict(id)
+	if err := c.inner.Remove(ctx, id); err != nil {
+		return err
+	}
+	c.broadcast(ctx, id)
+	return nil
+}
+
+func (c *Cache) Invalidate(ctx context.Context, id string) error {
+	c.evict(id)
+	if err := c.inner.Invalidate(ctx, id); err != nil {
+		return err
+	}
+	c.broadcast(ctx, id)
+	return nil
+}
+
+func (c *Cache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	for id := range assets {
+		c.evict(id)
+	}
+	if err := c.inner.AddBatch(ctx, assets, score); err != nil {
+		return err
+	}
+	for id, data := range assets {
+		c.store(id, data)
+	}
+	return nil
+}
+
+func (c *Cache) RemoveBatch(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		c.evict(id)
+	}
+	if err := c.inner.RemoveBatch(ctx, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		c.broadcast(ctx, id)
+	}
+	return nil
+}
+
+// broadcast tells the other replicas to drop id. A lost message leaves them
+// serving the old copy for at most the TTL, so it does not fail the caller.
+func (c *Cache) broadcast(ctx context.Context, id string) {
+	if err := c.invalidations.Publish(ctx, id); err != nil {
+		c.logger.Warn("failed to publish cache invalidation", "id", id, "error", err)
+	}
+}
+
+func (c *Cache) load(id string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return e.data, true
+}
+
+func (c *Cache) store(id string, data []byte) {
+	ttl := c.cfg.TTL
+	if c.cfg.Jitter > 0 {
+		ttl += rand.N(c.cfg.Jitter)
+	}
+	expires := c.now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		e := elem.Value.(*entry)
+		e.data, e.expires = data, expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[id] = c.lru.PushFront(&entry{id: id, data: data, expires: expires})
+	for c.lru.Len() > c.cfg.Size {
+		c.removeElement(c.lru.Back())
+		l1Evictions.Inc()
+	}
+}
+
+func (c *Cache) evict(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *Cache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// removeElement must be called with mu held.
+func (c *Cache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).id)
+}
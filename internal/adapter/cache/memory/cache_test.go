@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCache is a map-backed shared cache that records GetBatch calls.
+type stubCache struct {
+	data    map[string][]byte
+	batches [][]string
+	err     error
+}
+
+func newStubCache() *stubCache {
+	return &stubCache{data: make(map[string][]byte)}
+}
+
+func (s *stubCache) AddToSet(ctx context.Context, id string, score float64) error { return s.err }
+func (s *stubCache) Set(ctx context.Context, id string, data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.data[id] = data
+	return nil
+}
+func (s *stubCache) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
+	s.batches = append(s.batches, ids)
+	if s.err != nil {
+		return nil, s.err
+	}
+	res := make(map[string][]byte)
+	for _, id := range ids {
+		if d, ok := s.data[id]; ok {
+			res[id] = d
+		}
+	}
+	return res, nil
+}
+func (s *stubCache) GetIdsFromSet(ctx context.Context, start, stop int64) ([]string, error) {
+	return nil, s.err
+}
+func (s *stubCache) Remove(ctx context.Context, id string) error {
+	delete(s.data, id)
+	return s.err
+}
+func (s *stubCache) Invalidate(ctx context.Context, id string) error {
+	delete(s.data, id)
+	return s.err
+}
+func (s *stubCache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	if s.err != nil {
+		return s.err
+	}
+	for id, data := range assets {
+		s.data[id] = data
+	}
+	return nil
+}
+func (s *stubCache) RemoveBatch(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(s.data, id)
+	}
+	return s.err
+}
+
+// stubInvalidations records published IDs. Subscribe optionally reports a
+// (re)subscription, then delivers the incoming IDs.
+type stubInvalidations struct {
+	published    []string
+	incoming     []string
+	resubscribed bool
+}
+
+func (s *stubInvalidations) Publish(ctx context.Context, id string) error {
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *stubInvalidations) Subscribe(ctx context.Context, evict func(id string), reset func()) error {
+	if s.resubscribed {
+		reset()
+	}
+	for _, id := range s.incoming {
+		evict(id)
+	}
+	return nil
+}
+
+func newTestCache(inner *stubCache, bus *stubInvalidations, cfg Config) *Cache {
+	return NewCache(inner, bus, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestCache_ServesRepeatedReadsLocally(t *testing.T) {
+	inner := newStubCache()
+	inner.data["a"] = []byte("A")
+	inner.data["b"] = []byte("B")
+	c := newTestCache(inner, &stubInvalidations{}, Config{Size: 10, TTL: time.Minute})
+
+	first, err := c.GetBatch(context.Background(), []string{"a", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("A")}, first)
+
+	second, err := c.GetBatch(context.Background(), []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("A"), "b": []byte("B")}, second)
+
+	// Only the misses went to the shared cache.
+	assert.Equal(t, [][]string{{"a", "missing"}, {"b"}}, inner.batches)
+
+	_, _ = c.GetBatch(context.Background(), []string{"a", "b"})
+	assert.Len(t, inner.batches, 2)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	inner := newStubCache()
+	c := newTestCache(inner, &stubInvalidations{}, Config{Size: 2, TTL: time.Minute})
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", []byte("A"))
+	_ = c.Set(ctx, "b", []byte("B"))
+	_, _ = c.GetBatch(ctx, []string{"a"}) // a is now more recent than b
+	_ = c.Set(ctx, "c", []byte("C"))
+
+	_, ok := c.load("b")
+	assert.False(t, ok)
+	_, ok = c.load("a")
+	assert.True(t, ok)
+	_, ok = c.load("c")
+	assert.True(t, ok)
+}
+
+func TestCache_Expiry(t *testing.T) {
+	inner := newStubCache()
+	c := newTestCache(inner, &stubInvalidations{}, Config{Size: 10, TTL: time.Minute, Jitter: 10 * time.Second})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(context.Background(), "a", []byte("A"))
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.load("a")
+	assert.True(t, ok)
+
+	now = now.Add(11 * time.Second) // past TTL plus the largest jitter
+	_, ok = c.load("a")
+	assert.False(t, ok)
+}
+
+func TestCache_RemoveBroadcasts(t *testing.T) {
+	inner := newStubCache()
+	bus := &stubInvalidations{}
+	c := newTestCache(inner, bus, Config{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", []byte("A"))
+	_ = c.Set(ctx, "b", []byte("B"))
+
+	assert.NoError(t, c.Remove(ctx, "a"))
+	assert.NoError(t, c.Invalidate(ctx, "b"))
+
+	assert.Equal(t, []string{"a", "b"}, bus.published)
+	_, ok := c.load("a")
+	assert.False(t, ok)
+	_, ok = c.load("b")
+	assert.False(t, ok)
+}
+
+func TestCache_BatchWrites(t *testing.T) {
+	inner := newStubCache()
+	bus := &stubInvalidations{}
+	c := newTestCache(inner, bus, Config{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+
+	assert.NoError(t, c.AddBatch(ctx, map[string][]byte{"a": []byte("A"), "b": []byte("B")}, 1))
+	data, ok := c.load("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("A"), data)
+
+	assert.NoError(t, c.RemoveBatch(ctx, []string{"a", "b"}))
+	assert.Equal(t, []string{"a", "b"}, bus.published)
+	_, ok = c.load("b")
+	assert.False(t, ok)
+	assert.Empty(t, inner.data)
+}
+
+func TestCache_FailedWritesDropLocalCopy(t *testing.T) {
+	inner := newStubCache()
+	bus := &stubInvalidations{}
+	c := newTestCache(inner, bus, Config{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", []byte("A"))
+	inner.err = errors.New("redis down")
+
+	assert.Error(t, c.Set(ctx, "a", []byte("A2")))
+	_, ok := c.load("a")
+	assert.False(t, ok)
+
+	// Nothing is broadcast unless the shared cache was updated.
+	assert.Error(t, c.Remove(ctx, "a"))
+	assert.Empty(t, bus.published)
+}
+
+func TestCache_Listen(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("remote invalidation evicts", func(t *testing.T) {
+		c := newTestCache(newStubCache(), &stubInvalidations{incoming: []string{"a"}}, Config{Size: 10, TTL: time.Minute})
+		_ = c.Set(ctx, "a", []byte("A"))
+		_ = c.Set(ctx, "b", []byte("B"))
+
+		c.Listen(ctx)
+
+		_, ok := c.load("a")
+		assert.False(t, ok)
+		_, ok = c.load("b")
+		assert.True(t, ok)
+	})
+
+	t.Run("resubscribing drops everything", func(t *testing.T) {
+		c := newTestCache(newStubCache(), &stubInvalidations{resubscribed: true}, Config{Size: 10, TTL: time.Minute})
+		_ = c.Set(ctx, "b", []byte("B"))
+
+		c.Listen(ctx)
+
+		_, ok := c.load("b")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,30 @@
+package memory
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	l1Hits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "cache_l1_hits_total",
+			Help: "Asset reads served by the in-process cache",
+		},
+	)
+	l1Misses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "cache_l1_misses_total",
+			Help: "Asset reads passed on to the shared cache",
+		},
+	)
+	l1Evictions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "cache_l1_evictions_total",
+			Help: "Entries dropped from the in-process cache to stay within its size",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(l1Hits)
+	prometheus.MustRegister(l1Misses)
+	prometheus.MustRegister(l1Evictions)
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// RateLimiter implements ports.RateLimiter in process, with the same
+// algorithm (GCRA) as the Redis limiter. Each replica counts on its own, so
+// a caller spread over n replicas gets up to n times the rate.
+type RateLimiter struct {
+	now func() time.Time
+
+	mu sync.Mutex
+	// tats holds the theoretical arrival time of each key's next request.
+	tats  map[string]time.Time
+	sweep time.Time
+}
+
+// Ensure RateLimiter implements ports.RateLimiter
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{now: time.Now, tats: make(map[string]time.Time)}
+}
+
+func (l *RateLimiter) Allow(_ context.Context, key string, rate ports.Rate) (ports.RateLimitResult, error) {
+	interval := rate.Period / time.Duration(rate.Limit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.expire(now, rate.Period)
+
+	tat := l.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(interval)
+	if allowAt := next.Add(-rate.Period); now.Before(allowAt) {
+		return ports.RateLimitResult{Limit: rate.Limit, RetryAfter: allowAt.Sub(now), ResetAfter: tat.Sub(now)}, nil
+	}
+	l.tats[key] = next
+	return ports.RateLimitResult{
+		Allowed:    true,
+		Limit:      rate.Limit,
+		Remaining:  int((rate.Period - next.Sub(now)) / interval),
+		ResetAfter: next.Sub(now),
+	}, nil
+}
+
+// expire drops keys that have fully recovered, at most once per period, so
+// the map only holds recently active callers.
+func (l *RateLimiter) expire(now time.Time, period time.Duration) {
+	if now.Sub(l.sweep) < period {
+		return
+	}
+	l.sweep = now
+	for key, tat := range l.tats {
+		if !tat.After(now) {
+			delete(l.tats, key)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter()
+	l.now = func() time.Time { return now }
+	rate := ports.Rate{Limit: 3, Period: 3 * time.Second}
+	ctx := context.Background()
+
+	// A burst of up to Limit is allowed.
+	for want := 2; want >= 0; want-- {
+		res, err := l.Allow(ctx, "k", rate)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, want, res.Remaining)
+	}
+
+	res, _ := l.Allow(ctx, "k", rate)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.ResetAfter)
+
+	// Other keys are counted apart.
+	res, _ = l.Allow(ctx, "other", rate)
+	assert.True(t, res.Allowed)
+
+	// One emission interval later, one more request fits.
+	now = now.Add(time.Second)
+	res, _ = l.Allow(ctx, "k", rate)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	// Recovered keys are forgotten.
+	now = now.Add(time.Minute)
+	res, _ = l.Allow(ctx, "k", rate)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+	assert.Len(t, l.tats, 1)
+}
@@ -2,6 +2,9 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"math/rand/v2"
 	"time"
 
 	"go-favorites-app/internal/core/ports"
@@ -9,72 +12,209 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-type Adapter struct {
-	client *redis.Client
+// defaultAssetTTL applies when Options.AssetTTL is unset.
+const defaultAssetTTL = 24 * time.Hour
+
+// Deployment modes.
+const (
+	ModeStandalone = "standalone"
+	// ModeSentinel connects to the primary that Sentinel reports for
+	// MasterName and follows it on failover.
+	ModeSentinel = "sentinel"
+	ModeCluster  = "cluster"
+)
+
+// Options configures the connection and the keys the adapter writes.
+// Zero pool and timeout values keep the go-redis defaults.
+type Options struct {
+	// Mode defaults to ModeStandalone.
+	Mode string
+	// Addrs is the server address in standalone mode, the Sentinel
+	// addresses in sentinel mode and the seed nodes in cluster mode.
+	Addrs      []string
+	MasterName string
+	// Sentinel credentials, if they differ from the data nodes'.
+	SentinelUsername string
+	SentinelPassword string
+
+	Username string
+	Password string
+	// DB is ignored in cluster mode, which only has database 0.
+	DB int
+	// TLS enables TLS when set.
+	TLS *tls.Config
+
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration
+
+	// KeyPrefix namespaces every key and channel, e.g. "staging:".
+	KeyPrefix string
+	// AssetTTL is how long asset data is cached. SetTTL, if positive,
+	// expires the ID set when it has not been written for that long.
+	AssetTTL time.Duration
+	SetTTL   time.Duration
+	// TTLJitter adds up to this much to each TTL, so entries written
+	// together do not all expire together.
+	TTLJitter time.Duration
 }
 
-func NewAdapter(addr string) *Adapter {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: addr,
-	})
-	return &Adapter{client: rdb}
+type Adapter struct {
+	client redis.UniversalClient
+	keys   keyspace
+	opts   Options
 }
 
-// Ensure Adapter implements ports.Cache
-var _ ports.Cache = (*Adapter)(nil)
+func NewAdapter(opts Options) *Adapter {
+	if opts.AssetTTL <= 0 {
+		opts.AssetTTL = defaultAssetTTL
+	}
+	universal := &redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		SentinelUsername: opts.SentinelUsername,
+		SentinelPassword: opts.SentinelPassword,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		DB:               opts.DB,
+		TLSConfig:        opts.TLS,
+		PoolSize:         opts.PoolSize,
+		MinIdleConns:     opts.MinIdleConns,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+		PoolTimeout:      opts.PoolTimeout,
+	}
 
-const (
-	SetKey = "favorites:all"
-	Prefix = "favorite:"
+	// Pick the client explicitly rather than letting go-redis guess from
+	// the number of addresses; a cluster may be reached through one endpoint.
+	var rdb redis.UniversalClient
+	switch opts.Mode {
+	case ModeSentinel:
+		rdb = redis.NewFailoverClient(universal.Failover())
+	case ModeCluster:
+		rdb = redis.NewClusterClient(universal.Cluster())
+	default:
+		rdb = redis.NewClient(universal.Simple())
+	}
+	return &Adapter{client: rdb, keys: keyspace{prefix: opts.KeyPrefix}, opts: opts}
+}
+
+// Ensure Adapter implements ports.Cache and ports.CacheScanner
+var (
+	_ ports.Cache        = (*Adapter)(nil)
+	_ ports.CacheScanner = (*Adapter)(nil)
 )
 
+// ttl adds the configured jitter to base.
+func (a *Adapter) ttl(base time.Duration) time.Duration {
+	if a.opts.TTLJitter <= 0 {
+		return base
+	}
+	return base + rand.N(a.opts.TTLJitter)
+}
+
 func (a *Adapter) AddToSet(ctx context.Context, id string, score float64) error {
 	pipe := a.client.Pipeline()
-	pipe.ZAdd(ctx, SetKey, redis.Z{Score: score, Member: id})
-	// Refresh TTL if needed, but for "all" set usually we keep it or use logic
+	pipe.ZAdd(ctx, a.keys.set(), redis.Z{Score: score, Member: id})
+	if a.opts.SetTTL > 0 {
+		pipe.Expire(ctx, a.keys.set(), a.ttl(a.opts.SetTTL))
+	}
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
 func (a *Adapter) Set(ctx context.Context, id string, data []byte) error {
-	return a.client.Set(ctx, Prefix+id, data, 24*time.Hour).Err()
+	return a.client.Set(ctx, a.keys.asset(id), data, a.ttl(a.opts.AssetTTL)).Err()
 }
 
 func (a *Adapter) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	keys := make([]string, len(ids))
+
+	// One GET per key rather than MGET, which a cluster rejects when the
+	// keys hash to different slots.
+	pipe := a.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(ids))
 	for i, id := range ids {
-		keys[i] = Prefix + id
+		cmds[i] = pipe.Get(ctx, a.keys.asset(id))
 	}
-
-	vals, err := a.client.MGet(ctx, keys...).Result()
-	if err != nil {
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
 		return nil, err
 	}
 
 	result := make(map[string][]byte)
-	for i, val := range vals {
-		if v, ok := val.(string); ok {
-			result[ids[i]] = []byte(v)
+	for i, cmd := range cmds {
+		if v, err := cmd.Bytes(); err == nil {
+			result[ids[i]] = v
 		}
 	}
 	return result, nil
 }
 
 func (a *Adapter) GetIdsFromSet(ctx context.Context, start, stop int64) ([]string, error) {
-	return a.client.ZRevRange(ctx, SetKey, start, stop).Result()
+	return a.client.ZRevRange(ctx, a.keys.set(), start, stop).Result()
+}
+
+func (a *Adapter) ScanSet(ctx context.Context, cursor uint64, count int64) ([]string, uint64, error) {
+	// ZSCAN returns members and scores interleaved.
+	pairs, next, err := a.client.ZScan(ctx, a.keys.set(), cursor, "", count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		ids = append(ids, pairs[i])
+	}
+	return ids, next, nil
 }
 
 func (a *Adapter) Remove(ctx context.Context, id string) error {
 	pipe := a.client.Pipeline()
-	pipe.ZRem(ctx, SetKey, id)
-	pipe.Del(ctx, Prefix+id)
+	pipe.ZRem(ctx, a.keys.set(), id)
+	pipe.Del(ctx, a.keys.asset(id))
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
 func (a *Adapter) Invalidate(ctx context.Context, id string) error {
-	return a.client.Del(ctx, Prefix+id).Err()
+	return a.client.Del(ctx, a.keys.asset(id)).Err()
+}
+
+func (a *Adapter) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	pipe := a.client.Pipeline()
+	members := make([]redis.Z, 0, len(assets))
+	for id, data := range assets {
+		members = append(members, redis.Z{Score: score, Member: id})
+		pipe.Set(ctx, a.keys.asset(id), data, a.ttl(a.opts.AssetTTL))
+	}
+	pipe.ZAdd(ctx, a.keys.set(), members...)
+	if a.opts.SetTTL > 0 {
+		pipe.Expire(ctx, a.keys.set(), a.ttl(a.opts.SetTTL))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (a *Adapter) RemoveBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pipe := a.client.Pipeline()
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = id
+		// One DEL per key, since a cluster cannot delete across slots at once.
+		pipe.Del(ctx, a.keys.asset(id))
+	}
+	pipe.ZRem(ctx, a.keys.set(), members...)
+	_, err := pipe.Exec(ctx)
+	return err
 }
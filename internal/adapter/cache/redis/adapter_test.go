@@ -3,9 +3,14 @@ package redis
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/testcontainers/testcontainers-go/modules/redis"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
 )
 
 func TestRedisAdapter_Integration(t *testing.T) {
@@ -39,7 +44,7 @@ func TestRedisAdapter_Integration(t *testing.T) {
 		addr = addr[8:]
 	}
 
-	adapter := NewAdapter(addr)
+	adapter := NewAdapter(Options{Addrs: []string{addr}, KeyPrefix: "test:"})
 	defer adapter.client.Close()
 
 	t.Run("Set and Get ids from set", func(t *testing.T) {
@@ -81,4 +86,187 @@ func TestRedisAdapter_Integration(t *testing.T) {
 		batch, _ := adapter.GetBatch(ctx, []string{id})
 		assert.Empty(t, batch)
 	})
+
+	t.Run("AddBatch and RemoveBatch", func(t *testing.T) {
+		assets := map[string][]byte{"fav-b1": []byte("one"), "fav-b2": []byte("two")}
+		err := adapter.AddBatch(ctx, assets, 4.0)
+		assert.NoError(t, err)
+
+		ids, _ := adapter.GetIdsFromSet(ctx, 0, -1)
+		assert.Subset(t, ids, []string{"fav-b1", "fav-b2"})
+		batch, err := adapter.GetBatch(ctx, []string{"fav-b1", "fav-b2"})
+		assert.NoError(t, err)
+		assert.Equal(t, assets, batch)
+
+		err = adapter.RemoveBatch(ctx, []string{"fav-b1", "fav-b2"})
+		assert.NoError(t, err)
+
+		ids, _ = adapter.GetIdsFromSet(ctx, 0, -1)
+		assert.NotContains(t, ids, "fav-b1")
+		batch, _ = adapter.GetBatch(ctx, []string{"fav-b1", "fav-b2"})
+		assert.Empty(t, batch)
+	})
+
+	t.Run("ScanSet", func(t *testing.T) {
+		for i, id := range []string{"scan-1", "scan-2", "scan-3"} {
+			assert.NoError(t, adapter.AddToSet(ctx, id, float64(10+i)))
+		}
+
+		var ids []string
+		var cursor uint64
+		for {
+			page, next, err := adapter.ScanSet(ctx, cursor, 2)
+			assert.NoError(t, err)
+			ids = append(ids, page...)
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+		assert.Subset(t, ids, []string{"scan-1", "scan-2", "scan-3"})
+	})
+
+	t.Run("Session state", func(t *testing.T) {
+		sessions := adapter.Sessions()
+
+		state, err := sessions.GetSessionState(ctx, "sess-1")
+		assert.NoError(t, err)
+		assert.Empty(t, state)
+
+		err = sessions.SetSessionState(ctx, "sess-1", auth.SessionRevoked, time.Minute)
+		assert.NoError(t, err)
+
+		state, err = sessions.GetSessionState(ctx, "sess-1")
+		assert.NoError(t, err)
+		assert.Equal(t, auth.SessionRevoked, state)
+	})
+
+	t.Run("Feed history", func(t *testing.T) {
+		feed := adapter.Feed(1000, time.Minute)
+
+		first, err := feed.Append(ctx, ports.FeedEvent{UserID: "feed-user", Type: favorites.EventCreated, Data: []byte(`{"n":1}`)})
+		assert.NoError(t, err)
+		second, err := feed.Append(ctx, ports.FeedEvent{UserID: "feed-user", Type: favorites.EventDeleted, Data: []byte(`{"n":2}`)})
+		assert.NoError(t, err)
+
+		events, complete, err := feed.Since(ctx, "feed-user", first.ID)
+		assert.NoError(t, err)
+		assert.True(t, complete)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, second.ID, events[0].ID)
+			assert.Equal(t, favorites.EventDeleted, events[0].Type)
+			assert.JSONEq(t, `{"n":2}`, string(events[0].Data))
+		}
+
+		_, complete, err = feed.Since(ctx, "other-user", first.ID)
+		assert.NoError(t, err)
+		assert.False(t, complete, "no history is kept for the user")
+
+		_, complete, err = feed.Since(ctx, "feed-user", "not-an-id")
+		assert.NoError(t, err)
+		assert.False(t, complete)
+
+		assert.NoError(t, feed.Delete(ctx, "feed-user"))
+		_, complete, err = feed.Since(ctx, "feed-user", first.ID)
+		assert.NoError(t, err)
+		assert.False(t, complete, "the history is gone")
+	})
+
+	t.Run("Feed history trimmed", func(t *testing.T) {
+		feed := adapter.Feed(1, time.Minute)
+
+		first, err := feed.Append(ctx, ports.FeedEvent{UserID: "trim-user", Type: favorites.EventCreated, Data: []byte(`{}`)})
+		assert.NoError(t, err)
+		for range 200 {
+			_, err = feed.Append(ctx, ports.FeedEvent{UserID: "trim-user", Type: favorites.EventCreated, Data: []byte(`{}`)})
+			assert.NoError(t, err)
+		}
+		// Approximate trimming removes whole nodes; force an exact trim.
+		adapter.client.XTrimMaxLen(ctx, adapter.keys.feed("trim-user"), 1)
+
+		_, complete, err := feed.Since(ctx, "trim-user", first.ID)
+		assert.NoError(t, err)
+		assert.False(t, complete)
+	})
+
+	t.Run("Feed broadcast", func(t *testing.T) {
+		feed := adapter.Feed(1000, time.Minute)
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		subscribed := make(chan struct{}, 1)
+		received := make(chan ports.FeedEvent, 1)
+		go func() {
+			_ = feed.Subscribe(subCtx, func(e ports.FeedEvent) { received <- e }, func() { subscribed <- struct{}{} })
+		}()
+		<-subscribed
+
+		sent, err := feed.Append(ctx, ports.FeedEvent{UserID: "feed-user", Type: favorites.EventUpdated, Data: []byte(`{"n":3}`)})
+		assert.NoError(t, err)
+		select {
+		case e := <-received:
+			assert.Equal(t, sent.ID, e.ID)
+			assert.Equal(t, "feed-user", e.UserID)
+			assert.JSONEq(t, `{"n":3}`, string(e.Data))
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not broadcast")
+		}
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		limiter := adapter.RateLimiter()
+		rate := ports.Rate{Limit: 3, Period: time.Hour}
+
+		for want := 2; want >= 0; want-- {
+			res, err := limiter.Allow(ctx, "write:user:u1", rate)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, want, res.Remaining)
+		}
+
+		res, err := limiter.Allow(ctx, "write:user:u1", rate)
+		assert.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.InDelta(t, 20*time.Minute, res.RetryAfter, float64(time.Second))
+		assert.InDelta(t, time.Hour, res.ResetAfter, float64(time.Second))
+
+		res, err = limiter.Allow(ctx, "write:user:u2", rate)
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("Idempotency", func(t *testing.T) {
+		store := adapter.Idempotency()
+
+		_, ok, err := store.Reserve(ctx, "u1:k1", "fp", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		rec, ok, err := store.Reserve(ctx, "u1:k1", "other", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, ports.IdempotencyRecord{Fingerprint: "fp"}, rec)
+
+		done := ports.IdempotencyRecord{Fingerprint: "fp", Done: true, Status: 201, ContentType: "application/json", Body: []byte(`{"id":"a1"}`)}
+		assert.NoError(t, store.Complete(ctx, "u1:k1", done, time.Hour))
+		rec, ok, err = store.Reserve(ctx, "u1:k1", "fp", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, done, rec)
+
+		assert.NoError(t, store.Release(ctx, "u1:k1"))
+		_, ok, err = store.Reserve(ctx, "u1:k1", "fp", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		_, _, err = store.Reserve(ctx, "u1:k2", "fp", time.Minute)
+		assert.NoError(t, err)
+		_, _, err = store.Reserve(ctx, "u10:k1", "fp", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, store.ForgetUser(ctx, "u1"))
+		for key, forgotten := range map[string]bool{"u1:k1": true, "u1:k2": true, "u10:k1": false} {
+			_, ok, err = store.Reserve(ctx, key, "fp", time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, forgotten, ok, key)
+		}
+	})
 }
@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// Feed keeps each user's recent favorite events in a capped stream, whose
+// entry IDs are the event IDs, and broadcasts new events over pub/sub.
+type Feed struct {
+	client redis.UniversalClient
+	keys   keyspace
+	maxLen int64
+	ttl    time.Duration
+}
+
+// Ensure Feed implements ports.EventFeed
+var _ ports.EventFeed = (*Feed)(nil)
+
+// Feed returns an event feed sharing the adapter's connection pool. Each
+// user's history keeps about maxLen events and expires ttl after the last.
+func (a *Adapter) Feed(maxLen int64, ttl time.Duration) *Feed {
+	return &Feed{client: a.client, keys: a.keys, maxLen: maxLen, ttl: ttl}
+}
+
+// feedMessage is the pub/sub payload.
+type feedMessage struct {
+	ID     string              `json:"id"`
+	UserID string              `json:"user_id"`
+	Type   favorites.EventType `json:"type"`
+	Data   json.RawMessage     `json:"data"`
+}
+
+func (f *Feed) Append(ctx context.Context, e ports.FeedEvent) (ports.FeedEvent, error) {
+	key := f.keys.feed(e.UserID)
+	var add *redis.StringCmd
+	_, err := f.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		add = pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: key,
+			MaxLen: f.maxLen,
+			Approx: true,
+			Values: []any{"type", string(e.Type), "data", e.Data},
+		})
+		if f.ttl > 0 {
+			pipe.Expire(ctx, key, f.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return ports.FeedEvent{}, err
+	}
+	e.ID = add.Val()
+
+	msg, err := json.Marshal(feedMessage{ID: e.ID, UserID: e.UserID, Type: e.Type, Data: e.Data})
+	if err != nil {
+		return ports.FeedEvent{}, fmt.Errorf("failed to marshal feed message: %w", err)
+	}
+	if err := f.client.Publish(ctx, f.keys.feedChannel(), msg).Err(); err != nil {
+		return ports.FeedEvent{}, err
+	}
+	return e, nil
+}
+
+func (f *Feed) Since(ctx context.Context, userID, lastID string) ([]ports.FeedEvent, bool, error) {
+	last, ok := parseStreamID(lastID)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// One transaction, so the stream is not trimmed between the reads.
+	key := f.keys.feed(userID)
+	var (
+		exists  *redis.IntCmd
+		entries *redis.XMessageSliceCmd
+		info    *redis.XInfoStreamCmd
+	)
+	_, err := f.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, key)
+		entries = pipe.XRange(ctx, key, "("+lastID, "+")
+		info = pipe.XInfoStream(ctx, key)
+		return nil
+	})
+	if exists != nil && exists.Val() == 0 {
+		// Expired: whatever followed lastID is gone.
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	generated, _ := parseStreamID(info.Val().LastGeneratedID)
+	deleted, _ := parseStreamID(info.Val().MaxDeletedEntryID)
+	if generated.less(last) || last.less(deleted) {
+		return nil, false, nil
+	}
+
+	events := make([]ports.FeedEvent, 0, len(entries.Val()))
+	for _, m := range entries.Val() {
+		typ, _ := m.Values["type"].(string)
+		data, _ := m.Values["data"].(string)
+		events = append(events, ports.FeedEvent{
+			ID:     m.ID,
+			UserID: userID,
+			Type:   favorites.EventType(typ),
+			Data:   []byte(data),
+		})
+	}
+	return events, true, nil
+}
+
+func (f *Feed) Delete(ctx context.Context, userID string) error {
+	return f.client.Del(ctx, f.keys.feed(userID)).Err()
+}
+
+// Subscribe blocks until ctx is canceled. go-redis reconnects a dropped
+// subscription by itself; reset is called on every (re)subscribe.
+func (f *Feed) Subscribe(ctx context.Context, fn func(ports.FeedEvent), reset func()) error {
+	pubsub := f.client.Subscribe(ctx, f.keys.feedChannel())
+	defer pubsub.Close()
+
+	messages := pubsub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			switch m := msg.(type) {
+			case *redis.Subscription:
+				if m.Kind == "subscribe" {
+					reset()
+				}
+			case *redis.Message:
+				var fm feedMessage
+				if err := json.Unmarshal([]byte(m.Payload), &fm); err != nil {
+					continue
+				}
+				fn(ports.FeedEvent{ID: fm.ID, UserID: fm.UserID, Type: fm.Type, Data: fm.Data})
+			}
+		}
+	}
+}
+
+// streamID is a parsed stream entry ID, "<ms>-<seq>".
+type streamID struct {
+	ms, seq uint64
+}
+
+func parseStreamID(s string) (streamID, bool) {
+	ms, seq, ok := strings.Cut(s, "-")
+	if !ok {
+		return streamID{}, false
+	}
+	var (
+		id  streamID
+		err error
+	)
+	if id.ms, err = strconv.ParseUint(ms, 10, 64); err != nil {
+		return streamID{}, false
+	}
+	if id.seq, err = strconv.ParseUint(seq, 10, 64); err != nil {
+		return streamID{}, false
+	}
+	return id, true
+}
+
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || id.ms == other.ms && id.seq < other.seq
+}
@@ -0,0 +1,22 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreamID(t *testing.T) {
+	id, ok := parseStreamID("1700000000000-2")
+	assert.True(t, ok)
+	assert.Equal(t, streamID{ms: 1700000000000, seq: 2}, id)
+
+	for _, bad := range []string{"", "1700000000000", "abc-1", "1-x", "-1-2"} {
+		_, ok := parseStreamID(bad)
+		assert.False(t, ok, bad)
+	}
+
+	assert.True(t, streamID{1, 5}.less(streamID{2, 0}))
+	assert.True(t, streamID{2, 0}.less(streamID{2, 1}))
+	assert.False(t, streamID{2, 1}.less(streamID{2, 1}))
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// unlockScript deletes the lock only if this replica still owns it, so a
+// fill that outlived its TTL cannot release another replica's lock.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// FillLock implements ports.FillLock with SET NX PX.
+type FillLock struct {
+	client redis.UniversalClient
+	keys   keyspace
+	// owner identifies this replica's locks. Fills are coalesced in-process,
+	// so one token per replica is enough.
+	owner string
+}
+
+// Ensure FillLock implements ports.FillLock
+var _ ports.FillLock = (*FillLock)(nil)
+
+// FillLocks returns a fill lock sharing the adapter's connection pool.
+func (a *Adapter) FillLocks() *FillLock {
+	return &FillLock{client: a.client, keys: a.keys, owner: uuid.NewString()}
+}
+
+func (l *FillLock) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, l.keys.fillLock(key), l.owner, ttl).Result()
+}
+
+func (l *FillLock) Unlock(ctx context.Context, key string) error {
+	return unlockScript.Run(ctx, l.client, []string{l.keys.fillLock(key)}, l.owner).Err()
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// IdempotencyStore implements ports.IdempotencyStore with one JSON value per
+// key, claimed with SET NX.
+type IdempotencyStore struct {
+	client redis.UniversalClient
+	keys   keyspace
+}
+
+// Ensure IdempotencyStore implements ports.IdempotencyStore
+var _ ports.IdempotencyStore = (*IdempotencyStore)(nil)
+
+// Idempotency returns an idempotency store sharing the adapter's connection pool.
+func (a *Adapter) Idempotency() *IdempotencyStore {
+	return &IdempotencyStore{client: a.client, keys: a.keys}
+}
+
+// idempotencyRecord is the stored form of ports.IdempotencyRecord.
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (ports.IdempotencyRecord, bool, error) {
+	claim, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return ports.IdempotencyRecord{}, false, err
+	}
+	k := s.keys.idempotency(key)
+	// The key may expire between SET NX and GET; try again then.
+	for range 3 {
+		ok, err := s.client.SetNX(ctx, k, claim, ttl).Result()
+		if err != nil || ok {
+			return ports.IdempotencyRecord{}, ok, err
+		}
+		data, err := s.client.Get(ctx, k).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return ports.IdempotencyRecord{}, false, err
+		}
+		var rec idempotencyRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return ports.IdempotencyRecord{}, false, fmt.Errorf("failed to decode idempotency record: %w", err)
+		}
+		return ports.IdempotencyRecord(rec), false, nil
+	}
+	return ports.IdempotencyRecord{}, false, fmt.Errorf("idempotency key %q kept expiring", key)
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, rec ports.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(idempotencyRecord(rec))
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.keys.idempotency(key), data, ttl).Err()
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.keys.idempotency(key)).Err()
+}
+
+// ForgetUser scans for the user's keys, on every master of a cluster. The
+// user's requests have ended by then, so no key is added during the scan.
+func (s *IdempotencyStore) ForgetUser(ctx context.Context, userID string) error {
+	pattern := s.keys.idempotency(userID+":") + "*"
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return deleteMatching(ctx, node, pattern)
+		})
+	}
+	return deleteMatching(ctx, s.client, pattern)
+}
+
+// deleteMatching deletes the keys of one node that match pattern, one at a
+// time, so that it never spans cluster slots.
+func deleteMatching(ctx context.Context, client redis.Cmdable, pattern string) error {
+	keys := client.Scan(ctx, 0, pattern, 100).Iterator()
+	for keys.Next(ctx) {
+		if err := client.Del(ctx, keys.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return keys.Err()
+}
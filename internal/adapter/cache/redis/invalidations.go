@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Invalidations broadcasts cache evictions between replicas over pub/sub.
+type Invalidations struct {
+	client redis.UniversalClient
+	keys   keyspace
+}
+
+// Invalidations returns a broadcaster sharing the adapter's connection pool.
+func (a *Adapter) Invalidations() *Invalidations {
+	return &Invalidations{client: a.client, keys: a.keys}
+}
+
+func (i *Invalidations) Publish(ctx context.Context, id string) error {
+	return i.client.Publish(ctx, i.keys.invalidations(), id).Err()
+}
+
+// Subscribe blocks until ctx is canceled. go-redis reconnects a dropped
+// subscription by itself; reset is called on every (re)subscribe.
+func (i *Invalidations) Subscribe(ctx context.Context, evict func(id string), reset func()) error {
+	pubsub := i.client.Subscribe(ctx, i.keys.invalidations())
+	defer pubsub.Close()
+
+	messages := pubsub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			switch m := msg.(type) {
+			case *redis.Subscription:
+				if m.Kind == "subscribe" {
+					reset()
+				}
+			case *redis.Message:
+				evict(m.Payload)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package redis
+
+// Key names, before the deployment's prefix. No command touches more than
+// one key, so the keys carry no hash tag and spread across cluster slots;
+// pipelines are split per node by the cluster client.
+const (
+	SetKey         = "favorites:all"
+	Prefix         = "favorite:"
+	SessionPrefix  = "session:"
+	FillLockPrefix = "fill-lock:"
+	// FeedPrefix names the stream holding a user's recent favorite events.
+	FeedPrefix = "feed:"
+	// RateLimitPrefix names the GCRA state of one rate-limited caller.
+	RateLimitPrefix = "rate-limit:"
+	// IdempotencyPrefix names the stored response for an Idempotency-Key.
+	IdempotencyPrefix = "idempotency:"
+	// InvalidationChannel carries the IDs of assets removed from the cache.
+	InvalidationChannel = "favorites:invalidations"
+	// FeedChannel carries every user's new feed events to all replicas.
+	FeedChannel = "favorites:feed"
+)
+
+// keyspace builds the keys of one deployment, so that several environments
+// can share a Redis instance.
+type keyspace struct {
+	prefix string
+}
+
+func (k keyspace) set() string                   { return k.prefix + SetKey }
+func (k keyspace) asset(id string) string        { return k.prefix + Prefix + id }
+func (k keyspace) session(id string) string      { return k.prefix + SessionPrefix + id }
+func (k keyspace) fillLock(key string) string    { return k.prefix + FillLockPrefix + key }
+func (k keyspace) invalidations() string         { return k.prefix + InvalidationChannel }
+func (k keyspace) feed(userID string) string     { return k.prefix + FeedPrefix + userID }
+func (k keyspace) feedChannel() string           { return k.prefix + FeedChannel }
+func (k keyspace) rateLimit(key string) string   { return k.prefix + RateLimitPrefix + key }
+func (k keyspace) idempotency(key string) string { return k.prefix + IdempotencyPrefix + key }
@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyspace(t *testing.T) {
+	keys := keyspace{prefix: "staging:"}
+
+	assert.Equal(t, "staging:favorites:all", keys.set())
+	assert.Equal(t, "staging:favorite:a1", keys.asset("a1"))
+	assert.Equal(t, "staging:session:s1", keys.session("s1"))
+	assert.Equal(t, "staging:fill-lock:asset:a1", keys.fillLock("asset:a1"))
+	assert.Equal(t, "staging:favorites:invalidations", keys.invalidations())
+	assert.Equal(t, "staging:feed:u1", keys.feed("u1"))
+	assert.Equal(t, "staging:favorites:feed", keys.feedChannel())
+	assert.Equal(t, "staging:rate-limit:read:user:u1", keys.rateLimit("read:user:u1"))
+	assert.Equal(t, "staging:idempotency:u1:k1", keys.idempotency("u1:k1"))
+
+	assert.Equal(t, "favorite:a1", keyspace{}.asset("a1"))
+}
+
+func TestAdapter_TTL(t *testing.T) {
+	a := NewAdapter(Options{})
+	assert.Equal(t, defaultAssetTTL, a.ttl(a.opts.AssetTTL))
+
+	a = NewAdapter(Options{AssetTTL: time.Hour, TTLJitter: time.Minute})
+	for range 100 {
+		ttl := a.ttl(a.opts.AssetTTL)
+		assert.GreaterOrEqual(t, ttl, time.Hour)
+		assert.Less(t, ttl, time.Hour+time.Minute)
+	}
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// gcraScript applies the generic cell rate algorithm: the key holds the
+// theoretical arrival time (TAT) of the next request, in microseconds of the
+// server clock so that every replica agrees. A request is allowed if it
+// arrives no earlier than TAT minus the period, and then moves TAT on by one
+// emission interval.
+//
+// ARGV: emission interval and period, in microseconds.
+// Returns: allowed (0 or 1), remaining, retry after and reset after (µs).
+var gcraScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local next_tat = tat + interval
+local allow_at = next_tat - period
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+redis.call("SET", KEYS[1], string.format("%.0f", next_tat), "PX", math.ceil((next_tat - now) / 1000))
+return {1, math.floor((period - (next_tat - now)) / interval), 0, next_tat - now}
+`)
+
+// RateLimiter implements ports.RateLimiter with GCRA in a Lua script, so a
+// limit holds across replicas.
+type RateLimiter struct {
+	client redis.UniversalClient
+	keys   keyspace
+}
+
+// Ensure RateLimiter implements ports.RateLimiter
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+// RateLimiter returns a rate limiter sharing the adapter's connection pool.
+func (a *Adapter) RateLimiter() *RateLimiter {
+	return &RateLimiter{client: a.client, keys: a.keys}
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string, rate ports.Rate) (ports.RateLimitResult, error) {
+	interval := rate.Period.Microseconds() / int64(rate.Limit)
+	res, err := gcraScript.Run(ctx, l.client, []string{l.keys.rateLimit(key)}, interval, rate.Period.Microseconds()).Int64Slice()
+	if err != nil {
+		return ports.RateLimitResult{}, err
+	}
+	if len(res) != 4 {
+		return ports.RateLimitResult{}, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+	return ports.RateLimitResult{
+		Allowed:    res[0] == 1,
+		Limit:      rate.Limit,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
+)
+
+// SessionCache stores session states next to the favorites cache.
+type SessionCache struct {
+	client redis.UniversalClient
+	keys   keyspace
+}
+
+// Ensure SessionCache implements ports.SessionCache
+var _ ports.SessionCache = (*SessionCache)(nil)
+
+// Sessions returns a session cache sharing the adapter's connection pool.
+func (a *Adapter) Sessions() *SessionCache {
+	return &SessionCache{client: a.client, keys: a.keys}
+}
+
+func (c *SessionCache) GetSessionState(ctx context.Context, sessionID string) (auth.SessionState, error) {
+	state, err := c.client.Get(ctx, c.keys.session(sessionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return auth.SessionState(state), nil
+}
+
+func (c *SessionCache) SetSessionState(ctx context.Context, sessionID string, state auth.SessionState, ttl time.Duration) error {
+	return c.client.Set(ctx, c.keys.session(sessionID), string(state), ttl).Err()
+}
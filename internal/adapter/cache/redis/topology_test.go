@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The topology tests run against locally started Redis processes, e.g.
+// those of compose.topology.yml, which `make test-topology` starts and
+// points these variables at:
+//
+//	REDIS_CLUSTER_ADDRS=127.0.0.1:7000,127.0.0.1:7001,127.0.0.1:7002
+//	REDIS_SENTINEL_ADDRS=127.0.0.1:26379 REDIS_SENTINEL_MASTER=mymaster
+//
+// and are skipped when those are not set.
+
+func TestRedisAdapter_ClusterIntegration(t *testing.T) {
+	addrs := os.Getenv("REDIS_CLUSTER_ADDRS")
+	if testing.Short() || addrs == "" {
+		t.Skip("set REDIS_CLUSTER_ADDRS to run against a local cluster")
+	}
+	testCacheOperations(t, NewAdapter(Options{
+		Mode:      ModeCluster,
+		Addrs:     strings.Split(addrs, ","),
+		KeyPrefix: "test:",
+	}))
+}
+
+func TestRedisAdapter_SentinelIntegration(t *testing.T) {
+	addrs, master := os.Getenv("REDIS_SENTINEL_ADDRS"), os.Getenv("REDIS_SENTINEL_MASTER")
+	if testing.Short() || addrs == "" || master == "" {
+		t.Skip("set REDIS_SENTINEL_ADDRS and REDIS_SENTINEL_MASTER to run against local Sentinels")
+	}
+	testCacheOperations(t, NewAdapter(Options{
+		Mode:       ModeSentinel,
+		Addrs:      strings.Split(addrs, ","),
+		MasterName: master,
+		KeyPrefix:  "test:",
+	}))
+}
+
+// testCacheOperations covers the calls that touch several keys, which a
+// cluster rejects with CROSSSLOT if they are sent as one command.
+func testCacheOperations(t *testing.T, adapter *Adapter) {
+	ctx := context.Background()
+	defer adapter.client.Close()
+
+	ids := []string{"topology-1", "topology-2", "topology-3"}
+	for i, id := range ids {
+		assert.NoError(t, adapter.AddToSet(ctx, id, float64(i)))
+		assert.NoError(t, adapter.Set(ctx, id, []byte(id)))
+	}
+
+	batch, err := adapter.GetBatch(ctx, ids)
+	assert.NoError(t, err)
+	assert.Len(t, batch, len(ids))
+
+	assert.NoError(t, adapter.Remove(ctx, "topology-1"))
+	members, err := adapter.GetIdsFromSet(ctx, 0, -1)
+	assert.NoError(t, err)
+	assert.NotContains(t, members, "topology-1")
+
+	for _, id := range ids[1:] {
+		assert.NoError(t, adapter.Remove(ctx, id))
+	}
+}
@@ -0,0 +1,233 @@
+// Package metadata implements ports.Enricher on top of an HTTP metadata service.
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+var tracer = otel.Tracer("internal/adapter/enricher/metadata")
+
+// maxResponseBytes caps how much of a metadata response is read.
+const maxResponseBytes = 1 << 20
+
+// Config configures the metadata service client.
+type Config struct {
+	// Stage labels metrics and logs when several enrichers call different
+	// services for the same asset type. Defaults to "metadata".
+	Stage string
+
+	// Endpoints maps each asset type to the URL its metadata is requested from.
+	// Types without an endpoint are not enriched.
+	Endpoints map[favorites.AssetType]string
+
+	// Timeout bounds each HTTP attempt.
+	Timeout time.Duration
+	// MaxRetries is the number of additional attempts after a retryable failure.
+	MaxRetries int
+	// Backoff spaces out retries.
+	Backoff resilience.Backoff
+
+	// FailureThreshold consecutive failures open an endpoint's circuit for BreakerCooldown.
+	FailureThreshold int
+	BreakerCooldown  time.Duration
+
+	// Client defaults to a plain http.Client; the per-attempt timeout comes from Timeout.
+	Client *http.Client
+}
+
+// Enricher posts the asset to the metadata service for its type.
+type Enricher struct {
+	cfg      Config
+	client   *http.Client
+	breakers map[favorites.AssetType]*resilience.Breaker
+	logger   *slog.Logger
+}
+
+// Ensure Enricher implements ports.Enricher
+var _ ports.Enricher = (*Enricher)(nil)
+
+func NewEnricher(cfg Config, logger *slog.Logger) *Enricher {
+	if cfg.Stage == "" {
+		cfg.Stage = "metadata"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Backoff == (resilience.Backoff{}) {
+		cfg.Backoff = resilience.Backoff{Base: 100 * time.Millisecond, Max: 2 * time.Second}
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	e := &Enricher{
+		cfg:      cfg,
+		client:   client,
+		breakers: make(map[favorites.AssetType]*resilience.Breaker, len(cfg.Endpoints)),
+		logger:   logger,
+	}
+	for assetType := range cfg.Endpoints {
+		b := resilience.NewBreaker(cfg.FailureThreshold, cfg.BreakerCooldown)
+		b.OnStateChange = func(from, to resilience.State) {
+			circuitState.WithLabelValues(string(assetType), cfg.Stage).Set(float64(to))
+			logger.Warn("metadata service circuit changed state", "asset_type", assetType, "stage", cfg.Stage, "from", from, "to", to)
+		}
+		circuitState.WithLabelValues(string(assetType), cfg.Stage).Set(float64(resilience.StateClosed))
+		e.breakers[assetType] = b
+	}
+	return e
+}
+
+// Enrich requests metadata for the asset. It returns immediately with an error
+// wrapping resilience.ErrOpen while the endpoint's circuit is open.
+func (e *Enricher) Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, error) {
+	assetType := asset.GetType()
+	endpoint, ok := e.cfg.Endpoints[assetType]
+	if !ok {
+		return favorites.Metadata{}, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "Enricher.Enrich", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("asset.id", asset.GetID()),
+		attribute.String("asset.type", string(assetType)),
+	))
+	defer span.End()
+
+	// The service only needs what the user saved.
+	body, err := json.Marshal(favorites.WithEnrichment(asset, favorites.Enrichment{}))
+	if err != nil {
+		return favorites.Metadata{}, fmt.Errorf("failed to marshal asset: %w", err)
+	}
+
+	breaker := e.breakers[assetType]
+	for attempt := 0; ; attempt++ {
+		if err := breaker.Allow(); err != nil {
+			requestsTotal.WithLabelValues(string(assetType), e.cfg.Stage, "short_circuit").Inc()
+			span.SetStatus(codes.Error, "circuit open")
+			return favorites.Metadata{}, fmt.Errorf("metadata service for %s unavailable: %w", assetType, err)
+		}
+
+		start := time.Now()
+		metadata, err := e.call(ctx, endpoint, body)
+		requestDuration.WithLabelValues(string(assetType), e.cfg.Stage).Observe(time.Since(start).Seconds())
+
+		var permanent *permanentError
+		switch {
+		case err == nil:
+			breaker.Success()
+			requestsTotal.WithLabelValues(string(assetType), e.cfg.Stage, "success").Inc()
+			return metadata, nil
+		case errors.As(err, &permanent):
+			// The service answered; the request itself was rejected.
+			breaker.Success()
+			requestsTotal.WithLabelValues(string(assetType), e.cfg.Stage, "rejected").Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return favorites.Metadata{}, err
+		}
+
+		breaker.Failure()
+		requestsTotal.WithLabelValues(string(assetType), e.cfg.Stage, "error").Inc()
+		span.RecordError(err)
+
+		if attempt >= e.cfg.MaxRetries || ctx.Err() != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return favorites.Metadata{}, fmt.Errorf("metadata request for %s failed after %d attempts: %w", asset.GetID(), attempt+1, err)
+		}
+
+		delay := e.cfg.Backoff.Delay(attempt)
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.String("delay", delay.String()),
+		))
+		retriesTotal.WithLabelValues(string(assetType), e.cfg.Stage).Inc()
+		if err := resilience.Sleep(ctx, delay); err != nil {
+			return favorites.Metadata{}, err
+		}
+	}
+}
+
+// permanentError marks responses that retrying cannot fix (4xx other than 408 and 429).
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// response is the document returned by the metadata service.
+type response struct {
+	ThumbnailURL    string    `json:"thumbnail_url"`
+	Summary         string    `json:"summary"`
+	RowCount        int64     `json:"row_count"`
+	AudienceSize    int64     `json:"audience_size"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+}
+
+func (e *Enricher) call(ctx context.Context, endpoint string, body []byte) (favorites.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return favorites.Metadata{}, &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return favorites.Metadata{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return favorites.Metadata{}, fmt.Errorf("metadata service returned status %d", resp.StatusCode)
+	default:
+		return favorites.Metadata{}, &permanentError{err: fmt.Errorf("metadata service rejected request: status %d", resp.StatusCode)}
+	}
+
+	var doc response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&doc); err != nil {
+		return favorites.Metadata{}, fmt.Errorf("invalid metadata response: %w", err)
+	}
+	if doc.RowCount < 0 || doc.AudienceSize < 0 {
+		return favorites.Metadata{}, errors.New("invalid metadata response: negative count")
+	}
+	return favorites.Metadata{
+		ThumbnailURL:    doc.ThumbnailURL,
+		Summary:         doc.Summary,
+		RowCount:        doc.RowCount,
+		AudienceSize:    doc.AudienceSize,
+		LastRefreshedAt: doc.LastRefreshedAt,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/resilience"
+)
+
+var chart = favorites.Chart{BaseAsset: favorites.BaseAsset{ID: "c1", Type: favorites.AssetTypeChart, Name: "Sales"}}
+
+func newTestEnricher(url string, cfg Config) *Enricher {
+	cfg.Endpoints = map[favorites.AssetType]string{favorites.AssetTypeChart: url}
+	cfg.Backoff = resilience.Backoff{Base: time.Millisecond, Max: time.Millisecond}
+	return NewEnricher(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// enrich returns only the error, for tests that don't inspect the metadata.
+func enrich(e *Enricher) error {
+	_, err := e.Enrich(context.Background(), chart)
+	return err
+}
+
+func TestEnricher_Success(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"thumbnail_url":"https://img.test/c1.png","row_count":1200,"last_refreshed_at":"2026-01-02T03:04:05Z"}`))
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{})
+	metadata, err := e.Enrich(context.Background(), chart)
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", got["id"])
+	assert.Equal(t, favorites.Metadata{
+		ThumbnailURL:    "https://img.test/c1.png",
+		RowCount:        1200,
+		LastRefreshedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, metadata)
+}
+
+func TestEnricher_SkipsUnconfiguredType(t *testing.T) {
+	e := newTestEnricher("http://127.0.0.1:0", Config{})
+	insight := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "i1", Type: favorites.AssetTypeInsight}}
+	metadata, err := e.Enrich(context.Background(), insight)
+	assert.NoError(t, err)
+	assert.Zero(t, metadata)
+}
+
+func TestEnricher_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{MaxRetries: 2, FailureThreshold: 5})
+	assert.NoError(t, enrich(e))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestEnricher_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{MaxRetries: 3, FailureThreshold: 1})
+	assert.Error(t, enrich(e))
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, resilience.StateClosed, e.breakers[favorites.AssetTypeChart].State())
+}
+
+func TestEnricher_TimeoutPerAttempt(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{Timeout: 20 * time.Millisecond, MaxRetries: 1, FailureThreshold: 5})
+	assert.NoError(t, enrich(e))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestEnricher_CircuitOpens(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{FailureThreshold: 2, BreakerCooldown: time.Hour})
+
+	assert.Error(t, enrich(e))
+	assert.Error(t, enrich(e))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// The dependency is considered down: no request goes out.
+	_, err := e.Enrich(context.Background(), chart)
+	assert.ErrorIs(t, err, resilience.ErrOpen)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestEnricher_InvalidResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`not json`))
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{})
+	assert.Error(t, enrich(e))
+}
+
+func TestEnricher_NegativeCounts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"row_count":-1}`))
+	}))
+	defer srv.Close()
+
+	e := newTestEnricher(srv.URL, Config{})
+	assert.Error(t, enrich(e))
+}
@@ -0,0 +1,42 @@
+package metadata
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "enricher_requests_total",
+			Help: "Metadata service calls by asset type, stage and outcome (success, rejected, error, short_circuit)",
+		},
+		[]string{"asset_type", "stage", "outcome"},
+	)
+	retriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "enricher_retries_total",
+			Help: "Retried metadata service calls by asset type and stage",
+		},
+		[]string{"asset_type", "stage"},
+	)
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "enricher_request_duration_seconds",
+			Help:    "Latency of individual metadata service attempts in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"asset_type", "stage"},
+	)
+	circuitState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "enricher_circuit_state",
+			Help: "Circuit breaker state per asset type and stage (0 closed, 1 half-open, 2 open)",
+		},
+		[]string{"asset_type", "stage"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal)
+	prometheus.MustRegister(retriesTotal)
+	prometheus.MustRegister(requestDuration)
+	prometheus.MustRegister(circuitState)
+}
@@ -0,0 +1,26 @@
+package enricher
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	stagesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "enrichment_stage_runs_total",
+			Help: "Enrichment stage runs by asset type, stage and outcome (success, timeout, unavailable, error)",
+		},
+		[]string{"asset_type", "stage", "outcome"},
+	)
+	stageDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "enrichment_stage_duration_seconds",
+			Help:    "Duration of enrichment stages in seconds, including retries",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"asset_type", "stage"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(stagesTotal)
+	prometheus.MustRegister(stageDuration)
+}
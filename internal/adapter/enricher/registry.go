@@ -0,0 +1,122 @@
+// Package enricher composes ports.Enricher implementations into per-asset-type pipelines.
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+var tracer = otel.Tracer("internal/adapter/enricher")
+
+// defaultStageTimeout applies to stages registered without a timeout.
+const defaultStageTimeout = 5 * time.Second
+
+// Stage is one step of an asset type's enrichment pipeline.
+type Stage struct {
+	// Name identifies the stage in results, logs and metrics, e.g. "preview".
+	Name     string
+	Enricher ports.Enricher
+	// Timeout bounds the stage, including any retries inside the enricher.
+	Timeout time.Duration
+}
+
+// Registry runs the stages registered for each asset type in registration order.
+type Registry struct {
+	stages map[favorites.AssetType][]Stage
+	logger *slog.Logger
+}
+
+// Ensure Registry implements ports.EnricherRegistry
+var _ ports.EnricherRegistry = (*Registry)(nil)
+
+func NewRegistry(logger *slog.Logger) *Registry {
+	return &Registry{
+		stages: make(map[favorites.AssetType][]Stage),
+		logger: logger,
+	}
+}
+
+// Register appends a stage to the pipeline of the given asset type.
+// It is not safe to call concurrently with Enrich.
+func (r *Registry) Register(assetType favorites.AssetType, stage Stage) {
+	if stage.Timeout <= 0 {
+		stage.Timeout = defaultStageTimeout
+	}
+	r.stages[assetType] = append(r.stages[assetType], stage)
+}
+
+// Enrich runs each stage even if an earlier one failed, so one broken
+// dependency does not hide the metadata the others provide.
+func (r *Registry) Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, []favorites.StageResult, error) {
+	stages := r.stages[asset.GetType()]
+	if len(stages) == 0 {
+		return favorites.Metadata{}, nil, nil
+	}
+
+	var (
+		merged  favorites.Metadata
+		results = make([]favorites.StageResult, 0, len(stages))
+		errs    []error
+	)
+	for _, stage := range stages {
+		metadata, err := r.runStage(ctx, asset, stage)
+		if err != nil {
+			r.logger.Warn("enrichment stage failed", "id", asset.GetID(), "asset_type", asset.GetType(), "stage", stage.Name, "error", err)
+			results = append(results, favorites.StageResult{Name: stage.Name, Error: reason(err)})
+			errs = append(errs, fmt.Errorf("stage %s: %w", stage.Name, err))
+			continue
+		}
+		merged = merged.Merge(metadata)
+		results = append(results, favorites.StageResult{Name: stage.Name})
+	}
+	return merged, results, errors.Join(errs...)
+}
+
+func (r *Registry) runStage(ctx context.Context, asset favorites.Asset, stage Stage) (favorites.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, stage.Timeout)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "Registry.stage."+stage.Name)
+	span.SetAttributes(
+		attribute.String("asset.id", asset.GetID()),
+		attribute.String("asset.type", string(asset.GetType())),
+	)
+	defer span.End()
+
+	start := time.Now()
+	metadata, err := stage.Enricher.Enrich(ctx, asset)
+	stageDuration.WithLabelValues(string(asset.GetType()), stage.Name).Observe(time.Since(start).Seconds())
+
+	outcome := "success"
+	if err != nil {
+		outcome = reason(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	stagesTotal.WithLabelValues(string(asset.GetType()), stage.Name, outcome).Inc()
+	return metadata, err
+}
+
+// reason condenses a stage error for API clients, which must not see
+// internal hostnames or upstream messages.
+func reason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, resilience.ErrOpen):
+		return "unavailable"
+	default:
+		return "error"
+	}
+}
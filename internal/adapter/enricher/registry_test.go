@@ -0,0 +1,103 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/resilience"
+)
+
+// stubEnricher returns fixed metadata, or waits for the context when slow.
+type stubEnricher struct {
+	metadata favorites.Metadata
+	err      error
+	slow     bool
+	calls    int
+}
+
+func (s *stubEnricher) Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, error) {
+	s.calls++
+	if s.slow {
+		<-ctx.Done()
+		return favorites.Metadata{}, ctx.Err()
+	}
+	return s.metadata, s.err
+}
+
+var chart = favorites.Chart{BaseAsset: favorites.BaseAsset{ID: "c1", Type: favorites.AssetTypeChart, Name: "Sales"}}
+
+func newTestRegistry() *Registry {
+	return NewRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRegistry_MergesStagesInOrder(t *testing.T) {
+	r := newTestRegistry()
+	r.Register(favorites.AssetTypeChart, Stage{Name: "metadata", Enricher: &stubEnricher{
+		metadata: favorites.Metadata{RowCount: 10, ThumbnailURL: "old.png"},
+	}})
+	r.Register(favorites.AssetTypeChart, Stage{Name: "preview", Enricher: &stubEnricher{
+		metadata: favorites.Metadata{ThumbnailURL: "new.png"},
+	}})
+
+	metadata, stages, err := r.Enrich(context.Background(), chart)
+
+	assert.NoError(t, err)
+	assert.Equal(t, favorites.Metadata{RowCount: 10, ThumbnailURL: "new.png"}, metadata)
+	assert.Equal(t, []favorites.StageResult{{Name: "metadata"}, {Name: "preview"}}, stages)
+}
+
+func TestRegistry_PartialFailure(t *testing.T) {
+	r := newTestRegistry()
+	failing := &stubEnricher{err: errors.New("dial tcp 10.0.0.7:9000: connection refused")}
+	after := &stubEnricher{metadata: favorites.Metadata{RowCount: 3}}
+	r.Register(favorites.AssetTypeChart, Stage{Name: "metadata", Enricher: failing})
+	r.Register(favorites.AssetTypeChart, Stage{Name: "preview", Enricher: after})
+
+	metadata, stages, err := r.Enrich(context.Background(), chart)
+
+	assert.ErrorContains(t, err, "stage metadata")
+	// Later stages still run after a failure.
+	assert.Equal(t, 1, after.calls)
+	assert.Equal(t, favorites.Metadata{RowCount: 3}, metadata)
+	// Clients only see a short reason, never the upstream error.
+	assert.Equal(t, []favorites.StageResult{{Name: "metadata", Error: "error"}, {Name: "preview"}}, stages)
+}
+
+func TestRegistry_StageTimeout(t *testing.T) {
+	r := newTestRegistry()
+	r.Register(favorites.AssetTypeChart, Stage{Name: "preview", Enricher: &stubEnricher{slow: true}, Timeout: 10 * time.Millisecond})
+	r.Register(favorites.AssetTypeChart, Stage{Name: "metadata", Enricher: &stubEnricher{metadata: favorites.Metadata{RowCount: 1}}})
+
+	start := time.Now()
+	_, stages, err := r.Enrich(context.Background(), chart)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []favorites.StageResult{{Name: "preview", Error: "timeout"}, {Name: "metadata"}}, stages)
+}
+
+func TestRegistry_UnregisteredType(t *testing.T) {
+	r := newTestRegistry()
+	r.Register(favorites.AssetTypeChart, Stage{Name: "metadata", Enricher: &stubEnricher{}})
+
+	insight := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "i1", Type: favorites.AssetTypeInsight}}
+	metadata, stages, err := r.Enrich(context.Background(), insight)
+
+	assert.NoError(t, err)
+	assert.Zero(t, metadata)
+	assert.Empty(t, stages)
+}
+
+func TestReason(t *testing.T) {
+	assert.Equal(t, "timeout", reason(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	assert.Equal(t, "unavailable", reason(fmt.Errorf("wrapped: %w", resilience.ErrOpen)))
+	assert.Equal(t, "error", reason(errors.New("boom")))
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// Mailer implements ports.Mailer by keeping messages in memory and logging
+// that they were sent. Bodies carry single-use tokens and are never logged.
+// It is meant for local development and tests, where no SMTP server is available.
+type Mailer struct {
+	mu       sync.Mutex
+	messages []ports.MailMessage
+	logger   *slog.Logger
+}
+
+// Ensure Mailer implements ports.Mailer
+var _ ports.Mailer = (*Mailer)(nil)
+
+func NewMailer(logger *slog.Logger) *Mailer {
+	return &Mailer{logger: logger}
+}
+
+func (m *Mailer) Send(ctx context.Context, msg ports.MailMessage) error {
+	m.mu.Lock()
+	m.messages = append(m.messages, msg)
+	m.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.InfoContext(ctx, "mail captured", "to", msg.To, "subject", msg.Subject)
+	}
+	return nil
+}
+
+// Messages returns a copy of every message sent so far.
+func (m *Mailer) Messages() []ports.MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]ports.MailMessage, len(m.messages))
+	copy(out, m.messages)
+	return out
+}
+
+// Last returns the most recent message sent to the given address.
+func (m *Mailer) Last(to string) (ports.MailMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return ports.MailMessage{}, false
+}
@@ -0,0 +1,127 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// defaultTimeout bounds a send whose context has no earlier deadline, so an
+// unresponsive server cannot hold up the request that sends the mail.
+const defaultTimeout = 30 * time.Second
+
+// Mailer implements ports.Mailer by relaying through an SMTP server.
+type Mailer struct {
+	addr    string
+	host    string
+	from    string
+	auth    smtp.Auth
+	timeout time.Duration
+	dial    func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// Ensure Mailer implements ports.Mailer
+var _ ports.Mailer = (*Mailer)(nil)
+
+// NewMailer creates an SMTP mailer. Authentication is skipped when username is empty.
+func NewMailer(host, port, username, password, from string) *Mailer {
+	m := &Mailer{
+		addr:    net.JoinHostPort(host, port),
+		host:    host,
+		from:    from,
+		timeout: defaultTimeout,
+		dial:    (&net.Dialer{}).DialContext,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send delivers a plain-text message. The whole exchange is bounded by the
+// context's deadline, or defaultTimeout if that comes first, and is aborted
+// when the context is canceled.
+func (m *Mailer) Send(ctx context.Context, msg ports.MailMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	conn, err := m.dial(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	// Unblock any pending read or write once the context is done.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := m.send(conn, msg); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// send runs the SMTP exchange of smtp.SendMail over conn.
+func (m *Mailer) send(conn net.Conn, msg ports.MailMessage) error {
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(m.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(m.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.build(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (m *Mailer) build(msg ports.MailMessage) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
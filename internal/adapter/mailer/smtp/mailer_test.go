@@ -0,0 +1,124 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// fakeServer answers one SMTP session on conn and returns what the client
+// sent: the commands, then the message data.
+func fakeServer(conn net.Conn) <-chan []string {
+	lines := make(chan []string, 1)
+	go func() {
+		defer conn.Close()
+		var got []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		reply("220 mail.example.com ESMTP")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				lines <- got
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			got = append(got, line)
+			switch {
+			case inData:
+				if line == "." {
+					inData = false
+					reply("250 queued")
+				}
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 mail.example.com")
+			case line == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				lines <- got
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return lines
+}
+
+func TestMailer_Send(t *testing.T) {
+	msg := ports.MailMessage{To: "user@example.com", Subject: "Hello", Body: "line one\nline two"}
+
+	t.Run("builds message and relays", func(t *testing.T) {
+		m := NewMailer("mail.example.com", "587", "user", "pass", "noreply@example.com")
+		var gotAddr string
+		var session <-chan []string
+		m.dial = func(_ context.Context, _, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			gotAddr, session = addr, fakeServer(server)
+			return client, nil
+		}
+
+		err := m.Send(context.Background(), msg)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "mail.example.com:587", gotAddr)
+		raw := strings.Join(<-session, "\n")
+		assert.Contains(t, raw, "MAIL FROM:<noreply@example.com>")
+		assert.Contains(t, raw, "RCPT TO:<user@example.com>")
+		assert.Contains(t, raw, "Subject: Hello")
+		assert.Contains(t, raw, "\nline one\nline two\n.\nQUIT")
+	})
+
+	t.Run("relay error", func(t *testing.T) {
+		m := NewMailer("mail.example.com", "587", "", "", "noreply@example.com")
+		m.dial = func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		}
+		err := m.Send(context.Background(), msg)
+		assert.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("unresponsive server times out", func(t *testing.T) {
+		m := NewMailer("mail.example.com", "587", "", "", "noreply@example.com")
+		m.timeout = 50 * time.Millisecond
+		m.dial = func(context.Context, string, string) (net.Conn, error) {
+			// The server accepts the connection but never greets.
+			client, server := net.Pipe()
+			t.Cleanup(func() { server.Close() })
+			return client, nil
+		}
+
+		start := time.Now()
+		err := m.Send(context.Background(), msg)
+
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("canceled context aborts the exchange", func(t *testing.T) {
+		m := NewMailer("mail.example.com", "587", "", "", "noreply@example.com")
+		m.dial = func(context.Context, string, string) (net.Conn, error) {
+			client, server := net.Pipe()
+			t.Cleanup(func() { server.Close() })
+			return client, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		err := m.Send(ctx, msg)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// AccountStore implements ports.AccountStore, binding every repository of an
+// account to one transaction.
+type AccountStore struct {
+	db dbtx
+}
+
+// Ensure AccountStore implements ports.AccountStore
+var _ ports.AccountStore = (*AccountStore)(nil)
+
+func NewAccountStore(db *pgxpool.Pool) *AccountStore {
+	return &AccountStore{db: db}
+}
+
+func (s *AccountStore) WithTx(ctx context.Context, fn func(tx ports.AccountTx) error) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		return fn(ports.AccountTx{
+			Users:     &UserRepository{db: tx},
+			Sessions:  &SessionRepository{db: tx},
+			Favorites: &Repository{db: tx},
+			Outbox:    &Outbox{db: tx},
+			AuditLog:  &AuditLog{db: tx},
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// AdvisoryLock implements ports.LeaderLock with a session-level Postgres
+// advisory lock. The lock is tied to one pooled connection, which is held
+// for as long as the lock is; if that connection dies, Postgres releases the
+// lock and another replica can take over.
+type AdvisoryLock struct {
+	db  *pgxpool.Pool
+	key int64
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// Ensure AdvisoryLock implements ports.LeaderLock
+var _ ports.LeaderLock = (*AdvisoryLock)(nil)
+
+// NewAdvisoryLock creates a lock identified by name. Every replica must use the same name.
+func NewAdvisoryLock(db *pgxpool.Pool, name string) *AdvisoryLock {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return &AdvisoryLock{db: db, key: int64(h.Sum64())}
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		// We cannot tell whether the session survived, so end it; that
+		// releases the lock server-side if it still held it.
+		_ = l.conn.Conn().Close(context.WithoutCancel(ctx))
+		l.conn.Release()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection for advisory lock: %w", err)
+	}
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		// Closing the session is the other way to drop the lock.
+		_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+	return nil
+}
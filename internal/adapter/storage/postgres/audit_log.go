@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/ports"
+)
+
+// AuditLog implements ports.AuditLog on the append-only audit_log table.
+type AuditLog struct {
+	db dbtx
+}
+
+// Ensure AuditLog implements ports.AuditLog
+var _ ports.AuditLog = (*AuditLog)(nil)
+
+func NewAuditLog(db *pgxpool.Pool) *AuditLog {
+	return &AuditLog{db: db}
+}
+
+const auditColumns = `id, occurred_at, actor_id, action, target_id, changes, request_id, ip, user_agent, redacted_at`
+
+func (l *AuditLog) Append(ctx context.Context, entries ...audit.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO audit_log (occurred_at, actor_id, action, target_id, changes, request_id, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	batch := &pgx.Batch{}
+	for _, e := range entries {
+		var changes []byte
+		if len(e.Changes) > 0 {
+			var err error
+			if changes, err = json.Marshal(e.Changes); err != nil {
+				return fmt.Errorf("failed to marshal audit changes: %w", err)
+			}
+		}
+		batch.Queue(query, e.At, nullString(e.ActorID), string(e.Action), nullString(e.TargetID), changes,
+			nullString(e.RequestID), nullString(e.IP), nullString(e.UserAgent))
+	}
+	results := l.db.SendBatch(ctx, batch)
+	defer results.Close()
+	for i := range entries {
+		if err := results.QueryRow().Scan(&entries[i].ID); err != nil {
+			return fmt.Errorf("failed to insert audit entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Redact goes through redact_audit_log, the one update the table's trigger
+// lets through.
+func (l *AuditLog) Redact(ctx context.Context, actorID, pseudonym string) (int64, error) {
+	var redacted int64
+	if err := l.db.QueryRow(ctx, `SELECT redact_audit_log($1, $2)`, actorID, pseudonym).Scan(&redacted); err != nil {
+		return 0, fmt.Errorf("failed to redact audit log: %w", err)
+	}
+	return redacted, nil
+}
+
+func (l *AuditLog) List(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	where, args := auditWhere(f)
+	args = append(args, f.Limit)
+	query := `SELECT ` + auditColumns + ` FROM audit_log` + where + ` ORDER BY id DESC LIMIT $` + strconv.Itoa(len(args))
+	rows, err := l.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	entries, err := pgx.CollectRows(rows, scanAuditEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+	}
+	return entries, nil
+}
+
+func (l *AuditLog) Export(ctx context.Context, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	where, args := auditWhere(f)
+	rows, err := l.db.Query(ctx, `SELECT `+auditColumns+` FROM audit_log`+where+` ORDER BY id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+
+	return func(yield func(audit.Entry, error) bool) {
+		defer rows.Close()
+
+		for rows.Next() {
+			e, err := scanAuditEntry(rows)
+			if err != nil {
+				yield(audit.Entry{}, fmt.Errorf("failed to scan audit entry: %w", err))
+				return
+			}
+			if !yield(e, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(audit.Entry{}, fmt.Errorf("rows iteration error: %w", err))
+		}
+	}, nil
+}
+
+// auditWhere builds the WHERE clause for f and its arguments.
+func auditWhere(f audit.Filter) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ActorID != "" {
+		add("actor_id = $%d", f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", string(f.Action))
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if !f.From.IsZero() {
+		add("occurred_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("occurred_at < $%d", f.To)
+	}
+	if f.BeforeID > 0 {
+		add("id < $%d", f.BeforeID)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func scanAuditEntry(row pgx.CollectableRow) (audit.Entry, error) {
+	var e audit.Entry
+	var action string
+	var actorID, targetID, requestID, ip, userAgent *string
+	var changes []byte
+	var redactedAt *time.Time
+	err := row.Scan(&e.ID, &e.At, &actorID, &action, &targetID, &changes, &requestID, &ip, &userAgent, &redactedAt)
+	if err != nil {
+		return e, err
+	}
+	e.Action = audit.Action(action)
+	e.ActorID, e.TargetID = deref(actorID), deref(targetID)
+	e.RequestID, e.IP, e.UserAgent = deref(requestID), deref(ip), deref(userAgent)
+	if redactedAt != nil {
+		e.RedactedAt = *redactedAt
+	}
+	if changes != nil {
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/domain/audit"
+)
+
+func TestAuditLog_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	log := NewAuditLog(dbPool)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	entries := []audit.Entry{
+		{At: now.Add(-time.Hour), ActorID: "u1", Action: audit.ActionSignup, RequestID: "r1", IP: "192.0.2.1", UserAgent: "curl"},
+		{At: now.Add(-time.Minute), Action: audit.ActionLoginFailed, IP: "192.0.2.9"},
+		{At: now, ActorID: "u1", Action: audit.ActionFavoriteUpdated, TargetID: "a1", Changes: map[string]audit.Change{
+			"description": {Before: json.RawMessage(`"old"`), After: json.RawMessage(`"new"`)},
+		}},
+	}
+	if err := log.Append(ctx, entries...); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	assert.Less(t, entries[0].ID, entries[2].ID, "IDs are assigned in order")
+
+	t.Run("filters, newest first", func(t *testing.T) {
+		got, err := log.List(ctx, audit.Filter{ActorID: "u1", Limit: 10})
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, audit.ActionFavoriteUpdated, got[0].Action)
+			assert.Equal(t, json.RawMessage(`"new"`), got[0].Changes["description"].After)
+			assert.Equal(t, "192.0.2.1", got[1].IP)
+			assert.True(t, now.Add(-time.Hour).Equal(got[1].At))
+		}
+
+		got, err = log.List(ctx, audit.Filter{Action: audit.ActionLoginFailed, Limit: 10})
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Empty(t, got[0].ActorID)
+		}
+
+		got, err = log.List(ctx, audit.Filter{From: now.Add(-2 * time.Minute), To: now, Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("pages by ID", func(t *testing.T) {
+		first, err := log.List(ctx, audit.Filter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, first, 2)
+
+		rest, err := log.List(ctx, audit.Filter{BeforeID: first[1].ID, Limit: 2})
+		assert.NoError(t, err)
+		if assert.Len(t, rest, 1) {
+			assert.Equal(t, entries[0].ID, rest[0].ID)
+		}
+	})
+
+	t.Run("export streams every match", func(t *testing.T) {
+		seq, err := log.Export(ctx, audit.Filter{Limit: 1})
+		assert.NoError(t, err)
+		n := 0
+		for _, err := range seq {
+			assert.NoError(t, err)
+			n++
+		}
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("entries cannot be changed", func(t *testing.T) {
+		_, err := dbPool.Exec(ctx, `UPDATE audit_log SET actor_id = 'u2'`)
+		assert.ErrorContains(t, err, "append-only")
+		_, err = dbPool.Exec(ctx, `DELETE FROM audit_log`)
+		assert.ErrorContains(t, err, "append-only")
+	})
+
+	t.Run("redact strips an actor's entries", func(t *testing.T) {
+		n, err := log.Redact(ctx, "u1", "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		got, err := log.List(ctx, audit.Filter{ActorID: "p1", Limit: 10})
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, audit.ActionFavoriteUpdated, got[0].Action)
+			assert.Equal(t, "a1", got[0].TargetID)
+			assert.Nil(t, got[0].Changes)
+			assert.Empty(t, got[1].IP)
+			assert.Empty(t, got[1].UserAgent)
+			assert.Empty(t, got[1].RequestID)
+			assert.False(t, got[1].RedactedAt.IsZero())
+		}
+
+		got, err = log.List(ctx, audit.Filter{ActorID: "u1", Limit: 10})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		_, err = dbPool.Exec(ctx, `UPDATE audit_log SET actor_id = 'u1' WHERE actor_id = 'p1'`)
+		assert.ErrorContains(t, err, "append-only", "redaction does not open the table to other updates")
+	})
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// EnrichmentQueue implements ports.EnrichmentQueue on the enrichment_jobs table.
+type EnrichmentQueue struct {
+	db *pgxpool.Pool
+}
+
+// Ensure EnrichmentQueue implements ports.EnrichmentQueue
+var _ ports.EnrichmentQueue = (*EnrichmentQueue)(nil)
+
+func NewEnrichmentQueue(db *pgxpool.Pool) *EnrichmentQueue {
+	return &EnrichmentQueue{db: db}
+}
+
+func (q *EnrichmentQueue) Enqueue(ctx context.Context, assetIDs ...string) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	// A job that is already pending is made due now and bumped to a new version,
+	// so a worker holding the old version runs it once more after finishing.
+	query := `
+		INSERT INTO enrichment_jobs (asset_id)
+		SELECT DISTINCT unnest($1::uuid[])
+		ON CONFLICT (asset_id) WHERE status = 'pending'
+		DO UPDATE SET version = enrichment_jobs.version + 1,
+		              attempts = 0,
+		              run_at = LEAST(enrichment_jobs.run_at, NOW()),
+		              updated_at = NOW()
+	`
+	if _, err := q.db.Exec(ctx, query, assetIDs); err != nil {
+		return fmt.Errorf("failed to enqueue enrichment: %w", err)
+	}
+	return nil
+}
+
+func (q *EnrichmentQueue) Claim(ctx context.Context, limit int, lease time.Duration) ([]ports.EnrichmentJob, error) {
+	query := `
+		UPDATE enrichment_jobs
+		SET locked_until = NOW() + make_interval(secs => $2), attempts = attempts + 1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM enrichment_jobs
+			WHERE status = 'pending' AND run_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, asset_id, attempts, version
+	`
+	rows, err := q.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ports.EnrichmentJob, error) {
+		var job ports.EnrichmentJob
+		err := row.Scan(&job.ID, &job.AssetID, &job.Attempts, &job.Version)
+		return job, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (q *EnrichmentQueue) Complete(ctx context.Context, job ports.EnrichmentJob) error {
+	cmdTag, err := q.db.Exec(ctx, `DELETE FROM enrichment_jobs WHERE id = $1 AND version = $2`, job.ID, job.Version)
+	if err != nil {
+		return fmt.Errorf("failed to complete enrichment job: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		// Re-enqueued while running: release it so the newer request is picked up.
+		_, err = q.db.Exec(ctx, `UPDATE enrichment_jobs SET locked_until = NULL, updated_at = NOW() WHERE id = $1`, job.ID)
+		if err != nil {
+			return fmt.Errorf("failed to release enrichment job: %w", err)
+		}
+	}
+	return nil
+}
+
+func (q *EnrichmentQueue) Retry(ctx context.Context, job ports.EnrichmentJob, at time.Time, cause error) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET locked_until = NULL, run_at = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := q.db.Exec(ctx, query, job.ID, at, cause.Error()); err != nil {
+		return fmt.Errorf("failed to reschedule enrichment job: %w", err)
+	}
+	return nil
+}
+
+func (q *EnrichmentQueue) Bury(ctx context.Context, job ports.EnrichmentJob, cause error) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'dead', locked_until = NULL, last_error = $3, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+	`
+	cmdTag, err := q.db.Exec(ctx, query, job.ID, job.Version, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to bury enrichment job: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		// Re-enqueued while running: give the newer request its own attempts.
+		return q.Retry(ctx, job, time.Now(), cause)
+	}
+	return nil
+}
+
+func (q *EnrichmentQueue) EnqueueStale(ctx context.Context, before time.Time, limit int) (int, error) {
+	// Assets that never finished enrichment (NULL) come first.
+	query := `
+		INSERT INTO enrichment_jobs (asset_id)
+		SELECT f.id FROM favorites f
+		WHERE (f.enrichment_updated_at IS NULL OR f.enrichment_updated_at < $1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM enrichment_jobs j WHERE j.asset_id = f.id AND j.status = 'pending'
+		  )
+		ORDER BY f.enrichment_updated_at NULLS FIRST
+		LIMIT $2
+		ON CONFLICT (asset_id) WHERE status = 'pending' DO NOTHING
+	`
+	cmdTag, err := q.db.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue stale assets: %w", err)
+	}
+	return int(cmdTag.RowsAffected()), nil
+}
+
+func (q *EnrichmentQueue) Stats(ctx context.Context, staleBefore time.Time) (ports.EnrichmentStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM enrichment_jobs WHERE status = 'pending'),
+			(SELECT COUNT(*) FROM enrichment_jobs WHERE status = 'dead'),
+			(SELECT COUNT(*) FROM favorites f
+			 WHERE (f.enrichment_updated_at IS NULL OR f.enrichment_updated_at < $1)
+			   AND NOT EXISTS (
+				SELECT 1 FROM enrichment_jobs j WHERE j.asset_id = f.id AND j.status = 'pending'
+			   ))
+	`
+	var stats ports.EnrichmentStats
+	if err := q.db.QueryRow(ctx, query, staleBefore).Scan(&stats.Pending, &stats.Dead, &stats.Stale); err != nil {
+		return ports.EnrichmentStats{}, fmt.Errorf("failed to read enrichment stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,30 @@
+-- The UNIQUE constraint on users.email is case-sensitive, so "A@x.com" and "a@x.com"
+-- could both register. Emails are now normalized to lower case on signup and this
+-- index enforces uniqueness for rows created before that.
+--
+-- Accounts that differ only in case cannot be merged automatically, since each
+-- has its own password and favorites. Until an operator merges or renames them,
+-- the migration stops with the list of conflicts instead of a bare index error.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_users_email_lower') THEN
+        RETURN;
+    END IF;
+
+    SELECT string_agg(LOWER(email) || ' (users ' || ids || ')', '; ' ORDER BY LOWER(email))
+    INTO conflicts
+    FROM (
+        SELECT MIN(email) AS email, string_agg(id::text, ', ' ORDER BY created_at, id) AS ids
+        FROM users
+        GROUP BY LOWER(email)
+        HAVING COUNT(*) > 1
+    ) duplicates;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'users share an email address in different case; merge or rename them, then restart: %', conflicts;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
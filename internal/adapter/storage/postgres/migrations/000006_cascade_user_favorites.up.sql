@@ -0,0 +1,13 @@
+-- Deleting a user must take their favorites with them (GDPR erasure).
+-- The constraint from migration 2 has no ON DELETE action, so replace it once.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'favorites_user_id_fkey' AND confdeltype = 'c'
+    ) THEN
+        ALTER TABLE favorites DROP CONSTRAINT IF EXISTS favorites_user_id_fkey;
+        ALTER TABLE favorites ADD CONSTRAINT favorites_user_id_fkey
+            FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+    END IF;
+END $$;
@@ -0,0 +1,21 @@
+-- Background enrichment queue. Workers claim rows with FOR UPDATE SKIP LOCKED
+-- and hold them through locked_until; jobs that keep failing are kept with
+-- status 'dead' as the dead-letter queue.
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    asset_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    version INT NOT NULL DEFAULT 1,
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- At most one pending job per asset.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrichment_jobs_pending_asset
+    ON enrichment_jobs (asset_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_run_at
+    ON enrichment_jobs (run_at) WHERE status = 'pending';
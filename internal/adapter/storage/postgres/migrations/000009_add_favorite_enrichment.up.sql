@@ -0,0 +1,3 @@
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS enrichment_data JSONB;
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS enrichment_updated_at TIMESTAMP WITH TIME ZONE;
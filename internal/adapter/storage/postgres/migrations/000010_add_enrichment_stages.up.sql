@@ -0,0 +1 @@
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS enrichment_stages JSONB;
@@ -0,0 +1,3 @@
+-- Lets the re-enrichment scheduler find the oldest enrichment first.
+CREATE INDEX IF NOT EXISTS idx_favorites_enrichment_updated_at
+    ON favorites (enrichment_updated_at NULLS FIRST);
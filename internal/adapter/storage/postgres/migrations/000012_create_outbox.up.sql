@@ -0,0 +1,21 @@
+-- Favorite events written in the transaction of the change they describe and
+-- published by the relay. Rows are deleted once published; events that keep
+-- failing are kept with status 'dead'.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    asset_id UUID NOT NULL,
+    user_id UUID,
+    asset_type VARCHAR(50),
+    asset_data JSONB,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_run_at ON outbox (run_at) WHERE status = 'pending';
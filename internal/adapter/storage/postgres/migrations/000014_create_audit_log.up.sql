@@ -0,0 +1,28 @@
+-- Append-only record of security-relevant and data-changing actions. It has
+-- no foreign keys: entries outlive the users and assets they mention.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    actor_id TEXT,
+    action VARCHAR(50) NOT NULL,
+    target_id TEXT,
+    changes JSONB,
+    request_id TEXT,
+    ip TEXT,
+    user_agent TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_id, id DESC) WHERE target_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log (occurred_at);
+
+-- Reject changes to recorded entries, whatever the application does.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,40 @@
+-- Entries outlive their users, but the personal data in them must not: when
+-- an account is deleted, redact_audit_log clears the asset content, IP, user
+-- agent and request ID of its entries and replaces the actor with a random
+-- pseudonym, keeping what was done and when.
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS redacted_at TIMESTAMP WITH TIME ZONE;
+
+-- Any other change to a recorded entry is still rejected, whatever the
+-- application does. The redaction is only let through while
+-- redact_audit_log has audit_log.redacting set.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND current_setting('audit_log.redacting', true) = 'on'
+        AND NEW.id = OLD.id
+        AND NEW.occurred_at = OLD.occurred_at
+        AND NEW.action = OLD.action
+        AND NEW.target_id IS NOT DISTINCT FROM OLD.target_id
+        AND NEW.changes IS NULL AND NEW.request_id IS NULL
+        AND NEW.ip IS NULL AND NEW.user_agent IS NULL
+        AND NEW.redacted_at IS NOT NULL
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION redact_audit_log(actor TEXT, pseudonym TEXT) RETURNS BIGINT AS $$
+DECLARE
+    redacted BIGINT;
+BEGIN
+    PERFORM set_config('audit_log.redacting', 'on', true);
+    UPDATE audit_log
+    SET actor_id = pseudonym, changes = NULL, request_id = NULL, ip = NULL, user_agent = NULL, redacted_at = NOW()
+    WHERE actor_id = actor;
+    GET DIAGNOSTICS redacted = ROW_COUNT;
+    PERFORM set_config('audit_log.redacting', 'off', true);
+    RETURN redacted;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+-- Account deletions are published as events of their own, which concern no
+-- asset.
+ALTER TABLE outbox ALTER COLUMN asset_id DROP NOT NULL;
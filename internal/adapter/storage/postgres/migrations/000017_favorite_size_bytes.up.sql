@@ -0,0 +1,6 @@
+-- The size of what the user saved, as favorites.StoredSize measures it, so
+-- that quotas add up a column instead of re-encoding every asset. Existing
+-- rows are filled in by RunMigrations, which can compute that size.
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS size_bytes BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_favorites_user_size ON favorites (user_id) INCLUDE (size_bytes);
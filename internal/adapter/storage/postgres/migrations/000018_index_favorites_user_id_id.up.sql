@@ -0,0 +1,3 @@
+-- Exports page through a user's favorites in id order, resuming after the
+-- last id seen, so that rows saved or deleted meanwhile do not shift pages.
+CREATE INDEX IF NOT EXISTS idx_favorites_user_id_id ON favorites (user_id, id);
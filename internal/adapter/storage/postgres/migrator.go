@@ -6,36 +6,104 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/domain/favorites"
 )
 
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// migrations lists the embedded scripts in the order they must be applied.
+var migrations = []string{
+	"000001_create_favorites_table.up.sql",
+	"000002_add_users_table.up.sql",
+	"000003_add_email_verification.up.sql",
+	"000004_case_insensitive_email.up.sql",
+	"000005_add_user_profile.up.sql",
+	"000006_cascade_user_favorites.up.sql",
+	"000007_create_sessions.up.sql",
+	"000008_create_enrichment_jobs.up.sql",
+	"000009_add_favorite_enrichment.up.sql",
+	"000010_add_enrichment_stages.up.sql",
+	"000011_index_enrichment_updated_at.up.sql",
+	"000012_create_outbox.up.sql",
+	"000013_create_webhooks.up.sql",
+	"000014_create_audit_log.up.sql",
+	"000015_audit_log_redaction.up.sql",
+	"000016_outbox_account_events.up.sql",
+	"000017_favorite_size_bytes.up.sql",
+	"000018_index_favorites_user_id_id.up.sql",
+}
+
+// backfillBatchSize bounds how many rows backfillSizes updates at once.
+const backfillBatchSize = 500
+
 // RunMigrations executes the embedded SQL migration files.
 // For a real production app, use golang-migrate or goose.
-// For this challenge, we'll just execute the up script ensuring idempotency (IF NOT EXISTS).
+// For this challenge, we'll just execute the up scripts ensuring idempotency (IF NOT EXISTS).
 func RunMigrations(ctx context.Context, db *pgxpool.Pool, logger *slog.Logger) error {
 	logger.Info("running database migrations")
 
-	// 1. Create Favorites Table
-	content, err := migrationsFS.ReadFile("migrations/000001_create_favorites_table.up.sql")
-	if err != nil {
-		return fmt.Errorf("failed to read migration file 1: %w", err)
+	for i, name := range migrations {
+		content, err := migrationsFS.ReadFile("migrations/" + name)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file %d: %w", i+1, err)
+		}
+		if _, err := db.Exec(ctx, string(content)); err != nil {
+			return fmt.Errorf("failed to execute migration %d: %w", i+1, err)
+		}
 	}
-	if _, err := db.Exec(ctx, string(content)); err != nil {
-		return fmt.Errorf("failed to execute migration 1: %w", err)
+	if err := backfillSizes(ctx, db, logger); err != nil {
+		return err
 	}
 
-	// 2. Add Users Table
-	contentUsers, err := migrationsFS.ReadFile("migrations/000002_add_users_table.up.sql")
-	if err != nil {
-		return fmt.Errorf("failed to read migration file 2: %w", err)
+	logger.Info("migrations completed successfully")
+	return nil
+}
+
+// backfillSizes sets size_bytes where it is missing. SQL cannot reproduce
+// the length of Go's encoding, so each asset is decoded and measured here.
+// Once every row has a size this is a single empty query.
+func backfillSizes(ctx context.Context, db *pgxpool.Pool, logger *slog.Logger) error {
+	filled := 0
+	for {
+		rows, err := db.Query(ctx, `SELECT id, type, asset_data FROM favorites WHERE size_bytes IS NULL LIMIT $1`, backfillBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read favorites without a size: %w", err)
+		}
+		batch := &pgx.Batch{}
+		_, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (struct{}, error) {
+			var id, assetType string
+			var data []byte
+			if err := row.Scan(&id, &assetType, &data); err != nil {
+				return struct{}{}, err
+			}
+			asset, err := unmarshalAsset(assetType, data)
+			if err != nil {
+				return struct{}{}, fmt.Errorf("favorite %s: %w", id, err)
+			}
+			size, err := favorites.StoredSize(asset)
+			if err != nil {
+				return struct{}{}, fmt.Errorf("favorite %s: %w", id, err)
+			}
+			batch.Queue(`UPDATE favorites SET size_bytes = $1 WHERE id = $2 AND size_bytes IS NULL`, size, id)
+			return struct{}{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to measure favorites: %w", err)
+		}
+		if batch.Len() == 0 {
+			break
+		}
+		if err := db.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to backfill favorite sizes: %w", err)
+		}
+		filled += batch.Len()
 	}
-	if _, err := db.Exec(ctx, string(contentUsers)); err != nil {
-		return fmt.Errorf("failed to execute migration 2: %w", err)
+	if filled > 0 {
+		logger.Info("backfilled favorite sizes", "favorites", filled)
 	}
-
-	logger.Info("migrations completed successfully")
 	return nil
 }
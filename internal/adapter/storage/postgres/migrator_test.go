@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+func TestRunMigrations_CaseVariantEmails(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup := startPostgres(t)
+	defer cleanup()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// A database from before emails were normalized.
+	for _, name := range migrations[:3] {
+		content, err := migrationsFS.ReadFile("migrations/" + name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if _, err := db.Exec(ctx, string(content)); err != nil {
+			t.Fatalf("failed to apply %s: %v", name, err)
+		}
+	}
+	_, err := db.Exec(ctx, `
+		INSERT INTO users (id, email, password_hash) VALUES
+			('00000000-0000-0000-0000-00000000000a', 'A@x.com', 'h'),
+			('00000000-0000-0000-0000-00000000000b', 'a@x.com', 'h'),
+			('00000000-0000-0000-0000-00000000000c', 'b@x.com', 'h')`)
+	if err != nil {
+		t.Fatalf("failed to seed users: %v", err)
+	}
+
+	err = RunMigrations(ctx, db, logger)
+	if err == nil {
+		t.Fatal("expected the migration to refuse case-variant duplicates")
+	}
+	for _, want := range []string{"a@x.com", "00000000-0000-0000-0000-00000000000a", "00000000-0000-0000-0000-00000000000b"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not name %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "b@x.com") {
+		t.Errorf("error %q names a user without duplicates", err)
+	}
+
+	// Once an operator renames one of them, startup proceeds.
+	if _, err := db.Exec(ctx, `UPDATE users SET email = 'a+old@x.com' WHERE email = 'A@x.com'`); err != nil {
+		t.Fatalf("failed to rename user: %v", err)
+	}
+	if err := RunMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrations failed after the conflict was resolved: %v", err)
+	}
+	if err := RunMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrations are not idempotent: %v", err)
+	}
+}
+
+func TestRunMigrations_BackfillsSizes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup := startPostgres(t)
+	defer cleanup()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// A database from before sizes were stored.
+	for _, name := range migrations[:16] {
+		content, err := migrationsFS.ReadFile("migrations/" + name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if _, err := db.Exec(ctx, string(content)); err != nil {
+			t.Fatalf("failed to apply %s: %v", name, err)
+		}
+	}
+	userID := "00000000-0000-0000-0000-00000000000a"
+	asset := favorites.Insight{
+		BaseAsset: favorites.BaseAsset{ID: "00000000-0000-0000-0000-0000000000f1", UserID: userID, Type: favorites.AssetTypeInsight, Name: "<Sales & more>"},
+		Content:   "up, then down",
+	}
+	data, err := favorites.StoredJSON(asset)
+	if err != nil {
+		t.Fatalf("failed to encode asset: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO users (id, email, password_hash) VALUES ($1, 'a@x.com', 'h')`, userID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO favorites (id, type, asset_data, user_id) VALUES ($1, $2, $3, $4)`, asset.ID, string(asset.Type), data, userID); err != nil {
+		t.Fatalf("failed to seed favorite: %v", err)
+	}
+
+	if err := RunMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("RunMigrations error: %v", err)
+	}
+	usage, err := NewRepository(db).Usage(ctx, userID)
+	if err != nil {
+		t.Fatalf("Usage error: %v", err)
+	}
+	if want, _ := favorites.StoredSize(asset); usage.Bytes != want {
+		t.Errorf("expected the backfilled size %d, got %d", want, usage.Bytes)
+	}
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// Outbox implements ports.Outbox and ports.OutboxStore on the outbox table.
+type Outbox struct {
+	db dbtx
+}
+
+// Ensure Outbox implements ports.Outbox and ports.OutboxStore
+var (
+	_ ports.Outbox      = (*Outbox)(nil)
+	_ ports.OutboxStore = (*Outbox)(nil)
+)
+
+// NewOutbox returns the relay's side of the outbox. Events are added through
+// FavoriteRepository.WithTx, in the transaction of their change.
+func NewOutbox(db *pgxpool.Pool) *Outbox {
+	return &Outbox{db: db}
+}
+
+func (o *Outbox) Add(ctx context.Context, events ...favorites.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO outbox (event_type, asset_id, user_id, asset_type, asset_data, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		var assetType *string
+		var data []byte
+		if e.Asset != nil {
+			t := string(e.Asset.GetType())
+			assetType = &t
+			var err error
+			if data, err = json.Marshal(e.Asset); err != nil {
+				return fmt.Errorf("failed to marshal event asset: %w", err)
+			}
+		}
+		batch.Queue(query, string(e.Type), nullString(e.AssetID), nullString(e.UserID), assetType, data, e.OccurredAt)
+	}
+	results := o.db.SendBatch(ctx, batch)
+	for range events {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return fmt.Errorf("failed to add outbox event: %w", err)
+		}
+	}
+	return results.Close()
+}
+
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]ports.OutboxMessage, error) {
+	query := `
+		UPDATE outbox
+		SET locked_until = NOW() + make_interval(secs => $2), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = 'pending' AND run_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, attempts, event_id, event_type, COALESCE(asset_id::text, ''), COALESCE(user_id::text, ''), asset_type, asset_data, occurred_at
+	`
+	rows, err := o.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ports.OutboxMessage, error) {
+		var m ports.OutboxMessage
+		var eventType string
+		var assetType *string
+		var data []byte
+		if err := row.Scan(&m.ID, &m.Attempts, &m.Event.ID, &eventType, &m.Event.AssetID, &m.Event.UserID, &assetType, &data, &m.Event.OccurredAt); err != nil {
+			return m, err
+		}
+		m.Event.Type = favorites.EventType(eventType)
+		if assetType != nil && data != nil {
+			asset, err := unmarshalAsset(*assetType, data)
+			if err != nil {
+				return m, fmt.Errorf("unmarshal event asset error: %w", err)
+			}
+			m.Event.Asset = asset
+		}
+		return m, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	// RETURNING does not keep the subquery's order.
+	slices.SortFunc(messages, func(a, b ports.OutboxMessage) int { return cmp.Compare(a.ID, b.ID) })
+	return messages, nil
+}
+
+func (o *Outbox) Complete(ctx context.Context, id int64) error {
+	if _, err := o.db.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to complete outbox event: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) Retry(ctx context.Context, id int64, at time.Time, cause error) error {
+	query := `UPDATE outbox SET locked_until = NULL, run_at = $2, last_error = $3 WHERE id = $1`
+	if _, err := o.db.Exec(ctx, query, id, at, cause.Error()); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) Bury(ctx context.Context, id int64, cause error) error {
+	query := `UPDATE outbox SET status = 'dead', locked_until = NULL, last_error = $2 WHERE id = $1`
+	if _, err := o.db.Exec(ctx, query, id, cause.Error()); err != nil {
+		return fmt.Errorf("failed to bury outbox event: %w", err)
+	}
+	return nil
+}
+
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+func TestOutbox_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	outbox := NewOutbox(dbPool)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	newInsight := func() domain.Asset {
+		return domain.Insight{
+			BaseAsset: domain.BaseAsset{ID: uuid.NewString(), UserID: "user-1", Name: "Insight", Type: domain.AssetTypeInsight},
+			Content:   "c",
+		}
+	}
+	saveWithEvent := func(asset domain.Asset, fail error) error {
+		return repo.WithTx(ctx, func(tx ports.FavoriteRepository, events ports.Outbox) error {
+			if err := tx.Save(ctx, asset); err != nil {
+				return err
+			}
+			if err := events.Add(ctx, domain.NewEvent(domain.EventCreated, asset, now)); err != nil {
+				return err
+			}
+			return fail
+		})
+	}
+
+	t.Run("rollback drops the change and its event", func(t *testing.T) {
+		asset := newInsight()
+		if err := saveWithEvent(asset, errors.New("abort")); err == nil {
+			t.Fatal("expected the transaction error")
+		}
+		if _, err := repo.FindByID(ctx, asset.GetID()); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected the asset to be rolled back, got %v", err)
+		}
+		messages, err := outbox.Claim(ctx, 10, time.Minute)
+		if err != nil {
+			t.Fatalf("Claim error: %v", err)
+		}
+		if len(messages) != 0 {
+			t.Errorf("expected no events, got %+v", messages)
+		}
+	})
+
+	t.Run("commit publishes the event once claimed", func(t *testing.T) {
+		asset := newInsight()
+		if err := saveWithEvent(asset, nil); err != nil {
+			t.Fatalf("WithTx error: %v", err)
+		}
+
+		messages, err := outbox.Claim(ctx, 10, time.Minute)
+		if err != nil {
+			t.Fatalf("Claim error: %v", err)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("expected one event, got %+v", messages)
+		}
+		m := messages[0]
+		if m.Attempts != 1 || m.Event.ID == "" || m.Event.Type != domain.EventCreated || m.Event.AssetID != asset.GetID() || m.Event.UserID != "user-1" || !m.Event.OccurredAt.Equal(now) {
+			t.Errorf("unexpected event: %+v", m)
+		}
+		if m.Event.Asset == nil || m.Event.Asset.GetID() != asset.GetID() {
+			t.Errorf("expected the event to carry the asset, got %+v", m.Event.Asset)
+		}
+
+		// Leased events are not claimed twice; retried ones wait for their time.
+		if again, _ := outbox.Claim(ctx, 10, time.Minute); len(again) != 0 {
+			t.Errorf("expected leased event to be hidden, got %+v", again)
+		}
+		if err := outbox.Retry(ctx, m.ID, time.Now().Add(-time.Second), errors.New("redis down")); err != nil {
+			t.Fatalf("Retry error: %v", err)
+		}
+		retried, err := outbox.Claim(ctx, 10, time.Minute)
+		if err != nil || len(retried) != 1 || retried[0].Attempts != 2 {
+			t.Fatalf("expected the retried event on its second attempt, got %+v (%v)", retried, err)
+		}
+
+		if err := outbox.Complete(ctx, m.ID); err != nil {
+			t.Fatalf("Complete error: %v", err)
+		}
+		var count int
+		if err := dbPool.QueryRow(ctx, "SELECT COUNT(*) FROM outbox").Scan(&count); err != nil {
+			t.Fatalf("failed to count events: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("expected the published event to be removed, got %d rows", count)
+		}
+	})
+
+	t.Run("account events carry no asset", func(t *testing.T) {
+		if err := outbox.Add(ctx, domain.Event{Type: domain.EventAccountDeleted, UserID: "user-1", OccurredAt: now}); err != nil {
+			t.Fatalf("Add error: %v", err)
+		}
+		messages, err := outbox.Claim(ctx, 10, time.Minute)
+		if err != nil || len(messages) != 1 {
+			t.Fatalf("expected one event, got %+v (%v)", messages, err)
+		}
+		if e := messages[0].Event; e.Type != domain.EventAccountDeleted || e.AssetID != "" || e.UserID != "user-1" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	})
+}
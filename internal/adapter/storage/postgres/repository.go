@@ -6,70 +6,215 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"time"
 
 	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// dbtx is what the repositories need from a pool or a transaction.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 // Repository implements ports.FavoriteRepository using PostgreSQL.
 type Repository struct {
-	db *pgxpool.Pool
+	db dbtx
 }
 
+// Ensure Repository implements ports.FavoriteRepository
+var _ ports.FavoriteRepository = (*Repository)(nil)
+
 // NewRepository creates a new postgres repository.
 func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
-// entity represents the database structure for an asset.
-type entity struct {
-	ID        string          `db:"id"`
-	Type      string          `db:"type"`
-	AssetData json.RawMessage `db:"asset_data"`
+// WithTx runs fn in a transaction, or in a savepoint if the repository is
+// already bound to one.
+func (r *Repository) WithTx(ctx context.Context, fn func(repo ports.FavoriteRepository, outbox ports.Outbox) error) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&Repository{db: tx}, &Outbox{db: tx})
+	})
+}
+
+// assetColumns is the column list read by scanAsset.
+const assetColumns = `type, asset_data, updated_at, enrichment_status, enrichment_updated_at, enrichment_data, enrichment_stages`
+
+// insertAsset inserts the row built by insertArgs.
+const insertAsset = `
+	INSERT INTO favorites (id, type, asset_data, user_id, updated_at, enrichment_status, enrichment_updated_at, enrichment_data, enrichment_stages, size_bytes)
+	VALUES ($1, $2, $3, $4, COALESCE($5, NOW()), $6, $7, $8, $9, $10)
+`
+
+// insertArgs returns the arguments of insertAsset for the asset.
+func insertArgs(asset favorites.Asset) ([]any, error) {
+	// Enrichment and timestamps live in their own columns so asset_data only
+	// holds what the user saved. size_bytes is the length of this encoding,
+	// not of the JSONB, so that it matches favorites.StoredSize.
+	enrichment := asset.GetEnrichment()
+	data, err := favorites.StoredJSON(asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asset: %w", err)
+	}
+	if enrichment.Status == "" {
+		enrichment.Status = favorites.EnrichmentPending
+	}
+	enrichmentData, stages, err := marshalEnrichment(enrichment)
+	if err != nil {
+		return nil, err
+	}
+	return []any{asset.GetID(), string(asset.GetType()), data, asset.GetUserID(),
+		nullTime(asset.GetUpdatedAt()), string(enrichment.Status), nullTime(enrichment.UpdatedAt), enrichmentData, stages, int64(len(data))}, nil
 }
 
 // Save persists a generic Asset.
 func (r *Repository) Save(ctx context.Context, asset favorites.Asset) error {
-	data, err := json.Marshal(asset)
+	args, err := insertArgs(asset)
 	if err != nil {
-		return fmt.Errorf("failed to marshal asset: %w", err)
+		return err
 	}
-
-	query := `
-		INSERT INTO favorites (id, type, asset_data, user_id)
-		VALUES ($1, $2, $3, $4)
-	`
-	_, err = r.db.Exec(ctx, query, asset.GetID(), string(asset.GetType()), data, asset.GetUserID())
+	_, err = r.db.Exec(ctx, insertAsset, args...)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return favorites.ErrAlreadyExists
+		}
 		return fmt.Errorf("failed to insert asset: %w", err)
 	}
 	return nil
 }
 
+// SaveBatch persists assets in one round trip. created[i] reports whether
+// assets[i] was inserted; it is false when its ID already exists.
+func (r *Repository) SaveBatch(ctx context.Context, assets []favorites.Asset) ([]bool, error) {
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	// Taken IDs are skipped rather than failing, so that one of them does
+	// not abort the rest of the batch and can be reported on its own.
+	query := insertAsset + ` ON CONFLICT (id) DO NOTHING`
+	batch := &pgx.Batch{}
+	for _, asset := range assets {
+		args, err := insertArgs(asset)
+		if err != nil {
+			return nil, err
+		}
+		batch.Queue(query, args...)
+	}
+	results := r.db.SendBatch(ctx, batch)
+	created := make([]bool, len(assets))
+	for i := range assets {
+		tag, err := results.Exec()
+		if err != nil {
+			_ = results.Close()
+			return nil, fmt.Errorf("failed to insert asset %s: %w", assets[i].GetID(), err)
+		}
+		created[i] = tag.RowsAffected() == 1
+	}
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to insert assets: %w", err)
+	}
+	return created, nil
+}
+
+// ReplaceBatch overwrites the content of the user's existing assets in one
+// round trip. replaced[i] reports whether assets[i] was found.
+func (r *Repository) ReplaceBatch(ctx context.Context, assets []favorites.Asset) ([]bool, error) {
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	// Same arguments as insertAsset.
+	query := `
+		UPDATE favorites
+		SET type = $2, asset_data = $3, updated_at = COALESCE($5, NOW()), enrichment_status = $6,
+			enrichment_updated_at = $7, enrichment_data = $8, enrichment_stages = $9, size_bytes = $10
+		WHERE id = $1 AND user_id = $4
+	`
+	batch := &pgx.Batch{}
+	for _, asset := range assets {
+		args, err := insertArgs(asset)
+		if err != nil {
+			return nil, err
+		}
+		batch.Queue(query, args...)
+	}
+	results := r.db.SendBatch(ctx, batch)
+	replaced := make([]bool, len(assets))
+	for i := range assets {
+		tag, err := results.Exec()
+		if err != nil {
+			_ = results.Close()
+			return nil, fmt.Errorf("failed to replace asset %s: %w", assets[i].GetID(), err)
+		}
+		replaced[i] = tag.RowsAffected() == 1
+	}
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to replace assets: %w", err)
+	}
+	return replaced, nil
+}
+
 // FindByID retrieves an asset by its ID.
 func (r *Repository) FindByID(ctx context.Context, id string) (favorites.Asset, error) {
-	query := `SELECT type, asset_data FROM favorites WHERE id = $1`
-
-	var typeStr string
-	var data []byte
+	query := `SELECT ` + assetColumns + ` FROM favorites WHERE id = $1`
 
-	err := r.db.QueryRow(ctx, query, id).Scan(&typeStr, &data)
+	asset, err := scanAsset(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("asset not found")
+			return nil, favorites.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch asset: %w", err)
 	}
+	return asset, nil
+}
+
+// FindByIDs retrieves the assets that exist among ids, keyed by ID.
+func (r *Repository) FindByIDs(ctx context.Context, ids []string) (map[string]favorites.Asset, error) {
+	// The IDs come from the cache; one that is not a UUID cannot match a row.
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	found := make(map[string]favorites.Asset, len(valid))
+	if len(valid) == 0 {
+		return found, nil
+	}
 
-	return unmarshalAsset(typeStr, data)
+	query := `SELECT ` + assetColumns + ` FROM favorites WHERE id = ANY($1::uuid[])`
+	rows, err := r.db.Query(ctx, query, valid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		found[asset.GetID()] = asset
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return found, nil
 }
 
 // FindAll returns an iterator of Assets to stream results.
 func (r *Repository) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[favorites.Asset, error], error) {
 	query := `
-		SELECT type, asset_data 
+		SELECT ` + assetColumns + `
 		FROM favorites 
 		ORDER BY created_at DESC 
 		LIMIT $1 OFFSET $2
@@ -84,17 +229,9 @@ func (r *Repository) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[
 		defer rows.Close()
 
 		for rows.Next() {
-			var typeStr string
-			var data []byte
-
-			if err := rows.Scan(&typeStr, &data); err != nil {
-				yield(nil, fmt.Errorf("failed to scan row: %w", err))
-				return
-			}
-
-			asset, err := unmarshalAsset(typeStr, data)
+			asset, err := scanAsset(rows)
 			if err != nil {
-				yield(nil, err)
+				yield(nil, fmt.Errorf("failed to scan row: %w", err))
 				return
 			}
 
@@ -109,6 +246,31 @@ func (r *Repository) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[
 	}, nil
 }
 
+// CountByUser returns how many assets the user has favorited.
+func (r *Repository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM favorites WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count favorites: %w", err)
+	}
+	return count, nil
+}
+
+// Usage returns how many assets the user stores and the sum of their
+// size_bytes. It first takes a transaction-scoped advisory lock on the user, which is
+// released at once outside a transaction.
+func (r *Repository) Usage(ctx context.Context, userID string) (favorites.Usage, error) {
+	if _, err := r.db.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('favorites:quota:' || $1))`, userID); err != nil {
+		return favorites.Usage{}, fmt.Errorf("failed to lock quota: %w", err)
+	}
+	var u favorites.Usage
+	query := `SELECT COUNT(*), COALESCE(SUM(size_bytes), 0) FROM favorites WHERE user_id = $1`
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&u.Favorites, &u.Bytes); err != nil {
+		return favorites.Usage{}, fmt.Errorf("failed to read usage: %w", err)
+	}
+	return u, nil
+}
+
 // Delete removes an asset by ID.
 func (r *Repository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM favorites WHERE id = $1`
@@ -117,38 +279,101 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete asset: %w", err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return errors.New("asset not found")
+		return favorites.ErrNotFound
 	}
 	return nil
 }
 
-// UpdateDescription updates just the description of an asset.
+// DeleteByUser removes every asset owned by the user and returns their IDs.
+func (r *Repository) DeleteByUser(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `DELETE FROM favorites WHERE user_id = $1 RETURNING id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete favorites: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deleted ids: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteBatch removes the assets among ids that the user owns and returns
+// the IDs removed.
+func (r *Repository) DeleteBatch(ctx context.Context, userID string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.Query(ctx, `DELETE FROM favorites WHERE id = ANY($1::uuid[]) AND user_id = $2 RETURNING id`, ids, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete assets: %w", err)
+	}
+	deleted, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect deleted ids: %w", err)
+	}
+	return deleted, nil
+}
+
+// UpdateDescription updates just the description of an asset, then records
+// the size of the result. The caller checks that size against the quota.
 func (r *Repository) UpdateDescription(ctx context.Context, id, description string) (favorites.Asset, error) {
 	query := `
 		UPDATE favorites
 		SET asset_data = jsonb_set(asset_data, '{description}', to_jsonb($1::text)),
 		    updated_at = NOW()
 		WHERE id = $2
-		RETURNING type, asset_data
+		RETURNING ` + assetColumns + `
 	`
-	var typeStr string
-	var data []byte
+	var asset favorites.Asset
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		if asset, err = scanAsset(tx.QueryRow(ctx, query, description, id)); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return favorites.ErrNotFound
+			}
+			return fmt.Errorf("failed to update description: %w", err)
+		}
+		size, err := favorites.StoredSize(asset)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE favorites SET size_bytes = $1 WHERE id = $2`, size, id); err != nil {
+			return fmt.Errorf("failed to update size: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
 
-	err := r.db.QueryRow(ctx, query, description, id).Scan(&typeStr, &data)
+// UpdateEnrichment stores the enrichment state of an asset.
+func (r *Repository) UpdateEnrichment(ctx context.Context, id string, enrichment favorites.Enrichment) error {
+	data, stages, err := marshalEnrichment(enrichment)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("asset not found")
-		}
-		return nil, fmt.Errorf("failed to update description: %w", err)
+		return err
 	}
 
-	return unmarshalAsset(typeStr, data)
+	query := `
+		UPDATE favorites
+		SET enrichment_status = $1, enrichment_updated_at = $2, enrichment_data = $3, enrichment_stages = $4
+		WHERE id = $5
+	`
+	cmdTag, err := r.db.Exec(ctx, query, string(enrichment.Status), nullTime(enrichment.UpdatedAt), data, stages, id)
+	if err != nil {
+		return fmt.Errorf("failed to update enrichment: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return favorites.ErrNotFound
+	}
+	return nil
 }
 
 // FindByUser returns an iterator of Assets for a specific user.
 func (r *Repository) FindByUser(ctx context.Context, userID string, limit, offset int) (iter.Seq2[favorites.Asset, error], error) {
 	query := `
-		SELECT id, type, asset_data 
+		SELECT ` + assetColumns + `
 		FROM favorites 
 		WHERE user_id = $1
 		ORDER BY created_at DESC 
@@ -162,14 +387,43 @@ func (r *Repository) FindByUser(ctx context.Context, userID string, limit, offse
 	return func(yield func(favorites.Asset, error) bool) {
 		defer rows.Close()
 		for rows.Next() {
-			var ent entity
-			if err := rows.Scan(&ent.ID, &ent.Type, &ent.AssetData); err != nil {
+			asset, err := scanAsset(rows)
+			if err != nil {
 				yield(nil, fmt.Errorf("scan error: %w", err))
 				return
 			}
-			asset, err := unmarshalAsset(ent.Type, ent.AssetData)
+			if !yield(asset, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}, nil
+}
+
+// FindByUserAfter returns an iterator of up to limit of the user's Assets
+// with an ID greater than afterID, in ID order. An empty afterID starts from
+// the first asset.
+func (r *Repository) FindByUserAfter(ctx context.Context, userID, afterID string, limit int) (iter.Seq2[favorites.Asset, error], error) {
+	query := `
+		SELECT ` + assetColumns + `
+		FROM favorites
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR id > $2::uuid)
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, userID, nullString(afterID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorites: %w", err)
+	}
+
+	return func(yield func(favorites.Asset, error) bool) {
+		defer rows.Close()
+		for rows.Next() {
+			asset, err := scanAsset(rows)
 			if err != nil {
-				yield(nil, fmt.Errorf("unmarshal error: %w", err))
+				yield(nil, fmt.Errorf("scan error: %w", err))
 				return
 			}
 			if !yield(asset, nil) {
@@ -182,6 +436,64 @@ func (r *Repository) FindByUser(ctx context.Context, userID string, limit, offse
 	}, nil
 }
 
+// scanAsset reads one row selected with assetColumns.
+func scanAsset(row pgx.Row) (favorites.Asset, error) {
+	var typeStr, status string
+	var data, enrichmentData, stages []byte
+	var assetUpdatedAt, updatedAt *time.Time
+
+	if err := row.Scan(&typeStr, &data, &assetUpdatedAt, &status, &updatedAt, &enrichmentData, &stages); err != nil {
+		return nil, err
+	}
+
+	asset, err := unmarshalAsset(typeStr, data)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	if assetUpdatedAt != nil {
+		asset = favorites.WithUpdatedAt(asset, *assetUpdatedAt)
+	}
+
+	enrichment := favorites.Enrichment{Status: favorites.EnrichmentStatus(status)}
+	if updatedAt != nil {
+		enrichment.UpdatedAt = *updatedAt
+	}
+	if enrichmentData != nil {
+		if err := json.Unmarshal(enrichmentData, &enrichment.Data); err != nil {
+			return nil, fmt.Errorf("unmarshal enrichment error: %w", err)
+		}
+	}
+	if stages != nil {
+		if err := json.Unmarshal(stages, &enrichment.Stages); err != nil {
+			return nil, fmt.Errorf("unmarshal enrichment stages error: %w", err)
+		}
+	}
+	return favorites.WithEnrichment(asset, enrichment), nil
+}
+
+// marshalEnrichment encodes the metadata and stage results, returning nil
+// for empty values so their columns stay NULL.
+func marshalEnrichment(e favorites.Enrichment) (data, stages []byte, err error) {
+	if e.Data != (favorites.Metadata{}) {
+		if data, err = json.Marshal(e.Data); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal enrichment: %w", err)
+		}
+	}
+	if len(e.Stages) > 0 {
+		if stages, err = json.Marshal(e.Stages); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal enrichment stages: %w", err)
+		}
+	}
+	return data, stages, nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 // unmarshalAsset is a helper to deserialize JSON into the correct concrete type.
 func unmarshalAsset(t string, data []byte) (favorites.Asset, error) {
 	var asset favorites.Asset
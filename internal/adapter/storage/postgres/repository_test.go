@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	domain "go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -16,7 +20,8 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
+// startPostgres starts an empty database.
+func startPostgres(t *testing.T) (*pgxpool.Pool, func()) {
 	ctx := context.Background()
 	pgContainer, err := postgres.Run(ctx,
 		"postgres:16-alpine",
@@ -42,6 +47,20 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 		t.Fatalf("failed to connect to postgres: %v", err)
 	}
 
+	cleanup := func() {
+		dbPool.Close()
+		if err := pgContainer.Terminate(ctx); err != nil {
+			t.Logf("failed to terminate postgres: %v", err)
+		}
+	}
+
+	return dbPool, cleanup
+}
+
+func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
+	ctx := context.Background()
+	dbPool, cleanup := startPostgres(t)
+
 	// Schema initialization
 	schema := `
 	CREATE TABLE favorites (
@@ -49,20 +68,103 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 		type VARCHAR(50) NOT NULL,
 		user_id VARCHAR(255) NOT NULL,
 		asset_data JSONB NOT NULL,
+		enrichment_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		enrichment_data JSONB,
+		enrichment_updated_at TIMESTAMP WITH TIME ZONE,
+		enrichment_stages JSONB,
+		size_bytes BIGINT,
 		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);`
+	);
+	CREATE TABLE outbox (
+		id BIGSERIAL PRIMARY KEY,
+		event_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+		event_type VARCHAR(50) NOT NULL,
+		asset_id UUID,
+		user_id VARCHAR(255),
+		asset_type VARCHAR(50),
+		asset_data JSONB,
+		occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		locked_until TIMESTAMP WITH TIME ZONE,
+		last_error TEXT,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+	CREATE TABLE webhook_subscriptions (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		url TEXT NOT NULL,
+		event_types TEXT[] NOT NULL,
+		secret TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	);
+	CREATE TABLE webhook_deliveries (
+		id UUID PRIMARY KEY,
+		subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+		event_id UUID NOT NULL,
+		event_type VARCHAR(50) NOT NULL,
+		payload TEXT NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		response_status INT,
+		last_error TEXT,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		next_attempt_at TIMESTAMP WITH TIME ZONE,
+		locked_until TIMESTAMP WITH TIME ZONE,
+		delivered_at TIMESTAMP WITH TIME ZONE,
+		UNIQUE (subscription_id, event_id)
+	);
+	CREATE TABLE audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		actor_id TEXT,
+		action VARCHAR(50) NOT NULL,
+		target_id TEXT,
+		changes JSONB,
+		request_id TEXT,
+		ip TEXT,
+		user_agent TEXT,
+		redacted_at TIMESTAMP WITH TIME ZONE
+	);
+	CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'UPDATE'
+			AND current_setting('audit_log.redacting', true) = 'on'
+			AND NEW.id = OLD.id
+			AND NEW.occurred_at = OLD.occurred_at
+			AND NEW.action = OLD.action
+			AND NEW.target_id IS NOT DISTINCT FROM OLD.target_id
+			AND NEW.changes IS NULL AND NEW.request_id IS NULL
+			AND NEW.ip IS NULL AND NEW.user_agent IS NULL
+			AND NEW.redacted_at IS NOT NULL
+		THEN
+			RETURN NEW;
+		END IF;
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;
+	CREATE FUNCTION redact_audit_log(actor TEXT, pseudonym TEXT) RETURNS BIGINT AS $$
+	DECLARE
+		redacted BIGINT;
+	BEGIN
+		PERFORM set_config('audit_log.redacting', 'on', true);
+		UPDATE audit_log
+		SET actor_id = pseudonym, changes = NULL, request_id = NULL, ip = NULL, user_agent = NULL, redacted_at = NOW()
+		WHERE actor_id = actor;
+		GET DIAGNOSTICS redacted = ROW_COUNT;
+		PERFORM set_config('audit_log.redacting', 'off', true);
+		RETURN redacted;
+	END;
+	$$ LANGUAGE plpgsql;
+	CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();`
 	if _, err := dbPool.Exec(ctx, schema); err != nil {
+		cleanup()
 		t.Fatalf("failed to init schema: %v", err)
 	}
 
-	cleanup := func() {
-		dbPool.Close()
-		if err := pgContainer.Terminate(ctx); err != nil {
-			t.Logf("failed to terminate postgres: %v", err)
-		}
-	}
-
 	return dbPool, cleanup
 }
 
@@ -205,3 +307,323 @@ func TestRepository_ThreadSafety(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestRepository_Enrichment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	saved := domain.Chart{
+		BaseAsset: domain.BaseAsset{ID: id, UserID: "user-1", Name: "Chart", Type: domain.AssetTypeChart},
+		XAxis:     "x",
+	}
+	if err := repo.Save(ctx, saved); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed to fetch asset: %v", err)
+	}
+	if got.GetEnrichment().Status != domain.EnrichmentPending {
+		t.Errorf("expected pending enrichment, got %q", got.GetEnrichment().Status)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	enrichment := domain.Enrichment{
+		Status:    domain.EnrichmentReady,
+		UpdatedAt: now,
+		Data:      domain.Metadata{ThumbnailURL: "https://img.test/1.png", RowCount: 7, LastRefreshedAt: now},
+		Stages:    []domain.StageResult{{Name: "metadata"}, {Name: "preview", Error: "timeout"}},
+	}
+	if err := repo.UpdateEnrichment(ctx, id, enrichment); err != nil {
+		t.Fatalf("failed to update enrichment: %v", err)
+	}
+
+	// Updating the description must keep the enrichment columns.
+	got, err = repo.UpdateDescription(ctx, id, "new")
+	if err != nil {
+		t.Fatalf("failed to update description: %v", err)
+	}
+	e := got.GetEnrichment()
+	if e.Status != domain.EnrichmentReady || !e.UpdatedAt.Equal(now) || e.Data.RowCount != 7 || e.Data.ThumbnailURL != "https://img.test/1.png" || len(e.Stages) != 2 || e.Stages[1].Error != "timeout" {
+		t.Errorf("unexpected enrichment after update: %+v", e)
+	}
+
+	var raw string
+	if err := dbPool.QueryRow(ctx, "SELECT asset_data::text FROM favorites WHERE id = $1", id).Scan(&raw); err != nil {
+		t.Fatalf("failed to read asset_data: %v", err)
+	}
+	if strings.Contains(raw, "enrichment") {
+		t.Errorf("asset_data must not duplicate enrichment: %s", raw)
+	}
+
+	if err := repo.UpdateEnrichment(ctx, uuid.NewString(), enrichment); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown asset, got %v", err)
+	}
+}
+
+func TestRepository_FindByIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	savedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	id := uuid.NewString()
+	saved := domain.WithUpdatedAt(domain.Insight{
+		BaseAsset: domain.BaseAsset{ID: id, UserID: "user-1", Name: "Insight", Type: domain.AssetTypeInsight},
+		Content:   "c",
+	}, savedAt)
+	if err := repo.Save(ctx, saved); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+
+	found, err := repo.FindByIDs(ctx, []string{id, uuid.NewString(), "not-a-uuid"})
+	if err != nil {
+		t.Fatalf("FindByIDs error: %v", err)
+	}
+	if len(found) != 1 || found[id] == nil {
+		t.Fatalf("expected only %s, got %v", id, found)
+	}
+	if !found[id].GetUpdatedAt().Equal(savedAt) {
+		t.Errorf("expected updated_at %v, got %v", savedAt, found[id].GetUpdatedAt())
+	}
+
+	// A user edit moves updated_at; asset_data does not duplicate it.
+	updated, err := repo.UpdateDescription(ctx, id, "new")
+	if err != nil {
+		t.Fatalf("failed to update description: %v", err)
+	}
+	if !updated.GetUpdatedAt().After(savedAt) {
+		t.Errorf("expected updated_at after %v, got %v", savedAt, updated.GetUpdatedAt())
+	}
+	var raw string
+	if err := dbPool.QueryRow(ctx, "SELECT asset_data::text FROM favorites WHERE id = $1", id).Scan(&raw); err != nil {
+		t.Fatalf("failed to read asset_data: %v", err)
+	}
+	if strings.Contains(raw, "updated_at") {
+		t.Errorf("asset_data must not duplicate updated_at: %s", raw)
+	}
+}
+
+func TestRepository_FindByUserAfter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	var ids []string
+	for range 5 {
+		id := uuid.NewString()
+		asset := domain.Insight{
+			BaseAsset: domain.BaseAsset{ID: id, UserID: "user-1", Name: "Insight", Type: domain.AssetTypeInsight},
+			Content:   "c",
+		}
+		if err := repo.Save(ctx, asset); err != nil {
+			t.Fatalf("failed to save asset: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	other := domain.Insight{
+		BaseAsset: domain.BaseAsset{ID: uuid.NewString(), UserID: "user-2", Name: "Insight", Type: domain.AssetTypeInsight},
+		Content:   "c",
+	}
+	if err := repo.Save(ctx, other); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+	slices.Sort(ids)
+
+	page := func(afterID string) []string {
+		t.Helper()
+		assets, err := repo.FindByUserAfter(ctx, "user-1", afterID, 2)
+		if err != nil {
+			t.Fatalf("FindByUserAfter error: %v", err)
+		}
+		var got []string
+		for asset, err := range assets {
+			if err != nil {
+				t.Fatalf("iteration error: %v", err)
+			}
+			got = append(got, asset.GetID())
+		}
+		return got
+	}
+
+	first := page("")
+	if !slices.Equal(first, ids[:2]) {
+		t.Fatalf("expected %v, got %v", ids[:2], first)
+	}
+
+	// Deleting a row already read must not shift the next page.
+	if err := repo.Delete(ctx, ids[0]); err != nil {
+		t.Fatalf("failed to delete asset: %v", err)
+	}
+	if got := page(first[1]); !slices.Equal(got, ids[2:4]) {
+		t.Errorf("expected %v, got %v", ids[2:4], got)
+	}
+	if got := page(ids[3]); !slices.Equal(got, ids[4:]) {
+		t.Errorf("expected %v, got %v", ids[4:], got)
+	}
+}
+
+func TestRepository_Usage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	usage, err := repo.Usage(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("Usage error: %v", err)
+	}
+	if usage != (domain.Usage{}) {
+		t.Errorf("expected no usage, got %+v", usage)
+	}
+
+	var want int64
+	var last domain.Asset
+	for range 2 {
+		asset := domain.Insight{
+			BaseAsset: domain.BaseAsset{ID: uuid.NewString(), UserID: "user-1", Name: "Insight <&>", Type: domain.AssetTypeInsight},
+			Content:   "c",
+		}
+		if err := repo.Save(ctx, asset); err != nil {
+			t.Fatalf("failed to save asset: %v", err)
+		}
+		size, _ := domain.StoredSize(asset)
+		want += size
+		last = asset
+	}
+	updated, err := repo.UpdateDescription(ctx, last.GetID(), "longer description")
+	if err != nil {
+		t.Fatalf("UpdateDescription error: %v", err)
+	}
+	oldSize, _ := domain.StoredSize(last)
+	newSize, _ := domain.StoredSize(updated)
+	want += newSize - oldSize
+
+	// The lock is taken inside a transaction as well.
+	err = repo.WithTx(ctx, func(tx ports.FavoriteRepository, _ ports.Outbox) error {
+		usage, err = tx.Usage(ctx, "user-1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Usage in transaction error: %v", err)
+	}
+	// The stored sizes are what the service counts against the quota.
+	if usage.Favorites != 2 || usage.Bytes != want {
+		t.Errorf("expected 2 favorites of %d bytes, got %+v", want, usage)
+	}
+}
+
+func TestRepository_SaveDuplicateID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	asset := domain.Insight{
+		BaseAsset: domain.BaseAsset{ID: uuid.NewString(), UserID: "user-1", Name: "Insight", Type: domain.AssetTypeInsight},
+		Content:   "c",
+	}
+	if err := repo.Save(ctx, asset); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+	if err := repo.Save(ctx, asset); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+}
+
+func TestRepository_Batch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewRepository(dbPool)
+	ctx := context.Background()
+
+	newInsight := func(id, userID string) domain.Asset {
+		return domain.Insight{
+			BaseAsset: domain.BaseAsset{ID: id, UserID: userID, Name: "Insight", Type: domain.AssetTypeInsight},
+			Content:   "c",
+		}
+	}
+	taken, first, second := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	if err := repo.Save(ctx, newInsight(taken, "user-1")); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+
+	// Taken IDs, also within the batch, are skipped without failing the rest.
+	created, err := repo.SaveBatch(ctx, []domain.Asset{
+		newInsight(first, "user-1"), newInsight(taken, "user-1"), newInsight(second, "user-2"), newInsight(first, "user-1"),
+	})
+	if err != nil {
+		t.Fatalf("SaveBatch error: %v", err)
+	}
+	if want := []bool{true, false, true, false}; !slices.Equal(created, want) {
+		t.Errorf("expected created %v, got %v", want, created)
+	}
+
+	// Only the user's own assets are deleted.
+	deleted, err := repo.DeleteBatch(ctx, "user-1", []string{first, second, uuid.NewString()})
+	if err != nil {
+		t.Fatalf("DeleteBatch error: %v", err)
+	}
+	if !slices.Equal(deleted, []string{first}) {
+		t.Errorf("expected %s deleted, got %v", first, deleted)
+	}
+	if _, err := repo.FindByID(ctx, second); err != nil {
+		t.Errorf("expected the other user's asset to remain, got %v", err)
+	}
+
+	// Only the user's own assets are replaced.
+	changed := domain.Insight{
+		BaseAsset: domain.BaseAsset{ID: taken, UserID: "user-1", Name: "Changed", Type: domain.AssetTypeInsight},
+		Content:   "new",
+	}
+	replaced, err := repo.ReplaceBatch(ctx, []domain.Asset{changed, newInsight(second, "user-1")})
+	if err != nil {
+		t.Fatalf("ReplaceBatch error: %v", err)
+	}
+	if want := []bool{true, false}; !slices.Equal(replaced, want) {
+		t.Errorf("expected replaced %v, got %v", want, replaced)
+	}
+	got, err := repo.FindByID(ctx, taken)
+	if err != nil {
+		t.Fatalf("FindByID error: %v", err)
+	}
+	if insight, ok := got.(domain.Insight); !ok || insight.Content != "new" || insight.Name != "Changed" {
+		t.Errorf("expected the replaced content, got %+v", got)
+	}
+}
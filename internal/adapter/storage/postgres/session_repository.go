@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/domain/auth"
+)
+
+type SessionRepository struct {
+	db dbtx
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func (r *SessionRepository) Create(ctx context.Context, s auth.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(ctx, query, s.ID, s.UserID, s.UserAgent, s.IP, s.CreatedAt, s.LastSeenAt, s.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id string) (auth.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return auth.Session{}, fmt.Errorf("failed to find session: %w", err)
+	}
+	session, err := pgx.CollectExactlyOneRow(rows, scanSession)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.Session{}, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return auth.Session{}, fmt.Errorf("failed to find session: %w", err)
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) ListActive(ctx context.Context, userID string, now time.Time) ([]auth.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions, err := pgx.CollectRows(rows, scanSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE sessions SET last_seen_at = $1 WHERE id = $2`, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, userID, id string, at time.Time) (auth.Session, error) {
+	query := `
+		UPDATE sessions SET revoked_at = $1
+		WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL
+		RETURNING ` + sessionColumns
+	rows, err := r.db.Query(ctx, query, at, id, userID)
+	if err != nil {
+		return auth.Session{}, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	session, err := pgx.CollectExactlyOneRow(rows, scanSession)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.Session{}, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return auth.Session{}, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) RevokeAllExcept(ctx context.Context, userID, keepID string, at time.Time) ([]auth.Session, error) {
+	query := `
+		UPDATE sessions SET revoked_at = $1
+		WHERE user_id = $2 AND id IS DISTINCT FROM NULLIF($3::text, '')::uuid
+			AND revoked_at IS NULL AND expires_at > $1
+		RETURNING ` + sessionColumns
+	rows, err := r.db.Query(ctx, query, at, userID, keepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	sessions, err := pgx.CollectRows(rows, scanSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE id IN (SELECT id FROM sessions WHERE expires_at < $1 LIMIT $2)`
+	tag, err := r.db.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func scanSession(row pgx.CollectableRow) (auth.Session, error) {
+	var s auth.Session
+	var revokedAt *time.Time
+	err := row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &revokedAt)
+	if revokedAt != nil {
+		s.RevokedAt = *revokedAt
+	}
+	return s, err
+}
@@ -2,38 +2,159 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"go-favorites-app/internal/core/domain/auth"
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db dbtx
 }
 
 func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+const userColumns = `id, email, password_hash, email_verified_at, display_name, preferences, roles, created_at`
+
+// pgUniqueViolation is the SQLSTATE for unique constraint violations.
+const pgUniqueViolation = "23505"
+
 func (r *UserRepository) Save(ctx context.Context, user auth.User) error {
 	query := `INSERT INTO users (id, email, password_hash) VALUES ($1, $2, $3)`
 	_, err := r.db.Exec(ctx, query, user.ID, user.Email, user.PasswordHash)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return auth.ErrEmailTaken
+		}
 		return fmt.Errorf("failed to save user: %w", err)
 	}
 	return nil
 }
 
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (auth.User, error) {
-	query := `SELECT id, email, password_hash FROM users WHERE email = $1`
-	row := r.db.QueryRow(ctx, query, email)
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+	return r.scanUser(r.db.QueryRow(ctx, query, email))
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id string) (auth.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return r.scanUser(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *UserRepository) UpdateProfile(ctx context.Context, user auth.User) error {
+	prefs := user.Preferences
+	if prefs == nil {
+		prefs = map[string]any{}
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+
+	query := `UPDATE users SET display_name = $1, preferences = $2, updated_at = NOW() WHERE id = $3`
+	cmdTag, err := r.db.Exec(ctx, query, user.DisplayName, data, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID, email string) error {
+	query := `UPDATE users SET email = $1, email_verified_at = NULL, updated_at = NOW() WHERE id = $2`
+	cmdTag, err := r.db.Exec(ctx, query, email, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return auth.ErrEmailTaken
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+	return nil
+}
 
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`
+	cmdTag, err := r.db.Exec(ctx, query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID string, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = $1, updated_at = NOW() WHERE id = $2`
+	cmdTag, err := r.db.Exec(ctx, query, at, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	cmdTag, err := r.db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) ConsumeToken(ctx context.Context, token auth.ActionToken) error {
+	query := `
+		INSERT INTO consumed_tokens (id, user_id, purpose, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`
+	cmdTag, err := r.db.Exec(ctx, query, token.ID, token.UserID, string(token.Purpose), token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to consume token: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return auth.ErrTokenUsed
+	}
+	return nil
+}
+
+func (r *UserRepository) scanUser(row pgx.Row) (auth.User, error) {
 	var user auth.User
-	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash)
+	var verifiedAt *time.Time
+	var prefs []byte
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &verifiedAt,
+		&user.DisplayName, &prefs, &user.Roles, &user.CreatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.User{}, auth.ErrUserNotFound
+		}
 		return auth.User{}, fmt.Errorf("failed to find user: %w", err)
 	}
+	if verifiedAt != nil {
+		user.EmailVerifiedAt = *verifiedAt
+	}
+	if err := json.Unmarshal(prefs, &user.Preferences); err != nil {
+		return auth.User{}, fmt.Errorf("failed to unmarshal preferences: %w", err)
+	}
 	return user, nil
 }
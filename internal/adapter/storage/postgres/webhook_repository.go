@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+	"go-favorites-app/internal/core/ports"
+)
+
+// WebhookRepository implements ports.WebhookRepository.
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+// Ensure WebhookRepository implements ports.WebhookRepository
+var _ ports.WebhookRepository = (*WebhookRepository)(nil)
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const subscriptionColumns = `id, user_id, url, event_types, secret, created_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	COALESCE(response_status, 0), COALESCE(last_error, ''), created_at, next_attempt_at, delivered_at`
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub webhooks.Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (id, user_id, url, event_types, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Exec(ctx, query, sub.ID, sub.UserID, sub.URL, eventTypeStrings(sub.Events), sub.Secret, sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) FindSubscription(ctx context.Context, userID, id string) (webhooks.Subscription, error) {
+	if uuid.Validate(id) != nil {
+		return webhooks.Subscription{}, webhooks.ErrNotFound
+	}
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`
+	rows, err := r.db.Query(ctx, query, id, userID)
+	if err != nil {
+		return webhooks.Subscription{}, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	sub, err := pgx.CollectExactlyOneRow(rows, scanSubscription)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return webhooks.Subscription{}, webhooks.ErrNotFound
+	}
+	if err != nil {
+		return webhooks.Subscription{}, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, userID string) ([]webhooks.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE user_id = $1 ORDER BY created_at, id`
+	return r.querySubscriptions(ctx, query, userID)
+}
+
+func (r *WebhookRepository) SubscriptionsFor(ctx context.Context, userID string, t favorites.EventType) ([]webhooks.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE user_id = $1 AND $2 = ANY(event_types)`
+	return r.querySubscriptions(ctx, query, userID, string(t))
+}
+
+func (r *WebhookRepository) querySubscriptions(ctx context.Context, query string, args ...any) ([]webhooks.Subscription, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	subs, err := pgx.CollectRows(rows, scanSubscription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, userID, id string) error {
+	if uuid.Validate(id) != nil {
+		return webhooks.ErrNotFound
+	}
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return webhooks.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) AddDeliveries(ctx context.Context, deliveries ...webhooks.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, attempts,
+			response_status, last_error, created_at, next_attempt_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(query, d.ID, d.SubscriptionID, d.EventID, string(d.EventType), string(d.Payload), string(d.Status), d.Attempts,
+			nullInt(d.ResponseStatus), nullString(d.LastError), d.CreatedAt, nullTime(d.NextAttemptAt), nullTime(d.DeliveredAt))
+	}
+	results := r.db.SendBatch(ctx, batch)
+	for range deliveries {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return fmt.Errorf("failed to add webhook delivery: %w", err)
+		}
+	}
+	return results.Close()
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]ports.WebhookJob, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET locked_until = NOW() + make_interval(secs => $2), attempts = d.attempts + 1
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+			COALESCE(d.response_status, 0), COALESCE(d.last_error, ''), d.created_at, d.next_attempt_at, d.delivered_at,
+			s.id, s.user_id, s.url, s.event_types, s.secret, s.created_at
+	`
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ports.WebhookJob, error) {
+		var job ports.WebhookJob
+		var eventTypes []string
+		sub := &job.Subscription
+		d, err := scanDelivery(row, &sub.ID, &sub.UserID, &sub.URL, &eventTypes, &sub.Secret, &sub.CreatedAt)
+		job.Delivery = d
+		sub.Events = toEventTypes(eventTypes)
+		return job, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	// RETURNING does not keep the subquery's order.
+	slices.SortFunc(jobs, func(a, b ports.WebhookJob) int {
+		return cmp.Compare(a.Delivery.NextAttemptAt.UnixNano(), b.Delivery.NextAttemptAt.UnixNano())
+	})
+	return jobs, nil
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d webhooks.Delivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, response_status = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6, locked_until = NULL
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, d.ID, string(d.Status), nullInt(d.ResponseStatus), nullString(d.LastError), nullTime(d.NextAttemptAt), nullTime(d.DeliveredAt))
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhooks.Delivery, error) {
+		return scanDelivery(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func scanSubscription(row pgx.CollectableRow) (webhooks.Subscription, error) {
+	var s webhooks.Subscription
+	var eventTypes []string
+	err := row.Scan(&s.ID, &s.UserID, &s.URL, &eventTypes, &s.Secret, &s.CreatedAt)
+	s.Events = toEventTypes(eventTypes)
+	return s, err
+}
+
+// scanDelivery reads deliveryColumns, followed by the extra columns into extra.
+func scanDelivery(row pgx.Row, extra ...any) (webhooks.Delivery, error) {
+	var d webhooks.Delivery
+	var eventType, payload, status string
+	var nextAttemptAt, deliveredAt *time.Time
+	dest := append([]any{&d.ID, &d.SubscriptionID, &d.EventID, &eventType, &payload, &status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.CreatedAt, &nextAttemptAt, &deliveredAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return d, err
+	}
+	d.EventType = favorites.EventType(eventType)
+	d.Payload = []byte(payload)
+	d.Status = webhooks.DeliveryStatus(status)
+	if nextAttemptAt != nil {
+		d.NextAttemptAt = *nextAttemptAt
+	}
+	if deliveredAt != nil {
+		d.DeliveredAt = *deliveredAt
+	}
+	return d, nil
+}
+
+func eventTypeStrings(types []favorites.EventType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
+
+func toEventTypes(types []string) []favorites.EventType {
+	out := make([]favorites.EventType, len(types))
+	for i, t := range types {
+		out[i] = favorites.EventType(t)
+	}
+	return out
+}
+
+func nullInt(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
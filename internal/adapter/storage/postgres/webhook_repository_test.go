@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+)
+
+func TestWebhookRepository_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewWebhookRepository(dbPool)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	userID := uuid.NewString()
+
+	sub := webhooks.Subscription{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		URL:       "https://example.com/hooks",
+		Events:    []favorites.EventType{favorites.EventCreated, favorites.EventDeleted},
+		Secret:    "0123456789abcdef",
+		CreatedAt: now,
+	}
+	if err := repo.CreateSubscription(ctx, sub); err != nil {
+		t.Fatalf("CreateSubscription error: %v", err)
+	}
+
+	t.Run("subscriptions are scoped to their user", func(t *testing.T) {
+		got, err := repo.FindSubscription(ctx, userID, sub.ID)
+		if err != nil {
+			t.Fatalf("FindSubscription error: %v", err)
+		}
+		if got.URL != sub.URL || len(got.Events) != 2 || got.Secret != sub.Secret {
+			t.Errorf("unexpected subscription: %+v", got)
+		}
+		if _, err := repo.FindSubscription(ctx, uuid.NewString(), sub.ID); !errors.Is(err, webhooks.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for another user, got %v", err)
+		}
+		if _, err := repo.FindSubscription(ctx, userID, "not-a-uuid"); !errors.Is(err, webhooks.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a malformed ID, got %v", err)
+		}
+
+		wanting, err := repo.SubscriptionsFor(ctx, userID, favorites.EventDeleted)
+		if err != nil || len(wanting) != 1 {
+			t.Errorf("expected the subscription to want deletions, got %v, %v", wanting, err)
+		}
+		wanting, err = repo.SubscriptionsFor(ctx, userID, favorites.EventUpdated)
+		if err != nil || len(wanting) != 0 {
+			t.Errorf("expected no subscription to want updates, got %v, %v", wanting, err)
+		}
+	})
+
+	t.Run("deliveries are claimed, retried and logged", func(t *testing.T) {
+		event := favorites.Event{ID: uuid.NewString(), Type: favorites.EventCreated, AssetID: uuid.NewString(), UserID: userID, OccurredAt: now}
+		d, err := webhooks.NewDelivery(sub, event, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.ID = uuid.NewString()
+		if err := repo.AddDeliveries(ctx, d); err != nil {
+			t.Fatalf("AddDeliveries error: %v", err)
+		}
+		// The relay delivers events at least once; the duplicate is skipped.
+		dup := d
+		dup.ID = uuid.NewString()
+		if err := repo.AddDeliveries(ctx, dup); err != nil {
+			t.Fatalf("AddDeliveries duplicate error: %v", err)
+		}
+
+		jobs, err := repo.ClaimDeliveries(ctx, 10, time.Minute)
+		if err != nil {
+			t.Fatalf("ClaimDeliveries error: %v", err)
+		}
+		if len(jobs) != 1 || jobs[0].Delivery.ID != d.ID || jobs[0].Delivery.Attempts != 1 || jobs[0].Subscription.URL != sub.URL {
+			t.Fatalf("unexpected claim: %+v", jobs)
+		}
+		if string(jobs[0].Delivery.Payload) != string(d.Payload) {
+			t.Errorf("payload changed in storage: %s", jobs[0].Delivery.Payload)
+		}
+		if again, _ := repo.ClaimDeliveries(ctx, 10, time.Minute); len(again) != 0 {
+			t.Errorf("expected the lease to hide the delivery, got %+v", again)
+		}
+
+		retried := jobs[0].Delivery.Failed(503, errors.New("unavailable"), now.Add(-time.Second))
+		if err := repo.UpdateDelivery(ctx, retried); err != nil {
+			t.Fatalf("UpdateDelivery error: %v", err)
+		}
+		jobs, err = repo.ClaimDeliveries(ctx, 10, time.Minute)
+		if err != nil || len(jobs) != 1 || jobs[0].Delivery.Attempts != 2 || jobs[0].Delivery.ResponseStatus != 503 {
+			t.Fatalf("expected the retry to be claimable, got %+v, %v", jobs, err)
+		}
+		if err := repo.UpdateDelivery(ctx, jobs[0].Delivery.Succeeded(200, now)); err != nil {
+			t.Fatalf("UpdateDelivery error: %v", err)
+		}
+
+		log, err := repo.ListDeliveries(ctx, sub.ID, 10)
+		if err != nil {
+			t.Fatalf("ListDeliveries error: %v", err)
+		}
+		if len(log) != 1 || log[0].Status != webhooks.DeliveryDelivered || log[0].Attempts != 2 || log[0].LastError != "" || log[0].DeliveredAt.IsZero() {
+			t.Errorf("unexpected delivery log: %+v", log)
+		}
+	})
+
+	t.Run("deleting a subscription drops its log", func(t *testing.T) {
+		if err := repo.DeleteSubscription(ctx, uuid.NewString(), sub.ID); !errors.Is(err, webhooks.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for another user, got %v", err)
+		}
+		if err := repo.DeleteSubscription(ctx, userID, sub.ID); err != nil {
+			t.Fatalf("DeleteSubscription error: %v", err)
+		}
+		if log, _ := repo.ListDeliveries(ctx, sub.ID, 10); len(log) != 0 {
+			t.Errorf("expected the log to be gone, got %+v", log)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Package webhook implements ports.WebhookSender over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go-favorites-app/internal/core/domain/webhooks"
+	"go-favorites-app/internal/core/ports"
+)
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to an address
+// of the private network, which users must not be able to reach through us.
+var ErrForbiddenAddress = errors.New("webhook address not allowed")
+
+// maxResponseBytes caps how much of a receiver's response is read.
+const maxResponseBytes = 64 << 10
+
+// Config configures the sender.
+type Config struct {
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses. Only meant for development and tests.
+	AllowPrivateNetworks bool
+	// UserAgent defaults to "favorites-webhooks/1".
+	UserAgent string
+}
+
+// Sender posts signed deliveries.
+type Sender struct {
+	cfg    Config
+	client *http.Client
+}
+
+// Ensure Sender implements ports.WebhookSender
+var _ ports.WebhookSender = (*Sender)(nil)
+
+func NewSender(cfg Config) *Sender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "favorites-webhooks/1"
+	}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		// Checked on the resolved address, so DNS cannot point us elsewhere.
+		dialer.Control = denyPrivate
+	}
+	return &Sender{
+		cfg: cfg,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: cfg.Timeout,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// A redirect would be followed without the address check of its
+			// own URL; receivers must answer themselves.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, url, secret string, d webhooks.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.cfg.UserAgent)
+	req.Header.Set(webhooks.HeaderID, d.EventID)
+	req.Header.Set(webhooks.HeaderEvent, string(d.EventType))
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// specialPurpose lists the ranges of the IANA special-purpose address
+// registries that are not globally reachable, or that translate to other
+// addresses, beyond those the netip predicates cover.
+var specialPurpose = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // shared address space (carrier-grade NAT)
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64 to any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo, which tunnels to IPv4
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds an IPv4 address
+}
+
+// denyPrivate refuses connections to addresses of the private network and
+// to any other address that is not globally reachable.
+func denyPrivate(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	for _, prefix := range specialPurpose {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+		}
+	}
+	return nil
+}
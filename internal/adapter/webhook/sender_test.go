@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+)
+
+const secret = "0123456789abcdef"
+
+func TestSender_Send(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	var status atomic.Int32
+	status.Store(http.StatusNoContent)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(Config{Timeout: time.Second, AllowPrivateNetworks: true})
+	d := webhooks.Delivery{EventID: "e1", EventType: favorites.EventCreated, Payload: []byte(`{"id":"e1"}`)}
+
+	t.Run("signs the payload", func(t *testing.T) {
+		code, err := sender.Send(context.Background(), receiver.URL, secret, d)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+
+		r := <-got
+		assert.Equal(t, d.Payload, r.body)
+		assert.Equal(t, "e1", r.header.Get(webhooks.HeaderID))
+		assert.Equal(t, "favorite.created", r.header.Get(webhooks.HeaderEvent))
+		assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+		assert.NoError(t, webhooks.Verify(secret, r.header.Get(webhooks.HeaderTimestamp), r.header.Get(webhooks.HeaderSignature), r.body, time.Now(), time.Minute))
+	})
+
+	t.Run("non-2xx is an error", func(t *testing.T) {
+		status.Store(http.StatusInternalServerError)
+		code, err := sender.Send(context.Background(), receiver.URL, secret, d)
+		<-got
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		status.Store(http.StatusFound)
+		code, err := sender.Send(context.Background(), receiver.URL, secret, d)
+		<-got
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusFound, code)
+	})
+}
+
+func TestSender_DeniesPrivateNetworks(t *testing.T) {
+	var called atomic.Bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(Config{Timeout: time.Second})
+	code, err := sender.Send(context.Background(), receiver.URL, secret, webhooks.Delivery{Payload: []byte(`{}`)})
+
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+	assert.Zero(t, code)
+	assert.False(t, called.Load())
+}
+
+func TestDenyPrivate(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:80", "10.1.2.3:443", "192.168.0.1:80", "169.254.169.254:80", "[::1]:80", "[fd00::1]:443", "0.0.0.0:80", "[::ffff:127.0.0.1]:80"} {
+		assert.ErrorIs(t, denyPrivate("tcp", addr, nil), ErrForbiddenAddress, addr)
+	}
+	// Special-purpose ranges that the netip predicates do not cover.
+	for _, addr := range []string{
+		"0.1.2.3:80", "100.64.0.1:80", "100.127.255.254:80", "192.0.0.8:80", "198.18.0.1:80", "198.19.255.255:80",
+		"192.0.2.1:80", "255.255.255.255:80", "240.0.0.1:80",
+		"[64:ff9b::a9fe:a9fe]:80", "[64:ff9b::7f00:1]:80", "[2002:7f00:1::1]:80", "[2001:db8::1]:80",
+	} {
+		assert.ErrorIs(t, denyPrivate("tcp", addr, nil), ErrForbiddenAddress, addr)
+	}
+	for _, addr := range []string{"93.184.216.34:443", "[2606:2800:220:1::1]:443", "100.128.0.1:443", "198.20.0.1:443"} {
+		assert.NoError(t, denyPrivate("tcp", addr, nil), addr)
+	}
+}
@@ -2,16 +2,185 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net/netip"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DatabaseURL          string
-	RedisAddr            string
+	DatabaseURL string
+	// RedisAddrs is the server in standalone mode, the Sentinel addresses
+	// in sentinel mode and the seed nodes in cluster mode.
+	RedisAddrs           []string
 	Port                 string
 	AppEnv               string
 	JWTSecret            string
 	OtelExporterEndpoint string
+
+	// RedisMode is "standalone", "sentinel" (following the primary named
+	// RedisMasterName) or "cluster".
+	RedisMode             string
+	RedisMasterName       string
+	RedisSentinelUsername string
+	RedisSentinelPassword string
+
+	// Redis connection. Zero pool and timeout values keep the client defaults.
+	RedisUsername      string
+	RedisPassword      string
+	RedisDB            int
+	RedisTLS           bool
+	RedisTLSCAFile     string
+	RedisTLSServerName string
+	RedisPoolSize      int
+	RedisMinIdleConns  int
+	RedisDialTimeout   time.Duration
+	RedisReadTimeout   time.Duration
+	RedisWriteTimeout  time.Duration
+	RedisPoolTimeout   time.Duration
+	// RedisKeyPrefix namespaces all keys, so environments can share an instance.
+	RedisKeyPrefix string
+
+	// CacheAssetTTL is how long asset data stays in Redis. CacheSetTTL, if
+	// positive, expires the list of cached IDs after that long without
+	// writes. Both get up to CacheTTLJitter added.
+	CacheAssetTTL  time.Duration
+	CacheSetTTL    time.Duration
+	CacheTTLJitter time.Duration
+
+	// BaseURL is the externally reachable URL of the API, used in email links.
+	BaseURL string
+
+	// Mailer selects the mail adapter: "memory" (keeps mail in the process,
+	// the default when AppEnv is "local") or "smtp".
+	Mailer       string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// RequireVerifiedEmail prevents unverified users from creating favorites.
+	RequireVerifiedEmail bool
+
+	// PasswordMinLength is the minimum number of characters for new passwords.
+	PasswordMinLength int
+	// PasswordBreachedListFile optionally points to a newline-separated list of
+	// compromised passwords that are rejected on signup and reset.
+	PasswordBreachedListFile string
+
+	// EnrichmentStages are the enabled enrichment stages, in the order they run
+	// for their asset type. A stage is enabled when its URL is set and it is
+	// not listed in ENRICHMENT_DISABLED_STAGES.
+	EnrichmentStages []EnrichmentStage
+	// EnricherTimeout bounds each call to the metadata service.
+	EnricherTimeout    time.Duration
+	EnricherMaxRetries int
+	// EnricherBreakerThreshold consecutive failures stop calls to a metadata
+	// endpoint for EnricherBreakerCooldown.
+	EnricherBreakerThreshold int
+	EnricherBreakerCooldown  time.Duration
+
+	// EnrichmentWorkers is the number of background enrichment jobs run concurrently.
+	EnrichmentWorkers int
+	// EnrichmentJobTimeout bounds one enrichment attempt, including retries inside the enricher.
+	EnrichmentJobTimeout time.Duration
+	// EnrichmentMaxAttempts failed attempts move a job to the dead-letter queue.
+	EnrichmentMaxAttempts int
+	// EnrichmentMaxAge is how old enrichment may get before the scheduler
+	// refreshes it. It is checked every EnrichmentRefreshInterval, queueing at
+	// most EnrichmentRefreshBatchSize assets at a time.
+	EnrichmentMaxAge           time.Duration
+	EnrichmentRefreshInterval  time.Duration
+	EnrichmentRefreshBatchSize int
+
+	// CacheFillLock coalesces cache fills for the same asset across replicas
+	// with a Redis lock held for at most CacheFillLockTTL. Fills are always
+	// coalesced within a replica.
+	CacheFillLock    bool
+	CacheFillLockTTL time.Duration
+
+	// CacheL1Size is the number of assets each replica keeps in memory in
+	// front of Redis; 0 disables the in-process tier. Entries live for
+	// CacheL1TTL plus up to CacheL1Jitter.
+	CacheL1Size   int
+	CacheL1TTL    time.Duration
+	CacheL1Jitter time.Duration
+
+	// CacheTimeout bounds each Redis call. CacheBreakerThreshold consecutive
+	// failures bypass Redis for CacheBreakerCooldown; writes missed meanwhile
+	// are replayed on recovery, up to CacheMaxPendingInvalidations of them.
+	CacheTimeout                 time.Duration
+	CacheBreakerThreshold        int
+	CacheBreakerCooldown         time.Duration
+	CacheMaxPendingInvalidations int
+
+	// CacheReconcileInterval is how often the leader checks the cache against
+	// the database, CacheReconcileBatchSize IDs at a time. With
+	// CacheReconcileDryRun the drift is only reported.
+	CacheReconcileInterval  time.Duration
+	CacheReconcileBatchSize int
+	CacheReconcileDryRun    bool
+
+	// SessionCleanupInterval is how often expired session rows are deleted.
+	SessionCleanupInterval time.Duration
+
+	// OutboxPollInterval is how often the relay looks for unpublished events
+	// when idle, claiming up to OutboxBatchSize at once. An event is given up
+	// after OutboxMaxAttempts failed deliveries.
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxAttempts  int
+
+	// WebhookWorkers deliveries are sent concurrently, each attempt bounded
+	// by WebhookTimeout; a delivery is given up after WebhookMaxAttempts.
+	// WebhookAllowPrivateNetworks lets webhook URLs point at loopback and
+	// private addresses, for development only.
+	WebhookWorkers              int
+	WebhookTimeout              time.Duration
+	WebhookMaxAttempts          int
+	WebhookAllowPrivateNetworks bool
+
+	// FeedHistorySize events per user are kept, for FeedHistoryTTL after the
+	// last one, so that event stream clients can resume. Idle streams get a
+	// heartbeat every FeedHeartbeatInterval.
+	FeedHistorySize       int
+	FeedHistoryTTL        time.Duration
+	FeedHeartbeatInterval time.Duration
+
+	// MaxRequestBodyBytes caps the size of request bodies.
+	MaxRequestBodyBytes int64
+
+	// QuotaMaxFavorites and QuotaMaxBytes cap what each user stores; 0 is
+	// unlimited. QuotaMaxFieldLengths caps string fields in characters, by
+	// asset type ("*" for every type) and field.
+	QuotaMaxFavorites    int
+	QuotaMaxBytes        int64
+	QuotaMaxFieldLengths map[string]map[string]int
+
+	// RateLimitAuth requests per client IP to the auth routes, and
+	// RateLimitRead and RateLimitWrite requests per user to the other routes,
+	// are allowed every RateLimitPeriod; 0 lifts a limit.
+	RateLimitPeriod time.Duration
+	RateLimitAuth   int
+	RateLimitRead   int
+	RateLimitWrite  int
+
+	// TrustedProxies are the networks of the load balancers and proxies in
+	// front of the service. Only requests from them have X-Forwarded-For
+	// read for the client address.
+	TrustedProxies []netip.Prefix
+
+	// BatchMaxItems caps the items of one POST /favorites:batch,
+	// POST /favorites:batchDelete or POST /favorites/import request.
+	BatchMaxItems int
+
+	// IdempotencyTTL is how long the response to a request sent with an
+	// Idempotency-Key is replayed to retries.
+	IdempotencyTTL time.Duration
 }
 
 // Load reads configuration from environment variables.
@@ -22,6 +191,25 @@ func Load() (Config, error) {
 		AppEnv:               os.Getenv("APP_ENV"),
 		JWTSecret:            os.Getenv("JWT_SECRET"),
 		OtelExporterEndpoint: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		BaseURL:              os.Getenv("APP_BASE_URL"),
+		Mailer:               os.Getenv("MAILER"),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort:             os.Getenv("SMTP_PORT"),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:             os.Getenv("SMTP_FROM"),
+
+		RedisMode:             os.Getenv("REDIS_MODE"),
+		RedisMasterName:       os.Getenv("REDIS_MASTER_NAME"),
+		RedisSentinelUsername: os.Getenv("REDIS_SENTINEL_USERNAME"),
+		RedisSentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		RedisUsername:         os.Getenv("REDIS_USERNAME"),
+		RedisPassword:         os.Getenv("REDIS_PASSWORD"),
+		RedisTLSCAFile:        os.Getenv("REDIS_TLS_CA_FILE"),
+		RedisTLSServerName:    os.Getenv("REDIS_TLS_SERVER_NAME"),
+		RedisKeyPrefix:        os.Getenv("REDIS_KEY_PREFIX"),
+
+		PasswordBreachedListFile: os.Getenv("PASSWORD_BREACHED_LIST_FILE"),
 	}
 
 	if cfg.Port == "" {
@@ -44,10 +232,507 @@ func Load() (Config, error) {
 		return Config{}, errors.New("DATABASE_URL is required")
 	}
 
-	cfg.RedisAddr = os.Getenv("REDIS_ADDR")
-	if cfg.RedisAddr == "" {
+	cfg.RedisAddrs = getList("REDIS_ADDR")
+	if len(cfg.RedisAddrs) == 0 {
 		return Config{}, errors.New("REDIS_ADDR is required")
 	}
 
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:" + cfg.Port
+	}
+
+	switch cfg.Mailer {
+	case "":
+		// Outside development, mail that silently goes nowhere locks users
+		// out of verification and password resets.
+		if cfg.AppEnv != "local" {
+			return Config{}, errors.New("MAILER is required")
+		}
+		cfg.Mailer = "memory"
+	case "memory":
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+			return Config{}, errors.New("SMTP_HOST and SMTP_FROM are required when MAILER=smtp")
+		}
+		if cfg.SMTPPort == "" {
+			cfg.SMTPPort = "587"
+		}
+	default:
+		return Config{}, fmt.Errorf("unsupported MAILER %q", cfg.Mailer)
+	}
+
+	var err error
+	if cfg.RequireVerifiedEmail, err = getBool("REQUIRE_VERIFIED_EMAIL", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.PasswordMinLength, err = getInt("PASSWORD_MIN_LENGTH", 8); err != nil {
+		return Config{}, err
+	}
+	if cfg.PasswordMinLength < 1 {
+		return Config{}, errors.New("PASSWORD_MIN_LENGTH must be positive")
+	}
+
+	if cfg.EnricherTimeout, err = getDuration("ENRICHER_TIMEOUT", 2*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnricherMaxRetries, err = getInt("ENRICHER_MAX_RETRIES", 2); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnricherBreakerThreshold, err = getInt("ENRICHER_BREAKER_THRESHOLD", 5); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnricherBreakerCooldown, err = getDuration("ENRICHER_BREAKER_COOLDOWN", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentWorkers, err = getInt("ENRICHMENT_WORKERS", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentJobTimeout, err = getDuration("ENRICHMENT_JOB_TIMEOUT", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentMaxAttempts, err = getInt("ENRICHMENT_MAX_ATTEMPTS", 5); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentMaxAge, err = getDuration("ENRICHMENT_MAX_AGE", 12*time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentRefreshInterval, err = getDuration("ENRICHMENT_REFRESH_INTERVAL", time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentRefreshBatchSize, err = getInt("ENRICHMENT_REFRESH_BATCH_SIZE", 100); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnrichmentMaxAge <= 0 || cfg.EnrichmentRefreshInterval <= 0 || cfg.EnrichmentRefreshBatchSize < 1 {
+		return Config{}, errors.New("ENRICHMENT_MAX_AGE, ENRICHMENT_REFRESH_INTERVAL and ENRICHMENT_REFRESH_BATCH_SIZE must be positive")
+	}
+	if cfg.EnrichmentWorkers < 1 || cfg.EnrichmentJobTimeout <= 0 || cfg.EnrichmentMaxAttempts < 1 {
+		return Config{}, errors.New("ENRICHMENT_WORKERS, ENRICHMENT_JOB_TIMEOUT and ENRICHMENT_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.EnricherTimeout <= 0 || cfg.EnricherMaxRetries < 0 || cfg.EnricherBreakerThreshold < 1 {
+		return Config{}, errors.New("ENRICHER_TIMEOUT and ENRICHER_BREAKER_THRESHOLD must be positive, ENRICHER_MAX_RETRIES non-negative")
+	}
+	if cfg.EnrichmentStages, err = loadEnrichmentStages(); err != nil {
+		return Config{}, err
+	}
+	if err := loadRedis(&cfg); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheFillLock, err = getBool("CACHE_FILL_LOCK", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheFillLockTTL, err = getDuration("CACHE_FILL_LOCK_TTL", 2*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheFillLockTTL <= 0 {
+		return Config{}, errors.New("CACHE_FILL_LOCK_TTL must be positive")
+	}
+	if cfg.CacheL1Size, err = getInt("CACHE_L1_SIZE", 10000); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheL1TTL, err = getDuration("CACHE_L1_TTL", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheL1Jitter, err = getDuration("CACHE_L1_JITTER", 5*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheL1Size < 0 || cfg.CacheL1TTL <= 0 || cfg.CacheL1Jitter < 0 {
+		return Config{}, errors.New("CACHE_L1_TTL must be positive, CACHE_L1_SIZE and CACHE_L1_JITTER non-negative")
+	}
+	if cfg.CacheTimeout, err = getDuration("CACHE_TIMEOUT", 200*time.Millisecond); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheBreakerThreshold, err = getInt("CACHE_BREAKER_THRESHOLD", 5); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheBreakerCooldown, err = getDuration("CACHE_BREAKER_COOLDOWN", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheMaxPendingInvalidations, err = getInt("CACHE_MAX_PENDING_INVALIDATIONS", 10000); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheTimeout <= 0 || cfg.CacheBreakerThreshold < 1 || cfg.CacheBreakerCooldown <= 0 || cfg.CacheMaxPendingInvalidations < 1 {
+		return Config{}, errors.New("CACHE_TIMEOUT, CACHE_BREAKER_THRESHOLD, CACHE_BREAKER_COOLDOWN and CACHE_MAX_PENDING_INVALIDATIONS must be positive")
+	}
+
+	if cfg.CacheReconcileInterval, err = getDuration("CACHE_RECONCILE_INTERVAL", time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheReconcileBatchSize, err = getInt("CACHE_RECONCILE_BATCH_SIZE", 500); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheReconcileDryRun, err = getBool("CACHE_RECONCILE_DRY_RUN", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheReconcileInterval <= 0 || cfg.CacheReconcileBatchSize < 1 {
+		return Config{}, errors.New("CACHE_RECONCILE_INTERVAL and CACHE_RECONCILE_BATCH_SIZE must be positive")
+	}
+
+	if cfg.SessionCleanupInterval, err = getDuration("SESSION_CLEANUP_INTERVAL", time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.SessionCleanupInterval <= 0 {
+		return Config{}, errors.New("SESSION_CLEANUP_INTERVAL must be positive")
+	}
+
+	if cfg.OutboxPollInterval, err = getDuration("OUTBOX_POLL_INTERVAL", 500*time.Millisecond); err != nil {
+		return Config{}, err
+	}
+	if cfg.OutboxBatchSize, err = getInt("OUTBOX_BATCH_SIZE", 100); err != nil {
+		return Config{}, err
+	}
+	if cfg.OutboxMaxAttempts, err = getInt("OUTBOX_MAX_ATTEMPTS", 10); err != nil {
+		return Config{}, err
+	}
+	if cfg.OutboxPollInterval <= 0 || cfg.OutboxBatchSize < 1 || cfg.OutboxMaxAttempts < 1 {
+		return Config{}, errors.New("OUTBOX_POLL_INTERVAL, OUTBOX_BATCH_SIZE and OUTBOX_MAX_ATTEMPTS must be positive")
+	}
+
+	if cfg.WebhookWorkers, err = getInt("WEBHOOK_WORKERS", 4); err != nil {
+		return Config{}, err
+	}
+	if cfg.WebhookTimeout, err = getDuration("WEBHOOK_TIMEOUT", 5*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.WebhookMaxAttempts, err = getInt("WEBHOOK_MAX_ATTEMPTS", 8); err != nil {
+		return Config{}, err
+	}
+	if cfg.WebhookAllowPrivateNetworks, err = getBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.WebhookWorkers < 1 || cfg.WebhookTimeout <= 0 || cfg.WebhookMaxAttempts < 1 {
+		return Config{}, errors.New("WEBHOOK_WORKERS, WEBHOOK_TIMEOUT and WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+
+	if cfg.FeedHistorySize, err = getInt("FEED_HISTORY_SIZE", 1000); err != nil {
+		return Config{}, err
+	}
+	if cfg.FeedHistoryTTL, err = getDuration("FEED_HISTORY_TTL", 24*time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.FeedHeartbeatInterval, err = getDuration("FEED_HEARTBEAT_INTERVAL", 15*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.FeedHistorySize < 1 || cfg.FeedHistoryTTL <= 0 || cfg.FeedHeartbeatInterval <= 0 {
+		return Config{}, errors.New("FEED_HISTORY_SIZE, FEED_HISTORY_TTL and FEED_HEARTBEAT_INTERVAL must be positive")
+	}
+
+	maxBody, err := getInt("MAX_REQUEST_BODY_BYTES", 1<<20)
+	if err != nil {
+		return Config{}, err
+	}
+	if maxBody < 1 {
+		return Config{}, errors.New("MAX_REQUEST_BODY_BYTES must be positive")
+	}
+	cfg.MaxRequestBodyBytes = int64(maxBody)
+	if err := loadQuota(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.RateLimitPeriod, err = getDuration("RATE_LIMIT_PERIOD", time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitAuth, err = getInt("RATE_LIMIT_AUTH", 10); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitRead, err = getInt("RATE_LIMIT_READ", 600); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitWrite, err = getInt("RATE_LIMIT_WRITE", 120); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitPeriod <= 0 {
+		return Config{}, errors.New("RATE_LIMIT_PERIOD must be positive")
+	}
+	if cfg.RateLimitAuth < 0 || cfg.RateLimitRead < 0 || cfg.RateLimitWrite < 0 {
+		return Config{}, errors.New("RATE_LIMIT_AUTH, RATE_LIMIT_READ and RATE_LIMIT_WRITE must not be negative")
+	}
+	for _, item := range getList("TRUSTED_PROXIES") {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			// A single address trusts just that host.
+			addr, aerr := netip.ParseAddr(item)
+			if aerr != nil {
+				return Config{}, fmt.Errorf("TRUSTED_PROXIES: %q is neither a CIDR nor an IP address", item)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix.Masked())
+	}
+
+	if cfg.BatchMaxItems, err = getInt("BATCH_MAX_ITEMS", 1000); err != nil {
+		return Config{}, err
+	}
+	if cfg.BatchMaxItems < 1 {
+		return Config{}, errors.New("BATCH_MAX_ITEMS must be positive")
+	}
+
+	if cfg.IdempotencyTTL, err = getDuration("IDEMPOTENCY_TTL", 24*time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		return Config{}, errors.New("IDEMPOTENCY_TTL must be positive")
+	}
+
 	return cfg, nil
 }
+
+// defaultFieldLengths are the field limits applied unless
+// QUOTA_MAX_FIELD_LENGTHS overrides them.
+var defaultFieldLengths = map[string]map[string]int{
+	"*":        {"name": 200, "description": 2000},
+	"insight":  {"content": 20000},
+	"chart":    {"x_axis": 200, "y_axis": 200},
+	"audience": {"rules.gender": 50, "rules.country": 100},
+}
+
+// quotaFields are the string fields of each asset type that can be limited.
+var quotaFields = map[string][]string{
+	"*":        {"name", "description"},
+	"insight":  {"name", "description", "content"},
+	"chart":    {"name", "description", "x_axis", "y_axis"},
+	"audience": {"name", "description", "rules.gender", "rules.country"},
+}
+
+// loadQuota reads the per-user limits. QUOTA_MAX_FIELD_LENGTHS entries such
+// as "insight.content=5000" or "*.name=100" replace the default for that
+// field; a limit of 0 lifts it.
+func loadQuota(cfg *Config) error {
+	var err error
+	if cfg.QuotaMaxFavorites, err = getInt("QUOTA_MAX_FAVORITES", 0); err != nil {
+		return err
+	}
+	maxBytes, err := getInt("QUOTA_MAX_BYTES", 0)
+	if err != nil {
+		return err
+	}
+	cfg.QuotaMaxBytes = int64(maxBytes)
+	if cfg.QuotaMaxFavorites < 0 || cfg.QuotaMaxBytes < 0 {
+		return errors.New("QUOTA_MAX_FAVORITES and QUOTA_MAX_BYTES must not be negative")
+	}
+
+	cfg.QuotaMaxFieldLengths = make(map[string]map[string]int)
+	set := func(assetType, field string, n int) {
+		if cfg.QuotaMaxFieldLengths[assetType] == nil {
+			cfg.QuotaMaxFieldLengths[assetType] = make(map[string]int)
+		}
+		cfg.QuotaMaxFieldLengths[assetType][field] = n
+	}
+	for assetType, fields := range defaultFieldLengths {
+		for field, n := range fields {
+			set(assetType, field, n)
+		}
+	}
+	for _, entry := range getList("QUOTA_MAX_FIELD_LENGTHS") {
+		key, value, ok := strings.Cut(entry, "=")
+		assetType, field, _ := strings.Cut(key, ".")
+		if !ok || !slices.Contains(quotaFields[assetType], field) {
+			return fmt.Errorf("QUOTA_MAX_FIELD_LENGTHS: expected <type>.<field>=<length> for a known field, got %q", entry)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("QUOTA_MAX_FIELD_LENGTHS: invalid length for %s: %q", key, value)
+		}
+		set(assetType, field, n)
+	}
+	return nil
+}
+
+// loadRedis reads the Redis connection and key settings.
+func loadRedis(cfg *Config) error {
+	switch cfg.RedisMode {
+	case "":
+		cfg.RedisMode = "standalone"
+		fallthrough
+	case "standalone":
+		if len(cfg.RedisAddrs) > 1 {
+			return errors.New("REDIS_ADDR must be a single address when REDIS_MODE=standalone")
+		}
+	case "sentinel":
+		if cfg.RedisMasterName == "" {
+			return errors.New("REDIS_MASTER_NAME is required when REDIS_MODE=sentinel")
+		}
+	case "cluster":
+	default:
+		return fmt.Errorf("unsupported REDIS_MODE %q", cfg.RedisMode)
+	}
+
+	var err error
+	if cfg.RedisDB, err = getInt("REDIS_DB", 0); err != nil {
+		return err
+	}
+	if cfg.RedisTLS, err = getBool("REDIS_TLS", false); err != nil {
+		return err
+	}
+	if cfg.RedisPoolSize, err = getInt("REDIS_POOL_SIZE", 0); err != nil {
+		return err
+	}
+	if cfg.RedisMinIdleConns, err = getInt("REDIS_MIN_IDLE_CONNS", 0); err != nil {
+		return err
+	}
+	if cfg.RedisDialTimeout, err = getDuration("REDIS_DIAL_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.RedisReadTimeout, err = getDuration("REDIS_READ_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.RedisWriteTimeout, err = getDuration("REDIS_WRITE_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.RedisPoolTimeout, err = getDuration("REDIS_POOL_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.CacheAssetTTL, err = getDuration("CACHE_ASSET_TTL", 24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.CacheSetTTL, err = getDuration("CACHE_SET_TTL", 0); err != nil {
+		return err
+	}
+	if cfg.CacheTTLJitter, err = getDuration("CACHE_TTL_JITTER", 0); err != nil {
+		return err
+	}
+
+	if cfg.RedisMode == "cluster" && cfg.RedisDB != 0 {
+		return errors.New("REDIS_DB must be 0 when REDIS_MODE=cluster")
+	}
+	if cfg.RedisDB < 0 || cfg.RedisPoolSize < 0 || cfg.RedisMinIdleConns < 0 {
+		return errors.New("REDIS_DB, REDIS_POOL_SIZE and REDIS_MIN_IDLE_CONNS must not be negative")
+	}
+	if cfg.RedisDialTimeout < 0 || cfg.RedisReadTimeout < 0 || cfg.RedisWriteTimeout < 0 || cfg.RedisPoolTimeout < 0 {
+		return errors.New("REDIS_*_TIMEOUT must not be negative")
+	}
+	if cfg.CacheAssetTTL <= 0 || cfg.CacheSetTTL < 0 || cfg.CacheTTLJitter < 0 {
+		return errors.New("CACHE_ASSET_TTL must be positive, CACHE_SET_TTL and CACHE_TTL_JITTER non-negative")
+	}
+	if !cfg.RedisTLS && (cfg.RedisTLSCAFile != "" || cfg.RedisTLSServerName != "") {
+		return errors.New("REDIS_TLS_CA_FILE and REDIS_TLS_SERVER_NAME require REDIS_TLS=true")
+	}
+	return nil
+}
+
+// EnrichmentStage is one step of an asset type's enrichment pipeline.
+type EnrichmentStage struct {
+	AssetType string
+	Name      string
+	URL       string
+	// Timeout bounds the stage, including retries.
+	Timeout time.Duration
+}
+
+// Key identifies the stage in ENRICHMENT_DISABLED_STAGES and ENRICHMENT_STAGE_TIMEOUTS.
+func (s EnrichmentStage) Key() string {
+	return s.AssetType + "." + s.Name
+}
+
+// enrichmentStages are the supported stages, in pipeline order, with the
+// variable holding each one's URL.
+var enrichmentStages = []struct {
+	stage  EnrichmentStage
+	urlEnv string
+}{
+	{EnrichmentStage{AssetType: "chart", Name: "metadata"}, "ENRICHER_CHART_URL"},
+	{EnrichmentStage{AssetType: "chart", Name: "preview"}, "ENRICHER_CHART_PREVIEW_URL"},
+	{EnrichmentStage{AssetType: "insight", Name: "metadata"}, "ENRICHER_INSIGHT_URL"},
+	{EnrichmentStage{AssetType: "insight", Name: "summary"}, "ENRICHER_INSIGHT_SUMMARY_URL"},
+	{EnrichmentStage{AssetType: "audience", Name: "metadata"}, "ENRICHER_AUDIENCE_URL"},
+	{EnrichmentStage{AssetType: "audience", Name: "size"}, "ENRICHER_AUDIENCE_SIZE_URL"},
+}
+
+func loadEnrichmentStages() ([]EnrichmentStage, error) {
+	known := make(map[string]bool, len(enrichmentStages))
+	for _, def := range enrichmentStages {
+		known[def.stage.Key()] = true
+	}
+
+	disabled := make(map[string]bool)
+	for _, key := range getList("ENRICHMENT_DISABLED_STAGES") {
+		if !known[key] {
+			return nil, fmt.Errorf("ENRICHMENT_DISABLED_STAGES: unknown stage %q", key)
+		}
+		disabled[key] = true
+	}
+
+	timeout, err := getDuration("ENRICHMENT_STAGE_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		return nil, errors.New("ENRICHMENT_STAGE_TIMEOUT must be positive")
+	}
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range getList("ENRICHMENT_STAGE_TIMEOUTS") {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || !known[key] {
+			return nil, fmt.Errorf("ENRICHMENT_STAGE_TIMEOUTS: expected <stage>=<duration> for a known stage, got %q", entry)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ENRICHMENT_STAGE_TIMEOUTS: invalid duration for %s: %q", key, value)
+		}
+		timeouts[key] = d
+	}
+
+	var stages []EnrichmentStage
+	for _, def := range enrichmentStages {
+		stage := def.stage
+		stage.URL = os.Getenv(def.urlEnv)
+		if stage.URL == "" || disabled[stage.Key()] {
+			continue
+		}
+		stage.Timeout = timeout
+		if d, ok := timeouts[stage.Key()]; ok {
+			stage.Timeout = d
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// getBool parses a boolean environment variable, returning def when it is unset.
+func getBool(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean: %w", key, err)
+	}
+	return b, nil
+}
+
+// getInt parses an integer environment variable, returning def when it is unset.
+func getInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	return n, nil
+}
+
+// getList splits a comma-separated environment variable, dropping empty items.
+func getList(key string) []string {
+	var items []string
+	for item := range strings.SplitSeq(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getDuration parses a duration environment variable such as "500ms", returning def when it is unset.
+func getDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration: %w", key, err)
+	}
+	return d, nil
+}
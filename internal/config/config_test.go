@@ -1,8 +1,10 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,6 +23,7 @@ func TestLoad(t *testing.T) {
 		os.Setenv("APP_ENV", originalAppEnv)
 		os.Setenv("JWT_SECRET", originalJWTSecret)
 	}()
+	t.Setenv("MAILER", "memory")
 
 	t.Run("success with all values set", func(t *testing.T) {
 		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
@@ -32,7 +35,7 @@ func TestLoad(t *testing.T) {
 		cfg, err := Load()
 		assert.NoError(t, err)
 		assert.Equal(t, "postgres://localhost:5432/test", cfg.DatabaseURL)
-		assert.Equal(t, "localhost:6379", cfg.RedisAddr)
+		assert.Equal(t, []string{"localhost:6379"}, cfg.RedisAddrs)
 		assert.Equal(t, "9000", cfg.Port)
 		assert.Equal(t, "test", cfg.AppEnv)
 		assert.Equal(t, "super-secret", cfg.JWTSecret)
@@ -80,4 +83,504 @@ func TestLoad(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "JWT_SECRET is required")
 	})
+
+	t.Run("mailer defaults to memory in local", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		os.Setenv("PORT", "9000")
+		t.Setenv("APP_ENV", "local")
+		t.Setenv("MAILER", "")
+		t.Setenv("APP_BASE_URL", "")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "memory", cfg.Mailer)
+		assert.Equal(t, "http://localhost:9000", cfg.BaseURL)
+		assert.False(t, cfg.RequireVerifiedEmail)
+	})
+
+	t.Run("mailer required outside local", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("MAILER", "")
+
+		_, err := Load()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "MAILER is required")
+	})
+
+	t.Run("smtp mailer requires host", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("MAILER", "smtp")
+		t.Setenv("SMTP_HOST", "")
+
+		_, err := Load()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "SMTP_HOST")
+	})
+
+	t.Run("invalid REQUIRE_VERIFIED_EMAIL", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("REQUIRE_VERIFIED_EMAIL", "sometimes")
+
+		_, err := Load()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "REQUIRE_VERIFIED_EMAIL")
+	})
+
+	t.Run("password policy", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("PASSWORD_MIN_LENGTH", "12")
+		t.Setenv("PASSWORD_BREACHED_LIST_FILE", "/etc/breached.txt")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 12, cfg.PasswordMinLength)
+		assert.Equal(t, "/etc/breached.txt", cfg.PasswordBreachedListFile)
+
+		t.Setenv("PASSWORD_MIN_LENGTH", "zero")
+		_, err = Load()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "PASSWORD_MIN_LENGTH")
+	})
+
+	t.Run("enricher settings", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("ENRICHER_CHART_URL", "http://metadata:9000/charts")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, []EnrichmentStage{
+			{AssetType: "chart", Name: "metadata", URL: "http://metadata:9000/charts", Timeout: 5 * time.Second},
+		}, cfg.EnrichmentStages)
+		assert.Equal(t, 2*time.Second, cfg.EnricherTimeout)
+		assert.Equal(t, 2, cfg.EnricherMaxRetries)
+		assert.Equal(t, 30*time.Second, cfg.EnricherBreakerCooldown)
+
+		t.Setenv("ENRICHER_TIMEOUT", "250ms")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 250*time.Millisecond, cfg.EnricherTimeout)
+
+		t.Setenv("ENRICHER_TIMEOUT", "soon")
+		_, err = Load()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ENRICHER_TIMEOUT")
+	})
+
+	t.Run("enrichment stages", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+		t.Setenv("ENRICHER_CHART_URL", "http://metadata:9000/charts")
+		t.Setenv("ENRICHER_CHART_PREVIEW_URL", "http://previews:9000/render")
+		t.Setenv("ENRICHER_AUDIENCE_SIZE_URL", "http://audiences:9000/size")
+		t.Setenv("ENRICHMENT_STAGE_TIMEOUTS", "chart.preview=8s")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, []EnrichmentStage{
+			{AssetType: "chart", Name: "metadata", URL: "http://metadata:9000/charts", Timeout: 5 * time.Second},
+			{AssetType: "chart", Name: "preview", URL: "http://previews:9000/render", Timeout: 8 * time.Second},
+			{AssetType: "audience", Name: "size", URL: "http://audiences:9000/size", Timeout: 5 * time.Second},
+		}, cfg.EnrichmentStages)
+
+		t.Setenv("ENRICHMENT_DISABLED_STAGES", "chart.preview, audience.size")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Len(t, cfg.EnrichmentStages, 1)
+		assert.Equal(t, "chart.metadata", cfg.EnrichmentStages[0].Key())
+
+		t.Setenv("ENRICHMENT_DISABLED_STAGES", "chart.thumbnail")
+		_, err = Load()
+		assert.ErrorContains(t, err, "ENRICHMENT_DISABLED_STAGES")
+
+		t.Setenv("ENRICHMENT_DISABLED_STAGES", "")
+		t.Setenv("ENRICHMENT_STAGE_TIMEOUTS", "chart.preview=fast")
+		_, err = Load()
+		assert.ErrorContains(t, err, "ENRICHMENT_STAGE_TIMEOUTS")
+	})
+
+	t.Run("enrichment worker settings", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, cfg.EnrichmentWorkers)
+		assert.Equal(t, 10*time.Second, cfg.EnrichmentJobTimeout)
+		assert.Equal(t, 5, cfg.EnrichmentMaxAttempts)
+		assert.Equal(t, 12*time.Hour, cfg.EnrichmentMaxAge)
+		assert.Equal(t, time.Minute, cfg.EnrichmentRefreshInterval)
+		assert.Equal(t, 100, cfg.EnrichmentRefreshBatchSize)
+
+		t.Setenv("ENRICHMENT_WORKERS", "0")
+		_, err = Load()
+		assert.Error(t, err)
+
+		t.Setenv("ENRICHMENT_WORKERS", "")
+		t.Setenv("ENRICHMENT_MAX_AGE", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "ENRICHMENT_MAX_AGE")
+	})
+
+	t.Run("cache fill lock", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.False(t, cfg.CacheFillLock)
+		assert.Equal(t, 2*time.Second, cfg.CacheFillLockTTL)
+
+		t.Setenv("CACHE_FILL_LOCK", "true")
+		t.Setenv("CACHE_FILL_LOCK_TTL", "500ms")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.True(t, cfg.CacheFillLock)
+		assert.Equal(t, 500*time.Millisecond, cfg.CacheFillLockTTL)
+
+		t.Setenv("CACHE_FILL_LOCK_TTL", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "CACHE_FILL_LOCK_TTL")
+	})
+
+	t.Run("in-process cache", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 10000, cfg.CacheL1Size)
+		assert.Equal(t, 30*time.Second, cfg.CacheL1TTL)
+		assert.Equal(t, 5*time.Second, cfg.CacheL1Jitter)
+
+		t.Setenv("CACHE_L1_SIZE", "0")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Zero(t, cfg.CacheL1Size)
+
+		t.Setenv("CACHE_L1_TTL", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "CACHE_L1_TTL")
+	})
+
+	t.Run("redis settings", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Zero(t, cfg.RedisDB)
+		assert.False(t, cfg.RedisTLS)
+		assert.Empty(t, cfg.RedisKeyPrefix)
+		assert.Equal(t, 24*time.Hour, cfg.CacheAssetTTL)
+		assert.Zero(t, cfg.CacheSetTTL)
+
+		t.Setenv("REDIS_USERNAME", "favorites")
+		t.Setenv("REDIS_PASSWORD", "secret")
+		t.Setenv("REDIS_DB", "2")
+		t.Setenv("REDIS_TLS", "true")
+		t.Setenv("REDIS_TLS_CA_FILE", "/etc/redis/ca.pem")
+		t.Setenv("REDIS_TLS_SERVER_NAME", "redis.staging")
+		t.Setenv("REDIS_POOL_SIZE", "50")
+		t.Setenv("REDIS_READ_TIMEOUT", "500ms")
+		t.Setenv("REDIS_KEY_PREFIX", "staging:")
+		t.Setenv("CACHE_ASSET_TTL", "6h")
+		t.Setenv("CACHE_TTL_JITTER", "10m")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "favorites", cfg.RedisUsername)
+		assert.Equal(t, "secret", cfg.RedisPassword)
+		assert.Equal(t, 2, cfg.RedisDB)
+		assert.True(t, cfg.RedisTLS)
+		assert.Equal(t, "/etc/redis/ca.pem", cfg.RedisTLSCAFile)
+		assert.Equal(t, "redis.staging", cfg.RedisTLSServerName)
+		assert.Equal(t, 50, cfg.RedisPoolSize)
+		assert.Equal(t, 500*time.Millisecond, cfg.RedisReadTimeout)
+		assert.Equal(t, "staging:", cfg.RedisKeyPrefix)
+		assert.Equal(t, 6*time.Hour, cfg.CacheAssetTTL)
+		assert.Equal(t, 10*time.Minute, cfg.CacheTTLJitter)
+
+		t.Setenv("REDIS_TLS", "false")
+		_, err = Load()
+		assert.ErrorContains(t, err, "REDIS_TLS")
+
+		t.Setenv("REDIS_TLS", "true")
+		t.Setenv("CACHE_ASSET_TTL", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "CACHE_ASSET_TTL")
+	})
+
+	t.Run("redis modes", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("JWT_SECRET", "super-secret")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "standalone", cfg.RedisMode)
+
+		os.Setenv("REDIS_ADDR", "sentinel-1:26379, sentinel-2:26379")
+		_, err = Load()
+		assert.ErrorContains(t, err, "single address")
+
+		t.Setenv("REDIS_MODE", "sentinel")
+		_, err = Load()
+		assert.ErrorContains(t, err, "REDIS_MASTER_NAME")
+
+		t.Setenv("REDIS_MASTER_NAME", "favorites")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, cfg.RedisAddrs)
+		assert.Equal(t, "favorites", cfg.RedisMasterName)
+
+		t.Setenv("REDIS_MODE", "cluster")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "cluster", cfg.RedisMode)
+
+		t.Setenv("REDIS_MODE", "replicated")
+		_, err = Load()
+		assert.ErrorContains(t, err, "REDIS_MODE")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+	})
+
+	t.Run("cache degradation", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 200*time.Millisecond, cfg.CacheTimeout)
+		assert.Equal(t, 5, cfg.CacheBreakerThreshold)
+		assert.Equal(t, 10*time.Second, cfg.CacheBreakerCooldown)
+		assert.Equal(t, 10000, cfg.CacheMaxPendingInvalidations)
+
+		t.Setenv("CACHE_BREAKER_THRESHOLD", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "CACHE_BREAKER_THRESHOLD")
+	})
+
+	t.Run("cache reconciler", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, cfg.CacheReconcileInterval)
+		assert.Equal(t, 500, cfg.CacheReconcileBatchSize)
+		assert.False(t, cfg.CacheReconcileDryRun)
+
+		t.Setenv("CACHE_RECONCILE_DRY_RUN", "true")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.True(t, cfg.CacheReconcileDryRun)
+
+		t.Setenv("CACHE_RECONCILE_BATCH_SIZE", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "CACHE_RECONCILE_BATCH_SIZE")
+	})
+
+	t.Run("session cleanup", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Hour, cfg.SessionCleanupInterval)
+
+		t.Setenv("SESSION_CLEANUP_INTERVAL", "15m")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, cfg.SessionCleanupInterval)
+
+		t.Setenv("SESSION_CLEANUP_INTERVAL", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "SESSION_CLEANUP_INTERVAL")
+	})
+
+	t.Run("outbox relay", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, cfg.OutboxPollInterval)
+		assert.Equal(t, 100, cfg.OutboxBatchSize)
+		assert.Equal(t, 10, cfg.OutboxMaxAttempts)
+
+		t.Setenv("OUTBOX_MAX_ATTEMPTS", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "OUTBOX_MAX_ATTEMPTS")
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 4, cfg.WebhookWorkers)
+		assert.Equal(t, 5*time.Second, cfg.WebhookTimeout)
+		assert.Equal(t, 8, cfg.WebhookMaxAttempts)
+		assert.False(t, cfg.WebhookAllowPrivateNetworks)
+
+		t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.True(t, cfg.WebhookAllowPrivateNetworks)
+
+		t.Setenv("WEBHOOK_TIMEOUT", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "WEBHOOK_TIMEOUT")
+	})
+
+	t.Run("event feed", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, cfg.FeedHistorySize)
+		assert.Equal(t, 24*time.Hour, cfg.FeedHistoryTTL)
+		assert.Equal(t, 15*time.Second, cfg.FeedHeartbeatInterval)
+
+		t.Setenv("FEED_HEARTBEAT_INTERVAL", "30s")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, cfg.FeedHeartbeatInterval)
+
+		t.Setenv("FEED_HISTORY_SIZE", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "FEED_HISTORY_SIZE")
+	})
+
+	t.Run("quotas", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1<<20), cfg.MaxRequestBodyBytes)
+		assert.Zero(t, cfg.QuotaMaxFavorites, "quotas are opt-in")
+		assert.Zero(t, cfg.QuotaMaxBytes, "quotas are opt-in")
+		assert.Equal(t, 200, cfg.QuotaMaxFieldLengths["*"]["name"])
+		assert.Equal(t, 20000, cfg.QuotaMaxFieldLengths["insight"]["content"])
+
+		t.Setenv("QUOTA_MAX_FAVORITES", "1000")
+		t.Setenv("QUOTA_MAX_BYTES", "10485760")
+		t.Setenv("QUOTA_MAX_FIELD_LENGTHS", "insight.content=5000, audience.name=80")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, cfg.QuotaMaxFavorites)
+		assert.Equal(t, int64(10<<20), cfg.QuotaMaxBytes)
+		assert.Equal(t, 5000, cfg.QuotaMaxFieldLengths["insight"]["content"])
+		assert.Equal(t, 80, cfg.QuotaMaxFieldLengths["audience"]["name"])
+		assert.Equal(t, 2000, cfg.QuotaMaxFieldLengths["*"]["description"])
+
+		t.Setenv("QUOTA_MAX_FIELD_LENGTHS", "chart.content=10")
+		_, err = Load()
+		assert.ErrorContains(t, err, "QUOTA_MAX_FIELD_LENGTHS")
+
+		t.Setenv("QUOTA_MAX_FIELD_LENGTHS", "")
+		t.Setenv("MAX_REQUEST_BODY_BYTES", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "MAX_REQUEST_BODY_BYTES")
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, cfg.RateLimitPeriod)
+		assert.Equal(t, 10, cfg.RateLimitAuth)
+		assert.Equal(t, 600, cfg.RateLimitRead)
+		assert.Equal(t, 120, cfg.RateLimitWrite)
+
+		t.Setenv("RATE_LIMIT_WRITE", "0")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, cfg.RateLimitWrite)
+
+		t.Setenv("RATE_LIMIT_AUTH", "-1")
+		_, err = Load()
+		assert.ErrorContains(t, err, "RATE_LIMIT_AUTH")
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, cfg.BatchMaxItems)
+
+		t.Setenv("BATCH_MAX_ITEMS", "0")
+		_, err = Load()
+		assert.ErrorContains(t, err, "BATCH_MAX_ITEMS")
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Empty(t, cfg.TrustedProxies)
+
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.7,fd00::1/64")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.Equal(t, []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.0.2.7/32"),
+			netip.MustParsePrefix("fd00::/64"),
+		}, cfg.TrustedProxies)
+
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/33")
+		_, err = Load()
+		assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+	})
+
+	t.Run("idempotency ttl", func(t *testing.T) {
+		os.Setenv("DATABASE_URL", "postgres://localhost:5432/test")
+		os.Setenv("REDIS_ADDR", "localhost:6379")
+		os.Setenv("JWT_SECRET", "super-secret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, cfg.IdempotencyTTL)
+
+		t.Setenv("IDEMPOTENCY_TTL", "0s")
+		_, err = Load()
+		assert.ErrorContains(t, err, "IDEMPOTENCY_TTL")
+	})
 }
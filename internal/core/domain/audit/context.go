@@ -0,0 +1,24 @@
+package audit
+
+import "context"
+
+// Metadata describes the request an action was taken in.
+type Metadata struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type metadataKey struct{}
+
+// WithMetadata attaches the request's metadata to ctx, for the entries
+// recorded while serving it.
+func WithMetadata(ctx context.Context, m Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, m)
+}
+
+// MetadataFrom returns the metadata attached to ctx, if any.
+func MetadataFrom(ctx context.Context) Metadata {
+	m, _ := ctx.Value(metadataKey{}).(Metadata)
+	return m
+}
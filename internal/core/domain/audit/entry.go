@@ -0,0 +1,158 @@
+// Package audit describes the append-only record of security-relevant and
+// data-changing actions.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrInvalidFilter is returned for filters that cannot be applied.
+	ErrInvalidFilter = errors.New("invalid audit filter")
+	// ErrForbidden is returned when a non-admin asks for other users' entries.
+	ErrForbidden = errors.New("admin role required")
+)
+
+// Action names what was done.
+type Action string
+
+const (
+	ActionSignup          Action = "user.signup"
+	ActionLoginSucceeded  Action = "user.login_succeeded"
+	ActionLoginFailed     Action = "user.login_failed"
+	ActionFavoriteCreated Action = "favorite.created"
+	ActionFavoriteUpdated Action = "favorite.updated"
+	ActionFavoriteDeleted Action = "favorite.deleted"
+)
+
+// Actions lists every action that is recorded.
+var Actions = []Action{
+	ActionSignup, ActionLoginSucceeded, ActionLoginFailed,
+	ActionFavoriteCreated, ActionFavoriteUpdated, ActionFavoriteDeleted,
+}
+
+// Change is a field's value before and after an action, as JSON. Before is
+// empty for fields the action added, After for fields it removed.
+type Change struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Entry records one action.
+type Entry struct {
+	// ID is assigned when the entry is stored and grows with every entry.
+	ID int64
+	At time.Time
+	// ActorID is the user who acted. It is empty for a failed login with
+	// an unknown email.
+	ActorID string
+	Action  Action
+	// TargetID is the asset acted on, if any.
+	TargetID string
+	// Changes holds the top-level fields of the target that changed.
+	Changes   map[string]Change
+	RequestID string
+	IP        string
+	UserAgent string
+	// RedactedAt is when the actor's account was deleted and the entry
+	// stripped of Changes, RequestID, IP and UserAgent. ActorID is then a
+	// pseudonym shared by the actor's entries.
+	RedactedAt time.Time
+}
+
+// Diff compares the JSON objects before and after field by field. Either may
+// be nil, for a creation or a deletion.
+func Diff(before, after any) (map[string]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for k, v := range b {
+		if w, ok := a[k]; !ok || !bytes.Equal(v, w) {
+			changes[k] = Change{Before: v, After: a[k]}
+		}
+	}
+	for k, w := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = Change{After: w}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+// fields splits v's JSON object into compacted field values.
+func fields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit target: %w", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("audit target is not a JSON object: %w", err)
+	}
+	for k, raw := range m {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return nil, err
+		}
+		m[k] = buf.Bytes()
+	}
+	return m, nil
+}
+
+// Page sizes for listing entries.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Filter selects entries. Zero fields do not filter.
+type Filter struct {
+	ActorID  string
+	Action   Action
+	TargetID string
+	// From and To bound the entries' time, To excluded.
+	From time.Time
+	To   time.Time
+	// BeforeID pages backwards: only entries older than it are returned.
+	BeforeID int64
+	// Limit caps a page. Exports ignore it.
+	Limit int
+}
+
+// Normalize applies the default limit and checks the filter.
+func (f Filter) Normalize() (Filter, error) {
+	if f.Action != "" && !slices.Contains(Actions, f.Action) {
+		return f, fmt.Errorf("%w: unknown action %q", ErrInvalidFilter, f.Action)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return f, fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	if f.BeforeID < 0 {
+		return f, fmt.Errorf("%w: before must be positive", ErrInvalidFilter)
+	}
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultLimit
+	case f.Limit < 0 || f.Limit > MaxLimit:
+		return f, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxLimit)
+	}
+	return f, nil
+}
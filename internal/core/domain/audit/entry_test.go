@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type doc struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description,omitempty"`
+		Tags        []string `json:"tags"`
+	}
+
+	t.Run("update", func(t *testing.T) {
+		changes, err := Diff(
+			doc{Title: "t", Description: "old", Tags: []string{"a"}},
+			doc{Title: "t", Description: "new", Tags: []string{"a"}},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Change{
+			"description": {Before: json.RawMessage(`"old"`), After: json.RawMessage(`"new"`)},
+		}, changes)
+	})
+
+	t.Run("removed and added fields", func(t *testing.T) {
+		changes, err := Diff(doc{Title: "t", Description: "old"}, doc{Title: "t", Tags: []string{"b"}})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Change{
+			"description": {Before: json.RawMessage(`"old"`)},
+			"tags":        {Before: json.RawMessage(`null`), After: json.RawMessage(`["b"]`)},
+		}, changes)
+	})
+
+	t.Run("creation", func(t *testing.T) {
+		changes, err := Diff(nil, doc{Title: "t"})
+		assert.NoError(t, err)
+		assert.Equal(t, json.RawMessage(`"t"`), changes["title"].After)
+		assert.Empty(t, changes["title"].Before)
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		changes, err := Diff(doc{Title: "t"}, doc{Title: "t"})
+		assert.NoError(t, err)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := Diff("text", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestFilter_Normalize(t *testing.T) {
+	f, err := Filter{}.Normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultLimit, f.Limit)
+
+	now := time.Now()
+	for name, f := range map[string]Filter{
+		"unknown action": {Action: "user.dance"},
+		"empty range":    {From: now, To: now},
+		"limit too big":  {Limit: MaxLimit + 1},
+		"negative limit": {Limit: -1},
+		"negative ID":    {BeforeID: -1},
+	} {
+		_, err := f.Normalize()
+		assert.ErrorIs(t, err, ErrInvalidFilter, name)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	assert.Empty(t, MetadataFrom(context.Background()))
+
+	m := Metadata{RequestID: "r1", IP: "192.0.2.1", UserAgent: "curl"}
+	assert.Equal(t, m, MetadataFrom(WithMetadata(context.Background(), m)))
+}
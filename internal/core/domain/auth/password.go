@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultPasswordMinLength applies when no minimum is configured.
+	DefaultPasswordMinLength = 8
+	// MaxPasswordBytes is bcrypt's input limit; longer passwords are silently truncated by it.
+	MaxPasswordBytes = 72
+)
+
+// PasswordPolicy describes the rules a new password must satisfy.
+type PasswordPolicy struct {
+	MinLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy builds a policy. breached is an optional newline-separated
+// list of known-compromised passwords; blank lines and lines starting with # are ignored.
+func NewPasswordPolicy(minLength int, breached io.Reader) (PasswordPolicy, error) {
+	p := PasswordPolicy{MinLength: minLength}
+	if breached == nil {
+		return p, nil
+	}
+
+	p.breached = make(map[string]struct{})
+	scanner := bufio.NewScanner(breached)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return PasswordPolicy{}, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return p, nil
+}
+
+// Validate checks a candidate password against the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	minLength := p.MinLength
+	if minLength < 1 {
+		minLength = DefaultPasswordMinLength
+	}
+
+	if len([]rune(password)) < minLength {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrValidation, minLength)
+	}
+	if len(password) > MaxPasswordBytes {
+		return fmt.Errorf("%w: password must be at most %d bytes", ErrValidation, MaxPasswordBytes)
+	}
+	if _, found := p.breached[strings.ToLower(password)]; found {
+		return fmt.Errorf("%w: password appears in a list of breached passwords", ErrValidation)
+	}
+	return nil
+}
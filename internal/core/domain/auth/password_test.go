@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy, err := NewPasswordPolicy(10, strings.NewReader("# common passwords\nPassword123!\n\nletmein12345\n"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "valid password",
+			password: "correct horse battery",
+			wantErr:  false,
+		},
+		{
+			name:     "too short",
+			password: "short",
+			wantErr:  true,
+			errMsg:   "validation failed: password must be at least 10 characters",
+		},
+		{
+			name:     "exceeds bcrypt limit",
+			password: strings.Repeat("a", MaxPasswordBytes+1),
+			wantErr:  true,
+			errMsg:   "validation failed: password must be at most 72 bytes",
+		},
+		{
+			name:     "breached password, case-insensitive",
+			password: "LetMeIn12345",
+			wantErr:  true,
+			errMsg:   "validation failed: password appears in a list of breached passwords",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValidation)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_DefaultMinLength(t *testing.T) {
+	var policy PasswordPolicy
+	assert.Error(t, policy.Validate("1234567"))
+	assert.NoError(t, policy.Validate("12345678"))
+}
@@ -0,0 +1,39 @@
+package auth
+
+import "strings"
+
+// Profile is the view of the current user returned by the account endpoints.
+type Profile struct {
+	User          User
+	FavoriteCount int
+}
+
+// ProfilePatch describes a partial update of the user's profile.
+// A nil DisplayName leaves the name untouched. Preferences are merged key by key;
+// a nil value removes the key.
+type ProfilePatch struct {
+	DisplayName *string
+	Preferences map[string]any
+}
+
+// Apply returns a copy of the user with the patch applied.
+func (p ProfilePatch) Apply(u User) User {
+	if p.DisplayName != nil {
+		u.DisplayName = strings.TrimSpace(*p.DisplayName)
+	}
+	if len(p.Preferences) > 0 {
+		merged := make(map[string]any, len(u.Preferences)+len(p.Preferences))
+		for k, v := range u.Preferences {
+			merged[k] = v
+		}
+		for k, v := range p.Preferences {
+			if v == nil {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+		u.Preferences = merged
+	}
+	return u
+}
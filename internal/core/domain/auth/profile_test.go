@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilePatch_Apply(t *testing.T) {
+	user := User{
+		ID:          "123",
+		DisplayName: "Old",
+		Preferences: map[string]any{"theme": "dark", "lang": "en"},
+	}
+
+	t.Run("merges preferences and trims name", func(t *testing.T) {
+		name := "  New Name "
+		got := ProfilePatch{
+			DisplayName: &name,
+			Preferences: map[string]any{"lang": nil, "page_size": float64(50)},
+		}.Apply(user)
+
+		assert.Equal(t, "New Name", got.DisplayName)
+		assert.Equal(t, map[string]any{"theme": "dark", "page_size": float64(50)}, got.Preferences)
+		// Original is untouched
+		assert.Equal(t, "en", user.Preferences["lang"])
+	})
+
+	t.Run("empty patch is a no-op", func(t *testing.T) {
+		assert.Equal(t, user, ProfilePatch{}.Apply(user))
+	})
+}
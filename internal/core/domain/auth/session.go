@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSessionNotFound is returned when a session does not exist or belongs to another user.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionRevoked is returned when a token's session was logged out or has expired.
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// SessionState is the cached outcome of a session lookup.
+type SessionState string
+
+const (
+	SessionActive  SessionState = "active"
+	SessionRevoked SessionState = "revoked"
+)
+
+// ClientInfo describes the device a login came from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is the server-side record behind a bearer token (its "sid" claim).
+type Session struct {
+	ID         string
+	UserID     string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  time.Time
+}
+
+// IsActive reports whether the session can still authenticate requests at the given time.
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
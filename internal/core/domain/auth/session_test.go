@@ -0,0 +1,16 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_IsActive(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, Session{ExpiresAt: now.Add(time.Minute)}.IsActive(now))
+	assert.False(t, Session{ExpiresAt: now.Add(-time.Minute)}.IsActive(now))
+	assert.False(t, Session{ExpiresAt: now.Add(time.Minute), RevokedAt: now}.IsActive(now))
+}
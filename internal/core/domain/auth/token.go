@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when an action token is malformed, expired or has the wrong purpose.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrTokenUsed is returned when a single-use token has already been consumed.
+	ErrTokenUsed = errors.New("token has already been used")
+)
+
+// TokenPurpose scopes a signed action token to a single flow.
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+)
+
+// ActionToken is the decoded form of a signed, single-use token sent by email.
+type ActionToken struct {
+	ID        string
+	UserID    string
+	Email     string
+	Purpose   TokenPurpose
+	ExpiresAt time.Time
+}
@@ -2,20 +2,97 @@ package auth
 
 import (
 	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrValidation is the sentinel error for invalid user input.
+	ErrValidation = errors.New("validation failed")
+	// ErrUserNotFound is returned when no user matches the lookup.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken is returned when another account already uses the email address.
+	ErrEmailTaken = errors.New("email is already registered")
+	// ErrEmailNotVerified is returned when an action requires a verified email address.
+	ErrEmailNotVerified = errors.New("email address is not verified")
+	// ErrInvalidCredentials is returned when a password re-check fails.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const (
+	maxEmailLength       = 254
+	maxDisplayNameLength = 100
+	maxPreferencesKeys   = 50
+)
+
+// Roles granted to users. Every account has RoleUser.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 )
 
 type User struct {
-	ID           string `json:"id"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"-"`
+	ID              string         `json:"id"`
+	Email           string         `json:"email"`
+	PasswordHash    string         `json:"-"`
+	EmailVerifiedAt time.Time      `json:"email_verified_at,omitzero"`
+	DisplayName     string         `json:"display_name,omitzero"`
+	Preferences     map[string]any `json:"preferences,omitzero"`
+	Roles           []string       `json:"roles,omitzero"`
+	CreatedAt       time.Time      `json:"created_at,omitzero"`
+}
+
+// IsVerified reports whether the user has proven ownership of their email address.
+func (u User) IsVerified() bool {
+	return !u.EmailVerifiedAt.IsZero()
+}
+
+// HasRole reports whether the user was granted the role.
+func (u User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 func (u User) Validate() error {
 	if u.ID == "" {
-		return errors.New("id is required")
+		return fmt.Errorf("%w: id is required", ErrValidation)
+	}
+	if err := ValidateEmail(u.Email); err != nil {
+		return err
+	}
+	if len([]rune(u.DisplayName)) > maxDisplayNameLength {
+		return fmt.Errorf("%w: display_name must be at most %d characters", ErrValidation, maxDisplayNameLength)
+	}
+	if len(u.Preferences) > maxPreferencesKeys {
+		return fmt.Errorf("%w: preferences may hold at most %d keys", ErrValidation, maxPreferencesKeys)
+	}
+	return nil
+}
+
+// NormalizeEmail trims surrounding whitespace and lower-cases the address,
+// so that uniqueness and lookups are case-insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks the address against RFC 5322 addr-spec syntax.
+// Display names ("Jane <jane@example.com>") are rejected; only the bare address is accepted.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return fmt.Errorf("%w: email is required", ErrValidation)
+	}
+	if len(email) > maxEmailLength {
+		return fmt.Errorf("%w: email must be at most %d characters", ErrValidation, maxEmailLength)
 	}
-	if u.Email == "" {
-		return errors.New("email is required")
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return fmt.Errorf("%w: email is not a valid address", ErrValidation)
 	}
 	return nil
 }
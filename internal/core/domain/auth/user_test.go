@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +29,7 @@ func TestUser_Validate(t *testing.T) {
 				Email: "test@example.com",
 			},
 			wantErr: true,
-			errMsg:  "id is required",
+			errMsg:  "validation failed: id is required",
 		},
 		{
 			name: "missing email",
@@ -35,7 +37,35 @@ func TestUser_Validate(t *testing.T) {
 				ID: "123",
 			},
 			wantErr: true,
-			errMsg:  "email is required",
+			errMsg:  "validation failed: email is required",
+		},
+		{
+			name: "malformed email",
+			user: User{
+				ID:    "123",
+				Email: "not-an-email",
+			},
+			wantErr: true,
+			errMsg:  "validation failed: email is not a valid address",
+		},
+		{
+			name: "display name too long",
+			user: User{
+				ID:          "123",
+				Email:       "test@example.com",
+				DisplayName: strings.Repeat("x", 101),
+			},
+			wantErr: true,
+			errMsg:  "validation failed: display_name must be at most 100 characters",
+		},
+		{
+			name: "email with display name",
+			user: User{
+				ID:    "123",
+				Email: "Jane <jane@example.com>",
+			},
+			wantErr: true,
+			errMsg:  "validation failed: email is not a valid address",
 		},
 	}
 
@@ -51,3 +81,18 @@ func TestUser_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_IsVerified(t *testing.T) {
+	assert.False(t, User{ID: "123"}.IsVerified())
+	assert.True(t, User{ID: "123", EmailVerifiedAt: time.Now()}.IsVerified())
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "jane@example.com", NormalizeEmail("  Jane@Example.COM "))
+}
+
+func TestUser_HasRole(t *testing.T) {
+	u := User{Roles: []string{RoleUser}}
+	assert.True(t, u.HasRole(RoleUser))
+	assert.False(t, u.HasRole(RoleAdmin))
+}
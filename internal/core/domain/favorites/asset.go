@@ -3,11 +3,21 @@ package favorites
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // ErrValidation is the sentinel error for validation failures.
 var ErrValidation = errors.New("validation failed")
 
+// ErrNotFound is returned when an asset does not exist.
+var ErrNotFound = errors.New("asset not found")
+
+// ErrAlreadyExists is returned when saving an asset whose ID is taken.
+var ErrAlreadyExists = errors.New("asset already exists")
+
+// ErrForbidden is returned when a user changes an asset they do not own.
+var ErrForbidden = errors.New("forbidden: you do not own this asset")
+
 // AssetType defines the supported asset types.
 type AssetType string
 
@@ -23,6 +33,8 @@ type Asset interface {
 	GetID() string
 	GetUserID() string
 	GetType() AssetType
+	GetEnrichment() Enrichment
+	GetUpdatedAt() time.Time
 	isAsset() // Sealed interface method
 }
 
@@ -33,6 +45,11 @@ type BaseAsset struct {
 	Type        AssetType `json:"type"`
 	Name        string    `json:"name"`
 	Description string    `json:"description,omitzero"`
+	// Enrichment and UpdatedAt are maintained by the server; client input is ignored.
+	Enrichment Enrichment `json:"enrichment,omitzero"`
+	// UpdatedAt is when the user last changed the asset. Cached copies carry
+	// it, so they can be told apart from newer rows.
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
 }
 
 func (b BaseAsset) GetID() string {
@@ -47,6 +64,14 @@ func (b BaseAsset) GetType() AssetType {
 	return b.Type
 }
 
+func (b BaseAsset) GetEnrichment() Enrichment {
+	return b.Enrichment
+}
+
+func (b BaseAsset) GetUpdatedAt() time.Time {
+	return b.UpdatedAt
+}
+
 // isAsset implements the sealed interface marker for all embedding types.
 func (b BaseAsset) isAsset() {}
 
@@ -63,3 +88,51 @@ func (b BaseAsset) ValidateCommon(expectedType AssetType) error {
 	}
 	return nil
 }
+
+// WithID returns a copy of the asset with the given ID.
+func WithID(asset Asset, id string) Asset {
+	switch a := asset.(type) {
+	case Chart:
+		a.ID = id
+		return a
+	case Insight:
+		a.ID = id
+		return a
+	case Audience:
+		a.ID = id
+		return a
+	}
+	return asset
+}
+
+// WithDescription returns a copy of the asset with the given description.
+func WithDescription(asset Asset, description string) Asset {
+	switch a := asset.(type) {
+	case Chart:
+		a.Description = description
+		return a
+	case Insight:
+		a.Description = description
+		return a
+	case Audience:
+		a.Description = description
+		return a
+	}
+	return asset
+}
+
+// WithUpdatedAt returns a copy of the asset last changed at t.
+func WithUpdatedAt(asset Asset, t time.Time) Asset {
+	switch a := asset.(type) {
+	case Chart:
+		a.UpdatedAt = t
+		return a
+	case Insight:
+		a.UpdatedAt = t
+		return a
+	case Audience:
+		a.UpdatedAt = t
+		return a
+	}
+	return asset
+}
@@ -2,6 +2,7 @@ package favorites
 
 import (
 	"testing"
+	"time"
 )
 
 func TestBaseAsset_ValidateCommon(t *testing.T) {
@@ -84,3 +85,56 @@ func TestBaseAsset_Getters(t *testing.T) {
 		t.Errorf("GetType() = %v, want %v", base.GetType(), AssetTypeChart)
 	}
 }
+
+func TestWithUpdatedAt(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assets := []Asset{
+		Chart{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeChart}},
+		Insight{BaseAsset: BaseAsset{ID: "2", Type: AssetTypeInsight}},
+		Audience{BaseAsset: BaseAsset{ID: "3", Type: AssetTypeAudience}},
+	}
+	for _, asset := range assets {
+		got := WithUpdatedAt(asset, now)
+		if !got.GetUpdatedAt().Equal(now) {
+			t.Errorf("%s: GetUpdatedAt() = %v, want %v", asset.GetType(), got.GetUpdatedAt(), now)
+		}
+		if !asset.GetUpdatedAt().IsZero() {
+			t.Errorf("%s: original asset was modified", asset.GetType())
+		}
+	}
+}
+
+func TestWithID(t *testing.T) {
+	assets := []Asset{
+		Chart{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeChart}},
+		Insight{BaseAsset: BaseAsset{ID: "2", Type: AssetTypeInsight}},
+		Audience{BaseAsset: BaseAsset{ID: "3", Type: AssetTypeAudience}},
+	}
+	for _, asset := range assets {
+		got := WithID(asset, "new")
+		if got.GetID() != "new" || got.GetType() != asset.GetType() {
+			t.Errorf("%s: WithID() = %+v, want the same asset with ID new", asset.GetType(), got)
+		}
+		if asset.GetID() == "new" {
+			t.Errorf("%s: original asset was modified", asset.GetType())
+		}
+	}
+}
+
+func TestWithDescription(t *testing.T) {
+	assets := []Asset{
+		Chart{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeChart, Description: "old"}},
+		Insight{BaseAsset: BaseAsset{ID: "2", Type: AssetTypeInsight, Description: "old"}},
+		Audience{BaseAsset: BaseAsset{ID: "3", Type: AssetTypeAudience, Description: "old"}},
+	}
+	for _, asset := range assets {
+		got := WithDescription(asset, "new")
+		if Fields(got)["description"] != "new" || got.GetID() != asset.GetID() {
+			t.Errorf("%s: WithDescription() = %+v, want the same asset described as new", asset.GetType(), got)
+		}
+		if Fields(asset)["description"] != "old" {
+			t.Errorf("%s: original asset was modified", asset.GetType())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package favorites
+
+import "errors"
+
+// ErrBatchAborted is reported for the items of an all-or-nothing batch that
+// were not applied because another item failed.
+var ErrBatchAborted = errors.New("not applied: another item in the batch failed")
+
+// BatchMode decides what happens to a batch when some of its items fail.
+type BatchMode string
+
+const (
+	// BatchAtomic applies every item or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies the items that succeed and reports the others.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchAction is what was done with an item of a batch.
+type BatchAction string
+
+const (
+	ActionCreated  BatchAction = "created"
+	ActionReplaced BatchAction = "replaced"
+	ActionSkipped  BatchAction = "skipped"
+	ActionUpdated  BatchAction = "updated"
+	ActionDeleted  BatchAction = "deleted"
+)
+
+// DescriptionUpdate sets the description of one asset of a batch.
+type DescriptionUpdate struct {
+	ID          string
+	Description string
+}
+
+// BatchResult is the outcome of one item of a batch. Err is nil when the
+// item was applied, and Action then says how.
+type BatchResult struct {
+	ID     string
+	Action BatchAction
+	Err    error
+}
+
+// Abort marks every item of results that has not failed as ErrBatchAborted.
+func Abort(results []BatchResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Action, results[i].Err = "", ErrBatchAborted
+		}
+	}
+}
+
+// Failed reports whether any item of results failed.
+func Failed(results []BatchResult) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return true
+		}
+	}
+	return false
+}
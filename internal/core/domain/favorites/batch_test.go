@@ -0,0 +1,25 @@
+package favorites
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAbort(t *testing.T) {
+	results := []BatchResult{{ID: "a"}, {ID: "b", Err: ErrAlreadyExists}}
+	if !Failed(results) {
+		t.Fatal("Failed() = false, want true")
+	}
+
+	Abort(results)
+
+	if !errors.Is(results[0].Err, ErrBatchAborted) {
+		t.Errorf("results[0].Err = %v, want ErrBatchAborted", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, ErrAlreadyExists) {
+		t.Errorf("results[1].Err = %v, want the original error", results[1].Err)
+	}
+	if Failed([]BatchResult{{ID: "a"}}) {
+		t.Error("Failed() = true for a batch without errors")
+	}
+}
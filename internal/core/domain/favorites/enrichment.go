@@ -0,0 +1,89 @@
+package favorites
+
+import "time"
+
+// EnrichmentStatus reports how far the metadata lookup for an asset got.
+type EnrichmentStatus string
+
+const (
+	// EnrichmentPending means the asset is queued for enrichment or being retried.
+	EnrichmentPending EnrichmentStatus = "pending"
+	// EnrichmentReady means Data holds the latest metadata.
+	EnrichmentReady EnrichmentStatus = "ready"
+	// EnrichmentPartial means some stages failed; Data holds what the others returned.
+	EnrichmentPartial EnrichmentStatus = "partial"
+	// EnrichmentFailed means enrichment gave up after repeated failures.
+	EnrichmentFailed EnrichmentStatus = "failed"
+)
+
+// Metadata is what the metadata service knows about an asset.
+// Fields that do not apply to the asset's type are left empty.
+type Metadata struct {
+	ThumbnailURL    string    `json:"thumbnail_url,omitzero"`
+	Summary         string    `json:"summary,omitzero"`
+	RowCount        int64     `json:"row_count,omitzero"`
+	AudienceSize    int64     `json:"audience_size,omitzero"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at,omitzero"`
+}
+
+// Merge returns m with every non-empty field of other applied on top.
+func (m Metadata) Merge(other Metadata) Metadata {
+	if other.ThumbnailURL != "" {
+		m.ThumbnailURL = other.ThumbnailURL
+	}
+	if other.Summary != "" {
+		m.Summary = other.Summary
+	}
+	if other.RowCount != 0 {
+		m.RowCount = other.RowCount
+	}
+	if other.AudienceSize != 0 {
+		m.AudienceSize = other.AudienceSize
+	}
+	if other.LastRefreshedAt.After(m.LastRefreshedAt) {
+		m.LastRefreshedAt = other.LastRefreshedAt
+	}
+	return m
+}
+
+// StageResult is the outcome of one enrichment stage.
+type StageResult struct {
+	Name string `json:"name"`
+	// Error is a short reason such as "timeout"; empty when the stage succeeded.
+	Error string `json:"error,omitzero"`
+}
+
+// Failed reports whether the stage failed.
+func (r StageResult) Failed() bool {
+	return r.Error != ""
+}
+
+// Enrichment is the server-owned enrichment state of an asset.
+type Enrichment struct {
+	Status    EnrichmentStatus `json:"status"`
+	UpdatedAt time.Time        `json:"updated_at,omitzero"`
+	Data      Metadata         `json:"data,omitzero"`
+	// Stages lists the outcome of each stage of the last run.
+	Stages []StageResult `json:"stages,omitzero"`
+}
+
+// PendingEnrichment is the state of an asset waiting for its first lookup.
+func PendingEnrichment(now time.Time) Enrichment {
+	return Enrichment{Status: EnrichmentPending, UpdatedAt: now}
+}
+
+// WithEnrichment returns a copy of the asset carrying the given enrichment state.
+func WithEnrichment(asset Asset, e Enrichment) Asset {
+	switch a := asset.(type) {
+	case Chart:
+		a.Enrichment = e
+		return a
+	case Insight:
+		a.Enrichment = e
+		return a
+	case Audience:
+		a.Enrichment = e
+		return a
+	}
+	return asset
+}
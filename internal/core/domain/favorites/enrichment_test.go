@@ -0,0 +1,88 @@
+package favorites
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithEnrichment(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := Enrichment{Status: EnrichmentReady, UpdatedAt: now, Data: Metadata{RowCount: 42}}
+
+	assets := []Asset{
+		Chart{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeChart}},
+		Insight{BaseAsset: BaseAsset{ID: "2", Type: AssetTypeInsight}},
+		Audience{BaseAsset: BaseAsset{ID: "3", Type: AssetTypeAudience}},
+	}
+	for _, asset := range assets {
+		got := WithEnrichment(asset, e)
+		if !reflect.DeepEqual(got.GetEnrichment(), e) {
+			t.Errorf("%s: GetEnrichment() = %+v, want %+v", asset.GetType(), got.GetEnrichment(), e)
+		}
+		if !reflect.DeepEqual(asset.GetEnrichment(), Enrichment{}) {
+			t.Errorf("%s: original asset was modified", asset.GetType())
+		}
+	}
+}
+
+func TestEnrichment_JSON(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("pending asset omits data", func(t *testing.T) {
+		asset := WithEnrichment(Insight{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeInsight, Name: "n"}}, PendingEnrichment(now))
+		data, err := json.Marshal(asset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `"enrichment":{"status":"pending","updated_at":"2026-01-02T03:04:05Z"}`
+		if !strings.Contains(string(data), want) {
+			t.Errorf("json = %s, want it to contain %s", data, want)
+		}
+	})
+
+	t.Run("asset without enrichment omits the field", func(t *testing.T) {
+		data, err := json.Marshal(Insight{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeInsight, Name: "n"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "enrichment") {
+			t.Errorf("json = %s, want no enrichment field", data)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		in := WithEnrichment(Chart{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeChart, Name: "n"}, XAxis: "x"}, Enrichment{
+			Status:    EnrichmentReady,
+			UpdatedAt: now,
+			Data:      Metadata{ThumbnailURL: "https://cdn/1.png", RowCount: 10, LastRefreshedAt: now},
+			Stages:    []StageResult{{Name: "metadata"}, {Name: "preview", Error: "timeout"}},
+		})
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out Chart
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(out.Enrichment, in.GetEnrichment()) {
+			t.Errorf("round trip = %+v, want %+v", out.Enrichment, in.GetEnrichment())
+		}
+	})
+}
+
+func TestMetadata_Merge(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	base := Metadata{ThumbnailURL: "a.png", RowCount: 5, LastRefreshedAt: newer}
+	got := base.Merge(Metadata{ThumbnailURL: "b.png", Summary: "s", LastRefreshedAt: older})
+
+	want := Metadata{ThumbnailURL: "b.png", Summary: "s", RowCount: 5, LastRefreshedAt: newer}
+	if got != want {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+}
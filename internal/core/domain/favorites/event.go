@@ -0,0 +1,43 @@
+package favorites
+
+import "time"
+
+// EventType names a change to a favorite.
+type EventType string
+
+const (
+	EventCreated EventType = "favorite.created"
+	EventUpdated EventType = "favorite.updated"
+	EventDeleted EventType = "favorite.deleted"
+	// EventAccountDeleted follows the deletion events of an account's
+	// favorites. It carries the user but no asset.
+	EventAccountDeleted EventType = "account.deleted"
+)
+
+// Event records a change to a favorite, or the deletion of an account. It is
+// stored with the change and published after it commits.
+type Event struct {
+	// ID is assigned when the event is stored. It is the same on every
+	// delivery, so subscribers can use it to drop duplicates.
+	ID         string
+	Type       EventType
+	AssetID    string
+	UserID     string
+	OccurredAt time.Time
+	// Asset is the asset after the change, nil for EventDeleted.
+	Asset Asset
+}
+
+// NewEvent describes a change to the asset. Deletions do not carry the asset.
+func NewEvent(t EventType, asset Asset, at time.Time) Event {
+	e := Event{
+		Type:       t,
+		AssetID:    asset.GetID(),
+		UserID:     asset.GetUserID(),
+		OccurredAt: at,
+	}
+	if t != EventDeleted {
+		e.Asset = asset
+	}
+	return e
+}
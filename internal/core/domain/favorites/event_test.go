@@ -0,0 +1,21 @@
+package favorites
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewEvent(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	asset := Insight{BaseAsset: BaseAsset{ID: "1", UserID: "u1", Type: AssetTypeInsight}}
+
+	created := NewEvent(EventCreated, asset, now)
+	if created.AssetID != "1" || created.UserID != "u1" || !created.OccurredAt.Equal(now) || created.Asset == nil {
+		t.Errorf("unexpected created event: %+v", created)
+	}
+
+	deleted := NewEvent(EventDeleted, asset, now)
+	if deleted.AssetID != "1" || deleted.UserID != "u1" || deleted.Asset != nil {
+		t.Errorf("unexpected deleted event: %+v", deleted)
+	}
+}
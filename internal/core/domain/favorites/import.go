@@ -0,0 +1,21 @@
+package favorites
+
+// ConflictPolicy decides what an import does with an asset whose ID is taken.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the existing asset as it is.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing asset, if the user owns it.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictNewID saves the asset under a new ID.
+	ConflictNewID ConflictPolicy = "new_id"
+)
+
+// ImportOptions controls an import.
+type ImportOptions struct {
+	Mode       BatchMode
+	OnConflict ConflictPolicy
+	// DryRun reports what the import would do without saving anything.
+	DryRun bool
+}
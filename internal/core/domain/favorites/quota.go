@@ -0,0 +1,144 @@
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrQuotaExceeded is returned when saving an asset would take the user over
+// their quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrFieldTooLong is returned when a field of an asset is longer than its limit.
+var ErrFieldTooLong = errors.New("field too long")
+
+// Quota reasons, reported to the client with the error.
+const (
+	QuotaReasonFavorites   = "max_favorites"
+	QuotaReasonBytes       = "max_bytes"
+	QuotaReasonFieldLength = "max_field_length"
+)
+
+// AnyType keys field limits that apply to every asset type.
+const AnyType AssetType = "*"
+
+// Quota limits what a user may store. Zero limits are unlimited.
+type Quota struct {
+	MaxFavorites int
+	// MaxBytes caps the total size of the user's assets as stored.
+	MaxBytes int64
+	// MaxFieldLengths caps string fields, in characters, by asset type and
+	// JSON field name (e.g. "content" or "rules.country"). Limits under
+	// AnyType apply to every type unless the type sets its own.
+	MaxFieldLengths map[AssetType]map[string]int
+}
+
+// Usage is what a user currently stores.
+type Usage struct {
+	Favorites int
+	Bytes     int64
+}
+
+// QuotaError describes the limit an asset broke. It matches ErrQuotaExceeded
+// or ErrFieldTooLong.
+type QuotaError struct {
+	Reason string
+	// Field is the offending field for QuotaReasonFieldLength.
+	Field string
+	Limit int64
+}
+
+func (e *QuotaError) Error() string {
+	switch e.Reason {
+	case QuotaReasonFavorites:
+		return fmt.Sprintf("quota exceeded: at most %d favorites", e.Limit)
+	case QuotaReasonBytes:
+		return fmt.Sprintf("quota exceeded: at most %d bytes of favorites", e.Limit)
+	default:
+		return fmt.Sprintf("field too long: %s is limited to %d characters", e.Field, e.Limit)
+	}
+}
+
+func (e *QuotaError) Is(target error) bool {
+	if e.Reason == QuotaReasonFieldLength {
+		return target == ErrFieldTooLong
+	}
+	return target == ErrQuotaExceeded
+}
+
+// FieldLimit returns the length limit of a field of the given type, or 0.
+func (q Quota) FieldLimit(t AssetType, field string) int {
+	if n, ok := q.MaxFieldLengths[t][field]; ok {
+		return n
+	}
+	return q.MaxFieldLengths[AnyType][field]
+}
+
+// CheckFields returns a *QuotaError for the first field of the asset, by
+// name, that is over its limit.
+func (q Quota) CheckFields(asset Asset) error {
+	fields := Fields(asset)
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		limit := q.FieldLimit(asset.GetType(), field)
+		if limit > 0 && utf8.RuneCountInString(fields[field]) > limit {
+			return &QuotaError{Reason: QuotaReasonFieldLength, Field: field, Limit: int64(limit)}
+		}
+	}
+	return nil
+}
+
+// Check returns a *QuotaError if adding an asset of size bytes to usage would
+// exceed the quota.
+func (q Quota) Check(usage Usage, size int64) error {
+	if q.MaxFavorites > 0 && usage.Favorites+1 > q.MaxFavorites {
+		return &QuotaError{Reason: QuotaReasonFavorites, Limit: int64(q.MaxFavorites)}
+	}
+	if q.MaxBytes > 0 && usage.Bytes+size > q.MaxBytes {
+		return &QuotaError{Reason: QuotaReasonBytes, Limit: q.MaxBytes}
+	}
+	return nil
+}
+
+// CheckReplace returns a *QuotaError if replacing an asset of oldSize bytes
+// with one of newSize bytes would exceed the quota.
+func (q Quota) CheckReplace(usage Usage, oldSize, newSize int64) error {
+	if q.MaxBytes > 0 && newSize > oldSize && usage.Bytes-oldSize+newSize > q.MaxBytes {
+		return &QuotaError{Reason: QuotaReasonBytes, Limit: q.MaxBytes}
+	}
+	return nil
+}
+
+// Fields returns the user-provided string fields of the asset, keyed by
+// JSON field name.
+func Fields(asset Asset) map[string]string {
+	switch a := asset.(type) {
+	case Chart:
+		return map[string]string{"name": a.Name, "description": a.Description, "x_axis": a.XAxis, "y_axis": a.YAxis}
+	case Insight:
+		return map[string]string{"name": a.Name, "description": a.Description, "content": a.Content}
+	case Audience:
+		return map[string]string{"name": a.Name, "description": a.Description, "rules.gender": a.Rules.Gender, "rules.country": a.Rules.Country}
+	}
+	return nil
+}
+
+// StoredJSON encodes what the user saved of the asset, without the
+// server-maintained enrichment and timestamps.
+func StoredJSON(asset Asset) ([]byte, error) {
+	return json.Marshal(WithUpdatedAt(WithEnrichment(asset, Enrichment{}), time.Time{}))
+}
+
+// StoredSize returns the length in bytes of StoredJSON. The repository
+// records it with each asset, and quotas add it up.
+func StoredSize(asset Asset) (int64, error) {
+	data, err := StoredJSON(asset)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
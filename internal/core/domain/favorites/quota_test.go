@@ -0,0 +1,109 @@
+package favorites
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuota_Check(t *testing.T) {
+	q := Quota{MaxFavorites: 2, MaxBytes: 100}
+
+	tests := []struct {
+		name   string
+		usage  Usage
+		size   int64
+		reason string
+	}{
+		{name: "within quota", usage: Usage{Favorites: 1, Bytes: 50}, size: 50},
+		{name: "too many favorites", usage: Usage{Favorites: 2}, size: 1, reason: QuotaReasonFavorites},
+		{name: "too many bytes", usage: Usage{Favorites: 1, Bytes: 50}, size: 51, reason: QuotaReasonBytes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.Check(tt.usage, tt.size)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var qerr *QuotaError
+			if !errors.As(err, &qerr) || qerr.Reason != tt.reason {
+				t.Fatalf("Check() error = %v, want reason %s", err, tt.reason)
+			}
+			if !errors.Is(err, ErrQuotaExceeded) {
+				t.Errorf("Check() error = %v, want ErrQuotaExceeded", err)
+			}
+		})
+	}
+
+	if err := (Quota{}).Check(Usage{Favorites: 1 << 30, Bytes: 1 << 40}, 1<<20); err != nil {
+		t.Errorf("zero quota should be unlimited, got %v", err)
+	}
+}
+
+func TestQuota_CheckReplace(t *testing.T) {
+	q := Quota{MaxFavorites: 1, MaxBytes: 100}
+
+	if err := q.CheckReplace(Usage{Favorites: 1, Bytes: 90}, 40, 50); err != nil {
+		t.Errorf("CheckReplace() error = %v, want nil: the count does not change", err)
+	}
+	if err := q.CheckReplace(Usage{Favorites: 1, Bytes: 120}, 40, 30); err != nil {
+		t.Errorf("CheckReplace() error = %v, want nil for a smaller asset", err)
+	}
+	if err := q.CheckReplace(Usage{Favorites: 1, Bytes: 90}, 40, 51); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("CheckReplace() error = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestQuota_CheckFields(t *testing.T) {
+	q := Quota{MaxFieldLengths: map[AssetType]map[string]int{
+		AnyType:          {"name": 5, "content": 3},
+		AssetTypeInsight: {"content": 4},
+	}}
+
+	tests := []struct {
+		name  string
+		asset Asset
+		field string
+	}{
+		{name: "within limits", asset: Insight{BaseAsset: BaseAsset{Type: AssetTypeInsight, Name: "héllo"}, Content: "abcd"}},
+		{name: "type limit overrides any", asset: Insight{BaseAsset: BaseAsset{Type: AssetTypeInsight, Name: "n"}, Content: "abcde"}, field: "content"},
+		{name: "any limit", asset: Chart{BaseAsset: BaseAsset{Type: AssetTypeChart, Name: "toolong"}, XAxis: "x"}, field: "name"},
+		{name: "nested field unlimited", asset: Audience{BaseAsset: BaseAsset{Type: AssetTypeAudience, Name: "n"}, Rules: AudienceRules{Country: "a long country"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.CheckFields(tt.asset)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("CheckFields() error = %v, want nil", err)
+				}
+				return
+			}
+			var qerr *QuotaError
+			if !errors.As(err, &qerr) || qerr.Field != tt.field {
+				t.Fatalf("CheckFields() error = %v, want field %s", err, tt.field)
+			}
+			if !errors.Is(err, ErrFieldTooLong) || errors.Is(err, ErrQuotaExceeded) {
+				t.Errorf("CheckFields() error = %v, want only ErrFieldTooLong", err)
+			}
+		})
+	}
+}
+
+func TestStoredSize(t *testing.T) {
+	asset := Insight{BaseAsset: BaseAsset{ID: "1", Type: AssetTypeInsight, Name: "n"}, Content: "c"}
+	plain, err := StoredSize(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enriched, err := StoredSize(WithEnrichment(asset, PendingEnrichment(time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != enriched || plain == 0 {
+		t.Errorf("StoredSize() = %d and %d, want equal sizes ignoring enrichment", plain, enriched)
+	}
+}
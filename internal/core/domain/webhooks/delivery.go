@@ -0,0 +1,105 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+// DeliveryStatus is the state of one event sent to one subscription.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are waiting for their first or next attempt.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered deliveries got a 2xx response.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed deliveries ran out of attempts.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is an event posted, or to be posted, to a subscription. The
+// deliveries of a subscription make up its delivery log.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      favorites.EventType
+	// Payload is the exact body sent, and signed, on every attempt.
+	Payload  []byte
+	Status   DeliveryStatus
+	Attempts int
+	// ResponseStatus is the HTTP status of the last attempt, 0 if it got no response.
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	// NextAttemptAt is when a pending delivery is tried again.
+	NextAttemptAt time.Time
+	DeliveredAt   time.Time
+}
+
+// payload is the JSON document posted to receivers.
+type payload struct {
+	ID         string              `json:"id"`
+	Type       favorites.EventType `json:"type"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Data       payloadData         `json:"data"`
+}
+
+type payloadData struct {
+	AssetID string          `json:"asset_id,omitempty"`
+	UserID  string          `json:"user_id,omitempty"`
+	Asset   favorites.Asset `json:"asset,omitempty"`
+}
+
+// NewDelivery prepares the delivery of event to the subscription.
+func NewDelivery(sub Subscription, event favorites.Event, now time.Time) (Delivery, error) {
+	body, err := json.Marshal(payload{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       payloadData{AssetID: event.AssetID, UserID: event.UserID, Asset: event.Asset},
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+	return Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        body,
+		Status:         DeliveryPending,
+		CreatedAt:      now,
+		NextAttemptAt:  now,
+	}, nil
+}
+
+// NewPing prepares a ping to the subscription. eventID identifies the ping.
+func NewPing(sub Subscription, eventID string, now time.Time) (Delivery, error) {
+	return NewDelivery(sub, favorites.Event{ID: eventID, Type: EventPing, UserID: sub.UserID, OccurredAt: now}, now)
+}
+
+// Succeeded records a 2xx response to an attempt.
+func (d Delivery) Succeeded(status int, at time.Time) Delivery {
+	d.Status = DeliveryDelivered
+	d.ResponseStatus = status
+	d.LastError = ""
+	d.DeliveredAt = at
+	d.NextAttemptAt = time.Time{}
+	return d
+}
+
+// Failed records a failed attempt. The delivery is tried again at retryAt,
+// or given up if retryAt is zero. status is 0 if no response was received.
+func (d Delivery) Failed(status int, cause error, retryAt time.Time) Delivery {
+	d.ResponseStatus = status
+	d.LastError = cause.Error()
+	d.NextAttemptAt = retryAt
+	if retryAt.IsZero() {
+		d.Status = DeliveryFailed
+	} else {
+		d.Status = DeliveryPending
+	}
+	return d
+}
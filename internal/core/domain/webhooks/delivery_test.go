@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+func TestNewDelivery(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sub := Subscription{ID: "s1", UserID: "u1"}
+	asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a1", UserID: "u1", Type: favorites.AssetTypeInsight, Name: "Churn"}}
+	event := favorites.NewEvent(favorites.EventCreated, asset, now)
+	event.ID = "e1"
+
+	d, err := NewDelivery(sub, event, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", d.SubscriptionID)
+	assert.Equal(t, "e1", d.EventID)
+	assert.Equal(t, DeliveryPending, d.Status)
+	assert.Equal(t, now, d.NextAttemptAt)
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(d.Payload, &body))
+	assert.Equal(t, "e1", body["id"])
+	assert.Equal(t, "favorite.created", body["type"])
+	data := body["data"].(map[string]any)
+	assert.Equal(t, "a1", data["asset_id"])
+	assert.Equal(t, "Churn", data["asset"].(map[string]any)["name"])
+
+	t.Run("deletions carry no asset", func(t *testing.T) {
+		deleted := favorites.NewEvent(favorites.EventDeleted, asset, now)
+		d, err := NewDelivery(sub, deleted, now)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(d.Payload), `"asset":`)
+	})
+}
+
+func TestDelivery_Outcome(t *testing.T) {
+	now := time.Now()
+	d := Delivery{Status: DeliveryPending, Attempts: 1}
+
+	retried := d.Failed(503, errors.New("unavailable"), now.Add(time.Minute))
+	assert.Equal(t, DeliveryPending, retried.Status)
+	assert.Equal(t, 503, retried.ResponseStatus)
+	assert.Equal(t, "unavailable", retried.LastError)
+
+	failed := d.Failed(0, errors.New("connection refused"), time.Time{})
+	assert.Equal(t, DeliveryFailed, failed.Status)
+
+	delivered := retried.Succeeded(204, now)
+	assert.Equal(t, DeliveryDelivered, delivered.Status)
+	assert.Empty(t, delivered.LastError)
+	assert.Equal(t, now, delivered.DeliveredAt)
+	assert.True(t, delivered.NextAttemptAt.IsZero())
+}
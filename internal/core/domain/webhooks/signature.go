@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	// HeaderID carries the event ID, which is the same on every attempt.
+	HeaderID = "Webhook-Id"
+	// HeaderEvent carries the event type.
+	HeaderEvent = "Webhook-Event"
+	// HeaderTimestamp carries the Unix time of the attempt, in seconds.
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature carries "sha256=" and the hex HMAC-SHA256, keyed with
+	// the subscription secret, of the timestamp, a dot and the body.
+	HeaderSignature = "Webhook-Signature"
+)
+
+// ErrInvalidSignature is returned by Verify for deliveries that were not
+// signed with the secret, or were signed too long ago.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign computes the HeaderSignature value of a body sent at the given time.
+// Covering the timestamp keeps a captured delivery from being replayed later.
+func Sign(secret string, at time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(at.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the HeaderTimestamp and HeaderSignature values of a received
+// delivery, rejecting those signed more than tolerance away from now. It is
+// what receivers are expected to do.
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	at := time.Unix(sec, 0)
+	if now.Sub(at).Abs() > tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, at, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"e1"}`)
+
+	sig := Sign("0123456789abcdef", at, body)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	assert.Equal(t, sig, Sign("0123456789abcdef", at, body), "signing is deterministic")
+	assert.NotEqual(t, sig, Sign("0123456789abcdef", at.Add(time.Second), body), "timestamp is signed")
+	assert.NotEqual(t, sig, Sign("fedcba9876543210", at, body), "secret is the key")
+}
+
+func TestVerify(t *testing.T) {
+	secret := "0123456789abcdef"
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"e1"}`)
+	ts := strconv.FormatInt(at.Unix(), 10)
+	sig := Sign(secret, at, body)
+
+	assert.NoError(t, Verify(secret, ts, sig, body, at.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verify(secret, ts, sig, []byte(`{"id":"e2"}`), at, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("fedcba9876543210", ts, sig, body, at, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, ts, sig, body, at.Add(time.Hour), 5*time.Minute), ErrInvalidSignature, "too old")
+	assert.ErrorIs(t, Verify(secret, "yesterday", sig, body, at, 5*time.Minute), ErrInvalidSignature)
+}
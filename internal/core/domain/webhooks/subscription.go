@@ -0,0 +1,69 @@
+// Package webhooks describes the HTTP callbacks users register to hear
+// about changes to their favorites.
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+var (
+	// ErrValidation is the sentinel error for invalid subscriptions.
+	ErrValidation = errors.New("validation failed")
+	// ErrNotFound is returned when a subscription does not exist or belongs to another user.
+	ErrNotFound = errors.New("webhook not found")
+)
+
+// MinSecretLength is the shortest secret accepted for signing deliveries.
+const MinSecretLength = 16
+
+// EventPing is sent by the test endpoint to check that a receiver is reachable.
+const EventPing favorites.EventType = "ping"
+
+// EventTypes lists the events a subscription can ask for.
+var EventTypes = []favorites.EventType{favorites.EventCreated, favorites.EventUpdated, favorites.EventDeleted}
+
+// Subscription asks for the events on a user's favorites to be posted to URL.
+type Subscription struct {
+	ID     string
+	UserID string
+	URL    string
+	Events []favorites.EventType
+	// Secret signs every delivery, so the receiver can tell it came from us.
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Validate checks the URL, the requested events and the secret.
+func (s Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrValidation)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: url must not contain credentials", ErrValidation)
+	}
+	if len(s.Events) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrValidation)
+	}
+	for _, e := range s.Events {
+		if !slices.Contains(EventTypes, e) {
+			return fmt.Errorf("%w: unknown event type %q", ErrValidation, e)
+		}
+	}
+	if len(strings.TrimSpace(s.Secret)) < MinSecretLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", ErrValidation, MinSecretLength)
+	}
+	return nil
+}
+
+// Wants reports whether the subscription asked for events of type t.
+func (s Subscription) Wants(t favorites.EventType) bool {
+	return slices.Contains(s.Events, t)
+}
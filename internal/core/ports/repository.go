@@ -3,15 +3,80 @@ package ports
 import (
 	"context"
 	"iter"
+	"time"
 
+	"go-favorites-app/internal/core/domain/audit"
 	"go-favorites-app/internal/core/domain/auth"
 	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
 )
 
 // UserRepository defines storage for users.
 type UserRepository interface {
 	Save(ctx context.Context, user auth.User) error
 	FindByEmail(ctx context.Context, email string) (auth.User, error)
+	FindByID(ctx context.Context, id string) (auth.User, error)
+
+	// UpdateProfile persists the display name and preferences.
+	UpdateProfile(ctx context.Context, user auth.User) error
+
+	// UpdateEmail changes the address and clears its verification.
+	// It returns auth.ErrEmailTaken if another account uses the address.
+	UpdateEmail(ctx context.Context, userID, email string) error
+
+	// UpdatePassword replaces the stored password hash.
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+
+	// MarkEmailVerified records when the user proved ownership of their email.
+	MarkEmailVerified(ctx context.Context, userID string, at time.Time) error
+
+	// Delete removes the user. Their favorites and tokens are removed by cascade.
+	Delete(ctx context.Context, userID string) error
+
+	// ConsumeToken marks a single-use action token as spent.
+	// It returns auth.ErrTokenUsed if the token was consumed before.
+	ConsumeToken(ctx context.Context, token auth.ActionToken) error
+}
+
+// AccountTx holds the stores bound to one AccountStore transaction.
+type AccountTx struct {
+	Users     UserRepository
+	Sessions  SessionRepository
+	Favorites FavoriteRepository
+	Outbox    Outbox
+	AuditLog  AuditLog
+}
+
+// AccountStore changes everything stored about an account at once.
+type AccountStore interface {
+	// WithTx runs fn in a transaction. The stores passed to fn write in it;
+	// it commits if fn returns nil and rolls back otherwise.
+	WithTx(ctx context.Context, fn func(tx AccountTx) error) error
+}
+
+// SessionRepository stores the server-side sessions behind bearer tokens.
+type SessionRepository interface {
+	Create(ctx context.Context, session auth.Session) error
+	FindByID(ctx context.Context, id string) (auth.Session, error)
+
+	// ListActive returns the user's sessions that are neither revoked nor expired,
+	// most recently used first.
+	ListActive(ctx context.Context, userID string, now time.Time) ([]auth.Session, error)
+
+	// Touch records activity on a session.
+	Touch(ctx context.Context, id string, at time.Time) error
+
+	// Revoke ends one of the user's sessions. It returns auth.ErrSessionNotFound
+	// if the session does not belong to the user or is already revoked.
+	Revoke(ctx context.Context, userID, id string, at time.Time) (auth.Session, error)
+
+	// RevokeAllExcept ends every active session of the user but keepID and
+	// returns the sessions it revoked. An empty keepID revokes them all.
+	RevokeAllExcept(ctx context.Context, userID, keepID string, at time.Time) ([]auth.Session, error)
+
+	// DeleteExpired deletes up to limit sessions that expired before cutoff
+	// and returns how many it deleted.
+	DeleteExpired(ctx context.Context, cutoff time.Time, limit int) (int, error)
 }
 
 // FavoriteRepository defines the interface for favorite asset storage.
@@ -19,9 +84,22 @@ type FavoriteRepository interface {
 	// Save persists a generic Asset.
 	Save(ctx context.Context, asset favorites.Asset) error
 
+	// SaveBatch persists assets in one round trip. created[i] reports
+	// whether assets[i] was inserted; it is false when its ID already exists,
+	// including earlier in the batch.
+	SaveBatch(ctx context.Context, assets []favorites.Asset) (created []bool, err error)
+
+	// ReplaceBatch overwrites the content of existing assets, keeping their
+	// owner, in one round trip. replaced[i] reports whether assets[i] was
+	// found among the assets of its user.
+	ReplaceBatch(ctx context.Context, assets []favorites.Asset) (replaced []bool, err error)
+
 	// FindByID retrieves an asset by its ID.
 	FindByID(ctx context.Context, id string) (favorites.Asset, error)
 
+	// FindByIDs retrieves the assets that exist among ids, keyed by ID.
+	FindByIDs(ctx context.Context, ids []string) (map[string]favorites.Asset, error)
+
 	// FindAll returns an iterator of Assets to stream results.
 	// limit and offset determine pagination.
 	FindAll(ctx context.Context, limit, offset int) (iter.Seq2[favorites.Asset, error], error)
@@ -29,9 +107,181 @@ type FavoriteRepository interface {
 	// FindByUser returns an iterator of Assets for a specific user.
 	FindByUser(ctx context.Context, userID string, limit, offset int) (iter.Seq2[favorites.Asset, error], error)
 
+	// FindByUserAfter returns up to limit of the user's Assets with an ID
+	// greater than afterID, in ID order; an empty afterID starts from the
+	// first. Paging on the last ID seen neither skips nor repeats assets
+	// when others are saved or deleted between pages.
+	FindByUserAfter(ctx context.Context, userID, afterID string, limit int) (iter.Seq2[favorites.Asset, error], error)
+
+	// CountByUser returns how many assets the user has favorited.
+	CountByUser(ctx context.Context, userID string) (int, error)
+
+	// Usage returns how many assets the user stores and their size. In a
+	// transaction it also holds the user's quota lock until the transaction
+	// ends, so concurrent saves for the user are checked one at a time.
+	Usage(ctx context.Context, userID string) (favorites.Usage, error)
+
 	// Delete removes an asset by ID.
 	Delete(ctx context.Context, id string) error
 
+	// DeleteByUser removes every asset owned by the user and returns their IDs.
+	DeleteByUser(ctx context.Context, userID string) ([]string, error)
+
+	// DeleteBatch removes the assets among ids that the user owns and
+	// returns the IDs removed.
+	DeleteBatch(ctx context.Context, userID string, ids []string) ([]string, error)
+
 	// UpdateDescription updates just the description of an asset.
 	UpdateDescription(ctx context.Context, id, description string) (favorites.Asset, error)
+
+	// UpdateEnrichment stores the enrichment state of an asset.
+	// It returns favorites.ErrNotFound if the asset no longer exists.
+	UpdateEnrichment(ctx context.Context, id string, enrichment favorites.Enrichment) error
+
+	// WithTx runs fn in a transaction. The repository and outbox passed to fn
+	// write in it; it commits if fn returns nil and rolls back otherwise.
+	WithTx(ctx context.Context, fn func(repo FavoriteRepository, outbox Outbox) error) error
+}
+
+// Outbox records events in the transaction of the change they describe, so
+// that an event is published if and only if the change commits.
+type Outbox interface {
+	Add(ctx context.Context, events ...favorites.Event) error
+}
+
+// OutboxMessage is a claimed outbox event.
+type OutboxMessage struct {
+	ID    int64
+	Event favorites.Event
+	// Attempts counts claims, including the current one.
+	Attempts int
+}
+
+// OutboxStore is the relay's side of the outbox.
+type OutboxStore interface {
+	// Claim leases up to limit due events, oldest first. Events whose lease
+	// expires without being completed, retried or buried become claimable again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error)
+
+	// Complete removes a published event.
+	Complete(ctx context.Context, id int64) error
+
+	// Retry releases the event to be published again at the given time.
+	Retry(ctx context.Context, id int64, at time.Time, cause error) error
+
+	// Bury keeps an event that keeps failing out of the relay's way.
+	Bury(ctx context.Context, id int64, cause error) error
+}
+
+// AuditLog stores audit entries. Entries are never deleted, and only Redact
+// changes them.
+type AuditLog interface {
+	// Append stores the entries, assigning their IDs.
+	Append(ctx context.Context, entries ...audit.Entry) error
+
+	// Redact strips the personal data from the entries of actorID and files
+	// them under pseudonym instead. It returns how many entries it redacted.
+	Redact(ctx context.Context, actorID, pseudonym string) (int64, error)
+
+	// List returns a page of the entries matching f, newest first.
+	List(ctx context.Context, f audit.Filter) ([]audit.Entry, error)
+
+	// Export streams every entry matching f, newest first, ignoring its limit.
+	Export(ctx context.Context, f audit.Filter) (iter.Seq2[audit.Entry, error], error)
+}
+
+// WebhookJob is a claimed delivery and the subscription it goes to.
+type WebhookJob struct {
+	Delivery     webhooks.Delivery
+	Subscription webhooks.Subscription
+}
+
+// WebhookRepository stores webhook subscriptions and their delivery log.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub webhooks.Subscription) error
+
+	// FindSubscription returns webhooks.ErrNotFound unless the subscription
+	// exists and belongs to the user.
+	FindSubscription(ctx context.Context, userID, id string) (webhooks.Subscription, error)
+
+	// ListSubscriptions returns the user's subscriptions, oldest first.
+	ListSubscriptions(ctx context.Context, userID string) ([]webhooks.Subscription, error)
+
+	// SubscriptionsFor returns the user's subscriptions to events of type t.
+	SubscriptionsFor(ctx context.Context, userID string, t favorites.EventType) ([]webhooks.Subscription, error)
+
+	// DeleteSubscription removes the subscription and its delivery log. It
+	// returns webhooks.ErrNotFound unless the subscription belongs to the user.
+	DeleteSubscription(ctx context.Context, userID, id string) error
+
+	// AddDeliveries records deliveries, skipping those already recorded for
+	// the same subscription and event.
+	AddDeliveries(ctx context.Context, deliveries ...webhooks.Delivery) error
+
+	// ClaimDeliveries leases up to limit pending deliveries that are due,
+	// counting an attempt on each. Deliveries whose lease expires without
+	// being updated become claimable again.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookJob, error)
+
+	// UpdateDelivery records the outcome of an attempt and releases the lease.
+	UpdateDelivery(ctx context.Context, d webhooks.Delivery) error
+
+	// ListDeliveries returns the latest deliveries of a subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Delivery, error)
+}
+
+// EnrichmentJob is a claimed request to enrich one asset.
+type EnrichmentJob struct {
+	ID      int64
+	AssetID string
+	// Attempts counts claims, including the current one.
+	Attempts int
+	// Version changes whenever the asset is enqueued again, so completing a
+	// stale claim leaves the newer request pending.
+	Version int
+}
+
+// EnrichmentQueue is a durable, per-asset deduplicated queue of enrichment jobs.
+type EnrichmentQueue interface {
+	// Enqueue schedules enrichment of the assets. An asset already waiting is not queued twice.
+	Enqueue(ctx context.Context, assetIDs ...string) error
+
+	// Claim leases up to limit due jobs. Jobs whose lease expires without being
+	// completed, retried or buried become claimable again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]EnrichmentJob, error)
+
+	// Complete removes a finished job.
+	Complete(ctx context.Context, job EnrichmentJob) error
+
+	// Retry releases the job to run again at the given time.
+	Retry(ctx context.Context, job EnrichmentJob, at time.Time, cause error) error
+
+	// Bury moves a job that keeps failing to the dead-letter queue.
+	Bury(ctx context.Context, job EnrichmentJob, cause error) error
+
+	// EnqueueStale queues up to limit assets whose enrichment was last updated
+	// before the given time, oldest first, skipping assets already waiting.
+	// It returns how many were queued.
+	EnqueueStale(ctx context.Context, before time.Time, limit int) (int, error)
+
+	// Stats counts queued jobs and stale assets as defined by EnqueueStale.
+	Stats(ctx context.Context, staleBefore time.Time) (EnrichmentStats, error)
+}
+
+// EnrichmentStats is a snapshot of the enrichment backlog.
+type EnrichmentStats struct {
+	Pending int
+	Dead    int
+	// Stale assets are due for re-enrichment but not queued yet.
+	Stale int
+}
+
+// LeaderLock elects a single replica to run a singleton task.
+type LeaderLock interface {
+	// TryAcquire takes the lock if it is free. It returns true while this
+	// process holds the lock, including when it already did.
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release gives up the lock if held.
+	Release(ctx context.Context) error
 }
@@ -3,19 +3,101 @@ package ports
 import (
 	"context"
 	"iter"
+	"time"
 
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/auth"
 	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
 )
 
 // AuthService defines the authentication service.
 type AuthService interface {
 	SignUp(ctx context.Context, email, password string) error
-	Login(ctx context.Context, email, password string) (token string, err error)
+	// Login starts a session for the client and returns a bearer token bound to it.
+	Login(ctx context.Context, email, password string, client auth.ClientInfo) (token string, err error)
+
+	// RequestPasswordReset emails a reset link if the address belongs to a user.
+	// It never reveals whether the address is registered.
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword replaces the password and revokes every session of the user.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	VerifyEmail(ctx context.Context, token string) error
+
+	// ResendVerification mails a new verification link to an unverified user.
+	ResendVerification(ctx context.Context, userID string) error
+
+	// ChangePassword re-checks the current password before replacing it, and
+	// revokes every session of the user but sessionID.
+	ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error
+
+	// ChangeEmail re-checks the password, switches the address and sends a
+	// verification link to it. The account is unverified until that link is used.
+	ChangeEmail(ctx context.Context, userID, password, newEmail string) error
+
+	// EnsureVerified returns auth.ErrEmailNotVerified when verification is
+	// enforced and the user has not verified their email yet.
+	EnsureVerified(ctx context.Context, userID string) error
+}
+
+// AccountService manages the profile of the authenticated user.
+type AccountService interface {
+	Me(ctx context.Context, userID string) (auth.Profile, error)
+	UpdateProfile(ctx context.Context, userID string, patch auth.ProfilePatch) (auth.Profile, error)
+
+	// Export returns the user record and a stream of all their favorites.
+	Export(ctx context.Context, userID string) (auth.User, iter.Seq2[favorites.Asset, error], error)
+
+	// Delete re-checks the password, then erases the user, their favorites and cached data.
+	Delete(ctx context.Context, userID, password string) error
+}
+
+// SessionService lets users see and end their login sessions.
+type SessionService interface {
+	List(ctx context.Context, userID string) ([]auth.Session, error)
+	Revoke(ctx context.Context, userID, sessionID string) error
+
+	// RevokeOthers logs out every session of the user except currentID.
+	RevokeOthers(ctx context.Context, userID, currentID string) (int, error)
+
+	// Validate returns auth.ErrSessionRevoked unless the session is active
+	// and belongs to the user.
+	Validate(ctx context.Context, userID, sessionID string) error
+}
+
+// SessionCache remembers session states so that authenticated requests do
+// not need a database round trip each time.
+type SessionCache interface {
+	// GetSessionState returns an empty state when nothing is cached.
+	GetSessionState(ctx context.Context, sessionID string) (auth.SessionState, error)
+	SetSessionState(ctx context.Context, sessionID string, state auth.SessionState, ttl time.Duration) error
+}
+
+// MailMessage is a plain-text transactional email.
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email (verification links, password resets).
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
 }
 
 // Enricher defines an external service that enriches assets.
 type Enricher interface {
-	Enrich(ctx context.Context, asset favorites.Asset) error
+	// Enrich looks up metadata for the asset. Asset types the service does
+	// not cover yield empty metadata and no error.
+	Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, error)
+}
+
+// EnricherRegistry runs the enrichment stages registered for an asset's type.
+type EnricherRegistry interface {
+	// Enrich runs every stage in order and merges the metadata of those that
+	// succeed. It reports the outcome of each stage and returns an error if
+	// any of them failed.
+	Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, []favorites.StageResult, error)
 }
 
 // Cache defines the caching operations.
@@ -38,6 +120,181 @@ type Cache interface {
 
 	// Invalidate removes only the asset data, keeping the ID in the set.
 	Invalidate(ctx context.Context, id string) error
+
+	// AddBatch adds assets, keyed by ID, to the sorted set with the same
+	// score and holds their data, in one round trip.
+	AddBatch(ctx context.Context, assets map[string][]byte, score float64) error
+
+	// RemoveBatch removes assets from cache in one round trip.
+	RemoveBatch(ctx context.Context, ids []string) error
+}
+
+// CacheScanner walks the shared cache directly, bypassing any in-process tier,
+// so that its content can be checked against the database.
+type CacheScanner interface {
+	// ScanSet returns about count IDs of the sorted set from cursor on and
+	// the cursor to continue from, which is 0 once the scan is complete. An
+	// ID may be returned more than once.
+	ScanSet(ctx context.Context, cursor uint64, count int64) (ids []string, next uint64, err error)
+
+	// GetBatch retrieves multiple assets by ID.
+	GetBatch(ctx context.Context, ids []string) (map[string][]byte, error)
+}
+
+// FillLock coordinates cache fills across replicas, so that a missing entry
+// is read from the database by one of them while the others wait for it.
+type FillLock interface {
+	// TryLock takes the lock on key for at most ttl. It reports false if
+	// another replica holds it.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock releases a lock taken by this replica.
+	Unlock(ctx context.Context, key string) error
+}
+
+// Rate allows Limit requests per Period, in bursts of up to Limit. Limit
+// must be positive.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimitResult is the outcome of counting one request.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a refused caller must wait.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full limit is available again.
+	ResetAfter time.Duration
+}
+
+// RateLimiter counts requests against a rate, per key.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rate Rate) (RateLimitResult, error)
+}
+
+// IdempotencyRecord is what is kept under an idempotency key: the
+// fingerprint of the request that claimed it and, once that request is
+// handled, its response.
+type IdempotencyRecord struct {
+	Fingerprint string
+	// Done is false while the first request is still being handled.
+	Done        bool
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore keeps the responses of requests sent with an
+// idempotency key, so that retries get the same answer.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with the given fingerprint for at
+	// most ttl. If the key is already claimed, it reports false with the
+	// record found.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (IdempotencyRecord, bool, error)
+	// Complete stores the response of the request that claimed key.
+	Complete(ctx context.Context, key string, rec IdempotencyRecord, ttl time.Duration) error
+	// Release drops a claim, so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	// ForgetUser drops every key of the user's requests, which are scoped
+	// as "<userID>:<Idempotency-Key>".
+	ForgetUser(ctx context.Context, userID string) error
+}
+
+// EventHandler reacts to favorite events published from the outbox. Events
+// are delivered at least once, so handling one twice must be harmless.
+type EventHandler interface {
+	Handle(ctx context.Context, event favorites.Event) error
+}
+
+// WebhookSender posts deliveries to webhook receivers.
+type WebhookSender interface {
+	// Send posts the delivery's payload to url, signed with secret. It
+	// returns the response status, 0 if none was received, and an error
+	// unless the status is 2xx.
+	Send(ctx context.Context, url, secret string, d webhooks.Delivery) (status int, err error)
+}
+
+// WebhookService manages the webhook subscriptions of the authenticated user.
+type WebhookService interface {
+	Create(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error)
+	List(ctx context.Context, userID string) ([]webhooks.Subscription, error)
+	Delete(ctx context.Context, userID, id string) error
+
+	// Deliveries returns the latest deliveries of one of the user's subscriptions.
+	Deliveries(ctx context.Context, userID, id string, limit int) ([]webhooks.Delivery, error)
+
+	// Test sends a ping to the subscription once, without retries, and
+	// returns the delivery as recorded in its log.
+	Test(ctx context.Context, userID, id string) (webhooks.Delivery, error)
+}
+
+// AuditRecorder records actions in the audit log. The request metadata is
+// taken from ctx. Recording never fails the action.
+type AuditRecorder interface {
+	Record(ctx context.Context, entry audit.Entry)
+}
+
+// AuditService reads the audit log.
+type AuditService interface {
+	// Mine returns a page of the user's own entries; f.ActorID is ignored.
+	Mine(ctx context.Context, userID string, f audit.Filter) ([]audit.Entry, error)
+	ExportMine(ctx context.Context, userID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error)
+
+	// Search returns a page of any entries. It returns audit.ErrForbidden
+	// unless adminID has the admin role.
+	Search(ctx context.Context, adminID string, f audit.Filter) ([]audit.Entry, error)
+	Export(ctx context.Context, adminID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error)
+}
+
+// FeedEvent is a favorite event as streamed to clients.
+type FeedEvent struct {
+	// ID is assigned by the EventFeed and orders the events of a user.
+	ID     string
+	UserID string
+	Type   favorites.EventType
+	// Data is the JSON document sent to clients.
+	Data []byte
+}
+
+// EventFeed keeps a bounded history of each user's favorite events and
+// broadcasts new ones to every replica.
+type EventFeed interface {
+	// Append adds the event to its user's history and broadcasts it. It
+	// returns the event with its ID.
+	Append(ctx context.Context, e FeedEvent) (FeedEvent, error)
+
+	// Since returns the user's events after lastID, oldest first. complete
+	// is false if some of them are no longer kept, or lastID is unknown.
+	Since(ctx context.Context, userID, lastID string) (events []FeedEvent, complete bool, err error)
+
+	// Subscribe passes every broadcast event to fn until ctx is canceled.
+	// reset is called on every (re)subscribe, since events may have been
+	// missed while disconnected.
+	Subscribe(ctx context.Context, fn func(FeedEvent), reset func()) error
+
+	// Delete drops the user's history.
+	Delete(ctx context.Context, userID string) error
+}
+
+// FeedWatch is a client's subscription to the changes of their favorites.
+type FeedWatch struct {
+	// Missed holds the events after the ID the client resumed from.
+	Missed []FeedEvent
+	// Resync is set when events after that ID are no longer available; the
+	// client must reload its favorites.
+	Resync bool
+	// Live delivers new events. It is closed when the watch ends, or when
+	// the client falls too far behind and should resume from its last ID.
+	Live <-chan FeedEvent
+}
+
+// FeedService streams the changes to a user's favorites.
+type FeedService interface {
+	// Watch subscribes until ctx is canceled. lastEventID is empty for a
+	// new client.
+	Watch(ctx context.Context, userID, lastEventID string) (FeedWatch, error)
 }
 
 // FavoriteService defines the application logic.
@@ -48,4 +305,29 @@ type FavoriteService interface {
 	FindAllByUser(ctx context.Context, userID string, limit, offset int) (iter.Seq2[favorites.Asset, error], error)
 	Delete(ctx context.Context, id, userID string) error
 	UpdateDescription(ctx context.Context, id, description, userID string) (favorites.Asset, error)
+
+	// SaveBatch saves the user's assets and returns the outcome of each, in
+	// order. The error is only set when the batch could not be attempted.
+	SaveBatch(ctx context.Context, userID string, assets []favorites.Asset, mode favorites.BatchMode) ([]favorites.BatchResult, error)
+
+	// UpdateBatch sets the description of the user's assets and returns the
+	// outcome of each update, in order. Assets of other users are reported as
+	// favorites.ErrForbidden.
+	UpdateBatch(ctx context.Context, userID string, updates []favorites.DescriptionUpdate) ([]favorites.BatchResult, error)
+
+	// DeleteBatch deletes the user's assets among ids and returns the outcome
+	// of each, in order. Assets of other users are reported as
+	// favorites.ErrForbidden.
+	DeleteBatch(ctx context.Context, userID string, ids []string) ([]favorites.BatchResult, error)
+
+	// Export streams every favorite of the user.
+	Export(ctx context.Context, userID string) iter.Seq2[favorites.Asset, error]
+
+	// Import saves the user's assets, resolving taken IDs as opts says, and
+	// returns the outcome of each, in order. A dry run reports the outcome
+	// without saving anything.
+	Import(ctx context.Context, userID string, assets []favorites.Asset, opts favorites.ImportOptions) ([]favorites.BatchResult, error)
+
+	// Usage returns what the user stores and the quota that applies to them.
+	Usage(ctx context.Context, userID string) (favorites.Usage, favorites.Quota, error)
 }
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// exportPageSize bounds how many rows are fetched per query while streaming an export.
+const exportPageSize = 500
+
+// AccountService serves the /me endpoints.
+type AccountService struct {
+	users     ports.UserRepository
+	favorites ports.FavoriteRepository
+	accounts  ports.AccountStore
+	cache     ports.Cache
+	logger    *slog.Logger
+
+	sessionCache ports.SessionCache
+	idempotency  ports.IdempotencyStore
+}
+
+func NewAccountService(users ports.UserRepository, favorites ports.FavoriteRepository, accounts ports.AccountStore, cache ports.Cache, logger *slog.Logger) *AccountService {
+	return &AccountService{
+		users:     users,
+		favorites: favorites,
+		accounts:  accounts,
+		cache:     cache,
+		logger:    logger,
+	}
+}
+
+// WithSessionCache records the sessions ended by an account deletion in the
+// cache that authenticated requests consult, so they stop working at once.
+func (s *AccountService) WithSessionCache(cache ports.SessionCache) *AccountService {
+	s.sessionCache = cache
+	return s
+}
+
+// WithIdempotency drops the stored responses of a deleted account's requests.
+func (s *AccountService) WithIdempotency(store ports.IdempotencyStore) *AccountService {
+	s.idempotency = store
+	return s
+}
+
+func (s *AccountService) Me(ctx context.Context, userID string) (auth.Profile, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.Me", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return auth.Profile{}, err
+	}
+	return s.profile(ctx, user)
+}
+
+func (s *AccountService) UpdateProfile(ctx context.Context, userID string, patch auth.ProfilePatch) (auth.Profile, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.UpdateProfile", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return auth.Profile{}, err
+	}
+
+	updated := patch.Apply(user)
+	if err := updated.Validate(); err != nil {
+		return auth.Profile{}, err
+	}
+	if err := s.users.UpdateProfile(ctx, updated); err != nil {
+		span.RecordError(err)
+		return auth.Profile{}, err
+	}
+	return s.profile(ctx, updated)
+}
+
+func (s *AccountService) Export(ctx context.Context, userID string) (auth.User, iter.Seq2[favorites.Asset, error], error) {
+	ctx, span := tracer.Start(ctx, "AccountService.Export", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return auth.User{}, nil, err
+	}
+	return user, allFavorites(ctx, s.favorites, userID), nil
+}
+
+// allFavorites pages through FindByUserAfter so an export streams every row
+// while each query stays bounded. Each page starts after the last ID seen,
+// so assets saved or deleted during the export do not shift later pages.
+func allFavorites(ctx context.Context, repo ports.FavoriteRepository, userID string) iter.Seq2[favorites.Asset, error] {
+	return func(yield func(favorites.Asset, error) bool) {
+		afterID := ""
+		for {
+			page, err := repo.FindByUserAfter(ctx, userID, afterID, exportPageSize)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			n := 0
+			for asset, err := range page {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				n++
+				afterID = asset.GetID()
+				if !yield(asset, nil) {
+					return
+				}
+			}
+			if n < exportPageSize {
+				return
+			}
+		}
+	}
+}
+
+func (s *AccountService) Delete(ctx context.Context, userID, password string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.Delete", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if err := checkPassword(user, password); err != nil {
+		return err
+	}
+
+	// Delete favorites and sessions explicitly (rather than relying only on
+	// the cascade) so we know which cache entries to purge. Everything is
+	// removed in one transaction, so a failure leaves the account whole.
+	var ids []string
+	var sessions []auth.Session
+	err = s.accounts.WithTx(ctx, func(tx ports.AccountTx) error {
+		now := time.Now()
+		var err error
+		if sessions, err = tx.Sessions.RevokeAllExcept(ctx, userID, "", now); err != nil {
+			return err
+		}
+		if ids, err = tx.Favorites.DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+		// The favorites' events carry no user, so neither the feed nor the
+		// webhooks act on them; the last event drops the user's feed.
+		events := make([]favorites.Event, 0, len(ids)+1)
+		for _, id := range ids {
+			events = append(events, favorites.Event{Type: favorites.EventDeleted, AssetID: id, OccurredAt: now})
+		}
+		events = append(events, favorites.Event{Type: favorites.EventAccountDeleted, UserID: userID, OccurredAt: now})
+		if err := tx.Outbox.Add(ctx, events...); err != nil {
+			return err
+		}
+		if err := tx.Users.Delete(ctx, userID); err != nil {
+			return err
+		}
+		// The audit log keeps what the user did, but not who they were or
+		// what their favorites held.
+		_, err = tx.AuditLog.Redact(ctx, userID, uuid.NewString())
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	for _, session := range sessions {
+		cacheRevoked(ctx, s.sessionCache, s.logger, session)
+	}
+	if s.idempotency != nil {
+		if err := s.idempotency.ForgetUser(ctx, userID); err != nil {
+			// They expire with IDEMPOTENCY_TTL; nobody can send the keys again.
+			s.logger.Error("failed to drop idempotency keys of deleted user", "user_id", userID, "error", err)
+		}
+	}
+	for _, id := range ids {
+		if err := s.cache.Remove(ctx, id); err != nil {
+			// The outbox event of the deletion removes it again.
+			s.logger.Error("failed to purge cached favorite of deleted user", "id", id, "error", err)
+		}
+	}
+	s.logger.InfoContext(ctx, "account deleted", "user_id", userID, "favorites", len(ids))
+	return nil
+}
+
+func (s *AccountService) profile(ctx context.Context, user auth.User) (auth.Profile, error) {
+	count, err := s.favorites.CountByUser(ctx, user.ID)
+	if err != nil {
+		return auth.Profile{}, err
+	}
+	return auth.Profile{User: user, FavoriteCount: count}, nil
+}
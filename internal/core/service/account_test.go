@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+var quietLogger = slog.New(slog.NewTextHandler(&testWriter{}, nil))
+
+// MockAccountStore runs WithTx against its mock stores; a failing fn is
+// what rolls back.
+type MockAccountStore struct {
+	Users     MockUserRepository
+	Sessions  MockSessionRepository
+	Favorites MockRepository
+	AuditLog  MockAuditLog
+}
+
+func (m *MockAccountStore) WithTx(ctx context.Context, fn func(tx ports.AccountTx) error) error {
+	return fn(ports.AccountTx{
+		Users:     &m.Users,
+		Sessions:  &m.Sessions,
+		Favorites: &m.Favorites,
+		Outbox:    &m.Favorites.Outbox,
+		AuditLog:  &m.AuditLog,
+	})
+}
+
+type MockIdempotencyStore struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (ports.IdempotencyRecord, bool, error) {
+	args := m.Called(ctx, key, fingerprint, ttl)
+	return args.Get(0).(ports.IdempotencyRecord), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyStore) Complete(ctx context.Context, key string, rec ports.IdempotencyRecord, ttl time.Duration) error {
+	args := m.Called(ctx, key, rec, ttl)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyStore) Release(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyStore) ForgetUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestAccountService_Me(t *testing.T) {
+	users := new(MockUserRepository)
+	favs := new(MockRepository)
+	svc := NewAccountService(users, favs, new(MockAccountStore), new(MockCache), quietLogger)
+
+	user := auth.User{ID: "user1", Email: "test@example.com", Roles: []string{auth.RoleUser}}
+	users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+	favs.On("CountByUser", mock.Anything, "user1").Return(3, nil)
+
+	profile, err := svc.Me(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, profile.User)
+	assert.Equal(t, 3, profile.FavoriteCount)
+}
+
+func TestAccountService_UpdateProfile(t *testing.T) {
+	user := auth.User{ID: "user1", Email: "test@example.com", Preferences: map[string]any{"theme": "light"}}
+
+	t.Run("success", func(t *testing.T) {
+		users := new(MockUserRepository)
+		favs := new(MockRepository)
+		svc := NewAccountService(users, favs, new(MockAccountStore), new(MockCache), quietLogger)
+
+		name := "Jane"
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+		users.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(u auth.User) bool {
+			return u.DisplayName == "Jane" && u.Preferences["theme"] == "dark"
+		})).Return(nil).Once()
+		favs.On("CountByUser", mock.Anything, "user1").Return(0, nil)
+
+		profile, err := svc.UpdateProfile(context.Background(), "user1", auth.ProfilePatch{
+			DisplayName: &name,
+			Preferences: map[string]any{"theme": "dark"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", profile.User.DisplayName)
+		users.AssertExpectations(t)
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		users := new(MockUserRepository)
+		svc := NewAccountService(users, new(MockRepository), new(MockAccountStore), new(MockCache), quietLogger)
+
+		name := strings.Repeat("x", 101)
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+
+		_, err := svc.UpdateProfile(context.Background(), "user1", auth.ProfilePatch{DisplayName: &name})
+		assert.ErrorIs(t, err, auth.ErrValidation)
+		users.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything)
+	})
+}
+
+func assetsOf(assets ...favorites.Asset) iter.Seq2[favorites.Asset, error] {
+	return func(yield func(favorites.Asset, error) bool) {
+		for _, a := range assets {
+			if !yield(a, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestAccountService_Export(t *testing.T) {
+	users := new(MockUserRepository)
+	favs := new(MockRepository)
+	svc := NewAccountService(users, favs, new(MockAccountStore), new(MockCache), quietLogger)
+
+	user := auth.User{ID: "user1", Email: "test@example.com"}
+	users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+
+	// A full first page forces a second query, which starts after the last
+	// ID of the first; the short second page ends the export.
+	page := make([]favorites.Asset, exportPageSize)
+	for i := range page {
+		page[i] = favorites.Chart{BaseAsset: favorites.BaseAsset{ID: fmt.Sprintf("c%04d", i), UserID: "user1"}}
+	}
+	last := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "i", UserID: "user1"}}
+	favs.On("FindByUserAfter", mock.Anything, "user1", "", exportPageSize).Return(assetsOf(page...), nil).Once()
+	favs.On("FindByUserAfter", mock.Anything, "user1", page[exportPageSize-1].GetID(), exportPageSize).Return(assetsOf(last), nil).Once()
+
+	got, assets, err := svc.Export(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	count := 0
+	for asset, err := range assets {
+		assert.NoError(t, err)
+		assert.NotNil(t, asset)
+		count++
+	}
+	assert.Equal(t, exportPageSize+1, count)
+	favs.AssertExpectations(t)
+}
+
+func TestAccountService_Delete(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := auth.User{ID: "user1", Email: "test@example.com", PasswordHash: string(hashed)}
+
+	t.Run("success purges cache", func(t *testing.T) {
+		users := new(MockUserRepository)
+		store := new(MockAccountStore)
+		cache := new(MockCache)
+		sessionCache := new(MockSessionCache)
+		idempotency := new(MockIdempotencyStore)
+		svc := NewAccountService(users, new(MockRepository), store, cache, quietLogger).
+			WithSessionCache(sessionCache).
+			WithIdempotency(idempotency)
+		session := auth.Session{ID: "s1", UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)}
+
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+		store.Sessions.On("RevokeAllExcept", mock.Anything, "user1", "", mock.Anything).Return([]auth.Session{session}, nil).Once()
+		store.Favorites.On("DeleteByUser", mock.Anything, "user1").Return([]string{"a", "b"}, nil).Once()
+		store.Favorites.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 3 &&
+				events[0].Type == favorites.EventDeleted && events[0].AssetID == "a" && events[0].UserID == "" &&
+				events[2].Type == favorites.EventAccountDeleted && events[2].UserID == "user1"
+		})).Return(nil).Once()
+		store.Users.On("Delete", mock.Anything, "user1").Return(nil).Once()
+		store.AuditLog.On("Redact", mock.Anything, "user1", mock.MatchedBy(func(pseudonym string) bool {
+			return pseudonym != "" && pseudonym != "user1"
+		})).Return(int64(3), nil).Once()
+		sessionCache.On("SetSessionState", mock.Anything, "s1", auth.SessionRevoked, mock.Anything).Return(nil).Once()
+		idempotency.On("ForgetUser", mock.Anything, "user1").Return(errors.New("redis down")).Once()
+		cache.On("Remove", mock.Anything, "a").Return(nil).Once()
+		cache.On("Remove", mock.Anything, "b").Return(errors.New("redis down")).Once()
+
+		err := svc.Delete(context.Background(), "user1", "password123")
+		assert.NoError(t, err)
+		store.Sessions.AssertExpectations(t)
+		store.Favorites.AssertExpectations(t)
+		store.Favorites.Outbox.AssertExpectations(t)
+		store.Users.AssertExpectations(t)
+		store.AuditLog.AssertExpectations(t)
+		sessionCache.AssertExpectations(t)
+		idempotency.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("a failed redaction rolls the deletion back", func(t *testing.T) {
+		users := new(MockUserRepository)
+		store := new(MockAccountStore)
+		cache := new(MockCache)
+		svc := NewAccountService(users, new(MockRepository), store, cache, quietLogger)
+
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+		store.Sessions.On("RevokeAllExcept", mock.Anything, "user1", "", mock.Anything).Return([]auth.Session{}, nil).Once()
+		store.Favorites.On("DeleteByUser", mock.Anything, "user1").Return([]string{"a"}, nil).Once()
+		store.Favorites.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+		store.Users.On("Delete", mock.Anything, "user1").Return(nil).Once()
+		store.AuditLog.On("Redact", mock.Anything, "user1", mock.Anything).Return(int64(0), errors.New("db error")).Once()
+
+		err := svc.Delete(context.Background(), "user1", "password123")
+		assert.Error(t, err)
+		cache.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+	})
+
+	t.Run("outbox failure keeps the account", func(t *testing.T) {
+		users := new(MockUserRepository)
+		store := new(MockAccountStore)
+		svc := NewAccountService(users, new(MockRepository), store, new(MockCache), quietLogger)
+
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+		store.Sessions.On("RevokeAllExcept", mock.Anything, "user1", "", mock.Anything).Return([]auth.Session{}, nil).Once()
+		store.Favorites.On("DeleteByUser", mock.Anything, "user1").Return([]string{"a"}, nil).Once()
+		store.Favorites.Outbox.On("Add", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		err := svc.Delete(context.Background(), "user1", "password123")
+		assert.Error(t, err)
+		store.Users.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		users := new(MockUserRepository)
+		store := new(MockAccountStore)
+		svc := NewAccountService(users, new(MockRepository), store, new(MockCache), quietLogger)
+
+		users.On("FindByID", mock.Anything, "user1").Return(user, nil)
+
+		err := svc.Delete(context.Background(), "user1", "wrong")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		store.Favorites.AssertNotCalled(t, "DeleteByUser", mock.Anything, mock.Anything)
+		store.Users.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
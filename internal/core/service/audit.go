@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"time"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// AuditService records actions in the audit log and serves it to users and
+// admins.
+type AuditService struct {
+	log    ports.AuditLog
+	users  ports.UserRepository
+	logger *slog.Logger
+}
+
+// Ensure AuditService implements ports.AuditService and ports.AuditRecorder
+var (
+	_ ports.AuditService  = (*AuditService)(nil)
+	_ ports.AuditRecorder = (*AuditService)(nil)
+)
+
+func NewAuditService(log ports.AuditLog, users ports.UserRepository, logger *slog.Logger) *AuditService {
+	return &AuditService{log: log, users: users, logger: logger}
+}
+
+// Record stores the entry with the request metadata from ctx. Recording is
+// best effort: the action it describes has already committed, so a failure
+// is logged and counted rather than returned.
+func (s *AuditService) Record(ctx context.Context, entry audit.Entry) {
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	m := audit.MetadataFrom(ctx)
+	entry.RequestID, entry.IP, entry.UserAgent = m.RequestID, m.IP, m.UserAgent
+
+	// The caller's request may be canceled right after the action.
+	if err := s.log.Append(context.WithoutCancel(ctx), entry); err != nil {
+		auditEntries.WithLabelValues(string(entry.Action), "failed").Inc()
+		s.logger.ErrorContext(ctx, "failed to record audit entry",
+			"action", entry.Action, "actor_id", entry.ActorID, "target_id", entry.TargetID, "request_id", entry.RequestID, "error", err)
+		return
+	}
+	auditEntries.WithLabelValues(string(entry.Action), "recorded").Inc()
+}
+
+func (s *AuditService) Mine(ctx context.Context, userID string, f audit.Filter) ([]audit.Entry, error) {
+	f.ActorID = userID
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return s.log.List(ctx, f)
+}
+
+func (s *AuditService) ExportMine(ctx context.Context, userID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	f.ActorID = userID
+	if _, err := f.Normalize(); err != nil {
+		return nil, err
+	}
+	return s.log.Export(ctx, f)
+}
+
+func (s *AuditService) Search(ctx context.Context, adminID string, f audit.Filter) ([]audit.Entry, error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	f, err := f.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return s.log.List(ctx, f)
+}
+
+func (s *AuditService) Export(ctx context.Context, adminID string, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+	if _, err := f.Normalize(); err != nil {
+		return nil, err
+	}
+	return s.log.Export(ctx, f)
+}
+
+// requireAdmin reads the roles from the database rather than the token, so
+// that revoking the role takes effect at once.
+func (s *AuditService) requireAdmin(ctx context.Context, userID string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return audit.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !user.HasRole(auth.RoleAdmin) {
+		return audit.ErrForbidden
+	}
+	return nil
+}
+
+// recordChange records an action on an asset with the fields it changed.
+// before is nil for a creation and after for a deletion.
+func recordChange(ctx context.Context, rec ports.AuditRecorder, logger *slog.Logger, action audit.Action, actorID string, before, after favorites.Asset) {
+	if rec == nil {
+		return
+	}
+	var b, a any
+	targetID := ""
+	if before != nil {
+		b, targetID = before, before.GetID()
+	}
+	if after != nil {
+		a, targetID = after, after.GetID()
+	}
+	changes, err := audit.Diff(b, a)
+	if err != nil {
+		// Still record who did what.
+		logger.ErrorContext(ctx, "failed to diff audited asset", "id", targetID, "error", err)
+	}
+	rec.Record(ctx, audit.Entry{ActorID: actorID, Action: action, TargetID: targetID, Changes: changes})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+type MockAuditLog struct {
+	mock.Mock
+}
+
+func (m *MockAuditLog) Append(ctx context.Context, entries ...audit.Entry) error {
+	args := m.Called(ctx, entries)
+	return args.Error(0)
+}
+
+func (m *MockAuditLog) Redact(ctx context.Context, actorID, pseudonym string) (int64, error) {
+	args := m.Called(ctx, actorID, pseudonym)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuditLog) List(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]audit.Entry), args.Error(1)
+}
+
+func (m *MockAuditLog) Export(ctx context.Context, f audit.Filter) (iter.Seq2[audit.Entry, error], error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(iter.Seq2[audit.Entry, error]), args.Error(1)
+}
+
+// MockAuditRecorder collects the recorded entries.
+type MockAuditRecorder struct {
+	Entries []audit.Entry
+}
+
+func (m *MockAuditRecorder) Record(ctx context.Context, entry audit.Entry) {
+	m.Entries = append(m.Entries, entry)
+}
+
+func newAuditTestService() (*AuditService, *MockAuditLog, *MockUserRepository) {
+	log, users := new(MockAuditLog), new(MockUserRepository)
+	return NewAuditService(log, users, slog.New(slog.NewTextHandler(io.Discard, nil))), log, users
+}
+
+func TestAuditService_Record(t *testing.T) {
+	ctx := audit.WithMetadata(context.Background(), audit.Metadata{RequestID: "r1", IP: "192.0.2.1", UserAgent: "curl"})
+
+	t.Run("adds the request metadata", func(t *testing.T) {
+		svc, log, _ := newAuditTestService()
+		log.On("Append", mock.Anything, mock.MatchedBy(func(es []audit.Entry) bool {
+			e := es[0]
+			return len(es) == 1 && e.Action == audit.ActionSignup && e.ActorID == "u1" &&
+				e.RequestID == "r1" && e.IP == "192.0.2.1" && e.UserAgent == "curl" && !e.At.IsZero()
+		})).Return(nil).Once()
+
+		svc.Record(ctx, audit.Entry{ActorID: "u1", Action: audit.ActionSignup})
+		log.AssertExpectations(t)
+	})
+
+	t.Run("failure does not reach the caller", func(t *testing.T) {
+		svc, log, _ := newAuditTestService()
+		log.On("Append", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+
+		assert.NotPanics(t, func() { svc.Record(ctx, audit.Entry{Action: audit.ActionLoginFailed}) })
+	})
+}
+
+func TestAuditService_Mine(t *testing.T) {
+	svc, log, _ := newAuditTestService()
+	log.On("List", mock.Anything, audit.Filter{ActorID: "u1", Limit: audit.DefaultLimit}).Return([]audit.Entry{{ID: 1}}, nil).Once()
+
+	entries, err := svc.Mine(context.Background(), "u1", audit.Filter{ActorID: "someone-else"})
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	log.AssertExpectations(t)
+}
+
+func TestAuditService_Search(t *testing.T) {
+	t.Run("admins see every entry", func(t *testing.T) {
+		svc, log, users := newAuditTestService()
+		users.On("FindByID", mock.Anything, "admin1").Return(auth.User{ID: "admin1", Roles: []string{auth.RoleUser, auth.RoleAdmin}}, nil).Once()
+		log.On("List", mock.Anything, audit.Filter{ActorID: "u2", Limit: 10}).Return([]audit.Entry{}, nil).Once()
+
+		_, err := svc.Search(context.Background(), "admin1", audit.Filter{ActorID: "u2", Limit: 10})
+
+		assert.NoError(t, err)
+		log.AssertExpectations(t)
+	})
+
+	t.Run("other users are refused", func(t *testing.T) {
+		svc, log, users := newAuditTestService()
+		users.On("FindByID", mock.Anything, "u1").Return(auth.User{ID: "u1", Roles: []string{auth.RoleUser}}, nil).Once()
+		users.On("FindByID", mock.Anything, "gone").Return(auth.User{}, auth.ErrUserNotFound).Once()
+
+		_, err := svc.Search(context.Background(), "u1", audit.Filter{})
+		assert.ErrorIs(t, err, audit.ErrForbidden)
+		_, err = svc.Export(context.Background(), "gone", audit.Filter{})
+		assert.ErrorIs(t, err, audit.ErrForbidden)
+		log.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		log.AssertNotCalled(t, "Export", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		svc, _, users := newAuditTestService()
+		users.On("FindByID", mock.Anything, "admin1").Return(auth.User{ID: "admin1", Roles: []string{auth.RoleAdmin}}, nil).Once()
+
+		_, err := svc.Search(context.Background(), "admin1", audit.Filter{Action: "nope"})
+		assert.ErrorIs(t, err, audit.ErrInvalidFilter)
+	})
+}
+
+func TestService_Audit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	userID := "user1"
+	before := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a1", UserID: userID, Type: favorites.AssetTypeInsight, Name: "n", Description: "old"}}
+	after := before
+	after.Description = "new"
+
+	t.Run("update records the changed fields", func(t *testing.T) {
+		repo, cache, rec := new(MockRepository), new(MockCache), new(MockAuditRecorder)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), logger).WithAudit(rec)
+		repo.On("FindByID", mock.Anything, "a1").Return(before, nil).Once()
+		repo.On("UpdateDescription", mock.Anything, "a1", "new").Return(after, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+		cache.On("Remove", mock.Anything, "a1").Return(nil).Once()
+
+		_, err := svc.UpdateDescription(context.Background(), "a1", "new", userID)
+
+		assert.NoError(t, err)
+		if assert.Len(t, rec.Entries, 1) {
+			e := rec.Entries[0]
+			assert.Equal(t, audit.ActionFavoriteUpdated, e.Action)
+			assert.Equal(t, userID, e.ActorID)
+			assert.Equal(t, "a1", e.TargetID)
+			assert.Equal(t, map[string]audit.Change{
+				"description": {Before: json.RawMessage(`"old"`), After: json.RawMessage(`"new"`)},
+			}, e.Changes)
+		}
+	})
+
+	t.Run("failed delete is not recorded", func(t *testing.T) {
+		repo, rec := new(MockRepository), new(MockAuditRecorder)
+		svc := NewService(repo, new(MockCache), new(MockEnricherRegistry), new(MockQueue), logger).WithAudit(rec)
+		repo.On("FindByID", mock.Anything, "a1").Return(before, nil).Once()
+		repo.On("Delete", mock.Anything, "a1").Return(errors.New("db down")).Once()
+
+		assert.Error(t, svc.Delete(context.Background(), "a1", userID))
+		assert.Empty(t, rec.Entries)
+	})
+}
+
+func TestAuthService_Audit(t *testing.T) {
+	users, rec := new(MockUserRepository), new(MockAuditRecorder)
+	svc := NewAuthService(users, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"}).WithAudit(rec)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	users.On("FindByEmail", mock.Anything, "known@example.com").Return(auth.User{ID: "u1", PasswordHash: string(hashed)}, nil)
+	users.On("FindByEmail", mock.Anything, "unknown@example.com").Return(auth.User{}, auth.ErrUserNotFound)
+
+	_, err := svc.Login(context.Background(), "known@example.com", "wrong", auth.ClientInfo{})
+	assert.Error(t, err)
+	_, err = svc.Login(context.Background(), "unknown@example.com", "wrong", auth.ClientInfo{})
+	assert.Error(t, err)
+
+	assert.Equal(t, []audit.Entry{
+		{ActorID: "u1", Action: audit.ActionLoginFailed},
+		{Action: audit.ActionLoginFailed},
+	}, rec.Entries)
+}
@@ -3,58 +3,385 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"go-favorites-app/internal/core/domain/audit"
 	"go-favorites-app/internal/core/domain/auth"
 	"go-favorites-app/internal/core/ports"
 )
 
+const (
+	passwordResetTTL = 1 * time.Hour
+	verifyEmailTTL   = 48 * time.Hour
+)
+
+// AuthConfig holds the settings the authentication service depends on.
+type AuthConfig struct {
+	JWTSecret string
+	// BaseURL is the public URL used to build links in outgoing emails.
+	BaseURL string
+	// RequireVerifiedEmail blocks favorite creation until the user verifies their email.
+	RequireVerifiedEmail bool
+	// PasswordPolicy is enforced on signup and password reset.
+	PasswordPolicy auth.PasswordPolicy
+}
+
 type AuthService struct {
 	repo      ports.UserRepository
+	sessions  ports.SessionRepository
+	mailer    ports.Mailer
 	jwtSecret []byte
+	baseURL   string
+	policy    auth.PasswordPolicy
+
+	requireVerified bool
+	audit           ports.AuditRecorder
+
+	// sessionCache, when set, learns of the sessions a password change revokes.
+	sessionCache ports.SessionCache
+	logger       *slog.Logger
 }
 
-func NewAuthService(repo ports.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(repo ports.UserRepository, sessions ports.SessionRepository, mailer ports.Mailer, cfg AuthConfig) *AuthService {
 	return &AuthService{
-		repo:      repo,
-		jwtSecret: []byte(jwtSecret),
+		repo:            repo,
+		sessions:        sessions,
+		mailer:          mailer,
+		jwtSecret:       []byte(cfg.JWTSecret),
+		baseURL:         cfg.BaseURL,
+		policy:          cfg.PasswordPolicy,
+		requireVerified: cfg.RequireVerifiedEmail,
+		logger:          slog.Default(),
+	}
+}
+
+// WithAudit records signups and logins in the audit log.
+func (s *AuthService) WithAudit(rec ports.AuditRecorder) *AuthService {
+	s.audit = rec
+	return s
+}
+
+// WithSessionCache records the sessions revoked by a password reset or
+// change in the cache that authenticated requests consult, so they stop
+// working at once on every instance.
+func (s *AuthService) WithSessionCache(cache ports.SessionCache, logger *slog.Logger) *AuthService {
+	s.sessionCache = cache
+	s.logger = logger
+	return s
+}
+
+func (s *AuthService) record(ctx context.Context, action audit.Action, actorID string) {
+	if s.audit != nil {
+		s.audit.Record(ctx, audit.Entry{ActorID: actorID, Action: action})
 	}
 }
 
 func (s *AuthService) SignUp(ctx context.Context, email, password string) error {
+	user := auth.User{
+		ID:    uuid.New().String(),
+		Email: auth.NormalizeEmail(email),
+	}
+	if err := user.Validate(); err != nil {
+		return err
+	}
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
+	user.PasswordHash = string(hashed)
 
-	user := auth.User{
-		ID:           uuid.New().String(),
-		Email:        email,
-		PasswordHash: string(hashed),
+	// The repository reports duplicates (case-insensitively) as auth.ErrEmailTaken.
+	if err := s.repo.Save(ctx, user); err != nil {
+		return err
 	}
+	s.record(ctx, audit.ActionSignup, user.ID)
 
-	return s.repo.Save(ctx, user)
+	// The account exists at this point; a mail outage must not fail the signup.
+	_ = s.sendVerification(ctx, user)
+	return nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := s.repo.FindByEmail(ctx, email)
+func (s *AuthService) Login(ctx context.Context, email, password string, client auth.ClientInfo) (string, error) {
+	user, err := s.repo.FindByEmail(ctx, auth.NormalizeEmail(email))
 	if err != nil {
+		s.record(ctx, audit.ActionLoginFailed, "")
 		return "", errors.New("invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.record(ctx, audit.ActionLoginFailed, user.ID)
 		return "", errors.New("invalid credentials")
 	}
 
+	now := time.Now()
+	session := auth.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(sessionTTL),
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return "", err
+	}
+
 	// Generate JWT
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": user.ID,
-		"exp": time.Now().Add(2 * time.Hour).Unix(),
+		"sid": session.ID,
+		"exp": session.ExpiresAt.Unix(),
+	})
+
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return "", err
+	}
+	s.record(ctx, audit.ActionLoginSucceeded, user.ID)
+	return signed, nil
+}
+
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, auth.NormalizeEmail(email))
+	if err != nil {
+		// Do not leak which addresses are registered.
+		return nil
+	}
+
+	token, err := s.issueActionToken(user, auth.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, ports.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password for this account. If it was you, submit the token below "+
+			"together with your new password to %s. It expires in one hour.\n\n%s\n", s.baseURL+"/password/reset", token),
+	})
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	claims, err := s.parseActionToken(token, auth.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	// As for VerifyEmail, the link is only good for the address it was sent
+	// to: a reset mailed before ChangeEmail must not work afterwards.
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email != claims.Email {
+		return auth.ErrInvalidToken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.ConsumeToken(ctx, claims); err != nil {
+		return err
+	}
+	// Whoever holds a session may be the reason for the reset: end them all
+	// before the new password takes effect, so a failure leaves no old
+	// session alive next to it.
+	if _, err := revokeSessions(ctx, s.sessions, s.sessionCache, s.logger, claims.UserID, ""); err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, claims.UserID, string(hashed))
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.parseActionToken(token, auth.TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	// The link only proves ownership of the address it was sent to.
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email != claims.Email {
+		return auth.ErrInvalidToken
+	}
+
+	if err := s.repo.ConsumeToken(ctx, claims); err != nil {
+		return err
+	}
+	return s.repo.MarkEmailVerified(ctx, user.ID, time.Now())
+}
+
+// ResendVerification sends a new verification link, unless the user's
+// address is verified already.
+func (s *AuthService) ResendVerification(ctx context.Context, userID string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsVerified() {
+		return nil
+	}
+	return s.sendVerification(ctx, user)
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) error {
+	if _, err := s.recheckPassword(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if _, err := revokeSessions(ctx, s.sessions, s.sessionCache, s.logger, userID, sessionID); err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, userID, string(hashed))
+}
+
+func (s *AuthService) ChangeEmail(ctx context.Context, userID, password, newEmail string) error {
+	user, err := s.recheckPassword(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+
+	email := auth.NormalizeEmail(newEmail)
+	if err := auth.ValidateEmail(email); err != nil {
+		return err
+	}
+	if email == user.Email {
+		return nil
+	}
+
+	if err := s.repo.UpdateEmail(ctx, userID, email); err != nil {
+		return err
+	}
+
+	// Verification links issued for the old address stop working because
+	// VerifyEmail checks the address embedded in the token.
+	user.Email = email
+	_ = s.sendVerification(ctx, user)
+	return nil
+}
+
+// recheckPassword confirms the caller knows the current password before a sensitive change.
+func (s *AuthService) recheckPassword(ctx context.Context, userID, password string) (auth.User, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return auth.User{}, err
+	}
+	if err := checkPassword(user, password); err != nil {
+		return auth.User{}, err
+	}
+	return user, nil
+}
+
+// checkPassword compares a plaintext password with the user's stored hash.
+func checkPassword(user auth.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return auth.ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (s *AuthService) EnsureVerified(ctx context.Context, userID string) error {
+	if !s.requireVerified {
+		return nil
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsVerified() {
+		return auth.ErrEmailNotVerified
+	}
+	return nil
+}
+
+func (s *AuthService) sendVerification(ctx context.Context, user auth.User) error {
+	token, err := s.issueActionToken(user, auth.TokenPurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, ports.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Confirm your email address by opening the link below:\n\n%s\n", s.link("/verify-email", token)),
 	})
+}
+
+func (s *AuthService) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
 
+// issueActionToken signs a short-lived token scoped to a single purpose.
+// The jti claim lets the repository enforce single use.
+func (s *AuthService) issueActionToken(user auth.User, purpose auth.TokenPurpose, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti":     uuid.NewString(),
+		"sub":     user.ID,
+		"email":   user.Email,
+		"purpose": string(purpose),
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
 	return token.SignedString(s.jwtSecret)
 }
+
+func (s *AuthService) parseActionToken(tokenString string, purpose auth.TokenPurpose) (auth.ActionToken, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return auth.ActionToken{}, auth.ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return auth.ActionToken{}, auth.ErrInvalidToken
+	}
+
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	p, _ := claims["purpose"].(string)
+	if jti == "" || sub == "" || auth.TokenPurpose(p) != purpose {
+		return auth.ActionToken{}, auth.ErrInvalidToken
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return auth.ActionToken{}, auth.ErrInvalidToken
+	}
+
+	return auth.ActionToken{
+		ID:        jti,
+		UserID:    sub,
+		Email:     email,
+		Purpose:   purpose,
+		ExpiresAt: exp.Time,
+	}, nil
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -27,9 +30,64 @@ func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (aut
 	return args.Get(0).(auth.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByID(ctx context.Context, id string) (auth.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(auth.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, user auth.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, userID, email string) error {
+	args := m.Called(ctx, userID, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	args := m.Called(ctx, userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, userID string, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ConsumeToken(ctx context.Context, token auth.ActionToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg ports.MailMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+// tokenFrom extracts the signed token from the last line of a mail body.
+func tokenFrom(body string) string {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	last := lines[len(lines)-1]
+	if i := strings.Index(last, "token="); i >= 0 {
+		return last[i+len("token="):]
+	}
+	return last
+}
+
 func TestAuthService_SignUp(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	svc := NewAuthService(mockRepo, "secret")
+	mailer := new(MockMailer)
+	svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret", BaseURL: "http://api.test"})
 
 	t.Run("success", func(t *testing.T) {
 		email := "test@example.com"
@@ -38,34 +96,97 @@ func TestAuthService_SignUp(t *testing.T) {
 		mockRepo.On("Save", mock.Anything, mock.MatchedBy(func(u auth.User) bool {
 			return u.Email == email && u.ID != "" && u.PasswordHash != ""
 		})).Return(nil)
+		mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg ports.MailMessage) bool {
+			return msg.To == email && strings.Contains(msg.Body, "http://api.test/verify-email?token=")
+		})).Return(nil).Once()
 
 		err := svc.SignUp(context.Background(), email, password)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("mail failure does not fail signup", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+		mailer.On("Send", mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		err := svc.SignUp(context.Background(), "test@example.com", "password123")
+		assert.NoError(t, err)
 	})
 
 	t.Run("repo error", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		svc := NewAuthService(mockRepo, "secret")
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
 		mockRepo.On("Save", mock.Anything, mock.Anything).Return(errors.New("db error"))
 
-		err := svc.SignUp(context.Background(), "test@example.com", "pass")
+		err := svc.SignUp(context.Background(), "test@example.com", "password123")
 		assert.Error(t, err)
 	})
+
+	t.Run("normalizes email", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("Save", mock.Anything, mock.MatchedBy(func(u auth.User) bool {
+			return u.Email == "mixed@example.com"
+		})).Return(nil).Once()
+		mailer.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+		err := svc.SignUp(context.Background(), " Mixed@Example.com ", "password123")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		policy, _ := auth.NewPasswordPolicy(8, strings.NewReader("password123\n"))
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret", PasswordPolicy: policy})
+
+		for _, tc := range []struct{ email, password string }{
+			{"", "password456"},
+			{"not-an-email", "password456"},
+			{"test@example.com", "short"},
+			{"test@example.com", strings.Repeat("x", 73)},
+			{"test@example.com", "Password123"},
+		} {
+			err := svc.SignUp(context.Background(), tc.email, tc.password)
+			assert.ErrorIs(t, err, auth.ErrValidation, "email=%q password=%q", tc.email, tc.password)
+		}
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(auth.ErrEmailTaken)
+
+		err := svc.SignUp(context.Background(), "taken@example.com", "password123")
+		assert.ErrorIs(t, err, auth.ErrEmailTaken)
+	})
 }
 
 func TestAuthService_Login(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	svc := NewAuthService(mockRepo, "mysecret")
+	sessions := new(MockSessionRepository)
+	svc := NewAuthService(mockRepo, sessions, new(MockMailer), AuthConfig{JWTSecret: "mysecret"})
 
 	password := "password123"
 	hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	user := auth.User{ID: "user1", Email: "test@example.com", PasswordHash: string(hashed)}
+	client := auth.ClientInfo{UserAgent: "curl/8.0", IP: "192.0.2.1"}
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
+		var created auth.Session
+		sessions.On("Create", mock.Anything, mock.MatchedBy(func(s auth.Session) bool {
+			created = s
+			return s.UserID == "user1" && s.UserAgent == "curl/8.0" && s.IP == "192.0.2.1"
+		})).Return(nil).Once()
 
-		token, err := svc.Login(context.Background(), "test@example.com", password)
+		token, err := svc.Login(context.Background(), "test@example.com", password, client)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 
@@ -76,13 +197,15 @@ func TestAuthService_Login(t *testing.T) {
 		claims, ok := parsedToken.Claims.(jwt.MapClaims)
 		assert.True(t, ok)
 		assert.Equal(t, "user1", claims["sub"])
+		assert.Equal(t, created.ID, claims["sid"])
+		sessions.AssertExpectations(t)
 	})
 
 	t.Run("invalid credentials - wrong password", func(t *testing.T) {
 		// Expect FindByEmail but validation fails after
 		mockRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(user, nil)
 
-		token, err := svc.Login(context.Background(), "test@example.com", "wrongpass")
+		token, err := svc.Login(context.Background(), "test@example.com", "wrongpass", client)
 		assert.Error(t, err)
 		assert.Equal(t, "invalid credentials", err.Error())
 		assert.Empty(t, token)
@@ -91,9 +214,252 @@ func TestAuthService_Login(t *testing.T) {
 	t.Run("invalid credentials - user not found", func(t *testing.T) {
 		mockRepo.On("FindByEmail", mock.Anything, "unknown@example.com").Return(auth.User{}, errors.New("not found"))
 
-		token, err := svc.Login(context.Background(), "unknown@example.com", "pass")
+		token, err := svc.Login(context.Background(), "unknown@example.com", "pass", client)
 		assert.Error(t, err)
 		assert.Equal(t, "invalid credentials", err.Error())
 		assert.Empty(t, token)
 	})
 }
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	user := auth.User{ID: "user1", Email: "test@example.com"}
+
+	t.Run("unknown email is silently ignored", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByEmail", mock.Anything, "ghost@example.com").Return(auth.User{}, auth.ErrUserNotFound)
+
+		err := svc.RequestPasswordReset(context.Background(), "ghost@example.com")
+		assert.NoError(t, err)
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("emailed token resets the password once", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		sessions := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewAuthService(mockRepo, sessions, mailer, AuthConfig{JWTSecret: "secret"}).WithSessionCache(cache, quietLogger)
+
+		var sent ports.MailMessage
+		mockRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(ports.MailMessage)
+		}).Return(nil)
+
+		assert.NoError(t, svc.RequestPasswordReset(context.Background(), user.Email))
+		token := tokenFrom(sent.Body)
+
+		mockRepo.On("ConsumeToken", mock.Anything, mock.MatchedBy(func(tok auth.ActionToken) bool {
+			return tok.UserID == user.ID && tok.Purpose == auth.TokenPurposePasswordReset
+		})).Return(nil).Once()
+		stolen := auth.Session{ID: "s1", UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		sessions.On("RevokeAllExcept", mock.Anything, user.ID, "", mock.Anything).Return([]auth.Session{stolen}, nil).Once()
+		cache.On("SetSessionState", mock.Anything, "s1", auth.SessionRevoked, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdatePassword", mock.Anything, user.ID, mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("newPassword1")) == nil
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.ResetPassword(context.Background(), token, "newPassword1"))
+		sessions.AssertExpectations(t)
+		cache.AssertExpectations(t)
+
+		// Second use is rejected by the repository
+		mockRepo.On("ConsumeToken", mock.Anything, mock.Anything).Return(auth.ErrTokenUsed).Once()
+		err := svc.ResetPassword(context.Background(), token, "newPassword2")
+		assert.ErrorIs(t, err, auth.ErrTokenUsed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("new password must satisfy the policy", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, _ := svc.issueActionToken(user, auth.TokenPurposePasswordReset, time.Hour)
+
+		err := svc.ResetPassword(context.Background(), token, "short")
+		assert.ErrorIs(t, err, auth.ErrValidation)
+		mockRepo.AssertNotCalled(t, "ConsumeToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("email changed since the link was sent", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, _ := svc.issueActionToken(user, auth.TokenPurposePasswordReset, time.Hour)
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(auth.User{ID: user.ID, Email: "new@example.com"}, nil)
+
+		err := svc.ResetPassword(context.Background(), token, "newPassword1")
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		mockRepo.AssertNotCalled(t, "ConsumeToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects tokens for another purpose", func(t *testing.T) {
+		svc := NewAuthService(new(MockUserRepository), new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, err := svc.issueActionToken(user, auth.TokenPurposeVerifyEmail, time.Hour)
+		assert.NoError(t, err)
+
+		err = svc.ResetPassword(context.Background(), token, "newPassword1")
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		svc := NewAuthService(new(MockUserRepository), new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, err := svc.issueActionToken(user, auth.TokenPurposePasswordReset, -time.Minute)
+		assert.NoError(t, err)
+
+		err = svc.ResetPassword(context.Background(), token, "newPassword1")
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	user := auth.User{ID: "user1", Email: "test@example.com"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, _ := svc.issueActionToken(user, auth.TokenPurposeVerifyEmail, time.Hour)
+
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		mockRepo.On("ConsumeToken", mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("MarkEmailVerified", mock.Anything, user.ID, mock.Anything).Return(nil)
+
+		assert.NoError(t, svc.VerifyEmail(context.Background(), token))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("email changed since the link was sent", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		token, _ := svc.issueActionToken(user, auth.TokenPurposeVerifyEmail, time.Hour)
+
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(auth.User{ID: user.ID, Email: "new@example.com"}, nil)
+
+		err := svc.VerifyEmail(context.Background(), token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		mockRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_EnsureVerified(t *testing.T) {
+	t.Run("not enforced", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		assert.NoError(t, svc.EnsureVerified(context.Background(), "user1"))
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret", RequireVerifiedEmail: true})
+		mockRepo.On("FindByID", mock.Anything, "unverified").Return(auth.User{ID: "unverified"}, nil)
+		mockRepo.On("FindByID", mock.Anything, "verified").Return(auth.User{ID: "verified", EmailVerifiedAt: time.Now()}, nil)
+
+		assert.ErrorIs(t, svc.EnsureVerified(context.Background(), "unverified"), auth.ErrEmailNotVerified)
+		assert.NoError(t, svc.EnsureVerified(context.Background(), "verified"))
+	})
+}
+
+func TestAuthService_ResendVerification(t *testing.T) {
+	t.Run("unverified", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, "user1").Return(auth.User{ID: "user1", Email: "test@example.com"}, nil)
+		mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg ports.MailMessage) bool {
+			return msg.To == "test@example.com" && strings.Contains(msg.Body, "/verify-email?token=")
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.ResendVerification(context.Background(), "user1"))
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("already verified", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, "user1").Return(auth.User{ID: "user1", EmailVerifiedAt: time.Now()}, nil)
+
+		assert.NoError(t, svc.ResendVerification(context.Background(), "user1"))
+		mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("currentPass1"), bcrypt.MinCost)
+	user := auth.User{ID: "user1", Email: "test@example.com", PasswordHash: string(hashed)}
+
+	t.Run("success logs out the other sessions", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		sessions := new(MockSessionRepository)
+		svc := NewAuthService(mockRepo, sessions, new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		sessions.On("RevokeAllExcept", mock.Anything, user.ID, "current", mock.Anything).Return([]auth.Session{}, nil).Once()
+		mockRepo.On("UpdatePassword", mock.Anything, user.ID, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, svc.ChangePassword(context.Background(), user.ID, "current", "currentPass1", "brandNewPass1"))
+		mockRepo.AssertExpectations(t)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("keeps the old password if sessions cannot be revoked", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		sessions := new(MockSessionRepository)
+		svc := NewAuthService(mockRepo, sessions, new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		sessions.On("RevokeAllExcept", mock.Anything, user.ID, "current", mock.Anything).Return([]auth.Session(nil), errors.New("db down"))
+
+		assert.Error(t, svc.ChangePassword(context.Background(), user.ID, "current", "currentPass1", "brandNewPass1"))
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+
+		err := svc.ChangePassword(context.Background(), user.ID, "current", "guess", "brandNewPass1")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_ChangeEmail(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("currentPass1"), bcrypt.MinCost)
+	user := auth.User{ID: "user1", Email: "old@example.com", PasswordHash: string(hashed), EmailVerifiedAt: time.Now()}
+
+	t.Run("switches address and sends verification", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mailer := new(MockMailer)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), mailer, AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		mockRepo.On("UpdateEmail", mock.Anything, user.ID, "new@example.com").Return(nil).Once()
+		mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg ports.MailMessage) bool {
+			return msg.To == "new@example.com"
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.ChangeEmail(context.Background(), user.ID, "currentPass1", "New@Example.com"))
+		mockRepo.AssertExpectations(t)
+		mailer.AssertExpectations(t)
+	})
+
+	t.Run("address taken", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+		mockRepo.On("UpdateEmail", mock.Anything, user.ID, "taken@example.com").Return(auth.ErrEmailTaken)
+
+		err := svc.ChangeEmail(context.Background(), user.ID, "currentPass1", "taken@example.com")
+		assert.ErrorIs(t, err, auth.ErrEmailTaken)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewAuthService(mockRepo, new(MockSessionRepository), new(MockMailer), AuthConfig{JWTSecret: "secret"})
+		mockRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+
+		err := svc.ChangeEmail(context.Background(), user.ID, "currentPass1", "nope")
+		assert.ErrorIs(t, err, auth.ErrValidation)
+	})
+}
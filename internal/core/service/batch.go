@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SaveBatch saves the user's assets in one transaction and returns the
+// outcome of each, in order. Each asset is validated and checked against the
+// quota as Save does; in favorites.BatchAtomic mode any failure leaves every
+// asset unsaved.
+func (s *Service) SaveBatch(ctx context.Context, userID string, assets []favorites.Asset, mode favorites.BatchMode) ([]favorites.BatchResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.SaveBatch", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.Int("batch.size", len(assets)),
+		attribute.String("batch.mode", string(mode)),
+	))
+	defer span.End()
+
+	// 1. Validate each asset on its own
+	now := time.Now()
+	results := make([]favorites.BatchResult, len(assets))
+	var candidates []favorites.Asset
+	var indexes []int
+	var sizes []int64
+	for i, asset := range assets {
+		results[i].ID = asset.GetID()
+		prepared, size, err := s.prepare(asset, now)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		candidates = append(candidates, prepared)
+		indexes = append(indexes, i)
+		sizes = append(sizes, size)
+	}
+	if mode == favorites.BatchAtomic && favorites.Failed(results) {
+		favorites.Abort(results)
+		countBatch("create", results)
+		return results, nil
+	}
+
+	// 2. Save DB, with the events, within the user's quota
+	var saved []favorites.Asset
+	err := s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		var usage favorites.Usage
+		if s.quota.MaxFavorites > 0 || s.quota.MaxBytes > 0 {
+			var err error
+			if usage, err = repo.Usage(ctx, userID); err != nil {
+				return err
+			}
+		}
+		var batch []favorites.Asset
+		var batchIndexes []int
+		for j, asset := range candidates {
+			if err := s.quota.Check(usage, sizes[j]); err != nil {
+				var qerr *favorites.QuotaError
+				if errors.As(err, &qerr) {
+					quotaRejections.WithLabelValues(qerr.Reason).Inc()
+				}
+				results[indexes[j]].Err = err
+				continue
+			}
+			usage.Favorites++
+			usage.Bytes += sizes[j]
+			batch = append(batch, asset)
+			batchIndexes = append(batchIndexes, indexes[j])
+		}
+		if mode == favorites.BatchAtomic && favorites.Failed(results) {
+			return favorites.ErrBatchAborted
+		}
+
+		created, err := repo.SaveBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		events := make([]favorites.Event, 0, len(batch))
+		for j, ok := range created {
+			if !ok {
+				results[batchIndexes[j]].Err = favorites.ErrAlreadyExists
+				continue
+			}
+			results[batchIndexes[j]].Action = favorites.ActionCreated
+			saved = append(saved, batch[j])
+			events = append(events, favorites.NewEvent(favorites.EventCreated, batch[j], now))
+		}
+		if mode == favorites.BatchAtomic && len(saved) < len(batch) {
+			return favorites.ErrBatchAborted
+		}
+		return outbox.Add(ctx, events...)
+	})
+	if errors.Is(err, favorites.ErrBatchAborted) {
+		favorites.Abort(results)
+		countBatch("create", results)
+		return results, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to save batch to db: %w", err)
+	}
+	countBatch("create", results)
+	for _, asset := range saved {
+		recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteCreated, userID, nil, asset)
+	}
+
+	// 3. Write-Through in one round trip, enrichment in the background
+	s.cacheAndEnqueueBatch(ctx, saved)
+	return results, nil
+}
+
+// prepare validates an asset as Save does and returns it as it is stored,
+// pending enrichment, with its stored size.
+func (s *Service) prepare(asset favorites.Asset, now time.Time) (favorites.Asset, int64, error) {
+	if err := asset.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := s.quota.CheckFields(asset); err != nil {
+		quotaRejections.WithLabelValues(favorites.QuotaReasonFieldLength).Inc()
+		return nil, 0, err
+	}
+	size, err := favorites.StoredSize(asset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to measure asset: %w", err)
+	}
+	return favorites.WithUpdatedAt(favorites.WithEnrichment(asset, favorites.PendingEnrichment(now)), now), size, nil
+}
+
+// DeleteBatch deletes the user's assets among ids in one transaction and
+// returns the outcome of each, in order. Missing assets are reported as
+// favorites.ErrNotFound and those of other users as favorites.ErrForbidden;
+// the others are deleted regardless.
+func (s *Service) DeleteBatch(ctx context.Context, userID string, ids []string) ([]favorites.BatchResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.DeleteBatch", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.Int("batch.size", len(ids)),
+	))
+	defer span.End()
+
+	// Verify ownership
+	found, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var owned []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if asset, ok := found[id]; ok && asset.GetUserID() == userID && !seen[id] {
+			owned = append(owned, id)
+			seen[id] = true
+		}
+	}
+
+	var deleted []string
+	if len(owned) > 0 {
+		err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+			var err error
+			if deleted, err = repo.DeleteBatch(ctx, userID, owned); err != nil {
+				return err
+			}
+			now := time.Now()
+			events := make([]favorites.Event, len(deleted))
+			for i, id := range deleted {
+				events[i] = favorites.NewEvent(favorites.EventDeleted, found[id], now)
+			}
+			return outbox.Add(ctx, events...)
+		})
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	gone := make(map[string]bool, len(deleted))
+	for _, id := range deleted {
+		gone[id] = true
+		recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteDeleted, userID, found[id], nil)
+	}
+	results := make([]favorites.BatchResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		asset, ok := found[id]
+		switch {
+		case gone[id]:
+			results[i].Action = favorites.ActionDeleted
+		case ok && asset.GetUserID() != userID:
+			results[i].Err = favorites.ErrForbidden
+		default:
+			// Missing, or deleted by another request meanwhile.
+			results[i].Err = favorites.ErrNotFound
+		}
+	}
+	countBatch("delete", results)
+
+	if len(deleted) == 0 {
+		return results, nil
+	}
+	// The rows are gone; the outbox relay removes the entries if this fails.
+	if err := s.cache.RemoveBatch(ctx, deleted); err != nil {
+		s.logger.Error("failed to remove deleted assets from cache", "count", len(deleted), "error", err)
+	}
+	return results, nil
+}
+
+// UpdateBatch sets the description of the user's assets in one transaction
+// and returns the outcome of each update, in order. Each description is
+// checked against the field limits and the byte quota as UpdateDescription
+// does, and refused ones are reported as *favorites.QuotaError. Missing assets are
+// reported as favorites.ErrNotFound and those of other users as
+// favorites.ErrForbidden; the others are updated regardless, in order, so
+// the last update of an asset listed twice wins.
+func (s *Service) UpdateBatch(ctx context.Context, userID string, updates []favorites.DescriptionUpdate) ([]favorites.BatchResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateBatch", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.Int("batch.size", len(updates)),
+	))
+	defer span.End()
+
+	ids := make([]string, len(updates))
+	for i, u := range updates {
+		ids[i] = u.ID
+	}
+	// Verify ownership
+	found, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]favorites.BatchResult, len(updates))
+	// before and after hold each update applied, for the audit log.
+	var before, after []favorites.Asset
+	err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		before, after = nil, nil
+		var usage favorites.Usage
+		if s.quota.MaxBytes > 0 {
+			var err error
+			if usage, err = repo.Usage(ctx, userID); err != nil {
+				return err
+			}
+		}
+		current := maps.Clone(found)
+		var events []favorites.Event
+		for i, u := range updates {
+			results[i] = favorites.BatchResult{ID: u.ID}
+			asset, ok := current[u.ID]
+			switch {
+			case !ok:
+				results[i].Err = favorites.ErrNotFound
+				continue
+			case asset.GetUserID() != userID:
+				results[i].Err = favorites.ErrForbidden
+				continue
+			}
+			oldSize, newSize, err := s.checkDescription(asset, u.Description)
+			var qerr *favorites.QuotaError
+			switch {
+			case errors.As(err, &qerr):
+				results[i].Err = err
+				continue
+			case err != nil:
+				return err
+			}
+			if err := s.quota.CheckReplace(usage, oldSize, newSize); err != nil {
+				if errors.As(err, &qerr) {
+					quotaRejections.WithLabelValues(qerr.Reason).Inc()
+				}
+				results[i].Err = err
+				continue
+			}
+			updated, err := repo.UpdateDescription(ctx, u.ID, u.Description)
+			if errors.Is(err, favorites.ErrNotFound) {
+				// Deleted by another request meanwhile.
+				delete(current, u.ID)
+				results[i].Err = err
+				continue
+			} else if err != nil {
+				return err
+			}
+			results[i].Action = favorites.ActionUpdated
+			usage.Bytes += newSize - oldSize
+			before, after = append(before, asset), append(after, updated)
+			current[u.ID] = updated
+			events = append(events, favorites.NewEvent(favorites.EventUpdated, updated, updated.GetUpdatedAt()))
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	updated := make([]string, 0, len(after))
+	seen := make(map[string]bool, len(after))
+	for i := range after {
+		recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteUpdated, userID, before[i], after[i])
+		if id := after[i].GetID(); !seen[id] {
+			updated = append(updated, id)
+			seen[id] = true
+		}
+	}
+	countBatch("update", results)
+
+	if len(updated) == 0 {
+		return results, nil
+	}
+	// The rows are updated; the outbox relay invalidates the entries if this
+	// fails.
+	if err := s.cache.RemoveBatch(ctx, updated); err != nil {
+		s.logger.Error("failed to invalidate updated assets in cache", "count", len(updated), "error", err)
+	}
+	return results, nil
+}
+
+// cacheAndEnqueueBatch writes the assets as stored in the database to the
+// cache in one round trip and schedules their enrichment. Neither step fails
+// the caller.
+func (s *Service) cacheAndEnqueueBatch(ctx context.Context, assets []favorites.Asset) {
+	if len(assets) == 0 {
+		return
+	}
+	ids := make([]string, len(assets))
+	entries := make(map[string][]byte, len(assets))
+	for i, asset := range assets {
+		ids[i] = asset.GetID()
+		data, err := json.Marshal(asset)
+		if err != nil {
+			s.logger.Error("failed to marshal asset for cache", "id", asset.GetID(), "error", err)
+			continue
+		}
+		entries[asset.GetID()] = data
+	}
+	if err := s.cache.AddBatch(ctx, entries, float64(time.Now().Unix())); err != nil {
+		s.logger.Error("failed to update cache", "count", len(entries), "error", err)
+	}
+	s.enqueue(ctx, ids...)
+}
+
+func countBatch(op string, results []favorites.BatchResult) {
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			batchItems.WithLabelValues(op, "applied").Inc()
+		case errors.Is(r.Err, favorites.ErrBatchAborted):
+			batchItems.WithLabelValues(op, "aborted").Inc()
+		default:
+			batchItems.WithLabelValues(op, "failed").Inc()
+		}
+	}
+}
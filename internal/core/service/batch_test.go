@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func batchInsight(id, content string) favorites.Insight {
+	return favorites.Insight{
+		BaseAsset: favorites.BaseAsset{ID: id, UserID: "user1", Name: "Test", Type: favorites.AssetTypeInsight},
+		Content:   content,
+	}
+}
+
+func TestService_SaveBatch(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
+
+	t.Run("best effort saves what it can", func(t *testing.T) {
+		repo, cache, queue := new(MockRepository), new(MockCache), new(MockQueue)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), queue, logger).WithQuota(favorites.Quota{MaxFavorites: 3})
+		assets := []favorites.Asset{batchInsight("1", "a"), batchInsight("2", ""), batchInsight("3", "c"), batchInsight("4", "d")}
+
+		repo.On("Usage", mock.Anything, "user1").Return(favorites.Usage{Favorites: 1}, nil).Once()
+		repo.On("SaveBatch", mock.Anything, mock.MatchedBy(func(batch []favorites.Asset) bool {
+			return len(batch) == 2 && batch[0].GetID() == "1" && batch[1].GetID() == "3" &&
+				batch[0].GetEnrichment().Status == favorites.EnrichmentPending
+		})).Return([]bool{true, false}, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 1 && events[0].AssetID == "1" && events[0].Type == favorites.EventCreated
+		})).Return(nil).Once()
+		cache.On("AddBatch", mock.Anything, mock.MatchedBy(func(entries map[string][]byte) bool {
+			_, ok := entries["1"]
+			return len(entries) == 1 && ok
+		}), mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"1"}).Return(nil).Once()
+
+		results, err := svc.SaveBatch(context.Background(), "user1", assets, favorites.BatchBestEffort)
+
+		assert.NoError(t, err)
+		if assert.Len(t, results, 4) {
+			assert.NoError(t, results[0].Err)
+			assert.ErrorIs(t, results[1].Err, favorites.ErrValidation)
+			assert.ErrorIs(t, results[2].Err, favorites.ErrAlreadyExists)
+			assert.ErrorIs(t, results[3].Err, favorites.ErrQuotaExceeded)
+			assert.Equal(t, "4", results[3].ID)
+		}
+		repo.AssertExpectations(t)
+		repo.Outbox.AssertExpectations(t)
+		cache.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("atomic refuses an invalid batch before the database", func(t *testing.T) {
+		repo := new(MockRepository)
+		svc := NewService(repo, new(MockCache), new(MockEnricherRegistry), new(MockQueue), logger)
+		assets := []favorites.Asset{batchInsight("1", "a"), batchInsight("2", "")}
+
+		results, err := svc.SaveBatch(context.Background(), "user1", assets, favorites.BatchAtomic)
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, favorites.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrValidation)
+		repo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic rolls back on a taken id", func(t *testing.T) {
+		repo, cache := new(MockRepository), new(MockCache)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), logger)
+		assets := []favorites.Asset{batchInsight("1", "a"), batchInsight("2", "b")}
+
+		repo.On("SaveBatch", mock.Anything, mock.Anything).Return([]bool{true, false}, nil).Once()
+
+		results, err := svc.SaveBatch(context.Background(), "user1", assets, favorites.BatchAtomic)
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, favorites.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrAlreadyExists)
+		repo.Outbox.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "AddBatch", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_DeleteBatch(t *testing.T) {
+	repo, cache := new(MockRepository), new(MockCache)
+	svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), slog.New(slog.NewTextHandler(&testWriter{}, nil)))
+	mine, theirs := batchInsight("1", "a"), batchInsight("2", "b")
+	theirs.UserID = "user2"
+
+	repo.On("FindByIDs", mock.Anything, []string{"1", "2", "3"}).Return(map[string]favorites.Asset{"1": mine, "2": theirs}, nil).Once()
+	repo.On("DeleteBatch", mock.Anything, "user1", []string{"1"}).Return([]string{"1"}, nil).Once()
+	repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+		return len(events) == 1 && events[0].AssetID == "1" && events[0].Type == favorites.EventDeleted
+	})).Return(nil).Once()
+	cache.On("RemoveBatch", mock.Anything, []string{"1"}).Return(nil).Once()
+
+	results, err := svc.DeleteBatch(context.Background(), "user1", []string{"1", "2", "3"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrForbidden)
+		assert.ErrorIs(t, results[2].Err, favorites.ErrNotFound)
+	}
+	repo.AssertExpectations(t)
+	repo.Outbox.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func TestService_UpdateBatch(t *testing.T) {
+	repo, cache := new(MockRepository), new(MockCache)
+	svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), slog.New(slog.NewTextHandler(&testWriter{}, nil)))
+	mine, theirs, gone := batchInsight("1", "a"), batchInsight("2", "b"), batchInsight("4", "d")
+	theirs.UserID = "user2"
+	updated := mine
+	updated.Description = "new"
+
+	repo.On("FindByIDs", mock.Anything, []string{"1", "2", "3", "4"}).Return(map[string]favorites.Asset{"1": mine, "2": theirs, "4": gone}, nil).Once()
+	repo.On("UpdateDescription", mock.Anything, "1", "new").Return(updated, nil).Once()
+	repo.On("UpdateDescription", mock.Anything, "4", "new").Return(nil, favorites.ErrNotFound).Once()
+	repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+		return len(events) == 1 && events[0].AssetID == "1" && events[0].Type == favorites.EventUpdated
+	})).Return(nil).Once()
+	cache.On("RemoveBatch", mock.Anything, []string{"1"}).Return(nil).Once()
+
+	results, err := svc.UpdateBatch(context.Background(), "user1", []favorites.DescriptionUpdate{
+		{ID: "1", Description: "new"}, {ID: "2", Description: "new"}, {ID: "3", Description: "new"}, {ID: "4", Description: "new"},
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 4) {
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, favorites.ActionUpdated, results[0].Action)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrForbidden)
+		assert.ErrorIs(t, results[2].Err, favorites.ErrNotFound)
+		assert.ErrorIs(t, results[3].Err, favorites.ErrNotFound)
+	}
+	repo.AssertExpectations(t)
+	repo.Outbox.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func TestService_UpdateBatch_Quota(t *testing.T) {
+	repo, cache := new(MockRepository), new(MockCache)
+	svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), slog.New(slog.NewTextHandler(&testWriter{}, nil))).WithQuota(favorites.Quota{
+		MaxBytes:        150,
+		MaxFieldLengths: map[favorites.AssetType]map[string]int{favorites.AnyType: {"description": 10}},
+	})
+	first, second, third := batchInsight("1", "a"), batchInsight("2", "b"), batchInsight("3", "c")
+	updated := first
+	updated.Description = "short"
+
+	repo.On("FindByIDs", mock.Anything, []string{"1", "2", "3"}).Return(map[string]favorites.Asset{"1": first, "2": second, "3": third}, nil).Once()
+	// 40 bytes of headroom: the first update grows its asset by 22 bytes and
+	// the third by 27, so each fits alone but not both.
+	repo.On("Usage", mock.Anything, "user1").Return(favorites.Usage{Favorites: 3, Bytes: 110}, nil).Once()
+	repo.On("UpdateDescription", mock.Anything, "1", "short").Return(updated, nil).Once()
+	repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+	cache.On("RemoveBatch", mock.Anything, []string{"1"}).Return(nil).Once()
+
+	results, err := svc.UpdateBatch(context.Background(), "user1", []favorites.DescriptionUpdate{
+		{ID: "1", Description: "short"}, {ID: "2", Description: "far too long"}, {ID: "3", Description: "0123456789"},
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrFieldTooLong)
+		assert.ErrorIs(t, results[2].Err, favorites.ErrQuotaExceeded)
+	}
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdateDescription", mock.Anything, "3", mock.Anything)
+}
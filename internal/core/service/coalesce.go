@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// fillTimeout bounds a coalesced cache fill. Fills are detached from the
+// request that started them, so that request going away does not fail the
+// others waiting on the same result.
+const fillTimeout = 5 * time.Second
+
+// fillPollInterval is how often a replica that lost the fill lock checks
+// whether the winner has filled the cache yet.
+const fillPollInterval = 25 * time.Millisecond
+
+// WithFillLock makes replicas coalesce asset cache fills through lock, in
+// addition to the in-process coalescing every Service does. A replica that
+// finds an asset being filled elsewhere waits up to ttl for the cache entry
+// before reading the database itself.
+func (s *Service) WithFillLock(lock ports.FillLock, ttl time.Duration) *Service {
+	s.fillLock = lock
+	s.fillLockTTL = ttl
+	return s
+}
+
+// coalesce runs fn once for all concurrent callers with the same key and
+// hands each of them the result.
+func (s *Service) coalesce(ctx context.Context, kind, key string, fn func(context.Context) (any, error)) (any, error) {
+	var ran bool
+	ch := s.flights.DoChan(key, func() (any, error) {
+		ran = true
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+		defer cancel()
+		return fn(ctx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if !ran {
+			cacheFillsCoalesced.WithLabelValues(kind, "local").Inc()
+		}
+		return res.Val, res.Err
+	}
+}
+
+// loadAsset reads an asset missing from the cache from the database and
+// read-repairs the cache entry.
+func (s *Service) loadAsset(ctx context.Context, id string) (favorites.Asset, error) {
+	v, err := s.coalesce(ctx, "asset", "asset:"+id, func(ctx context.Context) (any, error) {
+		return s.fillAsset(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(favorites.Asset), nil
+}
+
+func (s *Service) fillAsset(ctx context.Context, id string) (favorites.Asset, error) {
+	if s.fillLock != nil {
+		key := "asset:" + id
+		acquired, err := s.fillLock.TryLock(ctx, key, s.fillLockTTL)
+		switch {
+		case err != nil:
+			// Coalescing is an optimization; fill without it.
+			s.logger.Warn("failed to take cache fill lock", "id", id, "error", err)
+		case acquired:
+			defer func() {
+				if err := s.fillLock.Unlock(ctx, key); err != nil {
+					s.logger.Warn("failed to release cache fill lock", "id", id, "error", err)
+				}
+			}()
+		default:
+			if asset, ok := s.awaitFill(ctx, id); ok {
+				cacheFillsCoalesced.WithLabelValues("asset", "replica").Inc()
+				return asset, nil
+			}
+		}
+	}
+
+	asset, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Cache the stored copy and let the workers enrich it.
+	s.cacheAndEnqueue(ctx, asset)
+	return asset, nil
+}
+
+// awaitFill polls the cache while another replica fills it. It gives up
+// after the lock TTL, by which time the other replica has either filled the
+// entry or lost its lock.
+func (s *Service) awaitFill(ctx context.Context, id string) (favorites.Asset, bool) {
+	deadline := time.NewTimer(s.fillLockTTL)
+	defer deadline.Stop()
+	ticker := time.NewTicker(fillPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-deadline.C:
+			return nil, false
+		case <-ticker.C:
+		}
+
+		batch, err := s.cache.GetBatch(ctx, []string{id})
+		if err != nil {
+			return nil, false
+		}
+		if data, found := batch[id]; found {
+			asset, err := s.unmarshal(data)
+			return asset, err == nil
+		}
+	}
+}
+
+// loadPage reads a page of assets missing from the cache from the database
+// and caches them. Unlike the cached path, the page is buffered so that
+// concurrent requests for it can share it; pages are bounded by the API's
+// maximum limit.
+func (s *Service) loadPage(ctx context.Context, limit, offset int) (iter.Seq2[favorites.Asset, error], error) {
+	key := fmt.Sprintf("page:%d:%d", limit, offset)
+	v, err := s.coalesce(ctx, "page", key, func(ctx context.Context) (any, error) {
+		return s.fillPage(ctx, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := v.([]favorites.Asset)
+	return func(yield func(favorites.Asset, error) bool) {
+		for _, asset := range page {
+			if !yield(asset, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (s *Service) fillPage(ctx context.Context, limit, offset int) ([]favorites.Asset, error) {
+	rows, err := s.repo.FindAll(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var page []favorites.Asset
+	for asset, err := range s.cacheIterator(ctx, rows) {
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, asset)
+	}
+	return page, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+type MockFillLock struct {
+	mock.Mock
+}
+
+func (m *MockFillLock) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	args := m.Called(ctx, key, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFillLock) Unlock(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+var popular = favorites.Insight{
+	BaseAsset: favorites.BaseAsset{ID: "hot", Name: "Popular", Type: favorites.AssetTypeInsight},
+	Content:   "Knowledge",
+}
+
+// blockUntil makes a mocked call signal that it started, then wait for release.
+func blockUntil(started chan<- struct{}, release <-chan struct{}) func(mock.Arguments) {
+	var once sync.Once
+	return func(mock.Arguments) {
+		once.Do(func() { close(started) })
+		<-release
+	}
+}
+
+func TestService_FindByID_CoalescesMisses(t *testing.T) {
+	svc, repo, cache, _, queue := newEnrichmentTestService()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{}, nil)
+	repo.On("FindByID", mock.Anything, "hot").Return(popular, nil).Run(blockUntil(started, release)).Once()
+	cache.On("AddToSet", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+	cache.On("Set", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+	queue.On("Enqueue", mock.Anything, []string{"hot"}).Return(nil).Once()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]favorites.Asset, callers)
+	wg.Go(func() { results[0], _ = svc.FindByID(context.Background(), "hot") })
+	<-started
+	for i := 1; i < callers; i++ {
+		wg.Go(func() { results[i], _ = svc.FindByID(context.Background(), "hot") })
+	}
+	// Give the followers time to join the flight before the read finishes.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, asset := range results {
+		assert.Equal(t, "hot", asset.GetID())
+	}
+	repo.AssertNumberOfCalls(t, "FindByID", 1)
+	queue.AssertExpectations(t)
+}
+
+func TestService_FindByID_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	svc, repo, cache, _, queue := newEnrichmentTestService()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{}, nil)
+	repo.On("FindByID", mock.Anything, "hot").Return(popular, nil).Run(blockUntil(started, release)).Once()
+	cache.On("AddToSet", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	queue.On("Enqueue", mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.FindByID(ctx, "hot")
+		first <- err
+	}()
+	<-started
+
+	second := make(chan favorites.Asset, 1)
+	go func() {
+		asset, _ := svc.FindByID(context.Background(), "hot")
+		second <- asset
+	}()
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	assert.Equal(t, "hot", (<-second).GetID())
+	repo.AssertNumberOfCalls(t, "FindByID", 1)
+}
+
+func TestService_FindByID_FillLock(t *testing.T) {
+	t.Run("waits for the replica holding the lock", func(t *testing.T) {
+		svc, repo, cache, _, _ := newEnrichmentTestService()
+		lock := new(MockFillLock)
+		svc.WithFillLock(lock, time.Second)
+
+		cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{}, nil).Twice()
+		cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{"hot": mustMarshal(popular)}, nil).Once()
+		lock.On("TryLock", mock.Anything, "asset:hot", time.Second).Return(false, nil).Once()
+
+		asset, err := svc.FindByID(context.Background(), "hot")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hot", asset.GetID())
+		repo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("falls back to the database when the holder does not fill", func(t *testing.T) {
+		svc, repo, cache, _, queue := newEnrichmentTestService()
+		lock := new(MockFillLock)
+		svc.WithFillLock(lock, 60*time.Millisecond)
+
+		cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{}, nil)
+		lock.On("TryLock", mock.Anything, "asset:hot", 60*time.Millisecond).Return(false, nil).Once()
+		repo.On("FindByID", mock.Anything, "hot").Return(popular, nil).Once()
+		cache.On("AddToSet", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+		cache.On("Set", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"hot"}).Return(nil).Once()
+
+		asset, err := svc.FindByID(context.Background(), "hot")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hot", asset.GetID())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("holder fills and unlocks", func(t *testing.T) {
+		svc, repo, cache, _, queue := newEnrichmentTestService()
+		lock := new(MockFillLock)
+		svc.WithFillLock(lock, time.Second)
+
+		cache.On("GetBatch", mock.Anything, []string{"hot"}).Return(map[string][]byte{}, nil).Once()
+		lock.On("TryLock", mock.Anything, "asset:hot", time.Second).Return(true, nil).Once()
+		repo.On("FindByID", mock.Anything, "hot").Return(popular, nil).Once()
+		cache.On("AddToSet", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+		cache.On("Set", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"hot"}).Return(nil).Once()
+		lock.On("Unlock", mock.Anything, "asset:hot").Return(nil).Once()
+
+		_, err := svc.FindByID(context.Background(), "hot")
+
+		assert.NoError(t, err)
+		lock.AssertExpectations(t)
+	})
+}
+
+func TestService_FindAll_CoalescesPageMisses(t *testing.T) {
+	svc, repo, cache, _, queue := newEnrichmentTestService()
+
+	rows := func(yield func(favorites.Asset, error) bool) {
+		yield(popular, nil)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	cache.On("GetIdsFromSet", mock.Anything, int64(0), int64(9)).Return([]string{}, nil)
+	repo.On("FindAll", mock.Anything, 10, 0).Return(iter.Seq2[favorites.Asset, error](rows), nil).Run(blockUntil(started, release)).Once()
+	cache.On("AddToSet", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+	cache.On("Set", mock.Anything, "hot", mock.Anything).Return(nil).Once()
+	queue.On("Enqueue", mock.Anything, []string{"hot"}).Return(nil).Once()
+
+	count := func() int {
+		results, err := svc.FindAll(context.Background(), 10, 0)
+		if err != nil {
+			return -1
+		}
+		n := 0
+		for range results {
+			n++
+		}
+		return n
+	}
+
+	var wg sync.WaitGroup
+	counts := make([]int, 3)
+	wg.Go(func() { counts[0] = count() })
+	<-started
+	wg.Go(func() { counts[1] = count() })
+	wg.Go(func() { counts[2] = count() })
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []int{1, 1, 1}, counts)
+	repo.AssertNumberOfCalls(t, "FindAll", 1)
+	queue.AssertExpectations(t)
+}
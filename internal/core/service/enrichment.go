@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+// EnrichmentWorkerConfig tunes the background enrichment workers.
+type EnrichmentWorkerConfig struct {
+	// Workers is the number of jobs processed concurrently.
+	Workers int
+	// PollInterval is how long to wait when the queue has no due jobs.
+	PollInterval time.Duration
+	// Lease is how long a claimed job is hidden from other workers. It must
+	// exceed JobTimeout, or a slow job may be picked up twice.
+	Lease time.Duration
+	// JobTimeout bounds a single enrichment attempt.
+	JobTimeout time.Duration
+	// MaxAttempts failures move a job to the dead-letter queue.
+	MaxAttempts int
+	// Backoff spaces out retries of a failing job.
+	Backoff resilience.Backoff
+}
+
+func (c *EnrichmentWorkerConfig) setDefaults() {
+	if c.Workers < 1 {
+		c.Workers = 4
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.JobTimeout <= 0 {
+		c.JobTimeout = 30 * time.Second
+	}
+	if c.Lease <= c.JobTimeout {
+		c.Lease = 2 * c.JobTimeout
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 5
+	}
+	if c.Backoff == (resilience.Backoff{}) {
+		c.Backoff = resilience.Backoff{Base: time.Second, Max: 5 * time.Minute}
+	}
+}
+
+// RunEnrichmentWorkers processes the enrichment queue until ctx is canceled.
+// On cancellation it stops claiming jobs, lets the jobs it already claimed
+// finish, and then returns.
+func (s *Service) RunEnrichmentWorkers(ctx context.Context, cfg EnrichmentWorkerConfig) {
+	cfg.setDefaults()
+
+	// In-flight jobs must survive shutdown; JobTimeout bounds them instead.
+	jobCtx := context.WithoutCancel(ctx)
+	jobs := make(chan ports.EnrichmentJob)
+	var wg sync.WaitGroup
+	for range cfg.Workers {
+		wg.Go(func() {
+			for job := range jobs {
+				s.processEnrichmentJob(jobCtx, job, cfg)
+			}
+		})
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+		s.logger.Info("enrichment workers drained")
+	}()
+
+	for ctx.Err() == nil {
+		claimed, err := s.queue.Claim(ctx, cfg.Workers, cfg.Lease)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to claim enrichment jobs", "error", err)
+		}
+		for _, job := range claimed {
+			jobs <- job
+		}
+		if len(claimed) < cfg.Workers {
+			_ = resilience.Sleep(ctx, cfg.PollInterval)
+		}
+	}
+}
+
+func (s *Service) processEnrichmentJob(ctx context.Context, job ports.EnrichmentJob, cfg EnrichmentWorkerConfig) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.JobTimeout)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "Service.processEnrichmentJob", trace.WithAttributes(
+		attribute.String("asset.id", job.AssetID),
+		attribute.Int("job.attempts", job.Attempts),
+	))
+	defer span.End()
+
+	asset, err := s.repo.FindByID(ctx, job.AssetID)
+	if errors.Is(err, favorites.ErrNotFound) {
+		// Deleted while queued; nothing left to enrich.
+		s.finishEnrichmentJob(ctx, job)
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		s.failEnrichmentJob(ctx, job, nil, nil, err, cfg)
+		return
+	}
+
+	metadata, stages, err := s.enrichers.Enrich(ctx, asset)
+	if err != nil {
+		span.RecordError(err)
+		if slices.ContainsFunc(stages, func(r favorites.StageResult) bool { return !r.Failed() }) {
+			// Keep what the working stages returned; the retry runs every stage again.
+			partial := favorites.Enrichment{
+				Status:    favorites.EnrichmentPartial,
+				UpdatedAt: time.Now(),
+				Data:      metadata,
+				Stages:    stages,
+			}
+			if err := s.storeEnrichment(ctx, asset, partial); err != nil {
+				s.logger.Error("failed to record partial enrichment", "id", job.AssetID, "error", err)
+			} else {
+				asset = favorites.WithEnrichment(asset, partial)
+			}
+		}
+		s.failEnrichmentJob(ctx, job, asset, stages, err, cfg)
+		return
+	}
+
+	err = s.storeEnrichment(ctx, asset, favorites.Enrichment{
+		Status:    favorites.EnrichmentReady,
+		UpdatedAt: time.Now(),
+		Data:      metadata,
+		Stages:    stages,
+	})
+	switch {
+	case errors.Is(err, favorites.ErrNotFound):
+		s.finishEnrichmentJob(ctx, job)
+	case err != nil:
+		span.RecordError(err)
+		s.failEnrichmentJob(ctx, job, asset, stages, err, cfg)
+	default:
+		s.finishEnrichmentJob(ctx, job)
+	}
+}
+
+// storeEnrichment persists the enrichment state and refreshes the cached copy.
+func (s *Service) storeEnrichment(ctx context.Context, asset favorites.Asset, e favorites.Enrichment) error {
+	if err := s.repo.UpdateEnrichment(ctx, asset.GetID(), e); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(favorites.WithEnrichment(asset, e))
+	if err != nil {
+		s.logger.Error("failed to marshal asset for cache", "id", asset.GetID(), "error", err)
+		return nil
+	}
+	// The database is authoritative; a stale cache entry is fixed by the next retry.
+	return s.cache.Set(ctx, asset.GetID(), data)
+}
+
+func (s *Service) finishEnrichmentJob(ctx context.Context, job ports.EnrichmentJob) {
+	enrichmentJobsProcessed.WithLabelValues("completed").Inc()
+	if err := s.queue.Complete(ctx, job); err != nil {
+		// The lease expires and the job runs again, which is harmless.
+		s.logger.Error("failed to complete enrichment job", "job_id", job.ID, "error", err)
+	}
+}
+
+// failEnrichmentJob reschedules the job, or gives up once MaxAttempts is
+// reached. A partially enriched asset keeps that status; otherwise it is
+// marked as failed. asset is nil if it could not be loaded.
+func (s *Service) failEnrichmentJob(ctx context.Context, job ports.EnrichmentJob, asset favorites.Asset, stages []favorites.StageResult, cause error, cfg EnrichmentWorkerConfig) {
+	if job.Attempts >= cfg.MaxAttempts {
+		s.logger.Error("enrichment failed repeatedly, moving job to dead-letter queue",
+			"id", job.AssetID, "attempts", job.Attempts, "error", cause)
+		if asset != nil && asset.GetEnrichment().Status != favorites.EnrichmentPartial {
+			// Metadata from an earlier successful run is still worth showing.
+			failed := favorites.Enrichment{
+				Status:    favorites.EnrichmentFailed,
+				UpdatedAt: time.Now(),
+				Data:      asset.GetEnrichment().Data,
+				Stages:    stages,
+			}
+			if err := s.storeEnrichment(ctx, asset, failed); err != nil && !errors.Is(err, favorites.ErrNotFound) {
+				s.logger.Error("failed to record enrichment failure", "id", job.AssetID, "error", err)
+			}
+		}
+		enrichmentJobsProcessed.WithLabelValues("buried").Inc()
+		if err := s.queue.Bury(ctx, job, cause); err != nil {
+			s.logger.Error("failed to bury enrichment job", "job_id", job.ID, "error", err)
+		}
+		return
+	}
+
+	enrichmentJobsProcessed.WithLabelValues("retried").Inc()
+	delay := cfg.Backoff.Delay(job.Attempts - 1)
+	s.logger.Warn("enrichment failed, will retry", "id", job.AssetID, "attempts", job.Attempts, "retry_in", delay, "error", cause)
+	if err := s.queue.Retry(ctx, job, time.Now().Add(delay), cause); err != nil {
+		s.logger.Error("failed to reschedule enrichment job", "job_id", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+func newEnrichmentTestService() (*Service, *MockRepository, *MockCache, *MockEnricherRegistry, *MockQueue) {
+	repo := new(MockRepository)
+	cache := new(MockCache)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
+	return NewService(repo, cache, enricher, queue, quietLogger), repo, cache, enricher, queue
+}
+
+func testWorkerConfig() EnrichmentWorkerConfig {
+	cfg := EnrichmentWorkerConfig{
+		Workers:      2,
+		PollInterval: 10 * time.Millisecond,
+		JobTimeout:   time.Second,
+		MaxAttempts:  3,
+		Backoff:      resilience.Backoff{Base: time.Millisecond, Max: time.Millisecond},
+	}
+	cfg.setDefaults()
+	return cfg
+}
+
+func TestService_ProcessEnrichmentJob(t *testing.T) {
+	asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a1", Name: "One", Type: favorites.AssetTypeInsight}, Content: "x"}
+	metadata := favorites.Metadata{ThumbnailURL: "https://img.test/a1.png", RowCount: 12}
+	failedStages := []favorites.StageResult{{Name: "metadata", Error: "error"}}
+
+	t.Run("success caches the enriched asset and completes the job", func(t *testing.T) {
+		svc, repo, cache, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 1, AssetID: "a1", Attempts: 1, Version: 1}
+
+		repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+		enricher.On("Enrich", mock.Anything, asset).Return(metadata, nil, nil).Once()
+		repo.On("UpdateEnrichment", mock.Anything, "a1", mock.MatchedBy(func(e favorites.Enrichment) bool {
+			return e.Status == favorites.EnrichmentReady && e.Data == metadata && !e.UpdatedAt.IsZero()
+		})).Return(nil).Once()
+		cache.On("Set", mock.Anything, "a1", mock.MatchedBy(func(data []byte) bool {
+			var cached favorites.Insight
+			return json.Unmarshal(data, &cached) == nil && cached.Enrichment.Data == metadata
+		})).Return(nil).Once()
+		queue.On("Complete", mock.Anything, job).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		enricher.AssertExpectations(t)
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("failure reschedules the job", func(t *testing.T) {
+		svc, repo, _, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 2, AssetID: "a1", Attempts: 1, Version: 1}
+		cause := errors.New("metadata service down")
+
+		repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+		enricher.On("Enrich", mock.Anything, asset).Return(favorites.Metadata{}, failedStages, cause).Once()
+		queue.On("Retry", mock.Anything, job, mock.AnythingOfType("time.Time"), cause).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		queue.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("partial failure keeps the working stages and retries", func(t *testing.T) {
+		svc, repo, cache, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 6, AssetID: "a1", Attempts: 1, Version: 1}
+		cause := errors.New("stage preview: timeout")
+		stages := []favorites.StageResult{{Name: "metadata"}, {Name: "preview", Error: "timeout"}}
+
+		repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+		enricher.On("Enrich", mock.Anything, asset).Return(metadata, stages, cause).Once()
+		repo.On("UpdateEnrichment", mock.Anything, "a1", mock.MatchedBy(func(e favorites.Enrichment) bool {
+			return e.Status == favorites.EnrichmentPartial && e.Data == metadata && len(e.Stages) == 2
+		})).Return(nil).Once()
+		cache.On("Set", mock.Anything, "a1", mock.Anything).Return(nil).Once()
+		queue.On("Retry", mock.Anything, job, mock.AnythingOfType("time.Time"), cause).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("partial failure on the last attempt stays partial", func(t *testing.T) {
+		svc, repo, cache, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 7, AssetID: "a1", Attempts: 3, Version: 1}
+		cause := errors.New("stage preview: timeout")
+		stages := []favorites.StageResult{{Name: "metadata"}, {Name: "preview", Error: "timeout"}}
+
+		repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+		enricher.On("Enrich", mock.Anything, asset).Return(metadata, stages, cause).Once()
+		repo.On("UpdateEnrichment", mock.Anything, "a1", mock.MatchedBy(func(e favorites.Enrichment) bool {
+			return e.Status == favorites.EnrichmentPartial
+		})).Return(nil).Once()
+		cache.On("Set", mock.Anything, "a1", mock.Anything).Return(nil).Once()
+		queue.On("Bury", mock.Anything, job, cause).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		// Only the partial result is stored; it is not overwritten with "failed".
+		repo.AssertNumberOfCalls(t, "UpdateEnrichment", 1)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("last attempt marks the asset failed and buries the job", func(t *testing.T) {
+		svc, repo, cache, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 3, AssetID: "a1", Attempts: 3, Version: 1}
+		cause := errors.New("metadata service down")
+		// Metadata from an earlier run is kept alongside the failed status.
+		previous := favorites.WithEnrichment(asset, favorites.Enrichment{Status: favorites.EnrichmentReady, Data: metadata})
+
+		repo.On("FindByID", mock.Anything, "a1").Return(previous, nil).Once()
+		enricher.On("Enrich", mock.Anything, previous).Return(favorites.Metadata{}, failedStages, cause).Once()
+		repo.On("UpdateEnrichment", mock.Anything, "a1", mock.MatchedBy(func(e favorites.Enrichment) bool {
+			return e.Status == favorites.EnrichmentFailed && e.Data == metadata && len(e.Stages) == 1
+		})).Return(nil).Once()
+		cache.On("Set", mock.Anything, "a1", mock.Anything).Return(nil).Once()
+		queue.On("Bury", mock.Anything, job, cause).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("asset deleted during enrichment completes the job", func(t *testing.T) {
+		svc, repo, _, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 5, AssetID: "a1", Attempts: 1, Version: 1}
+
+		repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+		enricher.On("Enrich", mock.Anything, asset).Return(metadata, nil, nil).Once()
+		repo.On("UpdateEnrichment", mock.Anything, "a1", mock.Anything).Return(favorites.ErrNotFound).Once()
+		queue.On("Complete", mock.Anything, job).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("deleted asset completes the job without enriching", func(t *testing.T) {
+		svc, repo, _, enricher, queue := newEnrichmentTestService()
+		job := ports.EnrichmentJob{ID: 4, AssetID: "gone", Attempts: 1, Version: 1}
+
+		repo.On("FindByID", mock.Anything, "gone").Return(nil, favorites.ErrNotFound).Once()
+		queue.On("Complete", mock.Anything, job).Return(nil).Once()
+
+		svc.processEnrichmentJob(context.Background(), job, testWorkerConfig())
+
+		queue.AssertExpectations(t)
+		enricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_RunEnrichmentWorkers(t *testing.T) {
+	svc, repo, cache, enricher, queue := newEnrichmentTestService()
+	asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a1", Name: "One", Type: favorites.AssetTypeInsight}, Content: "x"}
+	job := ports.EnrichmentJob{ID: 1, AssetID: "a1", Attempts: 1, Version: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	queue.On("Claim", mock.Anything, 2, mock.Anything).Return([]ports.EnrichmentJob{job}, nil).Once()
+	queue.On("Claim", mock.Anything, 2, mock.Anything).Return([]ports.EnrichmentJob{}, nil)
+	repo.On("FindByID", mock.Anything, "a1").Return(asset, nil).Once()
+	enricher.On("Enrich", mock.Anything, asset).Return(favorites.Metadata{}, nil, nil).Once()
+	repo.On("UpdateEnrichment", mock.Anything, "a1", mock.Anything).Return(nil).Once()
+	cache.On("Set", mock.Anything, "a1", mock.Anything).Return(nil).Once()
+	queue.On("Complete", mock.Anything, job).Return(nil).Once().Run(func(mock.Arguments) { cancel() })
+
+	go func() {
+		defer close(done)
+		svc.RunEnrichmentWorkers(ctx, testWorkerConfig())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("workers did not stop after cancellation")
+	}
+
+	queue.AssertExpectations(t)
+	enricher.AssertExpectations(t)
+}
@@ -9,34 +9,58 @@ import (
 	"log/slog"
 	"time"
 
+	"go-favorites-app/internal/core/domain/audit"
 	"go-favorites-app/internal/core/domain/favorites"
 	"go-favorites-app/internal/core/ports"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 var tracer = otel.Tracer("internal/core/service")
 
 type Service struct {
-	repo     ports.FavoriteRepository
-	cache    ports.Cache
-	enricher ports.Enricher
-	logger   *slog.Logger
+	repo      ports.FavoriteRepository
+	cache     ports.Cache
+	enrichers ports.EnricherRegistry
+	queue     ports.EnrichmentQueue
+	logger    *slog.Logger
+
+	// flights coalesces concurrent cache fills for the same asset or page.
+	flights     singleflight.Group
+	fillLock    ports.FillLock
+	fillLockTTL time.Duration
+
+	audit ports.AuditRecorder
+	quota favorites.Quota
 }
 
-func NewService(repo ports.FavoriteRepository, cache ports.Cache, enricher ports.Enricher, logger *slog.Logger) *Service {
+func NewService(repo ports.FavoriteRepository, cache ports.Cache, enrichers ports.EnricherRegistry, queue ports.EnrichmentQueue, logger *slog.Logger) *Service {
 	s := &Service{
-		repo:     repo,
-		cache:    cache,
-		enricher: enricher,
-		logger:   logger,
+		repo:      repo,
+		cache:     cache,
+		enrichers: enrichers,
+		queue:     queue,
+		logger:    logger,
 	}
 
 	return s
 }
 
+// WithAudit records every change to favorites in the audit log.
+func (s *Service) WithAudit(rec ports.AuditRecorder) *Service {
+	s.audit = rec
+	return s
+}
+
+// WithQuota limits what each user may store. Save rejects assets over it.
+func (s *Service) WithQuota(q favorites.Quota) *Service {
+	s.quota = q
+	return s
+}
+
 func (s *Service) Save(ctx context.Context, asset favorites.Asset) error {
 	ctx, span := tracer.Start(ctx, "Service.Save", trace.WithAttributes(
 		attribute.String("asset.id", asset.GetID()),
@@ -51,37 +75,124 @@ func (s *Service) Save(ctx context.Context, asset favorites.Asset) error {
 		span.RecordError(err)
 		return fmt.Errorf("validation failed: %w", err)
 	}
+	if err := s.quota.CheckFields(asset); err != nil {
+		span.RecordError(err)
+		quotaRejections.WithLabelValues(favorites.QuotaReasonFieldLength).Inc()
+		return err
+	}
+	size, err := favorites.StoredSize(asset)
+	if err != nil {
+		return fmt.Errorf("failed to measure asset: %w", err)
+	}
 
-	// 2. Save DB
-	if err := s.repo.Save(ctx, asset); err != nil {
+	// 2. Save DB, with its event, within the user's quota
+	now := time.Now()
+	asset = favorites.WithUpdatedAt(favorites.WithEnrichment(asset, favorites.PendingEnrichment(now)), now)
+	err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		if err := s.checkQuota(ctx, repo, asset.GetUserID(), size); err != nil {
+			return err
+		}
+		if err := repo.Save(ctx, asset); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, favorites.NewEvent(favorites.EventCreated, asset, now))
+	})
+	if errors.Is(err, favorites.ErrQuotaExceeded) {
+		span.RecordError(err)
+		return err
+	}
+	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to save to db: %w", err)
 	}
+	recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteCreated, asset.GetUserID(), nil, asset)
 
-	// 3. Write-Through (Enrich + Cache)
-	// We perform this synchronously to ensure the user gets "Hydrated" data initially
-	// and to prevent "loading" states in the UI.
-	if _, err := s.enrichAndSaveCache(ctx, asset); err != nil {
-		// Log but don't fail the request if enrichment causes issues (though enrichAndSaveCache essentially swallows errors)
-		s.logger.Warn("failed to enrich and cache on save", "id", asset.GetID(), "error", err)
+	// 3. Write-Through (Cache now, Enrich in the background)
+	// The enrichment workers overwrite the cached copy once metadata is available.
+	s.cacheAndEnqueue(ctx, asset)
+
+	return nil
+}
+
+// checkQuota returns a *favorites.QuotaError if storing size more bytes would
+// take the user over their quota. repo must be bound to the transaction that
+// saves the asset, which then holds the user's quota lock.
+func (s *Service) checkQuota(ctx context.Context, repo ports.FavoriteRepository, userID string, size int64) error {
+	if s.quota.MaxFavorites == 0 && s.quota.MaxBytes == 0 {
+		return nil
+	}
+	usage, err := repo.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.quota.Check(usage, size); err != nil {
+		var qerr *favorites.QuotaError
+		if errors.As(err, &qerr) {
+			quotaRejections.WithLabelValues(qerr.Reason).Inc()
+		}
+		return err
+	}
+	return nil
+}
+
+// checkDescription checks the asset with its description replaced against
+// the field limits and returns the stored size of the asset before and after.
+func (s *Service) checkDescription(asset favorites.Asset, description string) (oldSize, newSize int64, err error) {
+	updated := favorites.WithDescription(asset, description)
+	if err := s.quota.CheckFields(updated); err != nil {
+		quotaRejections.WithLabelValues(favorites.QuotaReasonFieldLength).Inc()
+		return 0, 0, err
+	}
+	if oldSize, err = favorites.StoredSize(asset); err != nil {
+		return 0, 0, fmt.Errorf("failed to measure asset: %w", err)
+	}
+	if newSize, err = favorites.StoredSize(updated); err != nil {
+		return 0, 0, fmt.Errorf("failed to measure asset: %w", err)
 	}
+	return oldSize, newSize, nil
+}
 
+// checkResize returns a *favorites.QuotaError if growing an asset from
+// oldSize to newSize bytes would take the user over their quota. As with
+// checkQuota, repo must be bound to the transaction that writes the asset.
+func (s *Service) checkResize(ctx context.Context, repo ports.FavoriteRepository, userID string, oldSize, newSize int64) error {
+	if s.quota.MaxBytes == 0 || newSize <= oldSize {
+		return nil
+	}
+	usage, err := repo.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.quota.CheckReplace(usage, oldSize, newSize); err != nil {
+		var qerr *favorites.QuotaError
+		if errors.As(err, &qerr) {
+			quotaRejections.WithLabelValues(qerr.Reason).Inc()
+		}
+		return err
+	}
 	return nil
 }
 
-// enrichAndSaveCacheEnriched enriches the asset and updates the cache, returning the enriched result.
-func (s *Service) enrichAndSaveCache(ctx context.Context, asset favorites.Asset) (favorites.Asset, error) {
-	// 1. Enrich (Optimistic)
-	// We operate directly on the asset (assuming caller gave us a safe copy or we are fine enriching in place)
-	if err := s.enricher.Enrich(ctx, asset); err != nil {
-		s.logger.Warn("enrichment failed, caching unenriched asset", "id", asset.GetID(), "error", err)
-		// Proceed to cache anyway (Best Effort)
+// Usage returns what the user stores and the quota that applies to them.
+func (s *Service) Usage(ctx context.Context, userID string) (favorites.Usage, favorites.Quota, error) {
+	usage, err := s.repo.Usage(ctx, userID)
+	if err != nil {
+		return favorites.Usage{}, favorites.Quota{}, err
 	}
+	return usage, s.quota, nil
+}
 
-	// 2. Cache
+// cacheAndEnqueue writes the asset as stored in the database to the cache and
+// schedules its enrichment (ADR 002). Neither step fails the caller.
+func (s *Service) cacheAndEnqueue(ctx context.Context, asset favorites.Asset) {
 	s.updateCache(ctx, asset)
+	s.enqueue(ctx, asset.GetID())
+}
 
-	return asset, nil
+func (s *Service) enqueue(ctx context.Context, ids ...string) {
+	if err := s.queue.Enqueue(ctx, ids...); err != nil {
+		s.logger.Error("failed to enqueue enrichment", "ids", ids, "error", err)
+	}
 }
 
 func (s *Service) updateCache(ctx context.Context, asset favorites.Asset) {
@@ -110,14 +221,8 @@ func (s *Service) FindByID(ctx context.Context, id string) (favorites.Asset, err
 		return s.unmarshal(batch[id])
 	}
 
-	asset, err := s.repo.FindByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Write-through (optional read-repair)
-	// We need to enrich the asset before caching it, as the cache is expected to hold enriched data.
-	return s.enrichAndSaveCache(ctx, asset)
+	// Read-repair, shared with concurrent misses for the same ID.
+	return s.loadAsset(ctx, id)
 }
 
 func (s *Service) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[favorites.Asset, error], error) {
@@ -137,15 +242,9 @@ func (s *Service) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[fav
 		return s.chunkedCacheIterator(ctx, ids), nil
 	}
 
-	// 2. Stream from DB
-	s.logger.Info("streaming favorites from db")
-	repoIter, err := s.repo.FindAll(ctx, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-
-	// 3. Cache and Return (No blocking enrichment on read)
-	return s.cacheIterator(ctx, repoIter), nil
+	// 2. Load from DB, caching the page (enrichment is queued, not awaited)
+	s.logger.Info("loading favorites from db")
+	return s.loadPage(ctx, limit, offset)
 }
 
 func (s *Service) chunkedCacheIterator(ctx context.Context, ids []string) iter.Seq2[favorites.Asset, error] {
@@ -158,28 +257,26 @@ func (s *Service) chunkedCacheIterator(ctx context.Context, ids []string) iter.S
 			}
 			chunkIds := ids[i:end]
 
-			dataMap, err := s.cache.GetBatch(ctx, chunkIds)
-			if err != nil {
-				yield(nil, fmt.Errorf("failed to fetch cache batch: %w", err))
-				return
+			dataMap, batchErr := s.cache.GetBatch(ctx, chunkIds)
+			if batchErr != nil {
+				// Serve the chunk from the database rather than failing the page.
+				s.logger.Warn("failed to fetch cache batch, reading from db", "count", len(chunkIds), "error", batchErr)
 			}
 
 			for _, id := range chunkIds {
 				data, found := dataMap[id]
 				if !found {
 					// Read-Repair: ID exists in Set but Data missing in Hash/Set
-					s.logger.Warn("cache inconsistency detected (missing data), repairing from db", "id", id)
-					asset, err := s.repo.FindByID(ctx, id)
+					if batchErr == nil {
+						s.logger.Warn("cache inconsistency detected (missing data), repairing from db", "id", id)
+					}
+					asset, err := s.loadAsset(ctx, id)
 					if err != nil {
 						yield(nil, fmt.Errorf("failed to repair cache for id %s: %w", id, err))
 						return
 					}
 
-					// Synchronous Enrichment on Read Repair
-					// Ensures FindAll returns fully enriched data
-					enrichedAsset, _ := s.enrichAndSaveCache(ctx, asset)
-
-					if !yield(enrichedAsset, nil) {
+					if !yield(asset, nil) {
 						return
 					}
 					continue
@@ -219,13 +316,25 @@ func (s *Service) Delete(ctx context.Context, id, userID string) error {
 		return err
 	}
 	if asset.GetUserID() != userID {
-		return errors.New("forbidden: you do not own this asset")
+		return favorites.ErrForbidden
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		if err := repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, favorites.NewEvent(favorites.EventDeleted, asset, time.Now()))
+	})
+	if err != nil {
 		return err
 	}
-	return s.cache.Remove(ctx, id)
+	recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteDeleted, userID, asset, nil)
+	// The row is gone; a stale cache entry must not turn that into a failure.
+	// The outbox relay removes it if this fails.
+	if err := s.cache.Remove(ctx, id); err != nil {
+		s.logger.Error("failed to remove deleted asset from cache", "id", id, "error", err)
+	}
+	return nil
 }
 
 func (s *Service) UpdateDescription(ctx context.Context, id, description, userID string) (favorites.Asset, error) {
@@ -238,19 +347,34 @@ func (s *Service) UpdateDescription(ctx context.Context, id, description, userID
 		return nil, err
 	}
 	if asset.GetUserID() != userID {
-		return nil, errors.New("forbidden: you do not own this asset")
+		return nil, favorites.ErrForbidden
+	}
+	oldSize, newSize, err := s.checkDescription(asset, description)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	updatedAsset, err := s.repo.UpdateDescription(ctx, id, description)
+	var updatedAsset favorites.Asset
+	err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		if err := s.checkResize(ctx, repo, userID, oldSize, newSize); err != nil {
+			return err
+		}
+		var err error
+		if updatedAsset, err = repo.UpdateDescription(ctx, id, description); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, favorites.NewEvent(favorites.EventUpdated, updatedAsset, updatedAsset.GetUpdatedAt()))
+	})
 	if err != nil {
 		return nil, err
 	}
+	recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteUpdated, userID, asset, updatedAsset)
 
 	// Invalidate or update cache
 	if err := s.cache.Remove(ctx, id); err != nil {
-		// Log error but don't fail the operation as DB is already updated ??
-		// Ideally we should have a way to retry or ensure consistency.
-		// For now, logging.
+		// The DB is already updated, so don't fail the operation. The outbox
+		// relay invalidates the entry if this fails.
 		s.logger.Error("failed to invalidate cache after update", "id", id, "error", err)
 	}
 
@@ -290,30 +414,26 @@ func (s *Service) unmarshal(data []byte) (favorites.Asset, error) {
 
 func (s *Service) cacheIterator(ctx context.Context, input iter.Seq2[favorites.Asset, error]) iter.Seq2[favorites.Asset, error] {
 	return func(yield func(favorites.Asset, error) bool) {
+		// Enrichment is requested in one round trip once the stream ends,
+		// including when the consumer stops early.
+		var ids []string
+		defer func() {
+			if len(ids) > 0 {
+				s.enqueue(ctx, ids...)
+			}
+		}()
+
 		for asset, err := range input {
 			if err != nil {
 				yield(nil, err)
 				return
 			}
 
-			// Synchronous Write-Through for List Streaming
-			// We optimize for consistency over raw speed here to prevent "flickering" data
-			if _, err := s.enrichAndSaveCache(ctx, asset); err != nil {
-				s.logger.Warn("failed to enrich asset during stream", "id", asset.GetID())
-			}
-
-			// We return the enriched asset implicitly because enrichAndSaveCache updates cache
-			// But here we are iterating over DB results (input).
-			// If we want to return enriched, we should use result of enrichAndSaveCache
-			// However, since we are streaming from DB, we might already have raw data.
-			// Ideally we yield the result of enrichAndSaveCache.
-			// For simplicity and matching current flow, let's just use the updated asset if possible.
-			// But enrichAndSaveCache helper returns (Asset, error). Let's use it.
-
-			// Actually, let's re-enrich.
-			enriched, _ := s.enrichAndSaveCache(ctx, asset)
+			// Write-Through for List Streaming
+			s.updateCache(ctx, asset)
+			ids = append(ids, asset.GetID())
 
-			if !yield(enriched, nil) {
+			if !yield(asset, nil) {
 				return
 			}
 		}
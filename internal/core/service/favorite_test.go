@@ -7,11 +7,14 @@ import (
 	"iter"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,6 +22,22 @@ import (
 
 type MockRepository struct {
 	mock.Mock
+	// Outbox receives the events added in WithTx.
+	Outbox MockOutbox
+}
+
+// WithTx runs fn against the mock itself; a failing fn is what rolls back.
+func (m *MockRepository) WithTx(ctx context.Context, fn func(repo ports.FavoriteRepository, outbox ports.Outbox) error) error {
+	return fn(m, &m.Outbox)
+}
+
+type MockOutbox struct {
+	mock.Mock
+}
+
+func (m *MockOutbox) Add(ctx context.Context, events ...favorites.Event) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
 }
 
 func (m *MockRepository) Save(ctx context.Context, asset favorites.Asset) error {
@@ -26,6 +45,22 @@ func (m *MockRepository) Save(ctx context.Context, asset favorites.Asset) error
 	return args.Error(0)
 }
 
+func (m *MockRepository) SaveBatch(ctx context.Context, assets []favorites.Asset) ([]bool, error) {
+	args := m.Called(ctx, assets)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bool), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceBatch(ctx context.Context, assets []favorites.Asset) ([]bool, error) {
+	args := m.Called(ctx, assets)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bool), args.Error(1)
+}
+
 func (m *MockRepository) FindByID(ctx context.Context, id string) (favorites.Asset, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -34,6 +69,14 @@ func (m *MockRepository) FindByID(ctx context.Context, id string) (favorites.Ass
 	return args.Get(0).(favorites.Asset), args.Error(1)
 }
 
+func (m *MockRepository) FindByIDs(ctx context.Context, ids []string) (map[string]favorites.Asset, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]favorites.Asset), args.Error(1)
+}
+
 func (m *MockRepository) FindAll(ctx context.Context, limit, offset int) (iter.Seq2[favorites.Asset, error], error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -50,11 +93,45 @@ func (m *MockRepository) FindByUser(ctx context.Context, userID string, limit, o
 	return args.Get(0).(iter.Seq2[favorites.Asset, error]), args.Error(1)
 }
 
+func (m *MockRepository) FindByUserAfter(ctx context.Context, userID, afterID string, limit int) (iter.Seq2[favorites.Asset, error], error) {
+	args := m.Called(ctx, userID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(iter.Seq2[favorites.Asset, error]), args.Error(1)
+}
+
+func (m *MockRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) Usage(ctx context.Context, userID string) (favorites.Usage, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(favorites.Usage), args.Error(1)
+}
+
 func (m *MockRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockRepository) DeleteByUser(ctx context.Context, userID string) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepository) DeleteBatch(ctx context.Context, userID string, ids []string) ([]string, error) {
+	args := m.Called(ctx, userID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockRepository) UpdateDescription(ctx context.Context, id, description string) (favorites.Asset, error) {
 	args := m.Called(ctx, id, description)
 	if args.Get(0) == nil {
@@ -63,6 +140,11 @@ func (m *MockRepository) UpdateDescription(ctx context.Context, id, description
 	return args.Get(0).(favorites.Asset), args.Error(1)
 }
 
+func (m *MockRepository) UpdateEnrichment(ctx context.Context, id string, enrichment favorites.Enrichment) error {
+	args := m.Called(ctx, id, enrichment)
+	return args.Error(0)
+}
+
 type MockCache struct {
 	mock.Mock
 }
@@ -103,15 +185,68 @@ func (m *MockCache) Invalidate(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
-type MockEnricher struct {
+func (m *MockCache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	args := m.Called(ctx, assets, score)
+	return args.Error(0)
+}
+
+func (m *MockCache) RemoveBatch(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+type MockEnricherRegistry struct {
 	mock.Mock
 }
 
-func (m *MockEnricher) Enrich(ctx context.Context, asset favorites.Asset) error {
+func (m *MockEnricherRegistry) Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, []favorites.StageResult, error) {
 	args := m.Called(ctx, asset)
+	stages, _ := args.Get(1).([]favorites.StageResult)
+	return args.Get(0).(favorites.Metadata), stages, args.Error(2)
+}
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, assetIDs ...string) error {
+	args := m.Called(ctx, assetIDs)
+	return args.Error(0)
+}
+
+func (m *MockQueue) Claim(ctx context.Context, limit int, lease time.Duration) ([]ports.EnrichmentJob, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.EnrichmentJob), args.Error(1)
+}
+
+func (m *MockQueue) Complete(ctx context.Context, job ports.EnrichmentJob) error {
+	args := m.Called(ctx, job)
 	return args.Error(0)
 }
 
+func (m *MockQueue) Retry(ctx context.Context, job ports.EnrichmentJob, at time.Time, cause error) error {
+	args := m.Called(ctx, job, at, cause)
+	return args.Error(0)
+}
+
+func (m *MockQueue) Bury(ctx context.Context, job ports.EnrichmentJob, cause error) error {
+	args := m.Called(ctx, job, cause)
+	return args.Error(0)
+}
+
+func (m *MockQueue) EnqueueStale(ctx context.Context, before time.Time, limit int) (int, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQueue) Stats(ctx context.Context, staleBefore time.Time) (ports.EnrichmentStats, error) {
+	args := m.Called(ctx, staleBefore)
+	return args.Get(0).(ports.EnrichmentStats), args.Error(1)
+}
+
 // Helper to silence logs
 type testWriter struct{}
 
@@ -127,23 +262,31 @@ func mustMarshal(v any) []byte {
 func TestService_Save(t *testing.T) {
 	repo := new(MockRepository)
 	cache := new(MockCache)
-	enricher := new(MockEnricher)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
 	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
 
 	t.Run("successful save", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: "1", Name: "Test", Type: favorites.AssetTypeInsight},
 			Content:   "Knowledge",
 		}
 
-		repo.On("Save", mock.Anything, asset).Return(nil).Once()
+		// The stored copy is marked as pending enrichment
+		repo.On("Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool {
+			e := a.GetEnrichment()
+			return a.GetID() == "1" && e.Status == favorites.EnrichmentPending && !e.UpdatedAt.IsZero()
+		})).Return(nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 1 && events[0].Type == favorites.EventCreated && events[0].AssetID == "1" && events[0].Asset != nil
+		})).Return(nil).Once()
 
-		// Synchronous Enriched + Cache
-		enricher.On("Enrich", mock.Anything, asset).Return(nil).Once()
+		// Cache the stored copy and queue enrichment; the enricher is not called inline
 		cache.On("AddToSet", mock.Anything, "1", mock.Anything).Return(nil).Once()
 		cache.On("Set", mock.Anything, "1", mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"1"}).Return(nil).Once()
 
 		err := svc.Save(context.Background(), asset)
 		if err != nil {
@@ -151,12 +294,32 @@ func TestService_Save(t *testing.T) {
 		}
 
 		repo.AssertExpectations(t)
-		enricher.AssertExpectations(t)
+		repo.Outbox.AssertExpectations(t)
 		cache.AssertExpectations(t)
+		queue.AssertExpectations(t)
+		enricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
+
+	t.Run("outbox failure fails the save", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger)
+
+		asset := favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: "2", Name: "Test", Type: favorites.AssetTypeInsight},
+			Content:   "Knowledge",
+		}
+
+		repo.On("Save", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		err := svc.Save(context.Background(), asset)
+		if err == nil {
+			t.Error("expected outbox error, got nil")
+		}
+		cache.AssertNotCalled(t, "Set", mock.Anything, "2", mock.Anything)
 	})
 
 	t.Run("validation failure", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: "1", Name: "Test", Type: favorites.AssetTypeInsight},
@@ -169,30 +332,77 @@ func TestService_Save(t *testing.T) {
 	})
 
 	t.Run("repo failure", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: "1", Name: "Test", Type: favorites.AssetTypeInsight},
 			Content:   "Knowledge",
 		}
 
-		repo.On("Save", mock.Anything, asset).Return(errors.New("db error")).Once()
+		repo.On("Save", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
 
 		err := svc.Save(context.Background(), asset)
 		if err == nil {
 			t.Error("expected repo error, got nil")
 		}
 	})
+
+	t.Run("field over its limit", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger).WithQuota(favorites.Quota{
+			MaxFieldLengths: map[favorites.AssetType]map[string]int{favorites.AssetTypeInsight: {"content": 3}},
+		})
+
+		asset := favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: "3", UserID: "user1", Name: "Test", Type: favorites.AssetTypeInsight},
+			Content:   "Knowledge",
+		}
+
+		err := svc.Save(context.Background(), asset)
+		assert.ErrorIs(t, err, favorites.ErrFieldTooLong)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool { return a.GetID() == "3" }))
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger).WithQuota(favorites.Quota{MaxFavorites: 10, MaxBytes: 1000})
+
+		asset := favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: "4", UserID: "user1", Name: "Test", Type: favorites.AssetTypeInsight},
+			Content:   "Knowledge",
+		}
+		repo.On("Usage", mock.Anything, "user1").Return(favorites.Usage{Favorites: 10}, nil).Once()
+
+		err := svc.Save(context.Background(), asset)
+		var qerr *favorites.QuotaError
+		if assert.ErrorAs(t, err, &qerr) {
+			assert.Equal(t, favorites.QuotaReasonFavorites, qerr.Reason)
+		}
+		assert.ErrorIs(t, err, favorites.ErrQuotaExceeded)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.MatchedBy(func(a favorites.Asset) bool { return a.GetID() == "4" }))
+	})
+}
+
+func TestService_Usage(t *testing.T) {
+	repo := new(MockRepository)
+	quota := favorites.Quota{MaxFavorites: 10}
+	svc := NewService(repo, new(MockCache), new(MockEnricherRegistry), new(MockQueue), slog.New(slog.NewTextHandler(&testWriter{}, nil))).WithQuota(quota)
+	repo.On("Usage", mock.Anything, "user1").Return(favorites.Usage{Favorites: 3, Bytes: 120}, nil).Once()
+
+	usage, q, err := svc.Usage(context.Background(), "user1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, favorites.Usage{Favorites: 3, Bytes: 120}, usage)
+	assert.Equal(t, quota, q)
 }
 
 func TestService_FindByID(t *testing.T) {
 	repo := new(MockRepository)
 	cache := new(MockCache)
-	enricher := new(MockEnricher)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
 	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
 
 	t.Run("cache hit", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: "1", Name: "Test", Type: favorites.AssetTypeInsight},
@@ -212,8 +422,8 @@ func TestService_FindByID(t *testing.T) {
 		}
 	})
 
-	t.Run("cache miss - read repair with enrichment", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+	t.Run("cache miss - read repair queues enrichment", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: "2", Name: "Test Miss", Type: favorites.AssetTypeInsight},
@@ -225,12 +435,10 @@ func TestService_FindByID(t *testing.T) {
 		// Repo Hit
 		repo.On("FindByID", mock.Anything, "2").Return(asset, nil).Once()
 
-		// Read Repair Expectations (Synchronous)
-		// We expect Enrich to be called
-		enricher.On("Enrich", mock.Anything, asset).Return(nil).Once()
-		// We expect Cache update
+		// Read Repair Expectations
 		cache.On("AddToSet", mock.Anything, "2", mock.Anything).Return(nil).Once()
 		cache.On("Set", mock.Anything, "2", mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"2"}).Return(nil).Once()
 
 		res, err := svc.FindByID(context.Background(), "2")
 		if err != nil {
@@ -241,19 +449,21 @@ func TestService_FindByID(t *testing.T) {
 		}
 
 		repo.AssertExpectations(t)
-		enricher.AssertExpectations(t)
 		cache.AssertExpectations(t)
+		queue.AssertExpectations(t)
+		enricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
 	})
 }
 
 func TestService_FindAll(t *testing.T) {
 	repo := new(MockRepository)
 	cache := new(MockCache)
-	enricher := new(MockEnricher)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
 	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
 
 	t.Run("cache hit FindAll", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		cache.On("GetIdsFromSet", mock.Anything, int64(0), int64(9)).Return([]string{"1"}, nil).Once()
 		cache.On("GetBatch", mock.Anything, []string{"1"}).Return(map[string][]byte{
@@ -276,19 +486,78 @@ func TestService_FindAll(t *testing.T) {
 			t.Errorf("expected 1 item, got %d", count)
 		}
 	})
+
+	t.Run("cache batch failure reads from db", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger)
+
+		asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "b1", Name: "One", Type: favorites.AssetTypeInsight}, Content: "x"}
+		cache.On("GetIdsFromSet", mock.Anything, int64(20), int64(29)).Return([]string{"b1"}, nil).Once()
+		cache.On("GetBatch", mock.Anything, []string{"b1"}).Return(nil, errors.New("redis down")).Twice()
+		repo.On("FindByID", mock.Anything, "b1").Return(asset, nil).Once()
+		cache.On("AddToSet", mock.Anything, "b1", mock.Anything).Return(nil).Once()
+		cache.On("Set", mock.Anything, "b1", mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"b1"}).Return(nil).Once()
+
+		results, err := svc.FindAll(context.Background(), 10, 20)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for asset, err := range results {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids = append(ids, asset.GetID())
+		}
+
+		assert.Equal(t, []string{"b1"}, ids)
+	})
+
+	t.Run("cache miss streams from db and queues enrichment once", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger)
+
+		a1 := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a1", Name: "One", Type: favorites.AssetTypeInsight}, Content: "x"}
+		a2 := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: "a2", Name: "Two", Type: favorites.AssetTypeInsight}, Content: "y"}
+		rows := func(yield func(favorites.Asset, error) bool) {
+			_ = yield(a1, nil) && yield(a2, nil)
+		}
+
+		cache.On("GetIdsFromSet", mock.Anything, int64(10), int64(19)).Return([]string{}, nil).Once()
+		repo.On("FindAll", mock.Anything, 10, 10).Return(iter.Seq2[favorites.Asset, error](rows), nil).Once()
+		cache.On("AddToSet", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+		cache.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+		queue.On("Enqueue", mock.Anything, []string{"a1", "a2"}).Return(nil).Once()
+
+		results, err := svc.FindAll(context.Background(), 10, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for asset, err := range results {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids = append(ids, asset.GetID())
+		}
+
+		assert.Equal(t, []string{"a1", "a2"}, ids)
+		queue.AssertExpectations(t)
+		enricher.AssertNotCalled(t, "Enrich", mock.Anything, mock.Anything)
+	})
 }
 
 func TestService_Delete(t *testing.T) {
 	repo := new(MockRepository)
 	cache := new(MockCache)
-	enricher := new(MockEnricher)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
 	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
 
 	id := "1"
 	userID := uuid.NewString()
 
 	t.Run("successful delete", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		// Setup FindByID callback check
 		repo.On("FindByID", mock.Anything, id).Return(favorites.Insight{
@@ -296,6 +565,9 @@ func TestService_Delete(t *testing.T) {
 		}, nil).Once()
 
 		repo.On("Delete", mock.Anything, id).Return(nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 1 && events[0].Type == favorites.EventDeleted && events[0].AssetID == id && events[0].UserID == userID
+		})).Return(nil).Once()
 		cache.On("Remove", mock.Anything, id).Return(nil).Once()
 
 		err := svc.Delete(context.Background(), id, userID)
@@ -304,8 +576,24 @@ func TestService_Delete(t *testing.T) {
 		}
 	})
 
+	t.Run("cache failure does not fail the delete", func(t *testing.T) {
+		svc := NewService(repo, cache, enricher, queue, logger)
+
+		repo.On("FindByID", mock.Anything, id).Return(favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: id, UserID: userID, Type: favorites.AssetTypeInsight},
+		}, nil).Once()
+		repo.On("Delete", mock.Anything, id).Return(nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+		cache.On("Remove", mock.Anything, id).Return(errors.New("redis down")).Once()
+
+		err := svc.Delete(context.Background(), id, userID)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
 	t.Run("delete forbidden", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		otherUser := uuid.NewString()
 
@@ -324,7 +612,8 @@ func TestService_Delete(t *testing.T) {
 func TestService_UpdateDescription(t *testing.T) {
 	repo := new(MockRepository)
 	cache := new(MockCache)
-	enricher := new(MockEnricher)
+	enricher := new(MockEnricherRegistry)
+	queue := new(MockQueue)
 	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
 
 	id := "1"
@@ -332,7 +621,7 @@ func TestService_UpdateDescription(t *testing.T) {
 	newDesc := "new desc"
 
 	t.Run("successful update", func(t *testing.T) {
-		svc := NewService(repo, cache, enricher, logger)
+		svc := NewService(repo, cache, enricher, queue, logger)
 
 		asset := favorites.Insight{
 			BaseAsset: favorites.BaseAsset{ID: id, UserID: userID, Name: "Test", Type: favorites.AssetTypeInsight, Description: newDesc},
@@ -345,6 +634,9 @@ func TestService_UpdateDescription(t *testing.T) {
 		}, nil).Once()
 
 		repo.On("UpdateDescription", mock.Anything, id, newDesc).Return(asset, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 1 && events[0].Type == favorites.EventUpdated && events[0].AssetID == id && events[0].Asset != nil
+		})).Return(nil).Once()
 		// Expect cache invalidation
 		cache.On("Remove", mock.Anything, id).Return(nil).Once()
 
@@ -356,4 +648,38 @@ func TestService_UpdateDescription(t *testing.T) {
 			t.Errorf("expected ID %s, got %s", id, updated.GetID())
 		}
 	})
+
+	t.Run("description too long", func(t *testing.T) {
+		repo := new(MockRepository)
+		svc := NewService(repo, cache, enricher, queue, logger).WithQuota(favorites.Quota{
+			MaxFieldLengths: map[favorites.AssetType]map[string]int{favorites.AnyType: {"description": 3}},
+		})
+		repo.On("FindByID", mock.Anything, id).Return(favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: id, UserID: userID, Type: favorites.AssetTypeInsight},
+		}, nil).Once()
+
+		_, err := svc.UpdateDescription(context.Background(), id, newDesc, userID)
+
+		var qerr *favorites.QuotaError
+		if !errors.As(err, &qerr) || qerr.Reason != favorites.QuotaReasonFieldLength || qerr.Field != "description" {
+			t.Errorf("expected a description length error, got %v", err)
+		}
+	})
+
+	t.Run("byte quota exceeded", func(t *testing.T) {
+		repo := new(MockRepository)
+		svc := NewService(repo, cache, enricher, queue, logger).WithQuota(favorites.Quota{MaxBytes: 100})
+		repo.On("FindByID", mock.Anything, id).Return(favorites.Insight{
+			BaseAsset: favorites.BaseAsset{ID: id, UserID: userID, Type: favorites.AssetTypeInsight},
+		}, nil).Once()
+		repo.On("Usage", mock.Anything, userID).Return(favorites.Usage{Favorites: 1, Bytes: 95}, nil).Once()
+
+		_, err := svc.UpdateDescription(context.Background(), id, newDesc, userID)
+
+		var qerr *favorites.QuotaError
+		if !errors.As(err, &qerr) || qerr.Reason != favorites.QuotaReasonBytes {
+			t.Errorf("expected a byte quota error, got %v", err)
+		}
+		repo.AssertNotCalled(t, "UpdateDescription", mock.Anything, id, newDesc)
+	})
 }
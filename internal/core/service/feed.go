@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// feedBuffer is how many events a watcher may fall behind before it is
+// dropped and has to resume from its last event ID.
+const feedBuffer = 64
+
+// feedData is the JSON document streamed for each event.
+type feedData struct {
+	ID         string              `json:"id"`
+	Type       favorites.EventType `json:"type"`
+	AssetID    string              `json:"asset_id"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Asset      favorites.Asset     `json:"asset,omitempty"`
+}
+
+// Feed streams favorite events to the clients connected to this replica.
+// As an outbox subscriber it appends every event to the shared feed; Run
+// hands the events broadcast by any replica to the local watchers of their
+// user.
+type Feed struct {
+	feed   ports.EventFeed
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	closed   bool
+	watchers map[string]map[chan ports.FeedEvent]struct{}
+}
+
+// Ensure Feed implements ports.FeedService and ports.EventHandler
+var (
+	_ ports.FeedService  = (*Feed)(nil)
+	_ ports.EventHandler = (*Feed)(nil)
+)
+
+func NewFeed(feed ports.EventFeed, logger *slog.Logger) *Feed {
+	return &Feed{
+		feed:     feed,
+		logger:   logger,
+		watchers: make(map[string]map[chan ports.FeedEvent]struct{}),
+	}
+}
+
+// Handle appends the event to its user's feed. An event delivered twice by
+// the relay is streamed twice; clients can drop it by its "id" field. The
+// deletion of an account drops its feed instead; the events of its favorites
+// carry no user, so they do not bring the feed back.
+func (f *Feed) Handle(ctx context.Context, event favorites.Event) error {
+	if event.UserID == "" {
+		return nil
+	}
+	if event.Type == favorites.EventAccountDeleted {
+		return f.feed.Delete(ctx, event.UserID)
+	}
+	data, err := json.Marshal(feedData{
+		ID:         event.ID,
+		Type:       event.Type,
+		AssetID:    event.AssetID,
+		OccurredAt: event.OccurredAt,
+		Asset:      event.Asset,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed event: %w", err)
+	}
+	_, err = f.feed.Append(ctx, ports.FeedEvent{UserID: event.UserID, Type: event.Type, Data: data})
+	return err
+}
+
+// Run dispatches broadcast events until ctx is canceled, then ends every
+// watch so that clients reconnect to another replica.
+func (f *Feed) Run(ctx context.Context) {
+	defer f.close()
+	// Events broadcast while resubscribing are lost to the watchers, so they
+	// are dropped and resume from their last ID.
+	if err := f.feed.Subscribe(ctx, f.dispatch, f.dropAll); err != nil && ctx.Err() == nil {
+		f.logger.Error("feed subscription stopped", "error", err)
+	}
+}
+
+// Watch registers the watcher before reading the history, so that no event
+// falls between the two; events found in both are only sent once.
+func (f *Feed) Watch(ctx context.Context, userID, lastEventID string) (ports.FeedWatch, error) {
+	in := make(chan ports.FeedEvent, feedBuffer)
+	if !f.add(userID, in) {
+		// Shutting down: the client reconnects elsewhere.
+		closed := make(chan ports.FeedEvent)
+		close(closed)
+		return ports.FeedWatch{Live: closed}, nil
+	}
+
+	var w ports.FeedWatch
+	if lastEventID != "" {
+		missed, complete, err := f.feed.Since(ctx, userID, lastEventID)
+		if err != nil {
+			f.remove(userID, in)
+			return ports.FeedWatch{}, err
+		}
+		w.Missed, w.Resync = missed, !complete
+	}
+	sent := make(map[string]struct{}, len(w.Missed))
+	for _, e := range w.Missed {
+		sent[e.ID] = struct{}{}
+	}
+
+	out := make(chan ports.FeedEvent)
+	w.Live = out
+	go func() {
+		defer close(out)
+		defer f.remove(userID, in)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-in:
+				if !ok {
+					return
+				}
+				if _, dup := sent[e.ID]; dup {
+					continue
+				}
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return w, nil
+}
+
+func (f *Feed) dispatch(e ports.FeedEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.watchers[e.UserID] {
+		select {
+		case ch <- e:
+		default:
+			// Too slow; blocking would hold up every other watcher.
+			f.removeLocked(e.UserID, ch)
+			feedWatchersDropped.Inc()
+		}
+	}
+}
+
+// dropAll ends every watch after the subscription was (re)established, as
+// events may have been missed before.
+func (f *Feed) dropAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for userID, chans := range f.watchers {
+		for ch := range chans {
+			f.removeLocked(userID, ch)
+		}
+	}
+}
+
+func (f *Feed) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.dropAll()
+}
+
+func (f *Feed) add(userID string, ch chan ports.FeedEvent) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	if f.watchers[userID] == nil {
+		f.watchers[userID] = make(map[chan ports.FeedEvent]struct{})
+	}
+	f.watchers[userID][ch] = struct{}{}
+	feedWatchers.Inc()
+	return true
+}
+
+func (f *Feed) remove(userID string, ch chan ports.FeedEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removeLocked(userID, ch)
+}
+
+// removeLocked closes ch if it is still registered, so it is closed once.
+func (f *Feed) removeLocked(userID string, ch chan ports.FeedEvent) {
+	chans := f.watchers[userID]
+	if _, ok := chans[ch]; !ok {
+		return
+	}
+	delete(chans, ch)
+	if len(chans) == 0 {
+		delete(f.watchers, userID)
+	}
+	close(ch)
+	feedWatchers.Dec()
+}
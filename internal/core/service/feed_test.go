@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+type MockEventFeed struct {
+	mock.Mock
+}
+
+func (m *MockEventFeed) Append(ctx context.Context, e ports.FeedEvent) (ports.FeedEvent, error) {
+	args := m.Called(ctx, e)
+	return args.Get(0).(ports.FeedEvent), args.Error(1)
+}
+
+func (m *MockEventFeed) Since(ctx context.Context, userID, lastID string) ([]ports.FeedEvent, bool, error) {
+	args := m.Called(ctx, userID, lastID)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]ports.FeedEvent), args.Bool(1), args.Error(2)
+}
+
+func (m *MockEventFeed) Subscribe(ctx context.Context, fn func(ports.FeedEvent), reset func()) error {
+	args := m.Called(ctx, fn, reset)
+	return args.Error(0)
+}
+
+func (m *MockEventFeed) Delete(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func newFeedTestService() (*Feed, *MockEventFeed) {
+	feed := new(MockEventFeed)
+	return NewFeed(feed, slog.New(slog.NewTextHandler(io.Discard, nil))), feed
+}
+
+func receive(t *testing.T, live <-chan ports.FeedEvent) (ports.FeedEvent, bool) {
+	t.Helper()
+	select {
+	case e, ok := <-live:
+		return e, ok
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return ports.FeedEvent{}, false
+	}
+}
+
+func TestFeed_Handle(t *testing.T) {
+	svc, feed := newFeedTestService()
+	feed.On("Append", mock.Anything, mock.MatchedBy(func(e ports.FeedEvent) bool {
+		var data map[string]any
+		return e.UserID == "u1" && e.Type == favorites.EventDeleted &&
+			json.Unmarshal(e.Data, &data) == nil && data["id"] == "e1" && data["asset_id"] == "a1" && data["asset"] == nil
+	})).Return(ports.FeedEvent{ID: "1-0"}, nil).Once()
+
+	err := svc.Handle(context.Background(), favorites.Event{ID: "e1", Type: favorites.EventDeleted, AssetID: "a1", UserID: "u1", OccurredAt: time.Now()})
+
+	assert.NoError(t, err)
+	feed.AssertExpectations(t)
+}
+
+func TestFeed_Handle_AccountDeleted(t *testing.T) {
+	svc, feed := newFeedTestService()
+	feed.On("Delete", mock.Anything, "u1").Return(nil).Once()
+
+	err := svc.Handle(context.Background(), favorites.Event{ID: "e1", Type: favorites.EventAccountDeleted, UserID: "u1", OccurredAt: time.Now()})
+
+	assert.NoError(t, err)
+	feed.AssertExpectations(t)
+	feed.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestFeed_Watch(t *testing.T) {
+	t.Run("replays missed events without repeating them", func(t *testing.T) {
+		svc, feed := newFeedTestService()
+		missed := []ports.FeedEvent{{ID: "2-0", UserID: "u1"}}
+		feed.On("Since", mock.Anything, "u1", "1-0").Return(missed, true, nil).Once()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		w, err := svc.Watch(ctx, "u1", "1-0")
+
+		assert.NoError(t, err)
+		assert.False(t, w.Resync)
+		assert.Equal(t, missed, w.Missed)
+
+		// 2-0 was broadcast while the history was read.
+		svc.dispatch(ports.FeedEvent{ID: "2-0", UserID: "u1"})
+		svc.dispatch(ports.FeedEvent{ID: "3-0", UserID: "u2"})
+		svc.dispatch(ports.FeedEvent{ID: "4-0", UserID: "u1"})
+		e, ok := receive(t, w.Live)
+		assert.True(t, ok)
+		assert.Equal(t, "4-0", e.ID)
+	})
+
+	t.Run("history lost", func(t *testing.T) {
+		svc, feed := newFeedTestService()
+		feed.On("Since", mock.Anything, "u1", "1-0").Return(nil, false, nil).Once()
+
+		w, err := svc.Watch(context.Background(), "u1", "1-0")
+
+		assert.NoError(t, err)
+		assert.True(t, w.Resync)
+	})
+
+	t.Run("history error unregisters the watcher", func(t *testing.T) {
+		svc, feed := newFeedTestService()
+		feed.On("Since", mock.Anything, "u1", "1-0").Return(nil, false, errors.New("redis down")).Once()
+
+		_, err := svc.Watch(context.Background(), "u1", "1-0")
+
+		assert.Error(t, err)
+		assert.Empty(t, svc.watchers)
+	})
+
+	t.Run("new client skips the history", func(t *testing.T) {
+		svc, feed := newFeedTestService()
+
+		_, err := svc.Watch(context.Background(), "u1", "")
+
+		assert.NoError(t, err)
+		feed.AssertNotCalled(t, "Since", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("slow watcher is dropped", func(t *testing.T) {
+		svc, _ := newFeedTestService()
+		w, err := svc.Watch(context.Background(), "u1", "")
+		assert.NoError(t, err)
+
+		for i := range feedBuffer + 2 {
+			svc.dispatch(ports.FeedEvent{ID: string(rune('a' + i)), UserID: "u1"})
+		}
+
+		n := 0
+		for range w.Live {
+			n++
+		}
+		assert.LessOrEqual(t, n, feedBuffer+1, "the stream ends instead of skipping events")
+	})
+}
+
+func TestFeed_Run(t *testing.T) {
+	svc, feed := newFeedTestService()
+	subscribed := make(chan struct{})
+	feed.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(func())()
+		close(subscribed)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil).Once()
+
+	w, err := svc.Watch(context.Background(), "u1", "")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.Run(ctx)
+		close(done)
+	}()
+	<-subscribed
+
+	_, ok := receive(t, w.Live)
+	assert.False(t, ok, "subscribing ends earlier watches")
+
+	cancel()
+	<-done
+	w, err = svc.Watch(context.Background(), "u1", "")
+	assert.NoError(t, err)
+	_, ok = receive(t, w.Live)
+	assert.False(t, ok, "no watches after shutdown")
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"go-favorites-app/internal/core/domain/audit"
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errDryRun rolls back the transaction of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// Export streams every favorite of the user, page by page.
+func (s *Service) Export(ctx context.Context, userID string) iter.Seq2[favorites.Asset, error] {
+	return allFavorites(ctx, s.repo, userID)
+}
+
+// importRow is an asset of an import that passed validation.
+type importRow struct {
+	index int
+	asset favorites.Asset
+	size  int64
+	// old is the asset it replaces, for favorites.ConflictOverwrite.
+	old     favorites.Asset
+	oldSize int64
+}
+
+// Import saves the user's assets in one transaction, resolving taken IDs as
+// opts says, and returns the outcome of each, in order. Assets are validated
+// and checked against the quota as SaveBatch does. A dry run goes as far as
+// writing to the database, so that it finds what a real import would, and
+// then rolls back.
+func (s *Service) Import(ctx context.Context, userID string, assets []favorites.Asset, opts favorites.ImportOptions) ([]favorites.BatchResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.Import", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.Int("batch.size", len(assets)),
+		attribute.String("batch.mode", string(opts.Mode)),
+		attribute.String("import.on_conflict", string(opts.OnConflict)),
+		attribute.Bool("import.dry_run", opts.DryRun),
+	))
+	defer span.End()
+
+	// 1. Validate each asset on its own
+	now := time.Now()
+	results := make([]favorites.BatchResult, len(assets))
+	var rows []importRow
+	for i, asset := range assets {
+		results[i].ID = asset.GetID()
+		prepared, size, err := s.prepare(asset, now)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		rows = append(rows, importRow{index: i, asset: prepared, size: size})
+	}
+	if opts.Mode == favorites.BatchAtomic && favorites.Failed(results) {
+		favorites.Abort(results)
+		if !opts.DryRun {
+			countBatch("import", results)
+		}
+		return results, nil
+	}
+
+	// 2. Resolve taken IDs, including those taken earlier in the import
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.asset.GetID()
+	}
+	existing, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing assets: %w", err)
+	}
+	var creates, replaces []importRow
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		id := row.asset.GetID()
+		old, exists := existing[id]
+		if !exists && !seen[id] {
+			seen[id] = true
+			creates = append(creates, row)
+			continue
+		}
+		switch opts.OnConflict {
+		case favorites.ConflictNewID:
+			row.asset = favorites.WithID(row.asset, uuid.NewString())
+			results[row.index].ID = row.asset.GetID()
+			creates = append(creates, row)
+		case favorites.ConflictOverwrite:
+			switch {
+			case !exists || seen[id]:
+				// Replacing an asset of the same import would lose one of them.
+				results[row.index].Err = favorites.ErrAlreadyExists
+			case old.GetUserID() != userID:
+				results[row.index].Err = favorites.ErrForbidden
+			default:
+				seen[id] = true
+				row.old = old
+				if row.oldSize, err = favorites.StoredSize(old); err != nil {
+					return nil, fmt.Errorf("failed to measure asset: %w", err)
+				}
+				replaces = append(replaces, row)
+			}
+		default:
+			results[row.index].Action = favorites.ActionSkipped
+		}
+	}
+
+	// 3. Save DB, with the events, within the user's quota
+	var created, replaced []importRow
+	err = s.repo.WithTx(ctx, func(repo ports.FavoriteRepository, outbox ports.Outbox) error {
+		created, replaced = nil, nil
+		var usage favorites.Usage
+		if s.quota.MaxFavorites > 0 || s.quota.MaxBytes > 0 {
+			var err error
+			if usage, err = repo.Usage(ctx, userID); err != nil {
+				return err
+			}
+		}
+		var toCreate, toReplace []importRow
+		for _, row := range replaces {
+			if err := s.checkImportQuota(s.quota.CheckReplace(usage, row.oldSize, row.size), results, row); err != nil {
+				continue
+			}
+			usage.Bytes += row.size - row.oldSize
+			toReplace = append(toReplace, row)
+		}
+		for _, row := range creates {
+			if err := s.checkImportQuota(s.quota.Check(usage, row.size), results, row); err != nil {
+				continue
+			}
+			usage.Favorites++
+			usage.Bytes += row.size
+			toCreate = append(toCreate, row)
+		}
+		if opts.Mode == favorites.BatchAtomic && favorites.Failed(results) {
+			return favorites.ErrBatchAborted
+		}
+
+		var events []favorites.Event
+		ok, err := repo.ReplaceBatch(ctx, assetsOfRows(toReplace))
+		if err != nil {
+			return err
+		}
+		for j, row := range toReplace {
+			if !ok[j] {
+				// Deleted since it was looked up.
+				results[row.index].Err = favorites.ErrNotFound
+				continue
+			}
+			results[row.index].Action = favorites.ActionReplaced
+			replaced = append(replaced, row)
+			events = append(events, favorites.NewEvent(favorites.EventUpdated, row.asset, now))
+		}
+		if ok, err = repo.SaveBatch(ctx, assetsOfRows(toCreate)); err != nil {
+			return err
+		}
+		for j, row := range toCreate {
+			if !ok[j] {
+				// Taken since it was looked up.
+				results[row.index].Err = favorites.ErrAlreadyExists
+				continue
+			}
+			results[row.index].Action = favorites.ActionCreated
+			created = append(created, row)
+			events = append(events, favorites.NewEvent(favorites.EventCreated, row.asset, now))
+		}
+		if opts.Mode == favorites.BatchAtomic && favorites.Failed(results) {
+			return favorites.ErrBatchAborted
+		}
+		if err := outbox.Add(ctx, events...); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, favorites.ErrBatchAborted):
+		favorites.Abort(results)
+		if !opts.DryRun {
+			countBatch("import", results)
+		}
+		return results, nil
+	case errors.Is(err, errDryRun):
+		return results, nil
+	case err != nil:
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to import to db: %w", err)
+	}
+	countBatch("import", results)
+
+	written := make([]favorites.Asset, 0, len(created)+len(replaced))
+	for _, row := range replaced {
+		recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteUpdated, userID, row.old, row.asset)
+		written = append(written, row.asset)
+	}
+	for _, row := range created {
+		recordChange(ctx, s.audit, s.logger, audit.ActionFavoriteCreated, userID, nil, row.asset)
+		written = append(written, row.asset)
+	}
+
+	// 4. Write-Through in one round trip, enrichment in the background
+	s.cacheAndEnqueueBatch(ctx, written)
+	return results, nil
+}
+
+// checkImportQuota records err, a quota check of row, as its outcome.
+func (s *Service) checkImportQuota(err error, results []favorites.BatchResult, row importRow) error {
+	if err == nil {
+		return nil
+	}
+	var qerr *favorites.QuotaError
+	if errors.As(err, &qerr) {
+		quotaRejections.WithLabelValues(qerr.Reason).Inc()
+	}
+	results[row.index].Err = err
+	return err
+}
+
+func assetsOfRows(rows []importRow) []favorites.Asset {
+	assets := make([]favorites.Asset, len(rows))
+	for i, row := range rows {
+		assets[i] = row.asset
+	}
+	return assets
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"go-favorites-app/internal/core/domain/favorites"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_Import(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&testWriter{}, nil))
+	mine, theirs := batchInsight("1", "old"), batchInsight("2", "b")
+	theirs.UserID = "user2"
+	existing := map[string]favorites.Asset{"1": mine, "2": theirs}
+	assets := func() []favorites.Asset {
+		return []favorites.Asset{batchInsight("1", "new"), batchInsight("2", "x"), batchInsight("3", "c"), batchInsight("3", "d")}
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		repo, cache, queue := new(MockRepository), new(MockCache), new(MockQueue)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), queue, logger)
+
+		repo.On("FindByIDs", mock.Anything, []string{"1", "2", "3", "3"}).Return(existing, nil).Once()
+		repo.On("ReplaceBatch", mock.Anything, []favorites.Asset{}).Return([]bool{}, nil).Once()
+		repo.On("SaveBatch", mock.Anything, mock.MatchedBy(func(batch []favorites.Asset) bool {
+			return len(batch) == 1 && batch[0].GetID() == "3"
+		})).Return([]bool{true}, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+		cache.On("AddBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"3"}).Return(nil).Once()
+
+		results, err := svc.Import(context.Background(), "user1", assets(), favorites.ImportOptions{Mode: favorites.BatchBestEffort, OnConflict: favorites.ConflictSkip})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []favorites.BatchResult{
+			{ID: "1", Action: favorites.ActionSkipped},
+			{ID: "2", Action: favorites.ActionSkipped},
+			{ID: "3", Action: favorites.ActionCreated},
+			{ID: "3", Action: favorites.ActionSkipped},
+		}, results)
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		repo, cache, queue := new(MockRepository), new(MockCache), new(MockQueue)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), queue, logger)
+
+		repo.On("FindByIDs", mock.Anything, mock.Anything).Return(existing, nil).Once()
+		repo.On("ReplaceBatch", mock.Anything, mock.MatchedBy(func(batch []favorites.Asset) bool {
+			return len(batch) == 1 && batch[0].(favorites.Insight).Content == "new"
+		})).Return([]bool{true}, nil).Once()
+		repo.On("SaveBatch", mock.Anything, mock.Anything).Return([]bool{true}, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []favorites.Event) bool {
+			return len(events) == 2 && events[0].Type == favorites.EventUpdated && events[1].Type == favorites.EventCreated
+		})).Return(nil).Once()
+		cache.On("AddBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		queue.On("Enqueue", mock.Anything, []string{"1", "3"}).Return(nil).Once()
+
+		results, err := svc.Import(context.Background(), "user1", assets(), favorites.ImportOptions{Mode: favorites.BatchBestEffort, OnConflict: favorites.ConflictOverwrite})
+
+		assert.NoError(t, err)
+		assert.Equal(t, favorites.ActionReplaced, results[0].Action)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrForbidden)
+		assert.Equal(t, favorites.ActionCreated, results[2].Action)
+		assert.ErrorIs(t, results[3].Err, favorites.ErrAlreadyExists)
+		repo.Outbox.AssertExpectations(t)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("new ids in a dry run", func(t *testing.T) {
+		repo, cache := new(MockRepository), new(MockCache)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), logger)
+
+		repo.On("FindByIDs", mock.Anything, mock.Anything).Return(existing, nil).Once()
+		repo.On("ReplaceBatch", mock.Anything, []favorites.Asset{}).Return([]bool{}, nil).Once()
+		repo.On("SaveBatch", mock.Anything, mock.MatchedBy(func(batch []favorites.Asset) bool {
+			return len(batch) == 4 && batch[0].GetID() != "1" && batch[1].GetID() != "2" && batch[2].GetID() == "3" && batch[3].GetID() != "3"
+		})).Return([]bool{true, true, true, true}, nil).Once()
+		repo.Outbox.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+
+		results, err := svc.Import(context.Background(), "user1", assets(), favorites.ImportOptions{Mode: favorites.BatchAtomic, OnConflict: favorites.ConflictNewID, DryRun: true})
+
+		assert.NoError(t, err)
+		for _, res := range results {
+			assert.NoError(t, res.Err)
+			assert.Equal(t, favorites.ActionCreated, res.Action)
+		}
+		assert.NotEqual(t, "1", results[0].ID)
+		repo.AssertExpectations(t)
+		cache.AssertNotCalled(t, "AddBatch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic with a conflict saves nothing", func(t *testing.T) {
+		repo, cache := new(MockRepository), new(MockCache)
+		svc := NewService(repo, cache, new(MockEnricherRegistry), new(MockQueue), logger)
+
+		repo.On("FindByIDs", mock.Anything, mock.Anything).Return(existing, nil).Once()
+
+		results, err := svc.Import(context.Background(), "user1", assets(), favorites.ImportOptions{Mode: favorites.BatchAtomic, OnConflict: favorites.ConflictOverwrite})
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, favorites.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, favorites.ErrForbidden)
+		repo.AssertNotCalled(t, "SaveBatch", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Export(t *testing.T) {
+	repo := new(MockRepository)
+	svc := NewService(repo, new(MockCache), new(MockEnricherRegistry), new(MockQueue), slog.New(slog.NewTextHandler(&testWriter{}, nil)))
+	repo.On("FindByUserAfter", mock.Anything, "user1", "", exportPageSize).Return(assetsOf(batchInsight("1", "a")), nil).Once()
+
+	var ids []string
+	for asset, err := range svc.Export(context.Background(), "user1") {
+		assert.NoError(t, err)
+		ids = append(ids, asset.GetID())
+	}
+
+	assert.Equal(t, []string{"1"}, ids)
+}
@@ -0,0 +1,140 @@
+package service
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	enrichmentJobsProcessed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "enrichment_jobs_processed_total",
+			Help: "Enrichment jobs finished by the workers by outcome (completed, retried, buried)",
+		},
+		[]string{"outcome"},
+	)
+	enrichmentBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "enrichment_backlog",
+			Help: "Enrichment backlog by state (pending and dead jobs, stale assets not queued yet), sampled by the leader",
+		},
+		[]string{"state"},
+	)
+	reenrichmentQueued = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "reenrichment_queued_total",
+			Help: "Stale assets queued for re-enrichment",
+		},
+	)
+	reenrichmentLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "reenrichment_leader",
+			Help: "1 if this replica holds the re-enrichment scheduler lock",
+		},
+	)
+	cacheFillsCoalesced = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_fills_coalesced_total",
+			Help: "Cache misses served by another call's database read, by kind (asset, page) and where that call ran (local, replica)",
+		},
+		[]string{"kind", "scope"},
+	)
+	cacheDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cache_drift",
+			Help: "Cache entries out of line with the database at the last reconciliation, by kind (orphan, stale, missing)",
+		},
+		[]string{"kind"},
+	)
+	cacheDriftRepaired = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_drift_repaired_total",
+			Help: "Cache entries repaired by the reconciler, by kind (orphan, stale)",
+		},
+		[]string{"kind"},
+	)
+	reconcilerLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_reconciler_leader",
+			Help: "1 if this replica holds the cache reconciler lock",
+		},
+	)
+	outboxEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_total",
+			Help: "Outbox events processed by the relay by outcome (published, retried, buried)",
+		},
+		[]string{"outcome"},
+	)
+	outboxDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_deliveries_total",
+			Help: "Outbox event deliveries by handler and outcome (ok, failed)",
+		},
+		[]string{"handler", "outcome"},
+	)
+	outboxPublishDelay = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "outbox_publish_delay_seconds",
+			Help:    "Time from a change to the publication of its event",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		},
+	)
+	webhookDeliveries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Webhook delivery attempts by kind (event, ping) and outcome (delivered, retried, failed)",
+		},
+		[]string{"kind", "outcome"},
+	)
+	feedWatchers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "feed_watchers",
+			Help: "Clients streaming favorite events from this replica",
+		},
+	)
+	feedWatchersDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "feed_watchers_dropped_total",
+			Help: "Event stream clients disconnected for falling behind",
+		},
+	)
+	auditEntries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_entries_total",
+			Help: "Audit entries by action and outcome (recorded, failed)",
+		},
+		[]string{"action", "outcome"},
+	)
+	quotaRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "quota_rejections_total",
+			Help: "Favorites rejected for breaking a limit, by reason (max_favorites, max_bytes, max_field_length)",
+		},
+		[]string{"reason"},
+	)
+	batchItems = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "favorite_batch_items_total",
+			Help: "Items of batch requests by operation (create, update, delete, import) and outcome (applied, failed, aborted)",
+		},
+		[]string{"op", "outcome"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(enrichmentJobsProcessed)
+	prometheus.MustRegister(enrichmentBacklog)
+	prometheus.MustRegister(reenrichmentQueued)
+	prometheus.MustRegister(reenrichmentLeader)
+	prometheus.MustRegister(cacheFillsCoalesced)
+	prometheus.MustRegister(cacheDrift)
+	prometheus.MustRegister(cacheDriftRepaired)
+	prometheus.MustRegister(reconcilerLeader)
+	prometheus.MustRegister(outboxEvents)
+	prometheus.MustRegister(outboxDeliveries)
+	prometheus.MustRegister(outboxPublishDelay)
+	prometheus.MustRegister(webhookDeliveries)
+	prometheus.MustRegister(feedWatchers)
+	prometheus.MustRegister(feedWatchersDropped)
+	prometheus.MustRegister(auditEntries)
+	prometheus.MustRegister(quotaRejections)
+	prometheus.MustRegister(batchItems)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+// OutboxRelayConfig tunes the relay that publishes outbox events.
+type OutboxRelayConfig struct {
+	// PollInterval is how long to wait when no events are due.
+	PollInterval time.Duration
+	// BatchSize is how many events are claimed at once.
+	BatchSize int
+	// Lease is how long claimed events are hidden from other replicas. It
+	// must exceed the time to publish a batch, or events are published twice,
+	// so it is raised to at least BatchSize × handlers × HandlerTimeout plus
+	// outboxLeaseMargin.
+	Lease time.Duration
+	// HandlerTimeout bounds one handler call.
+	HandlerTimeout time.Duration
+	// MaxAttempts failed deliveries bury an event.
+	MaxAttempts int
+	// Backoff spaces out retries of a failing event.
+	Backoff resilience.Backoff
+}
+
+// outboxLeaseMargin covers the store calls made while a batch is published.
+const outboxLeaseMargin = 30 * time.Second
+
+func (c *OutboxRelayConfig) setDefaults(handlers int) {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 500 * time.Millisecond
+	}
+	if c.BatchSize < 1 {
+		c.BatchSize = 100
+	}
+	if c.HandlerTimeout <= 0 {
+		c.HandlerTimeout = 5 * time.Second
+	}
+	// Every event of a batch may take each handler's full timeout.
+	if minLease := time.Duration(c.BatchSize*max(handlers, 1))*c.HandlerTimeout + outboxLeaseMargin; c.Lease < minLease {
+		c.Lease = minLease
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 10
+	}
+	if c.Backoff == (resilience.Backoff{}) {
+		c.Backoff = resilience.Backoff{Base: time.Second, Max: 5 * time.Minute}
+	}
+}
+
+// OutboxRelay publishes the events recorded by favorite changes to the
+// subscribed handlers, at least once: an event is removed only after every
+// handler accepted it, and a failed event is delivered to all of them again.
+// Every replica can run a relay; claims keep them from sharing events.
+type OutboxRelay struct {
+	store    ports.OutboxStore
+	logger   *slog.Logger
+	names    []string
+	handlers []ports.EventHandler
+}
+
+func NewOutboxRelay(store ports.OutboxStore, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{store: store, logger: logger}
+}
+
+// Subscribe adds a handler for every event. The name labels its logs and metrics.
+func (r *OutboxRelay) Subscribe(name string, h ports.EventHandler) {
+	r.names = append(r.names, name)
+	r.handlers = append(r.handlers, h)
+}
+
+// Run publishes events until ctx is canceled. A batch being published when
+// ctx is canceled is finished first.
+func (r *OutboxRelay) Run(ctx context.Context, cfg OutboxRelayConfig) {
+	cfg.setDefaults(len(r.handlers))
+
+	publishCtx := context.WithoutCancel(ctx)
+	for ctx.Err() == nil {
+		messages, err := r.store.Claim(ctx, cfg.BatchSize, cfg.Lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Error("failed to claim outbox events", "error", err)
+			}
+			_ = resilience.Sleep(ctx, cfg.PollInterval)
+			continue
+		}
+		if len(messages) == 0 {
+			_ = resilience.Sleep(ctx, cfg.PollInterval)
+			continue
+		}
+		for _, m := range messages {
+			r.publish(publishCtx, m, cfg)
+		}
+	}
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, m ports.OutboxMessage, cfg OutboxRelayConfig) {
+	ctx, span := tracer.Start(ctx, "OutboxRelay.publish", trace.WithAttributes(
+		attribute.String("event.id", m.Event.ID),
+		attribute.String("event.type", string(m.Event.Type)),
+		attribute.String("asset.id", m.Event.AssetID),
+		attribute.Int("event.attempt", m.Attempts),
+	))
+	defer span.End()
+
+	var errs []error
+	for i, h := range r.handlers {
+		if err := r.handle(ctx, r.names[i], h, m.Event, cfg.HandlerTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.names[i], err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	switch {
+	case err == nil:
+		if cerr := r.store.Complete(ctx, m.ID); cerr != nil {
+			// The lease runs out and the event is published again.
+			r.logger.Error("failed to complete outbox event", "event_id", m.Event.ID, "error", cerr)
+			return
+		}
+		outboxEvents.WithLabelValues("published").Inc()
+		outboxPublishDelay.Observe(time.Since(m.Event.OccurredAt).Seconds())
+	case m.Attempts >= cfg.MaxAttempts:
+		span.RecordError(err)
+		r.logger.Error("outbox event failed too often, burying", "event_id", m.Event.ID, "type", m.Event.Type, "attempts", m.Attempts, "error", err)
+		if berr := r.store.Bury(ctx, m.ID, err); berr != nil {
+			r.logger.Error("failed to bury outbox event", "event_id", m.Event.ID, "error", berr)
+			return
+		}
+		outboxEvents.WithLabelValues("buried").Inc()
+	default:
+		span.RecordError(err)
+		at := time.Now().Add(cfg.Backoff.Delay(m.Attempts - 1))
+		r.logger.Warn("failed to publish outbox event, retrying", "event_id", m.Event.ID, "type", m.Event.Type, "attempt", m.Attempts, "retry_at", at, "error", err)
+		if rerr := r.store.Retry(ctx, m.ID, at, err); rerr != nil {
+			r.logger.Error("failed to reschedule outbox event", "event_id", m.Event.ID, "error", rerr)
+			return
+		}
+		outboxEvents.WithLabelValues("retried").Inc()
+	}
+}
+
+func (r *OutboxRelay) handle(ctx context.Context, name string, h ports.EventHandler, event favorites.Event, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := h.Handle(ctx, event); err != nil {
+		outboxDeliveries.WithLabelValues(name, "failed").Inc()
+		return err
+	}
+	outboxDeliveries.WithLabelValues(name, "ok").Inc()
+	return nil
+}
+
+// CacheInvalidator drops cached copies of changed assets. It backs up the
+// best-effort invalidation done while handling the request, which is lost
+// if the cache is unreachable at that moment.
+type CacheInvalidator struct {
+	cache ports.Cache
+}
+
+// Ensure CacheInvalidator implements ports.EventHandler
+var _ ports.EventHandler = (*CacheInvalidator)(nil)
+
+func NewCacheInvalidator(cache ports.Cache) *CacheInvalidator {
+	return &CacheInvalidator{cache: cache}
+}
+
+// Handle is idempotent and does not depend on the order of events: new
+// assets are left to the write-through, updated ones are evicted and read
+// back from the database, deleted ones are removed from the list too.
+func (c *CacheInvalidator) Handle(ctx context.Context, event favorites.Event) error {
+	switch event.Type {
+	case favorites.EventUpdated:
+		return c.cache.Invalidate(ctx, event.AssetID)
+	case favorites.EventDeleted:
+		return c.cache.Remove(ctx, event.AssetID)
+	}
+	return nil
+}
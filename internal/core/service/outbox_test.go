@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+type MockOutboxStore struct {
+	mock.Mock
+}
+
+func (m *MockOutboxStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]ports.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxStore) Complete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) Retry(ctx context.Context, id int64, at time.Time, cause error) error {
+	args := m.Called(ctx, id, at, cause)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) Bury(ctx context.Context, id int64, cause error) error {
+	args := m.Called(ctx, id, cause)
+	return args.Error(0)
+}
+
+type MockEventHandler struct {
+	mock.Mock
+}
+
+func (m *MockEventHandler) Handle(ctx context.Context, event favorites.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func newRelayTestService() (*OutboxRelay, *MockOutboxStore, *MockEventHandler, *MockEventHandler) {
+	store := new(MockOutboxStore)
+	relay := NewOutboxRelay(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	first, second := new(MockEventHandler), new(MockEventHandler)
+	relay.Subscribe("first", first)
+	relay.Subscribe("second", second)
+	return relay, store, first, second
+}
+
+func TestOutboxRelay_Publish(t *testing.T) {
+	cfg := OutboxRelayConfig{MaxAttempts: 3}
+	cfg.setDefaults(2)
+	event := favorites.Event{ID: "e1", Type: favorites.EventDeleted, AssetID: "a1", OccurredAt: time.Now()}
+
+	t.Run("completes once every handler succeeded", func(t *testing.T) {
+		relay, store, first, second := newRelayTestService()
+		first.On("Handle", mock.Anything, event).Return(nil).Once()
+		second.On("Handle", mock.Anything, event).Return(nil).Once()
+		store.On("Complete", mock.Anything, int64(7)).Return(nil).Once()
+
+		relay.publish(context.Background(), ports.OutboxMessage{ID: 7, Event: event, Attempts: 1}, cfg)
+
+		first.AssertExpectations(t)
+		second.AssertExpectations(t)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("a failing handler retries the event for all", func(t *testing.T) {
+		relay, store, first, second := newRelayTestService()
+		first.On("Handle", mock.Anything, event).Return(errors.New("redis down")).Once()
+		second.On("Handle", mock.Anything, event).Return(nil).Once()
+		start := time.Now()
+		store.On("Retry", mock.Anything, int64(7), mock.MatchedBy(func(at time.Time) bool {
+			return !at.Before(start) && !at.After(time.Now().Add(2*time.Second))
+		}), mock.MatchedBy(func(err error) bool {
+			return err.Error() == "first: redis down"
+		})).Return(nil).Once()
+
+		relay.publish(context.Background(), ports.OutboxMessage{ID: 7, Event: event, Attempts: 2}, cfg)
+
+		second.AssertExpectations(t)
+		store.AssertExpectations(t)
+		store.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last attempt buries the event", func(t *testing.T) {
+		relay, store, first, second := newRelayTestService()
+		first.On("Handle", mock.Anything, event).Return(nil).Once()
+		second.On("Handle", mock.Anything, event).Return(errors.New("boom")).Once()
+		store.On("Bury", mock.Anything, int64(7), mock.Anything).Return(nil).Once()
+
+		relay.publish(context.Background(), ports.OutboxMessage{ID: 7, Event: event, Attempts: 3}, cfg)
+
+		store.AssertExpectations(t)
+		store.AssertNotCalled(t, "Retry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestOutboxRelayConfig_Lease(t *testing.T) {
+	cfg := OutboxRelayConfig{BatchSize: 10, HandlerTimeout: time.Second, Lease: time.Second}
+	cfg.setDefaults(3)
+	assert.Equal(t, 30*time.Second+outboxLeaseMargin, cfg.Lease, "a short lease is raised to cover a batch")
+
+	cfg = OutboxRelayConfig{BatchSize: 10, HandlerTimeout: time.Second, Lease: time.Hour}
+	cfg.setDefaults(3)
+	assert.Equal(t, time.Hour, cfg.Lease, "a long enough lease is kept")
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	relay, store, first, second := newRelayTestService()
+	ctx, cancel := context.WithCancel(context.Background())
+	event := favorites.Event{ID: "e1", Type: favorites.EventCreated, AssetID: "a1", OccurredAt: time.Now()}
+
+	// 100 events × 2 handlers × 5s, plus the margin.
+	store.On("Claim", mock.Anything, 100, 1000*time.Second+outboxLeaseMargin).Return([]ports.OutboxMessage{{ID: 1, Event: event, Attempts: 1}}, nil).Once()
+	first.On("Handle", mock.Anything, event).Return(nil).Once()
+	second.On("Handle", mock.Anything, event).Return(nil).Once()
+	// Canceling mid-batch still completes the event being published.
+	store.On("Complete", mock.Anything, int64(1)).Return(nil).Once().Run(func(mock.Arguments) { cancel() })
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx, OutboxRelayConfig{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("relay did not stop after cancellation")
+	}
+	store.AssertExpectations(t)
+}
+
+func TestCacheInvalidator_Handle(t *testing.T) {
+	cache := new(MockCache)
+	invalidator := NewCacheInvalidator(cache)
+	ctx := context.Background()
+
+	cache.On("Invalidate", mock.Anything, "updated").Return(nil).Once()
+	cache.On("Remove", mock.Anything, "deleted").Return(errors.New("redis down")).Once()
+
+	assert.NoError(t, invalidator.Handle(ctx, favorites.Event{Type: favorites.EventCreated, AssetID: "created"}))
+	assert.NoError(t, invalidator.Handle(ctx, favorites.Event{Type: favorites.EventUpdated, AssetID: "updated"}))
+	assert.Error(t, invalidator.Handle(ctx, favorites.Event{Type: favorites.EventDeleted, AssetID: "deleted"}))
+
+	cache.AssertExpectations(t)
+	assert.Len(t, cache.Calls, 2)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/ports"
+)
+
+// ReconcileConfig tunes the job that checks the cache against the database.
+type ReconcileConfig struct {
+	// Interval is how often the scheduler runs a pass.
+	Interval time.Duration
+	// BatchSize is how many IDs are checked per round trip.
+	BatchSize int
+	// DryRun reports drift without repairing it.
+	DryRun bool
+}
+
+func (c *ReconcileConfig) setDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	if c.BatchSize < 1 {
+		c.BatchSize = 500
+	}
+}
+
+// ReconcileReport counts the drift found by one pass.
+type ReconcileReport struct {
+	Scanned int
+	// Orphans are IDs in the set whose asset no longer exists. Only the
+	// asset data expires, so without removal they stay in the set forever.
+	Orphans int
+	// Stale entries are cached copies older than the database row.
+	Stale int
+	// Missing IDs have no cached data. Reads repair them from the database,
+	// so they are only reported.
+	Missing int
+	// Repaired counts the orphans removed and stale entries re-cached.
+	Repaired int
+}
+
+// CacheReconciler finds and repairs drift between the cache and the database
+// left by cache writes that failed or were lost.
+type CacheReconciler struct {
+	repo    ports.FavoriteRepository
+	scanner ports.CacheScanner
+	// cache is written through every tier, so that the replicas drop their
+	// local copies of what is repaired.
+	cache  ports.Cache
+	logger *slog.Logger
+}
+
+func NewCacheReconciler(repo ports.FavoriteRepository, scanner ports.CacheScanner, cache ports.Cache, logger *slog.Logger) *CacheReconciler {
+	return &CacheReconciler{
+		repo:    repo,
+		scanner: scanner,
+		cache:   cache,
+		logger:  logger,
+	}
+}
+
+// Run reconciles every Interval until ctx is canceled. Only the replica
+// holding lock does any work, so every replica can run it.
+func (r *CacheReconciler) Run(ctx context.Context, lock ports.LeaderLock, cfg ReconcileConfig) {
+	cfg.setDefaults()
+
+	defer func() {
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+			r.logger.Error("failed to release cache reconciler lock", "error", err)
+		}
+		reconcilerLeader.Set(0)
+	}()
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		r.reconcileAsLeader(ctx, lock, cfg)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *CacheReconciler) reconcileAsLeader(ctx context.Context, lock ports.LeaderLock, cfg ReconcileConfig) {
+	leader, err := lock.TryAcquire(ctx)
+	if err != nil && ctx.Err() == nil {
+		r.logger.Error("failed to acquire cache reconciler lock", "error", err)
+	}
+	if !leader {
+		reconcilerLeader.Set(0)
+		return
+	}
+	reconcilerLeader.Set(1)
+
+	if _, err := r.Reconcile(ctx, cfg); err != nil && ctx.Err() == nil {
+		r.logger.Error("cache reconciliation failed", "error", err)
+	}
+}
+
+// Reconcile makes one pass over the ID set: IDs of deleted assets are removed
+// and cached copies older than their row are replaced. With DryRun it only
+// reports what it would repair. The drift gauges are updated once a pass
+// completes.
+func (r *CacheReconciler) Reconcile(ctx context.Context, cfg ReconcileConfig) (ReconcileReport, error) {
+	cfg.setDefaults()
+
+	ctx, span := tracer.Start(ctx, "CacheReconciler.Reconcile")
+	defer span.End()
+
+	var report ReconcileReport
+	var cursor uint64
+	for {
+		ids, next, err := r.scanner.ScanSet(ctx, cursor, int64(cfg.BatchSize))
+		if err != nil {
+			span.RecordError(err)
+			return report, err
+		}
+		if err := r.reconcileBatch(ctx, ids, cfg.DryRun, &report); err != nil {
+			span.RecordError(err)
+			return report, err
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	cacheDrift.WithLabelValues("orphan").Set(float64(report.Orphans))
+	cacheDrift.WithLabelValues("stale").Set(float64(report.Stale))
+	cacheDrift.WithLabelValues("missing").Set(float64(report.Missing))
+	r.logger.Info("cache reconciled",
+		"scanned", report.Scanned,
+		"orphans", report.Orphans,
+		"stale", report.Stale,
+		"missing", report.Missing,
+		"repaired", report.Repaired,
+		"dry_run", cfg.DryRun,
+	)
+	return report, nil
+}
+
+// cachedVersion is the part of a cached asset that dates it.
+type cachedVersion struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	Enrichment struct {
+		UpdatedAt time.Time `json:"updated_at"`
+	} `json:"enrichment"`
+}
+
+func (r *CacheReconciler) reconcileBatch(ctx context.Context, ids []string, dryRun bool, report *ReconcileReport) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	report.Scanned += len(ids)
+
+	// Read the cache before the database, so a write landing in between
+	// makes the row look newer rather than hiding a stale entry.
+	cached, err := r.scanner.GetBatch(ctx, ids)
+	if err != nil {
+		return err
+	}
+	assets, err := r.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		asset, exists := assets[id]
+		if !exists {
+			report.Orphans++
+			r.repair(ctx, "orphan", id, dryRun, report, func() error { return r.cache.Remove(ctx, id) })
+			continue
+		}
+
+		data, ok := cached[id]
+		if !ok {
+			report.Missing++
+			continue
+		}
+		if !isStale(asset, data) {
+			continue
+		}
+		report.Stale++
+		r.repair(ctx, "stale", id, dryRun, report, func() error { return r.recache(ctx, asset) })
+	}
+	return nil
+}
+
+// isStale reports whether the cached copy predates the row. An unreadable
+// copy is stale too.
+func isStale(asset favorites.Asset, data []byte) bool {
+	var v cachedVersion
+	if err := json.Unmarshal(data, &v); err != nil {
+		return true
+	}
+	return asset.GetUpdatedAt().After(v.UpdatedAt) || asset.GetEnrichment().UpdatedAt.After(v.Enrichment.UpdatedAt)
+}
+
+// repair applies fix unless this is a dry run. A failed fix is logged and
+// left for the next pass.
+func (r *CacheReconciler) repair(ctx context.Context, kind, id string, dryRun bool, report *ReconcileReport, fix func() error) {
+	if dryRun {
+		r.logger.InfoContext(ctx, "cache drift found", "kind", kind, "id", id)
+		return
+	}
+	if err := fix(); err != nil {
+		r.logger.WarnContext(ctx, "failed to repair cache drift", "kind", kind, "id", id, "error", err)
+		return
+	}
+	report.Repaired++
+	cacheDriftRepaired.WithLabelValues(kind).Inc()
+}
+
+// recache replaces the cached copy, keeping the ID's position in the set.
+func (r *CacheReconciler) recache(ctx context.Context, asset favorites.Asset) error {
+	data, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	// Invalidate first: only a removal reaches the other replicas' local copies.
+	if err := r.cache.Invalidate(ctx, asset.GetID()); err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, asset.GetID(), data)
+}
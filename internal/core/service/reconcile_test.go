@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+)
+
+type MockCacheScanner struct {
+	mock.Mock
+}
+
+func (m *MockCacheScanner) ScanSet(ctx context.Context, cursor uint64, count int64) ([]string, uint64, error) {
+	args := m.Called(ctx, cursor, count)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]string), args.Get(1).(uint64), args.Error(2)
+}
+
+func (m *MockCacheScanner) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]byte), args.Error(1)
+}
+
+func newReconcilerTestService() (*CacheReconciler, *MockRepository, *MockCacheScanner, *MockCache) {
+	repo := new(MockRepository)
+	scanner := new(MockCacheScanner)
+	cache := new(MockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewCacheReconciler(repo, scanner, cache, logger), repo, scanner, cache
+}
+
+func TestCacheReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	then := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := then.Add(time.Minute)
+
+	insight := func(id string, updatedAt, enrichedAt time.Time) favorites.Asset {
+		asset := favorites.Insight{BaseAsset: favorites.BaseAsset{ID: id, Type: favorites.AssetTypeInsight, Name: "n"}}
+		return favorites.WithUpdatedAt(favorites.WithEnrichment(asset, favorites.PendingEnrichment(enrichedAt)), updatedAt)
+	}
+	encode := func(asset favorites.Asset) []byte {
+		data, _ := json.Marshal(asset)
+		return data
+	}
+
+	// Two pages: "gone" was deleted, "edited" and "enriched" changed in the
+	// database, "fresh" is current and "expired" has no cached data.
+	setup := func() (*CacheReconciler, *MockRepository, *MockCacheScanner, *MockCache, map[string]favorites.Asset) {
+		r, repo, scanner, cache := newReconcilerTestService()
+		rows := map[string]favorites.Asset{
+			"edited":   insight("edited", later, then),
+			"enriched": insight("enriched", then, later),
+			"fresh":    insight("fresh", then, then),
+			"expired":  insight("expired", then, then),
+		}
+		scanner.On("ScanSet", mock.Anything, uint64(0), int64(3)).Return([]string{"gone", "edited", "fresh"}, uint64(7), nil).Once()
+		scanner.On("ScanSet", mock.Anything, uint64(7), int64(3)).Return([]string{"enriched", "expired"}, uint64(0), nil).Once()
+		scanner.On("GetBatch", mock.Anything, []string{"gone", "edited", "fresh"}).Return(map[string][]byte{
+			"gone":   encode(insight("gone", then, then)),
+			"edited": encode(insight("edited", then, then)),
+			"fresh":  encode(rows["fresh"]),
+		}, nil).Once()
+		scanner.On("GetBatch", mock.Anything, []string{"enriched", "expired"}).Return(map[string][]byte{
+			"enriched": encode(insight("enriched", then, then)),
+		}, nil).Once()
+		repo.On("FindByIDs", mock.Anything, []string{"gone", "edited", "fresh"}).Return(map[string]favorites.Asset{
+			"edited": rows["edited"],
+			"fresh":  rows["fresh"],
+		}, nil).Once()
+		repo.On("FindByIDs", mock.Anything, []string{"enriched", "expired"}).Return(map[string]favorites.Asset{
+			"enriched": rows["enriched"],
+			"expired":  rows["expired"],
+		}, nil).Once()
+		return r, repo, scanner, cache, rows
+	}
+
+	t.Run("repairs orphans and stale entries", func(t *testing.T) {
+		r, _, _, cache, rows := setup()
+		cache.On("Remove", mock.Anything, "gone").Return(nil).Once()
+		for _, id := range []string{"edited", "enriched"} {
+			cache.On("Invalidate", mock.Anything, id).Return(nil).Once()
+			cache.On("Set", mock.Anything, id, encode(rows[id])).Return(nil).Once()
+		}
+
+		report, err := r.Reconcile(ctx, ReconcileConfig{BatchSize: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ReconcileReport{Scanned: 5, Orphans: 1, Stale: 2, Missing: 1, Repaired: 3}, report)
+		cache.AssertExpectations(t)
+		cache.AssertNotCalled(t, "Set", mock.Anything, "fresh", mock.Anything)
+		cache.AssertNotCalled(t, "Set", mock.Anything, "expired", mock.Anything)
+	})
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		r, _, _, cache, _ := setup()
+
+		report, err := r.Reconcile(ctx, ReconcileConfig{BatchSize: 3, DryRun: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ReconcileReport{Scanned: 5, Orphans: 1, Stale: 2, Missing: 1}, report)
+		cache.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "Invalidate", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed repair is left for the next pass", func(t *testing.T) {
+		r, _, _, cache, rows := setup()
+		cache.On("Remove", mock.Anything, "gone").Return(errors.New("redis down")).Once()
+		cache.On("Invalidate", mock.Anything, mock.Anything).Return(nil)
+		cache.On("Set", mock.Anything, "edited", encode(rows["edited"])).Return(nil).Once()
+		cache.On("Set", mock.Anything, "enriched", encode(rows["enriched"])).Return(nil).Once()
+
+		report, err := r.Reconcile(ctx, ReconcileConfig{BatchSize: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Orphans)
+		assert.Equal(t, 2, report.Repaired)
+	})
+
+	t.Run("database error stops the pass", func(t *testing.T) {
+		r, repo, scanner, cache := newReconcilerTestService()
+		scanner.On("ScanSet", mock.Anything, uint64(0), int64(500)).Return([]string{"a"}, uint64(3), nil).Once()
+		scanner.On("GetBatch", mock.Anything, []string{"a"}).Return(map[string][]byte{}, nil).Once()
+		repo.On("FindByIDs", mock.Anything, []string{"a"}).Return(nil, errors.New("db down")).Once()
+
+		_, err := r.Reconcile(ctx, ReconcileConfig{})
+
+		assert.Error(t, err)
+		scanner.AssertNumberOfCalls(t, "ScanSet", 1)
+		cache.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCacheReconciler_ReconcileAsLeader(t *testing.T) {
+	t.Run("follower does nothing", func(t *testing.T) {
+		r, _, scanner, _ := newReconcilerTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(false, nil).Once()
+
+		r.reconcileAsLeader(context.Background(), lock, ReconcileConfig{})
+
+		scanner.AssertNotCalled(t, "ScanSet", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("leader reconciles", func(t *testing.T) {
+		r, _, scanner, _ := newReconcilerTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(true, nil).Once()
+		scanner.On("ScanSet", mock.Anything, uint64(0), int64(500)).Return([]string{}, uint64(0), nil).Once()
+
+		r.reconcileAsLeader(context.Background(), lock, ReconcileConfig{})
+
+		scanner.AssertExpectations(t)
+	})
+}
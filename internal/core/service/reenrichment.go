@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+// ReenrichmentConfig tunes the scheduler that refreshes stale enrichment.
+type ReenrichmentConfig struct {
+	// Interval is how often the scheduler looks for stale assets.
+	Interval time.Duration
+	// MaxAge is how old enrichment may get before it is refreshed.
+	MaxAge time.Duration
+	// BatchSize caps the refresh jobs waiting in the queue at once. The
+	// enrichment workers bound how many of them run concurrently.
+	BatchSize int
+}
+
+func (c *ReenrichmentConfig) setDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = 12 * time.Hour
+	}
+	if c.BatchSize < 1 {
+		c.BatchSize = 100
+	}
+}
+
+// RunReenrichmentScheduler periodically queues assets whose enrichment is
+// older than MaxAge until ctx is canceled. Only the replica holding lock does
+// any work, so every replica can run the scheduler.
+func (s *Service) RunReenrichmentScheduler(ctx context.Context, lock ports.LeaderLock, cfg ReenrichmentConfig) {
+	cfg.setDefaults()
+
+	defer func() {
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+			s.logger.Error("failed to release re-enrichment lock", "error", err)
+		}
+		reenrichmentLeader.Set(0)
+	}()
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		s.reenrichStale(ctx, lock, cfg)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) reenrichStale(ctx context.Context, lock ports.LeaderLock, cfg ReenrichmentConfig) {
+	leader, err := lock.TryAcquire(ctx)
+	if err != nil && ctx.Err() == nil {
+		s.logger.Error("failed to acquire re-enrichment lock", "error", err)
+	}
+	if !leader {
+		reenrichmentLeader.Set(0)
+		return
+	}
+	reenrichmentLeader.Set(1)
+
+	ctx, span := tracer.Start(ctx, "Service.reenrichStale")
+	defer span.End()
+
+	before := time.Now().Add(-cfg.MaxAge)
+	stats, err := s.queue.Stats(ctx, before)
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Error("failed to read enrichment backlog", "error", err)
+		return
+	}
+	enrichmentBacklog.WithLabelValues("pending").Set(float64(stats.Pending))
+	enrichmentBacklog.WithLabelValues("dead").Set(float64(stats.Dead))
+	enrichmentBacklog.WithLabelValues("stale").Set(float64(stats.Stale))
+
+	// Top up to one batch, so refreshes never crowd out enrichment of new saves.
+	room := cfg.BatchSize - stats.Pending
+	if stats.Stale == 0 || room <= 0 {
+		return
+	}
+	queued, err := s.queue.EnqueueStale(ctx, before, room)
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Error("failed to queue stale assets", "error", err)
+		return
+	}
+	reenrichmentQueued.Add(float64(queued))
+	s.logger.Info("queued stale assets for re-enrichment", "count", queued, "stale", stats.Stale)
+}
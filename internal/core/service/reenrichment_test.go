@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/ports"
+)
+
+type MockLeaderLock struct {
+	mock.Mock
+}
+
+func (m *MockLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaderLock) Release(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestService_ReenrichStale(t *testing.T) {
+	cfg := ReenrichmentConfig{MaxAge: time.Hour, BatchSize: 10}
+
+	t.Run("follower does nothing", func(t *testing.T) {
+		svc, _, _, _, queue := newEnrichmentTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(false, nil).Once()
+
+		svc.reenrichStale(context.Background(), lock, cfg)
+
+		queue.AssertNotCalled(t, "Stats", mock.Anything, mock.Anything)
+		queue.AssertNotCalled(t, "EnqueueStale", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lock error does nothing", func(t *testing.T) {
+		svc, _, _, _, queue := newEnrichmentTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(false, errors.New("db down")).Once()
+
+		svc.reenrichStale(context.Background(), lock, cfg)
+
+		queue.AssertNotCalled(t, "Stats", mock.Anything, mock.Anything)
+	})
+
+	t.Run("leader tops up the queue to one batch", func(t *testing.T) {
+		svc, _, _, _, queue := newEnrichmentTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(true, nil).Once()
+
+		start := time.Now()
+		beforeMaxAge := mock.MatchedBy(func(before time.Time) bool {
+			return !before.Before(start.Add(-time.Hour)) && before.Before(start.Add(-time.Hour+time.Minute))
+		})
+		queue.On("Stats", mock.Anything, beforeMaxAge).Return(ports.EnrichmentStats{Pending: 4, Stale: 50}, nil).Once()
+		queue.On("EnqueueStale", mock.Anything, beforeMaxAge, 6).Return(6, nil).Once()
+
+		svc.reenrichStale(context.Background(), lock, cfg)
+
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("full queue is left to drain", func(t *testing.T) {
+		svc, _, _, _, queue := newEnrichmentTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(true, nil).Once()
+		queue.On("Stats", mock.Anything, mock.Anything).Return(ports.EnrichmentStats{Pending: 10, Stale: 50}, nil).Once()
+
+		svc.reenrichStale(context.Background(), lock, cfg)
+
+		queue.AssertNotCalled(t, "EnqueueStale", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("nothing stale", func(t *testing.T) {
+		svc, _, _, _, queue := newEnrichmentTestService()
+		lock := new(MockLeaderLock)
+		lock.On("TryAcquire", mock.Anything).Return(true, nil).Once()
+		queue.On("Stats", mock.Anything, mock.Anything).Return(ports.EnrichmentStats{}, nil).Once()
+
+		svc.reenrichStale(context.Background(), lock, cfg)
+
+		queue.AssertNotCalled(t, "EnqueueStale", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_RunReenrichmentScheduler(t *testing.T) {
+	svc, _, _, _, queue := newEnrichmentTestService()
+	lock := new(MockLeaderLock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lock.On("TryAcquire", mock.Anything).Return(true, nil)
+	queue.On("Stats", mock.Anything, mock.Anything).Return(ports.EnrichmentStats{}, nil).Once().Run(func(mock.Arguments) { cancel() })
+	queue.On("Stats", mock.Anything, mock.Anything).Return(ports.EnrichmentStats{}, nil)
+	lock.On("Release", mock.Anything).Return(nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.RunReenrichmentScheduler(ctx, lock, ReenrichmentConfig{Interval: 10 * time.Millisecond})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduler did not stop after cancellation")
+	}
+
+	// Stepping down lets another replica take over immediately.
+	lock.AssertCalled(t, "Release", mock.Anything)
+	assert.True(t, lock.AssertExpectations(t))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/auth"
+	"go-favorites-app/internal/core/ports"
+)
+
+const (
+	// sessionTTL is how long a login stays valid; bearer tokens expire with their session.
+	sessionTTL = 2 * time.Hour
+	// activeSessionCacheTTL is how often last_seen_at is refreshed, and how long
+	// a revocation can go unnoticed if recording it in the cache fails.
+	activeSessionCacheTTL = 1 * time.Minute
+	// sessionCleanupBatchSize caps the rows one DELETE removes, so a cleanup
+	// never holds locks on many rows at once.
+	sessionCleanupBatchSize = 1000
+)
+
+// SessionService tracks the server-side sessions behind bearer tokens.
+type SessionService struct {
+	repo   ports.SessionRepository
+	cache  ports.SessionCache
+	logger *slog.Logger
+}
+
+func NewSessionService(repo ports.SessionRepository, cache ports.SessionCache, logger *slog.Logger) *SessionService {
+	return &SessionService{repo: repo, cache: cache, logger: logger}
+}
+
+func (s *SessionService) List(ctx context.Context, userID string) ([]auth.Session, error) {
+	ctx, span := tracer.Start(ctx, "SessionService.List", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	sessions, err := s.repo.ListActive(ctx, userID, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	ctx, span := tracer.Start(ctx, "SessionService.Revoke", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	// Session IDs are UUIDs; anything else cannot name one of the user's sessions.
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return auth.ErrSessionNotFound
+	}
+	session, err := s.repo.Revoke(ctx, userID, sessionID, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	cacheRevoked(ctx, s.cache, s.logger, session)
+	return nil
+}
+
+// RunCleanup deletes expired sessions every interval until ctx is canceled.
+// An expired session is rejected whether or not its row exists, so the rows
+// are only kept until the next pass. Replicas may run it concurrently.
+func (s *SessionService) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to delete expired sessions", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeleteExpired deletes every session that has expired, in batches, and
+// returns how many it deleted.
+func (s *SessionService) DeleteExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now()
+	total := 0
+	for {
+		n, err := s.repo.DeleteExpired(ctx, cutoff, sessionCleanupBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < sessionCleanupBatchSize {
+			if total > 0 {
+				s.logger.Info("deleted expired sessions", "count", total)
+			}
+			return total, nil
+		}
+	}
+}
+
+func (s *SessionService) RevokeOthers(ctx context.Context, userID, currentID string) (int, error) {
+	ctx, span := tracer.Start(ctx, "SessionService.RevokeOthers", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer span.End()
+
+	revoked, err := revokeSessions(ctx, s.repo, s.cache, s.logger, userID, currentID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	return len(revoked), nil
+}
+
+// Validate consults the cache first. On a miss it loads the session from the
+// database, records activity and caches the result, so last_seen_at has a
+// resolution of activeSessionCacheTTL.
+func (s *SessionService) Validate(ctx context.Context, userID, sessionID string) error {
+	state, err := s.cache.GetSessionState(ctx, sessionID)
+	if err != nil {
+		// Fall through to the database rather than locking everybody out.
+		s.logger.Warn("session cache lookup failed", "session_id", sessionID, "error", err)
+	}
+	switch state {
+	case auth.SessionActive:
+		return nil
+	case auth.SessionRevoked:
+		return auth.ErrSessionRevoked
+	}
+
+	now := time.Now()
+	session, err := s.repo.FindByID(ctx, sessionID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return auth.ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return auth.ErrSessionRevoked
+	}
+	if !session.IsActive(now) {
+		cacheRevoked(ctx, s.cache, s.logger, session)
+		return auth.ErrSessionRevoked
+	}
+
+	if err := s.repo.Touch(ctx, sessionID, now); err != nil {
+		s.logger.Warn("failed to record session activity", "session_id", sessionID, "error", err)
+	}
+	ttl := min(activeSessionCacheTTL, session.ExpiresAt.Sub(now))
+	if err := s.cache.SetSessionState(ctx, sessionID, auth.SessionActive, ttl); err != nil {
+		s.logger.Warn("failed to cache session state", "session_id", sessionID, "error", err)
+	}
+	return nil
+}
+
+// revokeSessions ends every active session of the user but keepID, which
+// may be empty, and caches each revocation.
+func revokeSessions(ctx context.Context, repo ports.SessionRepository, cache ports.SessionCache, logger *slog.Logger, userID, keepID string) ([]auth.Session, error) {
+	revoked, err := repo.RevokeAllExcept(ctx, userID, keepID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range revoked {
+		cacheRevoked(ctx, cache, logger, session)
+	}
+	return revoked, nil
+}
+
+// cacheRevoked remembers a revocation until the session would have expired anyway,
+// after which the token's own exp claim rejects it. A nil cache is skipped.
+func cacheRevoked(ctx context.Context, cache ports.SessionCache, logger *slog.Logger, session auth.Session) {
+	ttl := time.Until(session.ExpiresAt)
+	if cache == nil || ttl <= 0 {
+		return
+	}
+	if err := cache.SetSessionState(ctx, session.ID, auth.SessionRevoked, ttl); err != nil {
+		// Other instances notice once their cached "active" entry expires.
+		logger.Warn("failed to cache session revocation", "session_id", session.ID, "error", err)
+	}
+}
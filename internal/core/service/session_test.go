@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/auth"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session auth.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByID(ctx context.Context, id string) (auth.Session, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(auth.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActive(ctx context.Context, userID string, now time.Time) ([]auth.Session, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Get(0).([]auth.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, userID, id string, at time.Time) (auth.Session, error) {
+	args := m.Called(ctx, userID, id, at)
+	return args.Get(0).(auth.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeAllExcept(ctx context.Context, userID, keepID string, at time.Time) ([]auth.Session, error) {
+	args := m.Called(ctx, userID, keepID, at)
+	return args.Get(0).([]auth.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) DeleteExpired(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Int(0), args.Error(1)
+}
+
+type MockSessionCache struct {
+	mock.Mock
+}
+
+func (m *MockSessionCache) GetSessionState(ctx context.Context, sessionID string) (auth.SessionState, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Get(0).(auth.SessionState), args.Error(1)
+}
+
+func (m *MockSessionCache) SetSessionState(ctx context.Context, sessionID string, state auth.SessionState, ttl time.Duration) error {
+	args := m.Called(ctx, sessionID, state, ttl)
+	return args.Error(0)
+}
+
+func TestSessionService_Validate(t *testing.T) {
+	active := auth.Session{ID: "s1", UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("cached active skips database", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionActive, nil)
+
+		assert.NoError(t, svc.Validate(context.Background(), "user1", "s1"))
+		repo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cached revoked", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionRevoked, nil)
+
+		assert.ErrorIs(t, svc.Validate(context.Background(), "user1", "s1"), auth.ErrSessionRevoked)
+	})
+
+	t.Run("cache miss loads, touches and caches", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionState(""), nil)
+		repo.On("FindByID", mock.Anything, "s1").Return(active, nil)
+		repo.On("Touch", mock.Anything, "s1", mock.Anything).Return(nil).Once()
+		cache.On("SetSessionState", mock.Anything, "s1", auth.SessionActive, activeSessionCacheTTL).Return(nil).Once()
+
+		assert.NoError(t, svc.Validate(context.Background(), "user1", "s1"))
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("cache outage falls back to database", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionState(""), errors.New("redis down"))
+		cache.On("SetSessionState", mock.Anything, "s1", mock.Anything, mock.Anything).Return(errors.New("redis down"))
+		repo.On("FindByID", mock.Anything, "s1").Return(active, nil)
+		repo.On("Touch", mock.Anything, "s1", mock.Anything).Return(nil)
+
+		assert.NoError(t, svc.Validate(context.Background(), "user1", "s1"))
+	})
+
+	t.Run("revoked in database is cached", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		revoked := active
+		revoked.RevokedAt = time.Now()
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionState(""), nil)
+		repo.On("FindByID", mock.Anything, "s1").Return(revoked, nil)
+		cache.On("SetSessionState", mock.Anything, "s1", auth.SessionRevoked, mock.Anything).Return(nil).Once()
+
+		assert.ErrorIs(t, svc.Validate(context.Background(), "user1", "s1"), auth.ErrSessionRevoked)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("session of another user", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionState(""), nil)
+		repo.On("FindByID", mock.Anything, "s1").Return(active, nil)
+
+		assert.ErrorIs(t, svc.Validate(context.Background(), "user2", "s1"), auth.ErrSessionRevoked)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		repo := new(MockSessionRepository)
+		cache := new(MockSessionCache)
+		svc := NewSessionService(repo, cache, quietLogger)
+
+		cache.On("GetSessionState", mock.Anything, "s1").Return(auth.SessionState(""), nil)
+		repo.On("FindByID", mock.Anything, "s1").Return(auth.Session{}, auth.ErrSessionNotFound)
+
+		assert.ErrorIs(t, svc.Validate(context.Background(), "user1", "s1"), auth.ErrSessionRevoked)
+	})
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	repo := new(MockSessionRepository)
+	cache := new(MockSessionCache)
+	svc := NewSessionService(repo, cache, quietLogger)
+	s1, s9 := uuid.NewString(), uuid.NewString()
+
+	session := auth.Session{ID: s1, UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.On("Revoke", mock.Anything, "user1", s1, mock.Anything).Return(session, nil)
+	repo.On("Revoke", mock.Anything, "user1", s9, mock.Anything).Return(auth.Session{}, auth.ErrSessionNotFound)
+	cache.On("SetSessionState", mock.Anything, s1, auth.SessionRevoked, mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, svc.Revoke(context.Background(), "user1", s1))
+	assert.ErrorIs(t, svc.Revoke(context.Background(), "user1", s9), auth.ErrSessionNotFound)
+	assert.ErrorIs(t, svc.Revoke(context.Background(), "user1", "not-a-uuid"), auth.ErrSessionNotFound)
+	repo.AssertNotCalled(t, "Revoke", mock.Anything, "user1", "not-a-uuid", mock.Anything)
+	cache.AssertExpectations(t)
+}
+
+func TestSessionService_DeleteExpired(t *testing.T) {
+	repo := new(MockSessionRepository)
+	svc := NewSessionService(repo, new(MockSessionCache), quietLogger)
+
+	repo.On("DeleteExpired", mock.Anything, mock.Anything, sessionCleanupBatchSize).Return(sessionCleanupBatchSize, nil).Once()
+	repo.On("DeleteExpired", mock.Anything, mock.Anything, sessionCleanupBatchSize).Return(3, nil).Once()
+
+	n, err := svc.DeleteExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, sessionCleanupBatchSize+3, n)
+	repo.AssertExpectations(t)
+}
+
+func TestSessionService_RevokeOthers(t *testing.T) {
+	repo := new(MockSessionRepository)
+	cache := new(MockSessionCache)
+	svc := NewSessionService(repo, cache, quietLogger)
+
+	exp := time.Now().Add(time.Hour)
+	repo.On("RevokeAllExcept", mock.Anything, "user1", "current", mock.Anything).Return([]auth.Session{
+		{ID: "s1", ExpiresAt: exp},
+		{ID: "s2", ExpiresAt: exp},
+	}, nil)
+	cache.On("SetSessionState", mock.Anything, "s1", auth.SessionRevoked, mock.Anything).Return(nil).Once()
+	cache.On("SetSessionState", mock.Anything, "s2", auth.SessionRevoked, mock.Anything).Return(nil).Once()
+
+	n, err := svc.RevokeOthers(context.Background(), "user1", "current")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	cache.AssertExpectations(t)
+}
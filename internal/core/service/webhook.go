@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+// WebhookWorkerConfig tunes the workers delivering webhooks.
+type WebhookWorkerConfig struct {
+	// Workers is the number of deliveries sent concurrently.
+	Workers int
+	// PollInterval is how long to wait when no deliveries are due.
+	PollInterval time.Duration
+	// Lease is how long a claimed delivery is hidden from other workers. It
+	// must exceed the sender's timeout, or a slow receiver is called twice.
+	Lease time.Duration
+	// MaxAttempts failed attempts give a delivery up.
+	MaxAttempts int
+	// Backoff spaces out attempts of a failing delivery.
+	Backoff resilience.Backoff
+}
+
+func (c *WebhookWorkerConfig) setDefaults() {
+	if c.Workers < 1 {
+		c.Workers = 4
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 8
+	}
+	if c.Backoff == (resilience.Backoff{}) {
+		c.Backoff = resilience.Backoff{Base: 10 * time.Second, Max: time.Hour}
+	}
+}
+
+// WebhookService manages webhook subscriptions and delivers favorite events
+// to them. It subscribes to the outbox relay, which only records a delivery
+// per interested subscription; the workers then post them, so a slow or
+// failing receiver holds up neither the relay nor the other receivers.
+type WebhookService struct {
+	repo   ports.WebhookRepository
+	sender ports.WebhookSender
+	logger *slog.Logger
+}
+
+// Ensure WebhookService implements ports.WebhookService and ports.EventHandler
+var (
+	_ ports.WebhookService = (*WebhookService)(nil)
+	_ ports.EventHandler   = (*WebhookService)(nil)
+)
+
+func NewWebhookService(repo ports.WebhookRepository, sender ports.WebhookSender, logger *slog.Logger) *WebhookService {
+	return &WebhookService{repo: repo, sender: sender, logger: logger}
+}
+
+// Create validates and stores a subscription for sub.UserID.
+func (s *WebhookService) Create(ctx context.Context, sub webhooks.Subscription) (webhooks.Subscription, error) {
+	sub.URL = strings.TrimSpace(sub.URL)
+	if err := sub.Validate(); err != nil {
+		return webhooks.Subscription{}, err
+	}
+	sub.ID = uuid.NewString()
+	sub.CreatedAt = time.Now()
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return webhooks.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, userID string) ([]webhooks.Subscription, error) {
+	return s.repo.ListSubscriptions(ctx, userID)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID, id string) error {
+	return s.repo.DeleteSubscription(ctx, userID, id)
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, userID, id string, limit int) ([]webhooks.Delivery, error) {
+	if _, err := s.repo.FindSubscription(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, limit)
+}
+
+// Test posts a ping right away. Whatever the receiver answers is recorded in
+// the delivery log and returned; only failing to record it is an error.
+func (s *WebhookService) Test(ctx context.Context, userID, id string) (webhooks.Delivery, error) {
+	sub, err := s.repo.FindSubscription(ctx, userID, id)
+	if err != nil {
+		return webhooks.Delivery{}, err
+	}
+	d, err := webhooks.NewPing(sub, uuid.NewString(), time.Now())
+	if err != nil {
+		return webhooks.Delivery{}, fmt.Errorf("failed to build ping: %w", err)
+	}
+	d.ID = uuid.NewString()
+	d.Attempts = 1
+
+	status, err := s.sender.Send(ctx, sub.URL, sub.Secret, d)
+	if err != nil {
+		d = d.Failed(status, err, time.Time{})
+	} else {
+		d = d.Succeeded(status, time.Now())
+	}
+	webhookDeliveries.WithLabelValues("ping", string(d.Status)).Inc()
+	if err := s.repo.AddDeliveries(ctx, d); err != nil {
+		return webhooks.Delivery{}, err
+	}
+	return d, nil
+}
+
+// Handle records a delivery of the event for each of its owner's
+// subscriptions to it. Recording is idempotent, so the relay may call it
+// again for the same event.
+func (s *WebhookService) Handle(ctx context.Context, event favorites.Event) error {
+	if event.UserID == "" {
+		return nil
+	}
+	subs, err := s.repo.SubscriptionsFor(ctx, event.UserID, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	deliveries := make([]webhooks.Delivery, 0, len(subs))
+	for _, sub := range subs {
+		d, err := webhooks.NewDelivery(sub, event, now)
+		if err != nil {
+			return fmt.Errorf("failed to build webhook payload: %w", err)
+		}
+		d.ID = uuid.NewString()
+		deliveries = append(deliveries, d)
+	}
+	return s.repo.AddDeliveries(ctx, deliveries...)
+}
+
+// RunDeliveryWorkers sends due deliveries until ctx is canceled. On
+// cancellation it stops claiming deliveries, finishes those it claimed, and
+// then returns.
+func (s *WebhookService) RunDeliveryWorkers(ctx context.Context, cfg WebhookWorkerConfig) {
+	cfg.setDefaults()
+
+	// In-flight deliveries must survive shutdown; the sender's timeout bounds them.
+	sendCtx := context.WithoutCancel(ctx)
+	jobs := make(chan ports.WebhookJob)
+	var wg sync.WaitGroup
+	for range cfg.Workers {
+		wg.Go(func() {
+			for job := range jobs {
+				s.deliver(sendCtx, job, cfg)
+			}
+		})
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+		s.logger.Info("webhook workers drained")
+	}()
+
+	for ctx.Err() == nil {
+		claimed, err := s.repo.ClaimDeliveries(ctx, cfg.Workers, cfg.Lease)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to claim webhook deliveries", "error", err)
+		}
+		for _, job := range claimed {
+			jobs <- job
+		}
+		if len(claimed) < cfg.Workers {
+			_ = resilience.Sleep(ctx, cfg.PollInterval)
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, job ports.WebhookJob, cfg WebhookWorkerConfig) {
+	d, sub := job.Delivery, job.Subscription
+	ctx, span := tracer.Start(ctx, "WebhookService.deliver", trace.WithAttributes(
+		attribute.String("webhook.id", sub.ID),
+		attribute.String("event.id", d.EventID),
+		attribute.String("event.type", string(d.EventType)),
+		attribute.Int("webhook.attempt", d.Attempts),
+	))
+	defer span.End()
+
+	status, err := s.sender.Send(ctx, sub.URL, sub.Secret, d)
+	switch {
+	case err == nil:
+		d = d.Succeeded(status, time.Now())
+	case d.Attempts >= cfg.MaxAttempts:
+		span.RecordError(err)
+		s.logger.Warn("webhook delivery failed too often, giving up", "webhook_id", sub.ID, "event_id", d.EventID, "attempts", d.Attempts, "status", status, "error", err)
+		d = d.Failed(status, err, time.Time{})
+	default:
+		span.RecordError(err)
+		at := time.Now().Add(cfg.Backoff.Delay(d.Attempts - 1))
+		s.logger.Info("webhook delivery failed, retrying", "webhook_id", sub.ID, "event_id", d.EventID, "attempt", d.Attempts, "status", status, "retry_at", at, "error", err)
+		d = d.Failed(status, err, at)
+	}
+	outcome := string(d.Status)
+	if d.Status == webhooks.DeliveryPending {
+		outcome = "retried"
+	}
+	webhookDeliveries.WithLabelValues("event", outcome).Inc()
+
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		// The lease expires and the delivery is sent again.
+		s.logger.Error("failed to record webhook delivery", "delivery_id", d.ID, "error", err)
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go-favorites-app/internal/core/domain/favorites"
+	"go-favorites-app/internal/core/domain/webhooks"
+	"go-favorites-app/internal/core/ports"
+	"go-favorites-app/internal/resilience"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, sub webhooks.Subscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FindSubscription(ctx context.Context, userID, id string) (webhooks.Subscription, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(webhooks.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context, userID string) ([]webhooks.Subscription, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]webhooks.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) SubscriptionsFor(ctx context.Context, userID string, t favorites.EventType) ([]webhooks.Subscription, error) {
+	args := m.Called(ctx, userID, t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]webhooks.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) AddDeliveries(ctx context.Context, deliveries ...webhooks.Delivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]ports.WebhookJob, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.WebhookJob), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, d webhooks.Delivery) error {
+	args := m.Called(ctx, d)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]webhooks.Delivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	return args.Get(0).([]webhooks.Delivery), args.Error(1)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, url, secret string, d webhooks.Delivery) (int, error) {
+	args := m.Called(ctx, url, secret, d)
+	return args.Int(0), args.Error(1)
+}
+
+func newWebhookTestService() (*WebhookService, *MockWebhookRepository, *MockWebhookSender) {
+	repo, sender := new(MockWebhookRepository), new(MockWebhookSender)
+	return NewWebhookService(repo, sender, slog.New(slog.NewTextHandler(io.Discard, nil))), repo, sender
+}
+
+var testSubscription = webhooks.Subscription{
+	ID:     "s1",
+	UserID: "u1",
+	URL:    "https://example.com/hooks",
+	Events: []favorites.EventType{favorites.EventCreated},
+	Secret: "0123456789abcdef",
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	t.Run("stores a valid subscription", func(t *testing.T) {
+		svc, repo, _ := newWebhookTestService()
+		repo.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(s webhooks.Subscription) bool {
+			return s.ID != "" && s.UserID == "u1" && s.URL == "https://example.com/hooks" && !s.CreatedAt.IsZero()
+		})).Return(nil).Once()
+
+		in := testSubscription
+		in.ID, in.URL = "", "  https://example.com/hooks "
+		sub, err := svc.Create(context.Background(), in)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, sub.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid subscription", func(t *testing.T) {
+		svc, repo, _ := newWebhookTestService()
+		in := testSubscription
+		in.Secret = "short"
+
+		_, err := svc.Create(context.Background(), in)
+
+		assert.ErrorIs(t, err, webhooks.ErrValidation)
+		repo.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebhookService_Deliveries(t *testing.T) {
+	svc, repo, _ := newWebhookTestService()
+	repo.On("FindSubscription", mock.Anything, "u2", "s1").Return(webhooks.Subscription{}, webhooks.ErrNotFound).Once()
+
+	_, err := svc.Deliveries(context.Background(), "u2", "s1", 20)
+
+	assert.ErrorIs(t, err, webhooks.ErrNotFound)
+	repo.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Handle(t *testing.T) {
+	event := favorites.Event{ID: "e1", Type: favorites.EventCreated, AssetID: "a1", UserID: "u1", OccurredAt: time.Now()}
+
+	t.Run("records a delivery per subscription", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		other := testSubscription
+		other.ID = "s2"
+		repo.On("SubscriptionsFor", mock.Anything, "u1", favorites.EventCreated).Return([]webhooks.Subscription{testSubscription, other}, nil).Once()
+		repo.On("AddDeliveries", mock.Anything, mock.MatchedBy(func(ds []webhooks.Delivery) bool {
+			return len(ds) == 2 && ds[0].SubscriptionID == "s1" && ds[1].SubscriptionID == "s2" &&
+				ds[0].EventID == "e1" && ds[0].ID != ds[1].ID && ds[0].Status == webhooks.DeliveryPending
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.Handle(context.Background(), event))
+		repo.AssertExpectations(t)
+		// Sending is left to the workers.
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("nothing to record without subscriptions", func(t *testing.T) {
+		svc, repo, _ := newWebhookTestService()
+		repo.On("SubscriptionsFor", mock.Anything, "u1", favorites.EventCreated).Return(nil, nil).Once()
+
+		assert.NoError(t, svc.Handle(context.Background(), event))
+		repo.AssertNotCalled(t, "AddDeliveries", mock.Anything, mock.Anything)
+	})
+
+	t.Run("storage errors are returned for the relay to retry", func(t *testing.T) {
+		svc, repo, _ := newWebhookTestService()
+		repo.On("SubscriptionsFor", mock.Anything, "u1", favorites.EventCreated).Return(nil, errors.New("db down")).Once()
+
+		assert.Error(t, svc.Handle(context.Background(), event))
+	})
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	cfg := WebhookWorkerConfig{MaxAttempts: 3, Backoff: resilience.Backoff{Base: time.Minute, Max: time.Hour}}
+	cfg.setDefaults()
+	d, err := webhooks.NewDelivery(testSubscription, favorites.Event{ID: "e1", Type: favorites.EventCreated, UserID: "u1"}, time.Now())
+	assert.NoError(t, err)
+	d.ID = "d1"
+
+	t.Run("2xx marks the delivery delivered", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		job := ports.WebhookJob{Delivery: d, Subscription: testSubscription}
+		job.Delivery.Attempts = 1
+		sender.On("Send", mock.Anything, testSubscription.URL, testSubscription.Secret, job.Delivery).Return(200, nil).Once()
+		repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d webhooks.Delivery) bool {
+			return d.Status == webhooks.DeliveryDelivered && d.ResponseStatus == 200 && !d.DeliveredAt.IsZero()
+		})).Return(nil).Once()
+
+		svc.deliver(context.Background(), job, cfg)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failure is retried with backoff", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		job := ports.WebhookJob{Delivery: d, Subscription: testSubscription}
+		job.Delivery.Attempts = 2
+		sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(503, errors.New("receiver returned status 503")).Once()
+		start := time.Now()
+		repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d webhooks.Delivery) bool {
+			// Second attempt: up to twice the base delay.
+			return d.Status == webhooks.DeliveryPending && d.ResponseStatus == 503 && d.LastError != "" &&
+				!d.NextAttemptAt.Before(start) && !d.NextAttemptAt.After(time.Now().Add(2*time.Minute))
+		})).Return(nil).Once()
+
+		svc.deliver(context.Background(), job, cfg)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("last attempt gives up", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		job := ports.WebhookJob{Delivery: d, Subscription: testSubscription}
+		job.Delivery.Attempts = 3
+		sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("connection refused")).Once()
+		repo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d webhooks.Delivery) bool {
+			return d.Status == webhooks.DeliveryFailed && d.NextAttemptAt.IsZero() && d.LastError == "connection refused"
+		})).Return(nil).Once()
+
+		svc.deliver(context.Background(), job, cfg)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestWebhookService_Test(t *testing.T) {
+	t.Run("logs the ping's outcome", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		repo.On("FindSubscription", mock.Anything, "u1", "s1").Return(testSubscription, nil).Once()
+		sender.On("Send", mock.Anything, testSubscription.URL, testSubscription.Secret, mock.MatchedBy(func(d webhooks.Delivery) bool {
+			return d.EventType == webhooks.EventPing && d.SubscriptionID == "s1"
+		})).Return(500, errors.New("receiver returned status 500")).Once()
+		repo.On("AddDeliveries", mock.Anything, mock.MatchedBy(func(ds []webhooks.Delivery) bool {
+			return len(ds) == 1 && ds[0].Status == webhooks.DeliveryFailed && ds[0].Attempts == 1
+		})).Return(nil).Once()
+
+		d, err := svc.Test(context.Background(), "u1", "s1")
+
+		assert.NoError(t, err, "a failing receiver is reported in the delivery")
+		assert.Equal(t, webhooks.DeliveryFailed, d.Status)
+		assert.Equal(t, 500, d.ResponseStatus)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unknown subscription", func(t *testing.T) {
+		svc, repo, sender := newWebhookTestService()
+		repo.On("FindSubscription", mock.Anything, "u1", "s9").Return(webhooks.Subscription{}, webhooks.ErrNotFound).Once()
+
+		_, err := svc.Test(context.Background(), "u1", "s9")
+
+		assert.ErrorIs(t, err, webhooks.ErrNotFound)
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
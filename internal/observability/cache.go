@@ -28,6 +28,12 @@ func (c *InstrumentedCache) Remove(ctx context.Context, id string) error {
 func (c *InstrumentedCache) Invalidate(ctx context.Context, id string) error {
 	return c.inner.Invalidate(ctx, id)
 }
+func (c *InstrumentedCache) AddBatch(ctx context.Context, assets map[string][]byte, score float64) error {
+	return c.inner.AddBatch(ctx, assets, score)
+}
+func (c *InstrumentedCache) RemoveBatch(ctx context.Context, ids []string) error {
+	return c.inner.RemoveBatch(ctx, ids)
+}
 func (c *InstrumentedCache) GetBatch(ctx context.Context, ids []string) (map[string][]byte, error) {
 	res, err := c.inner.GetBatch(ctx, ids)
 	if err == nil {
@@ -72,6 +72,15 @@ func (w *responseWriterSpy) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Flush and Unwrap keep streaming responses working behind the spy.
+func (w *responseWriterSpy) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriterSpy) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // StartDBStatsCollector starts a background goroutine to collect DB stats.
 func StartDBStatsCollector(dbPool *pgxpool.Pool) {
 	go func() {
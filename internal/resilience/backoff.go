@@ -0,0 +1,41 @@
+package resilience
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes "full jitter" retry delays: a random duration between zero
+// and min(Max, Base*2^attempt). Spreading retries out avoids synchronized
+// bursts against a dependency that is recovering.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the wait before retry number attempt (starting at 0).
+func (b Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.Max
+	if attempt < 32 {
+		if d := b.Base << attempt; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// Sleep waits for d or until ctx is done, returning ctx.Err() in the latter case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	var transitions []string
+	b.OnStateChange = func(from, to State) { transitions = append(transitions, from.String()+"->"+to.String()) }
+
+	// One failure is tolerated.
+	assert.NoError(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, StateClosed, b.State())
+
+	// The second consecutive failure opens the circuit.
+	assert.NoError(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+
+	// After the cooldown a single probe is let through.
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+
+	// A failed probe re-opens immediately.
+	b.Failure()
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := NewBreaker(2, time.Minute)
+
+	b.Failure()
+	b.Success()
+	b.Failure()
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Base: 100 * time.Millisecond, Max: time.Second}
+
+	for attempt := range 40 {
+		d := b.Delay(attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, time.Second)
+		if attempt == 0 {
+			assert.LessOrEqual(t, d, 100*time.Millisecond)
+		}
+	}
+}
+
+func TestSleep_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Sleep(ctx, time.Hour), context.Canceled)
+}
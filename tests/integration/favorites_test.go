@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"testing"
@@ -24,8 +25,8 @@ import (
 // NoOpEnricher mock
 type NoOpEnricher struct{}
 
-func (e *NoOpEnricher) Enrich(ctx context.Context, asset favorites.Asset) error {
-	return nil
+func (e *NoOpEnricher) Enrich(ctx context.Context, asset favorites.Asset) (favorites.Metadata, []favorites.StageResult, error) {
+	return favorites.Metadata{}, nil, nil
 }
 
 func TestFavoritesIntegration(t *testing.T) {
@@ -87,28 +88,8 @@ func TestFavoritesIntegration(t *testing.T) {
 	defer dbPool.Close()
 
 	// Init Schema
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password_hash VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	CREATE TABLE IF NOT EXISTS favorites (
-		id UUID PRIMARY KEY,
-		type VARCHAR(50) NOT NULL,
-		asset_data JSONB NOT NULL,
-		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-	CREATE INDEX IF NOT EXISTS idx_favorites_asset_data ON favorites USING GIN (asset_data);
-	CREATE INDEX IF NOT EXISTS idx_favorites_type ON favorites (type);
-	`
-	if _, err := dbPool.Exec(ctx, schema); err != nil {
-		t.Fatalf("failed to create schema: %v", err)
+	if err := repo.RunMigrations(ctx, dbPool, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
 	}
 
 	// Create a test user
@@ -122,7 +103,7 @@ func TestFavoritesIntegration(t *testing.T) {
 	repository := repo.NewRepository(dbPool)
 
 	// Clean up redis connection string for the adapter
-	// The adapter uses redis.NewClient(&redis.Options{Addr: addr}).
+	// The adapter expects plain host:port addresses.
 	// If redisConnStr starts with "redis://", we might need to strip it depending on go-redis version logic,
 	// but usually parsing is cleaner. For simplicity in this test environment:
 	redisUrl := redisConnStr
@@ -130,8 +111,9 @@ func TestFavoritesIntegration(t *testing.T) {
 		redisUrl = redisUrl[8:]
 	}
 
-	cache := adapter_redis.NewAdapter(redisUrl)
-	svc := service.NewService(repository, cache, &NoOpEnricher{}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	cache := adapter_redis.NewAdapter(adapter_redis.Options{Addrs: []string{redisUrl}})
+	queue := repo.NewEnrichmentQueue(dbPool)
+	svc := service.NewService(repository, cache, &NoOpEnricher{}, queue, slog.New(slog.NewTextHandler(os.Stdout, nil)))
 
 	// 4. Seed Data
 	totalAssets := 1000
@@ -204,4 +186,77 @@ func TestFavoritesIntegration(t *testing.T) {
 	} else {
 		t.Logf("Successfully retrieved all %d assets via iterator/paging", count)
 	}
+
+	// 6. Verify the enrichment queue holds one pending job per asset
+	t.Log("Verifying enrichment queue...")
+	var pending int
+	if err := dbPool.QueryRow(ctx, "SELECT COUNT(*) FROM enrichment_jobs WHERE status = 'pending'").Scan(&pending); err != nil {
+		t.Fatalf("failed to count jobs: %v", err)
+	}
+	if pending != totalAssets {
+		t.Errorf("Expected %d pending jobs, got %d", totalAssets, pending)
+	}
+
+	jobs, err := queue.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if len(jobs) != 10 {
+		t.Fatalf("Expected 10 claimed jobs, got %d", len(jobs))
+	}
+	again, err := queue.Claim(ctx, totalAssets, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if len(again) != totalAssets-10 {
+		t.Errorf("Leased jobs must not be claimed twice: expected %d, got %d", totalAssets-10, len(again))
+	}
+	for _, job := range jobs {
+		if err := queue.Complete(ctx, job); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+	}
+	if err := dbPool.QueryRow(ctx, "SELECT COUNT(*) FROM enrichment_jobs").Scan(&pending); err != nil {
+		t.Fatalf("failed to count jobs: %v", err)
+	}
+	if pending != totalAssets-10 {
+		t.Errorf("Expected %d jobs after completing 10, got %d", totalAssets-10, pending)
+	}
+
+	// 7. Verify stale assets are re-queued once, skipping those still pending
+	t.Log("Verifying re-enrichment...")
+	stats, err := queue.Stats(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Stale != 10 || stats.Pending != totalAssets-10 {
+		t.Errorf("Expected 10 stale and %d pending, got %+v", totalAssets-10, stats)
+	}
+	queued, err := queue.EnqueueStale(ctx, time.Now().Add(time.Minute), totalAssets)
+	if err != nil {
+		t.Fatalf("EnqueueStale failed: %v", err)
+	}
+	if queued != 10 {
+		t.Errorf("Expected 10 re-queued assets, got %d", queued)
+	}
+	if queued, _ = queue.EnqueueStale(ctx, time.Now().Add(time.Minute), totalAssets); queued != 0 {
+		t.Errorf("Expected no assets re-queued twice, got %d", queued)
+	}
+
+	// 8. Verify only one replica holds the re-enrichment lock
+	leader := repo.NewAdvisoryLock(dbPool, "favorites:reenrichment")
+	follower := repo.NewAdvisoryLock(dbPool, "favorites:reenrichment")
+	if ok, err := leader.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("leader failed to acquire lock: %v", err)
+	}
+	if ok, err := follower.TryAcquire(ctx); err != nil || ok {
+		t.Fatalf("follower must not acquire a held lock (ok=%v, err=%v)", ok, err)
+	}
+	if err := leader.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if ok, err := follower.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("follower failed to take over released lock: %v", err)
+	}
+	_ = follower.Release(ctx)
 }
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 
 	"go-favorites-app/internal/adapter/api/rest"
 	adapter_redis "go-favorites-app/internal/adapter/cache/redis"
+	"go-favorites-app/internal/adapter/mailer/memory"
 	repo "go-favorites-app/internal/adapter/storage/postgres"
 	"go-favorites-app/internal/core/domain/favorites"
 	"go-favorites-app/internal/core/service"
@@ -86,28 +88,8 @@ func TestHTTPIntegration(t *testing.T) {
 	defer dbPool.Close()
 
 	// Init Schema
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password_hash VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-
-	CREATE TABLE IF NOT EXISTS favorites (
-		id UUID PRIMARY KEY,
-		type VARCHAR(50) NOT NULL,
-		asset_data JSONB NOT NULL,
-		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-	CREATE INDEX IF NOT EXISTS idx_favorites_asset_data ON favorites USING GIN (asset_data);
-	CREATE INDEX IF NOT EXISTS idx_favorites_type ON favorites (type);
-	`
-	if _, err := dbPool.Exec(ctx, schema); err != nil {
-		t.Fatalf("failed to create schema: %v", err)
+	if err := repo.RunMigrations(ctx, dbPool, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
 	}
 
 	// --- 2. Application Wiring ---
@@ -117,24 +99,34 @@ func TestHTTPIntegration(t *testing.T) {
 	if len(redisUrl) > 8 && redisUrl[:8] == "redis://" {
 		redisUrl = redisUrl[8:]
 	}
-	cache := adapter_redis.NewAdapter(redisUrl)
+	cache := adapter_redis.NewAdapter(adapter_redis.Options{Addrs: []string{redisUrl}})
 
 	// User Service
 	userRepo := repo.NewUserRepository(dbPool)
+	sessionRepo := repo.NewSessionRepository(dbPool)
 	jwtSecret := "test-secret"
-	authService := service.NewAuthService(userRepo, jwtSecret)
+	mailer := memory.NewMailer(nil)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	authService := service.NewAuthService(userRepo, sessionRepo, mailer, service.AuthConfig{JWTSecret: jwtSecret, BaseURL: "http://favorites.test"}).
+		WithSessionCache(cache.Sessions(), logger)
 
 	// Favorite Service
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	favRepo := repo.NewRepository(dbPool)
-	favService := service.NewService(favRepo, cache, &NoOpEnricher{}, logger)
+	favService := service.NewService(favRepo, cache, &NoOpEnricher{}, repo.NewEnrichmentQueue(dbPool), logger)
 
 	// Handlers
 	authHandler := rest.NewAuthHandler(authService)
 	favHandler := rest.NewHandler(favService, logger)
+	accountHandler := rest.NewAccountHandler(service.NewAccountService(userRepo, favRepo, repo.NewAccountStore(dbPool), cache, logger).WithSessionCache(cache.Sessions()), authService, logger)
+	sessionHandler := rest.NewSessionHandler(service.NewSessionService(sessionRepo, cache.Sessions(), logger), logger)
 
 	// Router
-	handler := rest.NewRouter(favHandler, authHandler, jwtSecret)
+	handler := rest.NewRouter(rest.Handlers{
+		Favorites: favHandler,
+		Auth:      authHandler,
+		Account:   accountHandler,
+		Sessions:  sessionHandler,
+	}, jwtSecret)
 	server := httptest.NewServer(handler)
 	defer server.Close()
 
@@ -239,8 +231,8 @@ func TestHTTPIntegration(t *testing.T) {
 	}
 
 	t.Run("Multi-tenant Isolation", func(t *testing.T) {
-		tokenA := authenticate("userA@example.com", "passA")
-		tokenB := authenticate("userB@example.com", "passB")
+		tokenA := authenticate("userA@example.com", "passwordA")
+		tokenB := authenticate("userB@example.com", "passwordB")
 
 		// User A creates 2 assets
 		createAsset(tokenA, "Asset A1")
@@ -302,4 +294,273 @@ func TestHTTPIntegration(t *testing.T) {
 			t.Errorf("Expected 401 with bad token, got %d", resp.StatusCode)
 		}
 	})
+
+	t.Run("Password Reset and Email Verification", func(t *testing.T) {
+		email := "reset@example.com"
+		stolen := authenticate(email, "oldPassword")
+
+		// Verification link was mailed on signup
+		msg, ok := mailer.Last(email)
+		if !ok {
+			t.Fatal("expected verification email")
+		}
+		link := msg.Body[strings.Index(msg.Body, "http://favorites.test"):]
+		link = strings.TrimSpace(strings.TrimPrefix(link, "http://favorites.test"))
+		resp, err := client.Get(server.URL + link)
+		if err != nil {
+			t.Fatalf("Verify request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204 on verify, got %d", resp.StatusCode)
+		}
+
+		// Request reset
+		resp, err = client.Post(server.URL+"/password/forgot", "application/json", bytes.NewBufferString(fmt.Sprintf(`{"email":"%s"}`, email)))
+		if err != nil {
+			t.Fatalf("Forgot request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202 on forgot, got %d", resp.StatusCode)
+		}
+		msg, _ = mailer.Last(email)
+		lines := strings.Split(strings.TrimSpace(msg.Body), "\n")
+		token := lines[len(lines)-1]
+
+		resetBody := fmt.Sprintf(`{"token":"%s","password":"newPassword"}`, token)
+		resp, err = client.Post(server.URL+"/password/reset", "application/json", bytes.NewBufferString(resetBody))
+		if err != nil {
+			t.Fatalf("Reset request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204 on reset, got %d", resp.StatusCode)
+		}
+
+		// Sessions started before the reset are over
+		req, _ := http.NewRequest("GET", server.URL+"/favorites", nil)
+		req.Header.Set("Authorization", "Bearer "+stolen)
+		resp, err = client.Do(req)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with a token from before the reset, got %d", resp.StatusCode)
+		}
+
+		// Token is single-use
+		resp, err = client.Post(server.URL+"/password/reset", "application/json", bytes.NewBufferString(resetBody))
+		if err != nil {
+			t.Fatalf("Reset request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 on token reuse, got %d", resp.StatusCode)
+		}
+
+		resp, err = client.Post(server.URL+"/login", "application/json", bytes.NewBufferString(fmt.Sprintf(`{"email":"%s","password":"newPassword"}`, email)))
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected login with new password to succeed, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Signup Validation", func(t *testing.T) {
+		cases := []struct {
+			body string
+			want int
+		}{
+			{`{"email":"", "password":"password123"}`, http.StatusBadRequest},
+			{`{"email":"nope", "password":"password123"}`, http.StatusBadRequest},
+			{`{"email":"short@example.com", "password":"x"}`, http.StatusBadRequest},
+			{`{"email":"Case@Example.com", "password":"password123"}`, http.StatusCreated},
+			{`{"email":"case@example.COM", "password":"password123"}`, http.StatusConflict},
+		}
+		for _, tc := range cases {
+			resp, err := client.Post(server.URL+"/signup", "application/json", bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatalf("SignUp failed: %v", err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("SignUp %s: expected %d, got %d", tc.body, tc.want, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Current User Profile", func(t *testing.T) {
+		token := authenticate("profile@example.com", "profilePass1")
+		createAsset(token, "Profile Asset")
+
+		do := func(method, path, body string) *http.Response {
+			req, _ := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s failed: %v", method, path, err)
+			}
+			return resp
+		}
+
+		resp := do("PATCH", "/me", `{"display_name":"Profile User","preferences":{"theme":"dark"}}`)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 on PATCH /me, got %d", resp.StatusCode)
+		}
+
+		resp = do("GET", "/me", "")
+		defer resp.Body.Close()
+		var me struct {
+			Email         string         `json:"email"`
+			DisplayName   string         `json:"display_name"`
+			Preferences   map[string]any `json:"preferences"`
+			Roles         []string       `json:"roles"`
+			FavoriteCount int            `json:"favorite_count"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&me); err != nil {
+			t.Fatalf("Failed to decode /me: %v", err)
+		}
+		if me.Email != "profile@example.com" || me.DisplayName != "Profile User" || me.FavoriteCount != 1 {
+			t.Errorf("Unexpected profile: %+v", me)
+		}
+		if me.Preferences["theme"] != "dark" || len(me.Roles) != 1 || me.Roles[0] != "user" {
+			t.Errorf("Unexpected preferences or roles: %+v", me)
+		}
+
+		resp = do("POST", "/me/password", `{"current_password":"wrong","new_password":"profilePass2"}`)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 with wrong current password, got %d", resp.StatusCode)
+		}
+		resp = do("POST", "/me/password", `{"current_password":"profilePass1","new_password":"profilePass2"}`)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204 on password change, got %d", resp.StatusCode)
+		}
+
+		resp = do("POST", "/me/email", `{"email":"profile2@example.com","password":"profilePass2"}`)
+		if resp.StatusCode != http.StatusAccepted {
+			t.Errorf("Expected 202 on email change, got %d", resp.StatusCode)
+		}
+		if _, ok := mailer.Last("profile2@example.com"); !ok {
+			t.Error("Expected verification email for the new address")
+		}
+	})
+
+	t.Run("Account Export and Deletion", func(t *testing.T) {
+		token := authenticate("gdpr@example.com", "gdprPass1")
+		createAsset(token, "Export Asset")
+
+		do := func(method, path, body string) *http.Response {
+			req, _ := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s failed: %v", method, path, err)
+			}
+			return resp
+		}
+
+		resp := do("GET", "/me/export", "")
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 on export, got %d", resp.StatusCode)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != 2 || !strings.Contains(lines[0], `"kind":"user"`) || !strings.Contains(lines[1], "Export Asset") {
+			t.Errorf("Unexpected export: %s", data)
+		}
+
+		resp = do("DELETE", "/me", `{"password":"wrong"}`)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403 with wrong password, got %d", resp.StatusCode)
+		}
+		resp = do("DELETE", "/me", `{"password":"gdprPass1"}`)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204 on account deletion, got %d", resp.StatusCode)
+		}
+
+		var remaining int
+		if err := dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email = 'gdpr@example.com'`).Scan(&remaining); err != nil {
+			t.Fatalf("Failed to count users: %v", err)
+		}
+		if remaining != 0 {
+			t.Error("Expected user row to be deleted")
+		}
+		resp = do("GET", "/me", "")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected the deleted account's token to be rejected, got %d", resp.StatusCode)
+		}
+
+		loginBody, _ := json.Marshal(map[string]string{"email": "gdpr@example.com", "password": "gdprPass1"})
+		resp, _ = client.Post(server.URL+"/login", "application/json", bytes.NewBuffer(loginBody))
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected login to fail after deletion, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Session Management", func(t *testing.T) {
+		laptop := authenticate("sessions@example.com", "sessionPass1")
+
+		login := func() string {
+			body := `{"email":"sessions@example.com","password":"sessionPass1"}`
+			resp, err := client.Post(server.URL+"/login", "application/json", bytes.NewBufferString(body))
+			if err != nil {
+				t.Fatalf("Login failed: %v", err)
+			}
+			defer resp.Body.Close()
+			var res map[string]string
+			_ = json.NewDecoder(resp.Body).Decode(&res)
+			return res["token"]
+		}
+		phone, tablet := login(), login()
+
+		do := func(token, method, path string) *http.Response {
+			req, _ := http.NewRequest(method, server.URL+path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s failed: %v", method, path, err)
+			}
+			return resp
+		}
+
+		resp := do(laptop, "GET", "/me/sessions")
+		var sessions []struct {
+			ID      string `json:"id"`
+			Current bool   `json:"current"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&sessions)
+		resp.Body.Close()
+		if len(sessions) != 3 {
+			t.Fatalf("Expected 3 sessions, got %d", len(sessions))
+		}
+
+		// Log out the phone from the laptop.
+		resp = do(phone, "GET", "/me/sessions")
+		var phoneView []struct {
+			ID      string `json:"id"`
+			Current bool   `json:"current"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&phoneView)
+		resp.Body.Close()
+		var phoneID string
+		for _, s := range phoneView {
+			if s.Current {
+				phoneID = s.ID
+			}
+		}
+		if resp := do(laptop, "DELETE", "/me/sessions/"+phoneID); resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected 204 revoking session, got %d", resp.StatusCode)
+		}
+		if resp := do(phone, "GET", "/favorites"); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected revoked token to be rejected, got %d", resp.StatusCode)
+		}
+
+		if resp := do(laptop, "POST", "/me/sessions/revoke-others"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200 revoking other sessions, got %d", resp.StatusCode)
+		}
+		if resp := do(tablet, "GET", "/favorites"); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected tablet token to be rejected, got %d", resp.StatusCode)
+		}
+		if resp := do(laptop, "GET", "/favorites"); resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected current session to stay valid, got %d", resp.StatusCode)
+		}
+	})
 }
@@ -2,6 +2,9 @@ package integration
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log/slog"
 	"testing"
 	"time"
 
@@ -11,7 +14,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 	"golang.org/x/crypto/bcrypt"
 
+	"go-favorites-app/internal/adapter/mailer/memory"
 	repo "go-favorites-app/internal/adapter/storage/postgres"
+	"go-favorites-app/internal/core/domain/auth"
 	"go-favorites-app/internal/core/service"
 )
 
@@ -55,22 +60,13 @@ func TestUserIntegration(t *testing.T) {
 	defer dbPool.Close()
 
 	// 3. Init Schema
-	schema := `
-	CREATE TABLE IF NOT EXISTS users (
-		id UUID PRIMARY KEY,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password_hash VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-	);
-	`
-	if _, err := dbPool.Exec(ctx, schema); err != nil {
-		t.Fatalf("failed to create schema: %v", err)
+	if err := repo.RunMigrations(ctx, dbPool, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
 	}
 
 	// 4. Initialize Service
 	userRepo := repo.NewUserRepository(dbPool)
-	authService := service.NewAuthService(userRepo, "test-secret")
+	authService := service.NewAuthService(userRepo, repo.NewSessionRepository(dbPool), memory.NewMailer(nil), service.AuthConfig{JWTSecret: "test-secret"})
 
 	// 5. Test Scenarios
 	t.Run("SignUp Success", func(t *testing.T) {
@@ -112,8 +108,14 @@ func TestUserIntegration(t *testing.T) {
 
 		// Second creation
 		err = authService.SignUp(ctx, email, password)
-		if err == nil {
-			t.Fatal("expected error on duplicate email, got nil")
+		if !errors.Is(err, auth.ErrEmailTaken) {
+			t.Fatalf("expected ErrEmailTaken on duplicate email, got %v", err)
+		}
+
+		// Uniqueness is case-insensitive
+		err = authService.SignUp(ctx, "Duplicate@Example.com", password)
+		if !errors.Is(err, auth.ErrEmailTaken) {
+			t.Fatalf("expected ErrEmailTaken on case-variant email, got %v", err)
 		}
 	})
 
@@ -126,7 +128,7 @@ func TestUserIntegration(t *testing.T) {
 			t.Fatalf("signup failed: %v", err)
 		}
 
-		token, err := authService.Login(ctx, email, password)
+		token, err := authService.Login(ctx, email, password, auth.ClientInfo{})
 		if err != nil {
 			t.Fatalf("login failed: %v", err)
 		}
@@ -143,14 +145,14 @@ func TestUserIntegration(t *testing.T) {
 			t.Fatalf("signup failed: %v", err)
 		}
 
-		_, err := authService.Login(ctx, email, "wrongPass")
+		_, err := authService.Login(ctx, email, "wrongPass", auth.ClientInfo{})
 		if err == nil {
 			t.Fatal("expected error on wrong password, got nil")
 		}
 	})
 
 	t.Run("Login Failure - Non-existent User", func(t *testing.T) {
-		_, err := authService.Login(ctx, "ghost@example.com", "password")
+		_, err := authService.Login(ctx, "ghost@example.com", "password", auth.ClientInfo{})
 		if err == nil {
 			t.Fatal("expected error on missing user, got nil")
 		}